@@ -0,0 +1,151 @@
+// breach-filter 从HIBP导出的SHA-1密码库构建布隆过滤器，供 breach.source 为 bloom 时使用
+//
+//	go run ./cmd/breach-filter -input pwned-passwords-sha1-ordered-by-hash-v8.txt -output ./data/breach.bloom
+//
+// 输入文件每行一个 HASH:COUNT；也可以是k-anonymity前缀目录，每个 <PREFIX>.txt 文件的行格式为 SUFFIX:COUNT。
+// 完整密码库约有十亿条记录，误判率0.001时过滤器约1.8GB，可以提高 -min-count 只收录常见的泄露密码以减小体积。
+package main
+
+import (
+	"bufio"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"authcenter/pkg/breach"
+)
+
+func main() {
+	input := flag.String("input", "", "HIBP SHA-1 dump file or prefix directory")
+	output := flag.String("output", "./data/breach.bloom", "bloom filter output file")
+	falsePositiveRate := flag.Float64("fp-rate", 0.001, "target false positive rate")
+	minCount := flag.Int("min-count", 1, "skip hashes seen fewer times than this")
+	flag.Parse()
+
+	if *input == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, err := inputFiles(*input)
+	if err != nil {
+		log.Fatalf("Failed to read input: %v", err)
+	}
+
+	// 第一遍统计条目数以确定过滤器大小
+	var total uint64
+	if err := scan(files, *minCount, func([20]byte) { total++ }); err != nil {
+		log.Fatalf("Failed to scan input: %v", err)
+	}
+	log.Printf("Found %d hashes with count >= %d", total, *minCount)
+
+	filter, err := breach.NewBloomFilter(total, *falsePositiveRate)
+	if err != nil {
+		log.Fatalf("Failed to create bloom filter: %v", err)
+	}
+	log.Printf("Building bloom filter of %.1f MB", float64(filter.SizeBytes())/(1<<20))
+
+	if err := scan(files, *minCount, filter.Add); err != nil {
+		log.Fatalf("Failed to scan input: %v", err)
+	}
+
+	if err := write(*output, filter); err != nil {
+		log.Fatalf("Failed to write bloom filter: %v", err)
+	}
+	log.Printf("Wrote %d hashes to %s", filter.Count(), *output)
+}
+
+// inputFiles 返回需要读取的文件，目录按文件名排序并记录各文件的前缀
+func inputFiles(path string) ([]inputFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []inputFile{{path: path}}, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(path, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]inputFile, 0, len(matches))
+	for _, match := range matches {
+		files = append(files, inputFile{
+			path:   match,
+			prefix: strings.TrimSuffix(filepath.Base(match), ".txt"),
+		})
+	}
+	return files, nil
+}
+
+// inputFile 输入文件，prefix非空时每行只有摘要后缀
+type inputFile struct {
+	path   string
+	prefix string
+}
+
+// scan 逐行解析输入文件，对出现次数不低于minCount的摘要调用fn
+func scan(files []inputFile, minCount int, fn func([20]byte)) error {
+	for _, input := range files {
+		if err := scanFile(input, minCount, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanFile 逐行解析单个输入文件
+func scanFile(input inputFile, minCount int, fn func([20]byte)) error {
+	file, err := os.Open(input.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hash, count, err := breach.ParseLine(input.prefix + line)
+		if err != nil {
+			return err
+		}
+		if count >= minCount {
+			fn(hash)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// write 先写入临时文件再重命名，避免服务读到写了一半的过滤器
+func write(path string, filter *breach.BloomFilter) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := filter.WriteTo(file); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -36,7 +36,10 @@ func main() {
 	gin.SetMode(cfg.Server.Mode)
 
 	// 初始化路由
-	r := router.Setup(db, cfg)
+	r, err := router.Setup(db, cfg)
+	if err != nil {
+		log.Fatalf("Failed to setup router: %v", err)
+	}
 
 	// 启动服务器
 	logger.Info("Starting server on port %s", cfg.Server.Port)
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"authcenter/internal/auth/service"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessTokenHandler 个人访问令牌处理器
+type AccessTokenHandler struct {
+	accessTokenService service.AccessTokenService
+}
+
+// NewAccessTokenHandler 创建个人访问令牌处理器
+func NewAccessTokenHandler(accessTokenService service.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{
+		accessTokenService: accessTokenService,
+	}
+}
+
+// ListAccessTokens 获取当前用户的个人访问令牌
+func (h *AccessTokenHandler) ListAccessTokens(c *gin.Context) {
+	tokens, err := h.accessTokenService.List(c, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "获取访问令牌失败", err.Error())
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// CreateAccessToken 创建个人访问令牌，令牌明文只在此返回一次
+func (h *AccessTokenHandler) CreateAccessToken(c *gin.Context) {
+	var req service.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	value, _ := c.Get("claims")
+	claims, ok := value.(*jwt.Claims)
+	if !ok {
+		response.Error(c, http.StatusUnauthorized, "缺少认证信息", "")
+		return
+	}
+
+	token, err := h.accessTokenService.Create(c, claims, &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "创建访问令牌失败", err.Error())
+		return
+	}
+
+	response.Success(c, token)
+}
+
+// RevokeAccessToken 吊销当前用户的个人访问令牌
+func (h *AccessTokenHandler) RevokeAccessToken(c *gin.Context) {
+	if err := h.accessTokenService.Revoke(c, c.GetString("user_id"), c.Param("id")); err != nil {
+		response.Error(c, http.StatusNotFound, "吊销访问令牌失败", err.Error())
+		return
+	}
+
+	response.Success(c, "吊销成功")
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"authcenter/internal/auth/service"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/password"
 	"authcenter/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +27,7 @@ func NewAuthHandler(authService service.AuthService) *AuthHandler {
 // RefreshTokenRequest 刷新Token请求
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
+	Tenant       string `json:"tenant,omitempty"` // 切换到的租户标识，为空时沿用当前租户
 }
 
 // Register 用户注册
@@ -36,13 +40,29 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	user, err := h.authService.Register(c, &req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "注册失败", err.Error())
+		passwordError(c, http.StatusInternalServerError, "注册失败", err)
 		return
 	}
 
 	response.Success(c, user)
 }
 
+// SendSMSCode 发送短信验证码
+func (h *AuthHandler) SendSMSCode(c *gin.Context) {
+	var req service.SendSMSCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.authService.SendSMSCode(c, &req); err != nil {
+		response.Error(c, http.StatusBadRequest, "发送验证码失败", err.Error())
+		return
+	}
+
+	response.Success(c, "验证码已发送")
+}
+
 // Login 用户登录
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req service.LoginRequest
@@ -51,13 +71,35 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	tokenData, err := h.authService.Login(c, &req)
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	result, err := h.authService.Login(c, &req)
 	if err != nil {
 		response.Error(c, http.StatusUnauthorized, "登录失败", err.Error())
 		return
 	}
 
-	response.Success(c, tokenData)
+	response.Success(c, result)
+}
+
+// VerifyMFA 多因素认证登录第二步
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req service.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	result, err := h.authService.VerifyMFA(c, &req)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "登录失败", err.Error())
+		return
+	}
+
+	response.Success(c, result)
 }
 
 // RefreshToken 刷新Token
@@ -79,7 +121,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	tokenData, err := h.authService.RefreshToken(c, req.RefreshToken)
+	tokenData, err := h.authService.RefreshToken(c, req.RefreshToken, req.Tenant)
 	if err != nil {
 		response.Error(c, http.StatusUnauthorized, "Token刷新失败", err.Error())
 		return
@@ -105,7 +147,7 @@ func (h *AuthHandler) VerifyToken(c *gin.Context) {
 	response.Success(c, result)
 }
 
-// Logout 用户登出
+// Logout 用户登出，默认只退出当前会话，all=true时退出全部会话
 func (h *AuthHandler) Logout(c *gin.Context) {
 	token := c.GetHeader("Authorization")
 	if token == "" {
@@ -118,7 +160,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		token = token[7:]
 	}
 
-	err := h.authService.Logout(c, token)
+	err := h.authService.Logout(c, token, c.Query("all") == "true")
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, "登出失败", err.Error())
 		return
@@ -126,3 +168,189 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 	response.Success(c, "登出成功")
 }
+
+// VerifyEmailRequest 验证邮箱请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPassword 发送重置密码邮件
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req service.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+	req.IP = c.ClientIP()
+
+	if err := h.authService.ForgotPassword(c, &req); err != nil {
+		response.Error(c, http.StatusInternalServerError, "发送重置邮件失败", err.Error())
+		return
+	}
+
+	response.Success(c, "如果该邮箱已注册，重置密码邮件已发送")
+}
+
+// ResetPassword 重置密码
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+	req.IP = c.ClientIP()
+
+	if err := h.authService.ResetPassword(c, &req); err != nil {
+		passwordError(c, http.StatusBadRequest, "重置密码失败", err)
+		return
+	}
+
+	response.Success(c, "密码已重置，请重新登录")
+}
+
+// ChangePassword 修改当前用户的密码
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req service.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+	req.IP = c.ClientIP()
+
+	if err := h.authService.ChangePassword(c, c.GetString("user_id"), &req); err != nil {
+		passwordError(c, http.StatusBadRequest, "修改密码失败", err)
+		return
+	}
+
+	response.Success(c, "密码已修改，请重新登录")
+}
+
+// ChangeExpiredPassword 登录时修改过期密码
+func (h *AuthHandler) ChangeExpiredPassword(c *gin.Context) {
+	var req service.ChangeExpiredPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	tokenData, err := h.authService.ChangeExpiredPassword(c, &req)
+	if err != nil {
+		passwordError(c, http.StatusUnauthorized, "修改密码失败", err)
+		return
+	}
+
+	response.Success(c, tokenData)
+}
+
+// VerifyEmail 验证邮箱
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.authService.VerifyEmail(c, req.Token); err != nil {
+		response.Error(c, http.StatusBadRequest, "验证邮箱失败", err.Error())
+		return
+	}
+
+	response.Success(c, "邮箱验证成功")
+}
+
+// ResendEmailVerification 重新发送验证邮件
+func (h *AuthHandler) ResendEmailVerification(c *gin.Context) {
+	if err := h.authService.ResendEmailVerification(c, c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "发送验证邮件失败", err.Error())
+		return
+	}
+
+	response.Success(c, "验证邮件已发送")
+}
+
+// ChangeEmail 修改当前用户的邮箱
+func (h *AuthHandler) ChangeEmail(c *gin.Context) {
+	var req service.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.authService.ChangeEmail(c, c.GetString("user_id"), &req); err != nil {
+		response.Error(c, http.StatusBadRequest, "修改邮箱失败", err.Error())
+		return
+	}
+
+	response.Success(c, "邮箱已修改，请查收验证邮件")
+}
+
+// ListSessions 列出当前用户的登录会话
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.authService.ListSessions(c, c.GetString("user_id"), currentSessionID(c))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取会话列表失败", err.Error())
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// RevokeSession 吊销当前用户的指定会话
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	if err := h.authService.RevokeSession(c, c.GetString("user_id"), c.Param("id")); err != nil {
+		response.Error(c, http.StatusNotFound, "吊销会话失败", err.Error())
+		return
+	}
+
+	response.Success(c, "会话已吊销")
+}
+
+// RevokeOtherSessions 吊销当前会话以外的全部会话
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	count, err := h.authService.RevokeOtherSessions(c, c.GetString("user_id"), currentSessionID(c))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "吊销会话失败", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"revoked": count})
+}
+
+// SetActiveRoles 设置当前会话激活的角色
+func (h *AuthHandler) SetActiveRoles(c *gin.Context) {
+	var req service.SetActiveRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.authService.SetActiveRoles(c, c.GetString("user_id"), currentSessionID(c), &req); err != nil {
+		response.Error(c, http.StatusBadRequest, "设置激活角色失败", err.Error())
+		return
+	}
+
+	response.Success(c, "激活角色已更新，请刷新Token")
+}
+
+// currentSessionID 当前访问令牌所属的会话ID
+func currentSessionID(c *gin.Context) string {
+	if value, exists := c.Get("claims"); exists {
+		if claims, ok := value.(*jwt.Claims); ok {
+			return claims.SessionID
+		}
+	}
+	return ""
+}
+
+// passwordError 密码不符合策略时返回400和违反的策略项，其他错误使用指定的状态码
+func passwordError(c *gin.Context, status int, message string, err error) {
+	var policyErr *password.PolicyError
+	if errors.As(err, &policyErr) {
+		response.ErrorWithDetails(c, http.StatusBadRequest, message, err.Error(), policyErr.Violations)
+		return
+	}
+
+	response.Error(c, status, message, err.Error())
+}
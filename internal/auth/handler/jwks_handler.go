@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"authcenter/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler 公钥集处理器
+type JWKSHandler struct {
+	jwtManager jwt.Manager
+}
+
+// NewJWKSHandler 创建公钥集处理器
+func NewJWKSHandler(jwtManager jwt.Manager) *JWKSHandler {
+	return &JWKSHandler{
+		jwtManager: jwtManager,
+	}
+}
+
+// GetJWKS 获取验证Token的公钥集
+// 按RFC 7517格式直接输出，不使用统一响应包装，便于下游服务离线验证Token
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
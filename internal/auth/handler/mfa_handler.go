@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+
+	"authcenter/internal/auth/service"
+	"authcenter/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFAHandler 多因素认证处理器
+type MFAHandler struct {
+	mfaService service.MFAService
+}
+
+// NewMFAHandler 创建多因素认证处理器
+func NewMFAHandler(mfaService service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// MFACodeRequest 携带TOTP验证码的请求
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// BeginTOTPEnrollment 生成TOTP密钥和otpauth地址
+func (h *MFAHandler) BeginTOTPEnrollment(c *gin.Context) {
+	enrollment, err := h.mfaService.BeginTOTPEnrollment(c, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "生成TOTP密钥失败", err.Error())
+		return
+	}
+
+	response.Success(c, enrollment)
+}
+
+// ConfirmTOTPEnrollment 确认TOTP注册，返回恢复码
+func (h *MFAHandler) ConfirmTOTPEnrollment(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTPEnrollment(c, c.GetString("user_id"), req.Code)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "启用多因素认证失败", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c, c.GetString("user_id"), req.Code)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "生成恢复码失败", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// DisableMFA 停用多因素认证
+func (h *MFAHandler) DisableMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.mfaService.DisableMFA(c, c.GetString("user_id"), req.Code); err != nil {
+		response.Error(c, http.StatusBadRequest, "停用多因素认证失败", err.Error())
+		return
+	}
+
+	response.Success(c, "停用成功")
+}
+
+// ResetUserMFA 管理员重置用户的多因素认证
+func (h *MFAHandler) ResetUserMFA(c *gin.Context) {
+	if err := h.mfaService.ResetMFA(c, c.Param("id"), c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "重置多因素认证失败", err.Error())
+		return
+	}
+
+	response.Success(c, "重置成功")
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"authcenter/internal/auth/service"
+	"authcenter/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasskeyHandler 通行密钥处理器
+type PasskeyHandler struct {
+	passkeyService service.PasskeyService
+}
+
+// NewPasskeyHandler 创建通行密钥处理器
+func NewPasskeyHandler(passkeyService service.PasskeyService) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: passkeyService,
+	}
+}
+
+// FinishPasskeyRegistrationRequest 完成通行密钥注册请求
+type FinishPasskeyRegistrationRequest struct {
+	SessionID  string          `json:"session_id" binding:"required"`
+	Name       string          `json:"name,omitempty"`
+	Credential json.RawMessage `json:"credential" binding:"required"` // navigator.credentials.create返回的凭证
+}
+
+// BeginLogin 开始通行密钥登录，完成后以type=passkey调用登录接口
+func (h *PasskeyHandler) BeginLogin(c *gin.Context) {
+	challenge, err := h.passkeyService.BeginLogin(c)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "生成登录挑战失败", err.Error())
+		return
+	}
+
+	response.Success(c, challenge)
+}
+
+// BeginRegistration 开始注册通行密钥
+func (h *PasskeyHandler) BeginRegistration(c *gin.Context) {
+	challenge, err := h.passkeyService.BeginRegistration(c, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "生成注册挑战失败", err.Error())
+		return
+	}
+
+	response.Success(c, challenge)
+}
+
+// FinishRegistration 完成通行密钥注册
+func (h *PasskeyHandler) FinishRegistration(c *gin.Context) {
+	var req FinishPasskeyRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	passkey, err := h.passkeyService.FinishRegistration(c, c.GetString("user_id"), req.SessionID, req.Name, req.Credential)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "注册通行密钥失败", err.Error())
+		return
+	}
+
+	response.Success(c, passkey)
+}
+
+// ListPasskeys 获取当前用户的通行密钥
+func (h *PasskeyHandler) ListPasskeys(c *gin.Context) {
+	passkeys, err := h.passkeyService.ListPasskeys(c, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "获取通行密钥失败", err.Error())
+		return
+	}
+
+	response.Success(c, passkeys)
+}
+
+// RemovePasskey 删除当前用户的通行密钥
+func (h *PasskeyHandler) RemovePasskey(c *gin.Context) {
+	if err := h.passkeyService.RemovePasskey(c, c.GetString("user_id"), c.Param("id")); err != nil {
+		response.Error(c, http.StatusNotFound, "删除通行密钥失败", err.Error())
+		return
+	}
+
+	response.Success(c, "删除成功")
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AccessTokenRepository 个人访问令牌数据访问接口
+type AccessTokenRepository interface {
+	// Create 保存令牌
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+
+	// GetByHash 通过令牌摘要获取令牌
+	GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+
+	// ListByUser 获取用户的全部令牌，按创建时间倒序
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*models.PersonalAccessToken, error)
+
+	// Revoke 吊销用户的令牌，令牌不存在或已吊销时返回false
+	Revoke(ctx context.Context, userID, id primitive.ObjectID) (bool, error)
+
+	// RecordUsage 记录令牌的使用时间和IP，距上次记录不足interval时不更新
+	RecordUsage(ctx context.Context, id primitive.ObjectID, ip string, at time.Time, interval time.Duration) error
+}
+
+// accessTokenRepository 个人访问令牌仓储实现
+type accessTokenRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewAccessTokenRepository 创建个人访问令牌仓储
+func NewAccessTokenRepository(db *mongo.Database) AccessTokenRepository {
+	return &accessTokenRepository{
+		db:         db,
+		collection: db.Collection("personal_access_tokens"),
+	}
+}
+
+// Create 保存令牌
+func (r *accessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	result, err := r.collection.InsertOne(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		token.ID = id
+	}
+	return nil
+}
+
+// GetByHash 通过令牌摘要获取令牌
+func (r *accessTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("access token not found")
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ListByUser 获取用户的全部令牌
+func (r *accessTokenRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*models.PersonalAccessToken, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tokens := make([]*models.PersonalAccessToken, 0)
+	if err = cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Revoke 吊销用户的令牌
+func (r *accessTokenRepository) Revoke(ctx context.Context, userID, id primitive.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// RecordUsage 记录令牌的使用时间和IP
+func (r *accessTokenRepository) RecordUsage(ctx context.Context, id primitive.ObjectID, ip string, at time.Time, interval time.Duration) error {
+	filter := bson.M{
+		"_id": id,
+		"$or": []bson.M{
+			{"last_used_at": bson.M{"$exists": false}},
+			{"last_used_at": bson.M{"$lt": at.Add(-interval)}},
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"last_used_at": at,
+		"last_used_ip": ip,
+	}})
+	return err
+}
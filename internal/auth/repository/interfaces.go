@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"authcenter/internal/models"
 
@@ -28,5 +29,11 @@ type SessionRepository interface {
 	Update(ctx context.Context, session *models.Session) error
 	RevokeUserSessions(ctx context.Context, userID primitive.ObjectID) error
 	RevokeSession(ctx context.Context, sessionID string) error
+	RotateSession(ctx context.Context, sessionID string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	GetFamily(ctx context.Context, familyID string) ([]*models.Session, error)
+	TouchSession(ctx context.Context, sessionID string, at time.Time) error
+	SetActiveRoles(ctx context.Context, sessionID string, roles []string) error
+	RevokeIdleSessions(ctx context.Context, idleBefore time.Time) (int64, error)
 	CleanupExpiredSessions(ctx context.Context) error
 }
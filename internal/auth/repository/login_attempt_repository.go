@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginAttemptRepository 登录失败记录数据访问接口
+type LoginAttemptRepository interface {
+	// Get 获取登录标识的失败记录
+	Get(ctx context.Context, identifier string) (*models.LoginAttempt, error)
+
+	// RecordFailure 累加登录失败次数，记录在最后一次失败window时长后过期
+	RecordFailure(ctx context.Context, identifier string, window time.Duration) (*models.LoginAttempt, error)
+
+	// Lock 锁定登录标识直到指定时间
+	Lock(ctx context.Context, identifier string, until time.Time) error
+}
+
+// loginAttemptRepository 登录失败记录仓储实现
+type loginAttemptRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewLoginAttemptRepository 创建登录失败记录仓储
+func NewLoginAttemptRepository(db *mongo.Database) LoginAttemptRepository {
+	return &loginAttemptRepository{
+		db:         db,
+		collection: db.Collection("login_attempts"),
+	}
+}
+
+// Get 获取登录标识的失败记录
+func (r *loginAttemptRepository) Get(ctx context.Context, identifier string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := r.collection.FindOne(ctx, bson.M{"identifier": identifier}).Decode(&attempt)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("login attempt not found")
+		}
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// RecordFailure 累加登录失败次数
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, identifier string, window time.Duration) (*models.LoginAttempt, error) {
+	now := time.Now()
+	update := bson.M{
+		"$inc": bson.M{"failed_count": 1},
+		"$set": bson.M{
+			"last_failed_at": now,
+			"expires_at":     now.Add(window),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt models.LoginAttempt
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"identifier": identifier}, update, opts).Decode(&attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+// Lock 锁定登录标识，记录在解锁时间后自动清理
+func (r *loginAttemptRepository) Lock(ctx context.Context, identifier string, until time.Time) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"identifier": identifier},
+		bson.M{"$set": bson.M{
+			"locked_until": until,
+			"expires_at":   until,
+		}},
+	)
+	return err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RevocationRepository 访问令牌吊销记录数据访问接口
+type RevocationRepository interface {
+	// Create 创建吊销记录
+	Create(ctx context.Context, revoked *models.RevokedToken) error
+
+	// ListSince 获取指定时间之后创建且未过期的吊销记录
+	ListSince(ctx context.Context, since time.Time) ([]*models.RevokedToken, error)
+}
+
+// revocationRepository 访问令牌吊销记录仓储实现
+type revocationRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewRevocationRepository 创建访问令牌吊销记录仓储
+func NewRevocationRepository(db *mongo.Database) RevocationRepository {
+	return &revocationRepository{
+		db:         db,
+		collection: db.Collection("revoked_tokens"),
+	}
+}
+
+// Create 创建吊销记录
+func (r *revocationRepository) Create(ctx context.Context, revoked *models.RevokedToken) error {
+	_, err := r.collection.InsertOne(ctx, revoked)
+	return err
+}
+
+// ListSince 获取指定时间之后创建且未过期的吊销记录
+func (r *revocationRepository) ListSince(ctx context.Context, since time.Time) ([]*models.RevokedToken, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"revoked_at": bson.M{"$gte": since},
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []*models.RevokedToken
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -124,12 +124,114 @@ func (r *sessionRepository) RevokeSession(ctx context.Context, sessionID string)
 	return nil
 }
 
+// RotateSession 将未吊销的会话标记为已轮换，返回本次调用是否完成轮换
+// 会话已被吊销或已被并发请求轮换时返回false
+func (r *sessionRepository) RotateSession(ctx context.Context, sessionID string) (bool, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"session_id": sessionID, "is_revoked": false},
+		bson.M{"$set": bson.M{
+			"is_revoked":       true,
+			"rotated_at":       now,
+			"last_accessed_at": now,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// RevokeFamily 撤销刷新令牌族中的所有会话
+func (r *sessionRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"family_id": familyID, "is_revoked": false},
+		bson.M{"$set": bson.M{"is_revoked": true}},
+	)
+	return err
+}
+
+// GetFamily 获取令牌族中未过期的会话，包括已轮换和已吊销的会话
+// 早于令牌族创建的会话没有family_id，以会话ID作为族ID
+func (r *sessionRepository) GetFamily(ctx context.Context, familyID string) ([]*models.Session, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"family_id": familyID},
+			{"session_id": familyID},
+		},
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*models.Session
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// TouchSession 更新会话的最近访问时间
+func (r *sessionRepository) TouchSession(ctx context.Context, sessionID string, at time.Time) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"session_id":       sessionID,
+			"is_revoked":       false,
+			"last_accessed_at": bson.M{"$lt": at},
+		},
+		bson.M{"$set": bson.M{"last_accessed_at": at}},
+	)
+	return err
+}
+
+// SetActiveRoles 设置未吊销会话激活的角色，轮换产生的会话沿用
+func (r *sessionRepository) SetActiveRoles(ctx context.Context, sessionID string, roles []string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"session_id": sessionID, "is_revoked": false},
+		bson.M{"$set": bson.M{"active_roles": roles}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("session not found")
+	}
+
+	return nil
+}
+
+// RevokeIdleSessions 吊销最近访问时间早于idleBefore的会话，返回吊销的会话数
+func (r *sessionRepository) RevokeIdleSessions(ctx context.Context, idleBefore time.Time) (int64, error) {
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{
+			"is_revoked":       false,
+			"last_accessed_at": bson.M{"$lt": idleBefore},
+		},
+		bson.M{"$set": bson.M{"is_revoked": true}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
 // CleanupExpiredSessions 清理过期会话
 func (r *sessionRepository) CleanupExpiredSessions(ctx context.Context) error {
 	// MongoDB的TTL索引会自动清理过期文档，这里主要是手动清理被撤销的会话
+	// 已轮换的会话需保留到过期，用于识别刷新令牌重放
 	_, err := r.collection.DeleteMany(ctx, bson.M{
 		"$or": []bson.M{
-			{"is_revoked": true},
+			{"is_revoked": true, "rotated_at": bson.M{"$exists": false}},
 			{"expires_at": bson.M{"$lt": time.Now()}},
 		},
 	})
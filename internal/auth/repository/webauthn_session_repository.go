@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// WebAuthnSessionRepository WebAuthn仪式数据访问接口
+type WebAuthnSessionRepository interface {
+	// Create 保存仪式数据
+	Create(ctx context.Context, session *models.WebAuthnSession) error
+
+	// Consume 取出并删除仪式数据，保证挑战只能使用一次
+	Consume(ctx context.Context, sessionID, purpose string) (*models.WebAuthnSession, error)
+}
+
+// webAuthnSessionRepository WebAuthn仪式仓储实现
+type webAuthnSessionRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewWebAuthnSessionRepository 创建WebAuthn仪式仓储
+func NewWebAuthnSessionRepository(db *mongo.Database) WebAuthnSessionRepository {
+	return &webAuthnSessionRepository{
+		db:         db,
+		collection: db.Collection("webauthn_sessions"),
+	}
+}
+
+// Create 保存仪式数据
+func (r *webAuthnSessionRepository) Create(ctx context.Context, session *models.WebAuthnSession) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+// Consume 取出并删除仪式数据，已过期但尚未被TTL索引清理的记录同样视为不存在
+func (r *webAuthnSessionRepository) Consume(ctx context.Context, sessionID, purpose string) (*models.WebAuthnSession, error) {
+	var session models.WebAuthnSession
+	err := r.collection.FindOneAndDelete(ctx, bson.M{
+		"session_id": sessionID,
+		"purpose":    purpose,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("webauthn session not found")
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
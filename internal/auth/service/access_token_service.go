@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"authcenter/internal/auth/repository"
+	groupRepo "authcenter/internal/group/repository"
+	"authcenter/internal/models"
+	roleRepo "authcenter/internal/role/repository"
+	userRepo "authcenter/internal/user/repository"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/rbac"
+	"authcenter/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccessTokenPrefix 个人访问令牌前缀，用于区分JWT和便于密钥扫描工具识别
+const AccessTokenPrefix = "acp_"
+
+// accessTokenPrefixLength 保存用于展示的令牌开头字符数
+const accessTokenPrefixLength = len(AccessTokenPrefix) + 6
+
+// accessTokenUsageInterval 记录令牌使用时间的最小间隔，避免每个请求都写数据库
+const accessTokenUsageInterval = time.Minute
+
+// maxAccessTokenNameLength 令牌名称的最大字符数
+const maxAccessTokenNameLength = 100
+
+// CreateAccessTokenRequest 创建个人访问令牌请求
+type CreateAccessTokenRequest struct {
+	Name        string    `json:"name" binding:"required"`
+	Permissions []string  `json:"permissions" binding:"required"` // resource:action，必须是当前用户拥有的权限
+	ExpiresAt   time.Time `json:"expires_at" binding:"required"`
+}
+
+// CreatedAccessToken 新创建的令牌，明文只在创建时返回一次
+type CreatedAccessToken struct {
+	*models.PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// AccessTokenService 个人访问令牌服务接口
+type AccessTokenService interface {
+	// Create 为当前用户创建令牌，权限不能超出创建请求所用访问令牌的权限
+	Create(ctx context.Context, claims *jwt.Claims, req *CreateAccessTokenRequest) (*CreatedAccessToken, error)
+
+	// List 获取用户的令牌
+	List(ctx context.Context, userID string) ([]*models.PersonalAccessToken, error)
+
+	// Revoke 吊销用户的令牌
+	Revoke(ctx context.Context, userID, id string) error
+
+	// IsAccessToken 是否为个人访问令牌格式，不校验有效性
+	IsAccessToken(token string) bool
+
+	// Authenticate 校验令牌并生成声明，权限为令牌权限与用户当前权限的交集
+	Authenticate(ctx context.Context, token, ip string) (*jwt.Claims, error)
+}
+
+// accessTokenService 个人访问令牌服务实现
+type accessTokenService struct {
+	tokenRepo   repository.AccessTokenRepository
+	userRepo    userRepo.UserRepository
+	roleRepo    roleRepo.RoleRepository
+	sodRepo     roleRepo.SoDRepository
+	groupRepo   groupRepo.GroupRepository
+	maxLifetime time.Duration
+}
+
+// NewAccessTokenService 创建个人访问令牌服务，maxLifetime为令牌的最长有效期，为0时不限制
+func NewAccessTokenService(tokenRepo repository.AccessTokenRepository, userRepo userRepo.UserRepository, roleRepo roleRepo.RoleRepository, sodRepo roleRepo.SoDRepository, groupRepo groupRepo.GroupRepository, maxLifetime time.Duration) AccessTokenService {
+	return &accessTokenService{
+		tokenRepo:   tokenRepo,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		sodRepo:     sodRepo,
+		groupRepo:   groupRepo,
+		maxLifetime: maxLifetime,
+	}
+}
+
+// Create 创建令牌
+func (s *accessTokenService) Create(ctx context.Context, claims *jwt.Claims, req *CreateAccessTokenRequest) (*CreatedAccessToken, error) {
+	if claims.TokenType == jwt.TokenTypePersonalAccess {
+		return nil, errors.New("不能使用个人访问令牌创建新的令牌")
+	}
+	// 第三方应用获得的令牌只有用户授权的范围，不能借此换取长期有效的令牌
+	if claims.ClientID != "" || claims.Scope != "" || claims.IsServiceAccount() {
+		return nil, errors.New("第三方应用令牌不能创建个人访问令牌")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("令牌名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > maxAccessTokenNameLength {
+		return nil, errors.New("令牌名称过长")
+	}
+
+	now := time.Now()
+	if !req.ExpiresAt.After(now) {
+		return nil, errors.New("过期时间必须晚于当前时间")
+	}
+	if s.maxLifetime > 0 && req.ExpiresAt.After(now.Add(s.maxLifetime)) {
+		return nil, errors.New("过期时间超过允许的最长有效期")
+	}
+
+	permissions, err := scopePermissions(req.Permissions, claims.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		return nil, errors.New("用户ID格式错误")
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	plain := AccessTokenPrefix + secret
+
+	token := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   utils.HashToken(plain),
+		TokenPrefix: plain[:accessTokenPrefixLength],
+		Permissions: permissions,
+		AuthMethods: claims.AuthMethods,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedAt:   now,
+	}
+	if claims.TenantID != "" {
+		tenantID, err := primitive.ObjectIDFromHex(claims.TenantID)
+		if err != nil {
+			return nil, errors.New("租户ID格式错误")
+		}
+		token.TenantID = &tenantID
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+
+	logger.SecurityEvent("access_token_created", map[string]interface{}{
+		"user_id":     claims.UserID,
+		"token_id":    token.ID.Hex(),
+		"permissions": permissions,
+		"expires_at":  token.ExpiresAt,
+	})
+
+	return &CreatedAccessToken{PersonalAccessToken: token, Token: plain}, nil
+}
+
+// List 获取用户的令牌
+func (s *accessTokenService) List(ctx context.Context, userID string) ([]*models.PersonalAccessToken, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("用户ID格式错误")
+	}
+
+	return s.tokenRepo.ListByUser(ctx, userObjID)
+}
+
+// Revoke 吊销用户的令牌
+func (s *accessTokenService) Revoke(ctx context.Context, userID, id string) error {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("用户ID格式错误")
+	}
+	tokenID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("令牌不存在")
+	}
+
+	revoked, err := s.tokenRepo.Revoke(ctx, userObjID, tokenID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("令牌不存在或已吊销")
+	}
+
+	logger.SecurityEvent("access_token_revoked", map[string]interface{}{
+		"user_id":  userID,
+		"token_id": id,
+	})
+
+	return nil
+}
+
+// IsAccessToken 是否为个人访问令牌格式
+func (s *accessTokenService) IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// Authenticate 校验令牌并生成声明
+// 令牌只携带权限不携带角色，按角色授权的接口不接受个人访问令牌
+func (s *accessTokenService) Authenticate(ctx context.Context, plain, ip string) (*jwt.Claims, error) {
+	if !s.IsAccessToken(plain) {
+		return nil, errors.New("无效的访问令牌")
+	}
+
+	token, err := s.tokenRepo.GetByHash(ctx, utils.HashToken(plain))
+	if err != nil {
+		return nil, errors.New("无效的访问令牌")
+	}
+	if token.RevokedAt != nil {
+		return nil, errors.New("访问令牌已被吊销")
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, errors.New("访问令牌已过期")
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID.Hex())
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.Status != "active" {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	// 令牌只在创建时的租户中生效，用户退出该租户后随即失效
+	tenantID := primitive.NilObjectID
+	if token.TenantID != nil {
+		tenantID = *token.TenantID
+		if !user.MemberOf(tenantID) {
+			return nil, errors.New("用户已不属于令牌的租户")
+		}
+	}
+
+	// 用户失去的权限立即对令牌生效
+	grants := resolveGrants(s.roleRepo, s.sodRepo, effectiveAssignments(ctx, s.groupRepo, user, tenantID), containsMethod(token.AuthMethods, AuthMethodMFA), nil)
+	permissions := rbac.Intersect(token.Permissions, grants.permissions)
+
+	s.recordUsage(token.ID, ip)
+
+	return &jwt.Claims{
+		UserID:      user.ID.Hex(),
+		Username:    user.Username,
+		Permissions: permissions,
+		Department:  user.Profile.Department,
+		AuthMethods: token.AuthMethods,
+		TokenType:   jwt.TokenTypePersonalAccess,
+		SubjectType: jwt.SubjectTypeUser,
+		TenantID:    tenantHex(tenantID),
+		JTI:         token.ID.Hex(),
+	}, nil
+}
+
+// recordUsage 异步记录令牌的使用时间和IP
+func (s *accessTokenService) recordUsage(id primitive.ObjectID, ip string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := s.tokenRepo.RecordUsage(ctx, id, ip, time.Now(), accessTokenUsageInterval); err != nil {
+			logger.Error("记录访问令牌使用时间失败: %v", err)
+		}
+	}()
+}
+
+// scopePermissions 校验并去重令牌权限，每项都必须被granted包含
+func scopePermissions(requested, granted []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	permissions := make([]string, 0, len(requested))
+	for _, permission := range requested {
+		permission = strings.TrimSpace(permission)
+		if permission == "" || seen[permission] {
+			continue
+		}
+		if _, _, ok := rbac.Parse(permission); !ok {
+			return nil, errors.New("无效的权限: " + permission)
+		}
+		if !rbac.Covered(granted, permission) {
+			return nil, errors.New("不能授予自己没有的权限: " + permission)
+		}
+		seen[permission] = true
+		permissions = append(permissions, permission)
+	}
+
+	if len(permissions) == 0 {
+		return nil, errors.New("至少需要一项权限")
+	}
+	return permissions, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"authcenter/internal/models"
+	"authcenter/internal/testutil"
+	"authcenter/pkg/jwt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// accessTokenTestEnv 使用内存仓储的个人访问令牌服务
+type accessTokenTestEnv struct {
+	svc    AccessTokenService
+	tokens *testutil.AccessTokens
+	users  *testutil.Users
+	roles  *testutil.Roles
+	user   *models.User
+	role   *models.Role
+}
+
+// newAccessTokenTestEnv 创建个人访问令牌服务和一个拥有user:READ、user:UPDATE权限的用户
+func newAccessTokenTestEnv(t *testing.T) *accessTokenTestEnv {
+	t.Helper()
+
+	env := &accessTokenTestEnv{
+		tokens: testutil.NewAccessTokens(),
+		users:  testutil.NewUsers(),
+		roles:  testutil.NewRoles(),
+	}
+	env.role = env.roles.Put(&models.Role{
+		Name: "editor",
+		Permissions: []models.RolePermission{
+			{Resource: "user", Action: "READ"},
+			{Resource: "user", Action: "UPDATE"},
+		},
+	})
+	env.user = env.users.Put(&models.User{
+		Username: "alice",
+		Roles:    []models.UserRole{{RoleID: env.role.ID, RoleName: env.role.Name}},
+	})
+	env.svc = NewAccessTokenService(env.tokens, env.users, env.roles, nil, nil, 30*24*time.Hour)
+
+	return env
+}
+
+// claims 用户第一方登录会话的访问令牌声明
+func (e *accessTokenTestEnv) claims() *jwt.Claims {
+	return &jwt.Claims{
+		UserID:      e.user.ID.Hex(),
+		Username:    e.user.Username,
+		Permissions: []string{"user:READ", "user:UPDATE"},
+		TokenType:   "access",
+		SubjectType: jwt.SubjectTypeUser,
+	}
+}
+
+// create 以claims创建只有permissions的令牌
+func (e *accessTokenTestEnv) create(t *testing.T, claims *jwt.Claims, permissions ...string) *CreatedAccessToken {
+	t.Helper()
+
+	created, err := e.svc.Create(context.Background(), claims, &CreateAccessTokenRequest{
+		Name:        "ci",
+		Permissions: permissions,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return created
+}
+
+func TestCreateAccessTokenScope(t *testing.T) {
+	env := newAccessTokenTestEnv(t)
+
+	pat := env.claims()
+	pat.TokenType = jwt.TokenTypePersonalAccess
+	thirdParty := env.claims()
+	thirdParty.ClientID = "client-1"
+	thirdParty.Scope = "profile"
+	service := env.claims()
+	service.SubjectType = jwt.SubjectTypeService
+
+	tests := []struct {
+		name        string
+		claims      *jwt.Claims
+		permissions []string
+		expiresAt   time.Time
+	}{
+		{"超出自己的权限", env.claims(), []string{"user:DELETE"}, time.Now().Add(time.Hour)},
+		{"通配符超出自己的权限", env.claims(), []string{"user:*"}, time.Now().Add(time.Hour)},
+		{"无效的权限", env.claims(), []string{"user"}, time.Now().Add(time.Hour)},
+		{"没有权限", env.claims(), []string{" "}, time.Now().Add(time.Hour)},
+		{"已过期", env.claims(), []string{"user:READ"}, time.Now().Add(-time.Minute)},
+		{"超过最长有效期", env.claims(), []string{"user:READ"}, time.Now().Add(365 * 24 * time.Hour)},
+		{"个人访问令牌", pat, []string{"user:READ"}, time.Now().Add(time.Hour)},
+		{"第三方应用令牌", thirdParty, []string{"user:READ"}, time.Now().Add(time.Hour)},
+		{"服务账号令牌", service, []string{"user:READ"}, time.Now().Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := env.svc.Create(context.Background(), tt.claims, &CreateAccessTokenRequest{
+				Name:        "ci",
+				Permissions: tt.permissions,
+				ExpiresAt:   tt.expiresAt,
+			})
+			if err == nil {
+				t.Fatal("创建成功，期望被拒绝")
+			}
+		})
+	}
+}
+
+// 令牌的权限是创建时的授权与用户当前权限的交集，用户失去的权限立即对令牌生效
+func TestAuthenticateAccessTokenIntersectsCurrentPermissions(t *testing.T) {
+	ctx := context.Background()
+	env := newAccessTokenTestEnv(t)
+	created := env.create(t, env.claims(), "user:READ", "user:UPDATE", "user:READ")
+
+	claims, err := env.svc.Authenticate(ctx, created.Token, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if want := []string{"user:READ", "user:UPDATE"}; !reflect.DeepEqual(claims.Permissions, want) {
+		t.Fatalf("权限 = %v，期望 %v", claims.Permissions, want)
+	}
+	if claims.TokenType != jwt.TokenTypePersonalAccess || len(claims.Roles) != 0 {
+		t.Fatalf("令牌类型 = %s，角色 = %v，期望个人访问令牌且不携带角色", claims.TokenType, claims.Roles)
+	}
+
+	env.role.Permissions = []models.RolePermission{{Resource: "user", Action: "READ"}}
+	env.roles.Put(env.role)
+
+	claims, err = env.svc.Authenticate(ctx, created.Token, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if want := []string{"user:READ"}; !reflect.DeepEqual(claims.Permissions, want) {
+		t.Fatalf("角色失去权限后令牌的权限 = %v，期望 %v", claims.Permissions, want)
+	}
+}
+
+func TestAuthenticateAccessTokenRejected(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("已吊销", func(t *testing.T) {
+		env := newAccessTokenTestEnv(t)
+		created := env.create(t, env.claims(), "user:READ")
+		if err := env.svc.Revoke(ctx, primitive.NewObjectID().Hex(), created.ID.Hex()); err == nil {
+			t.Fatal("吊销了其他用户的令牌")
+		}
+		if err := env.svc.Revoke(ctx, env.user.ID.Hex(), created.ID.Hex()); err != nil {
+			t.Fatalf("Revoke: %v", err)
+		}
+		if _, err := env.svc.Authenticate(ctx, created.Token, ""); err == nil {
+			t.Fatal("已吊销的令牌认证成功")
+		}
+	})
+
+	t.Run("用户已禁用", func(t *testing.T) {
+		env := newAccessTokenTestEnv(t)
+		created := env.create(t, env.claims(), "user:READ")
+		env.user.Status = "inactive"
+		env.users.Put(env.user)
+		if _, err := env.svc.Authenticate(ctx, created.Token, ""); err == nil {
+			t.Fatal("禁用用户的令牌认证成功")
+		}
+	})
+
+	t.Run("用户已退出令牌的租户", func(t *testing.T) {
+		env := newAccessTokenTestEnv(t)
+		tenantID := primitive.NewObjectID()
+		env.user.Tenants = []models.TenantMembership{{TenantID: tenantID}}
+		env.users.Put(env.user)
+
+		claims := env.claims()
+		claims.TenantID = tenantID.Hex()
+		created := env.create(t, claims, "user:READ")
+		if _, err := env.svc.Authenticate(ctx, created.Token, ""); err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+
+		env.user.Tenants = nil
+		env.users.Put(env.user)
+		if _, err := env.svc.Authenticate(ctx, created.Token, ""); err == nil {
+			t.Fatal("用户退出租户后令牌认证成功")
+		}
+	})
+
+	t.Run("无效的令牌", func(t *testing.T) {
+		env := newAccessTokenTestEnv(t)
+		env.create(t, env.claims(), "user:READ")
+		if _, err := env.svc.Authenticate(ctx, AccessTokenPrefix+"unknown", ""); err == nil {
+			t.Fatal("不存在的令牌认证成功")
+		}
+	})
+}
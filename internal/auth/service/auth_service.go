@@ -2,15 +2,24 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"regexp"
 	"time"
 
 	sessionRepo "authcenter/internal/auth/repository"
+	"authcenter/internal/config"
+	groupRepo "authcenter/internal/group/repository"
 	"authcenter/internal/models"
 	roleRepo "authcenter/internal/role/repository"
+	tenantRepo "authcenter/internal/tenant/repository"
 	userRepo "authcenter/internal/user/repository"
+	verificationService "authcenter/internal/verification/service"
+	"authcenter/pkg/breach"
 	"authcenter/pkg/jwt"
-	"authcenter/pkg/utils"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/password"
+	"authcenter/pkg/rbac"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -18,20 +27,50 @@ import (
 // AuthService 认证服务接口
 type AuthService interface {
 	Register(ctx context.Context, req *RegisterRequest) (*models.User, error)
-	Login(ctx context.Context, req *LoginRequest) (*TokenData, error)
-	RefreshToken(ctx context.Context, refreshToken string) (*TokenData, error)
+	SendSMSCode(ctx context.Context, req *SendSMSCodeRequest) error
+	Login(ctx context.Context, req *LoginRequest) (*LoginResult, error)
+	VerifyMFA(ctx context.Context, req *MFALoginRequest) (*LoginResult, error)
+	RefreshToken(ctx context.Context, refreshToken, tenant string) (*TokenData, error)
 	VerifyToken(ctx context.Context, req *VerifyTokenRequest) (*VerifyResult, error)
-	Logout(ctx context.Context, token string) error
+	Logout(ctx context.Context, token string, all bool) error
+	IssueTokens(ctx context.Context, userID string, opts *IssueOptions) (*TokenData, error)
+	IssueServiceToken(ctx context.Context, client *models.OAuthClient, scope string) (*TokenData, error)
+	ChangePassword(ctx context.Context, userID string, req *ChangePasswordRequest) error
+	ChangeExpiredPassword(ctx context.Context, req *ChangeExpiredPasswordRequest) (*TokenData, error)
+	ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendEmailVerification(ctx context.Context, userID string) error
+	ChangeEmail(ctx context.Context, userID string, req *ChangeEmailRequest) error
+	ListSessions(ctx context.Context, userID, currentSessionID string) ([]*SessionInfo, error)
+	RevokeSession(ctx context.Context, userID, id string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error)
+	SetActiveRoles(ctx context.Context, userID, sessionID string, req *SetActiveRolesRequest) error
 }
 
 // authService 认证服务实现
 type authService struct {
-	userRepo    userRepo.UserRepository
-	sessionRepo sessionRepo.SessionRepository
-	roleRepo    roleRepo.RoleRepository
-	jwtManager  jwt.Manager
+	userRepo         userRepo.UserRepository
+	sessionRepo      sessionRepo.SessionRepository
+	loginAttemptRepo sessionRepo.LoginAttemptRepository
+	roleRepo         roleRepo.RoleRepository
+	sodRepo          roleRepo.SoDRepository
+	tenantRepo       tenantRepo.TenantRepository
+	groupRepo        groupRepo.GroupRepository
+	jwtManager       jwt.Manager
+	passwords        password.Manager
+	revocation       RevocationService
+	mfaService       MFAService
+	passkeyService   PasskeyService
+	verification     verificationService.VerificationService
+	email            verificationService.EmailService
+	security         config.SecurityConfig
+	passwordPolicy   *password.Policy
 }
 
+// phonePattern 手机号格式，允许国际区号前缀
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{6,15}$`)
+
 // RegisterRequest 注册请求结构
 type RegisterRequest struct {
 	Username string `json:"username"`
@@ -41,6 +80,12 @@ type RegisterRequest struct {
 	Code     string `json:"code,omitempty"`
 }
 
+// SendSMSCodeRequest 发送短信验证码请求
+type SendSMSCodeRequest struct {
+	Phone   string `json:"phone" binding:"required"`
+	Purpose string `json:"purpose" binding:"required"` // login, register
+}
+
 // LoginRequest 登录请求结构
 type LoginRequest struct {
 	Username string `json:"username,omitempty"` // 用户名
@@ -49,6 +94,34 @@ type LoginRequest struct {
 	Password string `json:"password,omitempty"`
 	Code     string `json:"code,omitempty"`
 	Type     string `json:"type"`
+	Tenant   string `json:"tenant,omitempty"` // 登录的租户标识，为空时使用用户加入的第一个租户
+
+	IP        string `json:"-"` // 客户端IP，由处理器填写
+	UserAgent string `json:"-"`
+
+	SessionID  string          `json:"session_id,omitempty"` // 通行密钥登录仪式ID
+	Credential json.RawMessage `json:"credential,omitempty"` // navigator.credentials.get返回的断言
+}
+
+// MFALoginRequest 多因素认证登录请求，提供验证码或恢复码之一
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+	Tenant       string `json:"tenant,omitempty"` // 登录的租户标识
+	IP           string `json:"-"`
+	UserAgent    string `json:"-"`
+}
+
+// LoginResult 登录结果，启用多因素认证或密码已过期时返回挑战令牌而非Token
+type LoginResult struct {
+	*TokenData
+	MFARequired bool     `json:"mfa_required,omitempty"`
+	MFAToken    string   `json:"mfa_token,omitempty"`
+	MFAMethods  []string `json:"mfa_methods,omitempty"`
+
+	PasswordChangeRequired bool   `json:"password_change_required,omitempty"`
+	PasswordChangeToken    string `json:"password_change_token,omitempty"`
 }
 
 // VerifyTokenRequest 验证Token请求
@@ -58,6 +131,18 @@ type VerifyTokenRequest struct {
 	Action   string `json:"action,omitempty"`
 }
 
+// IssueOptions 签发Token的附加选项
+type IssueOptions struct {
+	ClientID    string            // OAuth客户端ID，第一方登录为空
+	Scope       string            // OAuth授权范围
+	AuthMethods []string          // 已完成的认证方式，决定要求多因素认证的角色是否生效
+	Device      models.DeviceInfo // 登录设备，轮换会话时沿用原会话的设备信息
+	ActiveRoles []string          // 受动态职责分离规则约束时激活的角色，轮换会话时沿用
+	Tenant      string            // 切换到的租户标识，为空时沿用原会话的租户
+
+	tenantID *primitive.ObjectID // 调用方已确定的租户，设置时不再解析Tenant
+}
+
 // TokenData Token数据
 type TokenData struct {
 	AccessToken  string    `json:"access_token"`
@@ -66,13 +151,17 @@ type TokenData struct {
 	TokenType    string    `json:"token_type"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	UserID       string    `json:"user_id"`
+	SessionID    string    `json:"-"`
+	Roles        []string  `json:"-"`
 }
 
 // VerifyResult 验证结果
 type VerifyResult struct {
 	Valid       bool     `json:"valid"`
 	UserID      string   `json:"user_id,omitempty"`
+	SubjectType string   `json:"sub_type,omitempty"`
 	Username    string   `json:"username,omitempty"`
+	TenantID    string   `json:"tenant_id,omitempty"`
 	Roles       []string `json:"roles,omitempty"`
 	Permissions []string `json:"permissions,omitempty"`
 	HasAccess   bool     `json:"has_access,omitempty"`
@@ -82,14 +171,38 @@ type VerifyResult struct {
 func NewAuthService(
 	userRepo userRepo.UserRepository,
 	sessionRepo sessionRepo.SessionRepository,
+	loginAttemptRepo sessionRepo.LoginAttemptRepository,
 	roleRepo roleRepo.RoleRepository,
+	sodRepo roleRepo.SoDRepository,
+	tenantRepo tenantRepo.TenantRepository,
+	groupRepo groupRepo.GroupRepository,
 	jwtManager jwt.Manager,
+	passwords password.Manager,
+	breachChecker breach.Checker,
+	revocation RevocationService,
+	mfaService MFAService,
+	passkeyService PasskeyService,
+	verification verificationService.VerificationService,
+	email verificationService.EmailService,
+	security config.SecurityConfig,
 ) AuthService {
 	return &authService{
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
-		roleRepo:    roleRepo,
-		jwtManager:  jwtManager,
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		roleRepo:         roleRepo,
+		sodRepo:          sodRepo,
+		tenantRepo:       tenantRepo,
+		groupRepo:        groupRepo,
+		jwtManager:       jwtManager,
+		passwords:        passwords,
+		revocation:       revocation,
+		mfaService:       mfaService,
+		passkeyService:   passkeyService,
+		verification:     verification,
+		email:            email,
+		security:         security,
+		passwordPolicy:   newPasswordPolicy(security, breachChecker),
 	}
 }
 
@@ -114,12 +227,18 @@ func (s *authService) Register(ctx context.Context, req *RegisterRequest) (*mode
 		}
 	}
 
-	// 检查手机号是否已存在
+	// 检查手机号是否已存在，并校验短信验证码证明手机号归属
 	if req.Phone != "" {
 		existingUser, _ = s.userRepo.GetByPhone(req.Phone)
 		if existingUser != nil {
 			return nil, errors.New("手机号已被注册")
 		}
+		if req.Code == "" {
+			return nil, errors.New("验证码不能为空")
+		}
+		if err := s.verification.VerifyCode(ctx, req.Phone, verificationService.PurposeRegister, req.Code); err != nil {
+			return nil, err
+		}
 	}
 
 	// 创建用户
@@ -132,13 +251,17 @@ func (s *authService) Register(ctx context.Context, req *RegisterRequest) (*mode
 		UpdatedAt: time.Now(),
 	}
 
-	// 如果有密码，进行加密
-	if req.Password != "" {
-		hashedPassword, err := utils.HashPassword(req.Password)
+	// 通过短信验证码注册的用户可以不设置密码，其余情况必须设置符合策略的密码
+	if req.Password != "" || req.Phone == "" {
+		if err := s.validatePassword(req.Password, user); err != nil {
+			return nil, err
+		}
+		hashedPassword, err := s.passwords.HashPassword(req.Password)
 		if err != nil {
 			return nil, err
 		}
 		user.PasswordHash = hashedPassword
+		user.PasswordChangedAt = &user.CreatedAt
 	}
 
 	// 分配默认角色 (User)
@@ -161,13 +284,49 @@ func (s *authService) Register(ctx context.Context, req *RegisterRequest) (*mode
 		return nil, err
 	}
 
+	// 验证邮件发送失败不影响注册，用户可稍后重新发送
+	if user.Email != "" {
+		if err := s.email.SendEmailVerification(ctx, user); err != nil {
+			logger.Error("发送验证邮件失败: %v", err)
+		}
+	}
+
 	return user, nil
 }
 
+// SendSMSCode 发送登录或注册短信验证码
+// 登录验证码只发送给已注册的手机号，注册验证码只发送给未注册的手机号，
+// 不满足条件时同样返回成功，避免借此探测手机号是否注册
+func (s *authService) SendSMSCode(ctx context.Context, req *SendSMSCodeRequest) error {
+	if !phonePattern.MatchString(req.Phone) {
+		return errors.New("手机号格式错误")
+	}
+
+	existingUser, _ := s.userRepo.GetByPhone(req.Phone)
+
+	switch req.Purpose {
+	case verificationService.PurposeLogin:
+		if existingUser == nil {
+			return nil
+		}
+	case verificationService.PurposeRegister:
+		if existingUser != nil {
+			return nil
+		}
+	default:
+		return errors.New("不支持的验证码用途")
+	}
+
+	return s.verification.SendCode(ctx, req.Phone, req.Purpose)
+}
+
 // Login 用户登录
-func (s *authService) Login(ctx context.Context, req *LoginRequest) (*TokenData, error) {
+func (s *authService) Login(ctx context.Context, req *LoginRequest) (*LoginResult, error) {
 	var user *models.User
 	var err error
+	var identifier string
+	checkPassword := true
+	authMethod := AuthMethodPassword
 
 	// 根据登录类型获取用户
 	switch req.Type {
@@ -175,34 +334,27 @@ func (s *authService) Login(ctx context.Context, req *LoginRequest) (*TokenData,
 		if req.Phone == "" || req.Code == "" {
 			return nil, errors.New("手机号和验证码不能为空")
 		}
-		// TODO: 验证短信验证码
+		identifier = req.Phone
+		checkPassword = false
+		authMethod = AuthMethodSMS
 		user, err = s.userRepo.GetByPhone(req.Phone)
 	case "username":
 		if req.Username == "" || req.Password == "" {
 			return nil, errors.New("用户名和密码不能为空")
 		}
+		identifier = req.Username
 		user, err = s.userRepo.GetByUsername(req.Username)
-		if err == nil && user != nil {
-			if !utils.CheckPassword(req.Password, user.PasswordHash) {
-				return nil, errors.New("密码错误")
-			}
-		}
 	case "email", "": // 空字符串时默认为邮箱登录
 		if req.Email == "" || req.Password == "" {
 			return nil, errors.New("邮箱和密码不能为空")
 		}
+		identifier = req.Email
 		user, err = s.userRepo.GetByEmail(req.Email)
-		if err == nil && user != nil {
-			if !utils.CheckPassword(req.Password, user.PasswordHash) {
-				return nil, errors.New("密码错误")
-			}
-		}
 	case "auto": // 自动识别用户名或邮箱登录
 		if req.Password == "" {
 			return nil, errors.New("密码不能为空")
 		}
 
-		var identifier string
 		if req.Username != "" {
 			identifier = req.Username
 		} else if req.Email != "" {
@@ -213,29 +365,131 @@ func (s *authService) Login(ctx context.Context, req *LoginRequest) (*TokenData,
 
 		// 使用新的方法同时查询用户名和邮箱
 		user, err = s.userRepo.GetByUsernameOrEmail(identifier)
-		if err == nil && user != nil {
-			if !utils.CheckPassword(req.Password, user.PasswordHash) {
-				return nil, errors.New("密码错误")
-			}
+	case "passkey":
+		if req.SessionID == "" || len(req.Credential) == 0 {
+			return nil, errors.New("通行密钥凭证不能为空")
+		}
+		checkPassword = false
+		authMethod = AuthMethodHardwareKey
+		user, err = s.passkeyService.ValidateLogin(ctx, req.SessionID, req.Credential)
+		if err != nil {
+			return nil, err
 		}
 	default:
 		return nil, errors.New("不支持的登录类型")
 	}
 
-	if err != nil {
+	if err != nil || user == nil {
+		if lockErr := s.checkIdentifierLockout(ctx, identifier); lockErr != nil {
+			return nil, lockErr
+		}
+		s.recordIdentifierFailure(ctx, identifier, req.IP)
 		return nil, errors.New("用户不存在")
 	}
 
+	// 锁定期间不再校验密码，避免继续猜测
+	if err := s.checkLockout(user); err != nil {
+		return nil, err
+	}
+
+	if checkPassword && !s.checkPassword(user, req.Password) {
+		return nil, s.recordLoginFailure(user, req.IP, errors.New("密码错误"))
+	}
+
+	if authMethod == AuthMethodSMS {
+		if err := s.verification.VerifyCode(ctx, req.Phone, verificationService.PurposeLogin, req.Code); err != nil {
+			return nil, s.recordLoginFailure(user, req.IP, err)
+		}
+	}
+
 	if user.Status != "active" {
 		return nil, errors.New("用户已被禁用")
 	}
 
+	// 通行密钥要求用户验证（PIN或生物特征），本身即满足多因素认证
+	authMethods := []string{authMethod}
+	if authMethod == AuthMethodHardwareKey {
+		authMethods = append(authMethods, AuthMethodMFA)
+	}
+
+	// 启用多因素认证时签发挑战令牌，第二步验证通过后才清零失败次数，
+	// 避免已知密码的攻击者通过重新登录重置验证码的失败计数
+	if user.MFA.Enabled && !containsMethod(authMethods, AuthMethodMFA) {
+		mfaToken, err := s.jwtManager.GenerateMFAToken(user.ID.Hex(), authMethods)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			MFAMethods:  []string{"totp", "recovery_code"},
+		}, nil
+	}
+
+	s.recordLoginSuccess(user)
+
+	if challenge, err := s.passwordChangeChallenge(user, authMethods); challenge != nil || err != nil {
+		return challenge, err
+	}
+
 	// 生成Token
-	return s.generateTokens(ctx, user)
+	tokenData, err := s.generateTokens(ctx, user, &IssueOptions{
+		AuthMethods: authMethods,
+		Device:      newDeviceInfo(req.IP, req.UserAgent),
+		Tenant:      req.Tenant,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{TokenData: tokenData}, nil
+}
+
+// VerifyMFA 多因素认证登录的第二步，校验验证码后签发Token
+func (s *authService) VerifyMFA(ctx context.Context, req *MFALoginRequest) (*LoginResult, error) {
+	claims, err := s.jwtManager.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, errors.New("MFA令牌无效或已过期")
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if err := s.checkLockout(user); err != nil {
+		return nil, err
+	}
+
+	if user.Status != "active" {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	if err := s.mfaService.Verify(ctx, user, req.Code, req.RecoveryCode); err != nil {
+		return nil, s.recordLoginFailure(user, req.IP, err)
+	}
+
+	s.recordLoginSuccess(user)
+
+	authMethods := append(claims.AuthMethods, AuthMethodOTP, AuthMethodMFA)
+	if challenge, err := s.passwordChangeChallenge(user, authMethods); challenge != nil || err != nil {
+		return challenge, err
+	}
+
+	tokenData, err := s.generateTokens(ctx, user, &IssueOptions{
+		AuthMethods: authMethods,
+		Device:      newDeviceInfo(req.IP, req.UserAgent),
+		Tenant:      req.Tenant,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{TokenData: tokenData}, nil
 }
 
 // RefreshToken 刷新Token
-func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*TokenData, error) {
+// 每次刷新都会轮换会话：吊销当前会话并在同一令牌族中创建新会话
+// 已轮换的刷新令牌再次出现说明令牌可能被盗用，此时吊销整个令牌族；tenant不为空时切换到该租户
+func (s *authService) RefreshToken(ctx context.Context, refreshToken, tenant string) (*TokenData, error) {
 	// 验证Refresh Token
 	claims, err := s.jwtManager.ValidateRefreshToken(refreshToken)
 	if err != nil {
@@ -244,9 +498,21 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*T
 
 	// 检查会话是否存在且未被吊销
 	session, err := s.sessionRepo.GetBySessionID(ctx, claims.JTI)
-	if err != nil || session.IsRevoked {
+	if err != nil {
 		return nil, errors.New("会话已失效")
 	}
+	if session.IsRevoked {
+		if session.RotatedAt != nil {
+			s.handleRefreshTokenReuse(ctx, session)
+		}
+		return nil, errors.New("会话已失效")
+	}
+	if s.sessionIdle(session) {
+		if err := s.revokeLogin(ctx, session, "session_idle"); err != nil {
+			logger.Error("吊销空闲会话失败: %v", err)
+		}
+		return nil, errors.New("会话长时间未活动已失效，请重新登录")
+	}
 
 	// 获取用户信息
 	user, err := s.userRepo.GetByID(session.UserID.Hex())
@@ -254,8 +520,50 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*T
 		return nil, errors.New("用户不存在")
 	}
 
-	// 生成新的Token
-	return s.generateTokens(ctx, user)
+	if user.Status != "active" {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	// 轮换会话前确定租户，租户无效时原刷新令牌仍可重试，不会被误判为重放
+	tenantID, err := s.resolveTenant(ctx, user, tenant, session)
+	if err != nil {
+		return nil, err
+	}
+
+	// 轮换会话，失败说明该令牌已被并发请求使用
+	rotated, err := s.sessionRepo.RotateSession(ctx, session.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		s.handleRefreshTokenReuse(ctx, session)
+		return nil, errors.New("会话已失效")
+	}
+
+	// 生成新的Token，沿用原会话的客户端、授权范围和激活的角色
+	return s.generateTokens(ctx, user, &IssueOptions{
+		ClientID:    session.ClientID,
+		Scope:       session.Scope,
+		AuthMethods: session.AuthMethods,
+		ActiveRoles: session.ActiveRoles,
+		Tenant:      tenant,
+		tenantID:    &tenantID,
+	}, session)
+}
+
+// handleRefreshTokenReuse 处理刷新令牌重放：吊销整个令牌族及其签发的访问令牌并记录安全事件
+func (s *authService) handleRefreshTokenReuse(ctx context.Context, session *models.Session) {
+	familyID := sessionFamilyID(session)
+	if err := s.revokeLogin(ctx, session, "refresh_token_reuse"); err != nil {
+		logger.Error("吊销刷新令牌族失败: %v", err)
+	}
+
+	logger.SecurityEvent("refresh_token_reuse", map[string]interface{}{
+		"user_id":    session.UserID.Hex(),
+		"session_id": session.SessionID,
+		"family_id":  familyID,
+		"client_id":  session.ClientID,
+	})
 }
 
 // VerifyToken 验证Token
@@ -266,68 +574,136 @@ func (s *authService) VerifyToken(ctx context.Context, req *VerifyTokenRequest)
 		return &VerifyResult{Valid: false}, err
 	}
 
+	if s.revocation.IsRevoked(claims) {
+		return &VerifyResult{Valid: false}, errors.New("Token已被吊销")
+	}
+
 	result := &VerifyResult{
 		Valid:       true,
 		UserID:      claims.UserID,
+		SubjectType: claims.PrincipalType(),
 		Username:    claims.Username,
+		TenantID:    claims.TenantID,
 		Roles:       claims.Roles,
 		Permissions: claims.Permissions,
 	}
 
 	// 如果指定了资源和操作，检查权限
 	if req.Resource != "" && req.Action != "" {
-		result.HasAccess = s.checkPermission(claims.Permissions, req.Resource, req.Action)
+		result.HasAccess = rbac.Allowed(claims.Permissions, req.Resource, req.Action)
 	}
 
 	return result, nil
 }
 
-// Logout 用户登出
-func (s *authService) Logout(ctx context.Context, token string) error {
+// Logout 用户登出，默认只吊销当前会话，all为true时吊销用户的全部会话
+func (s *authService) Logout(ctx context.Context, token string, all bool) error {
 	// 验证Token
 	claims, err := s.jwtManager.ValidateAccessToken(token)
 	if err != nil {
 		return err
 	}
 
+	if !all {
+		return s.revokeCurrentSession(ctx, claims, "logout")
+	}
+
 	// 吊销相关的会话
 	userObjID, err := primitive.ObjectIDFromHex(claims.UserID)
 	if err != nil {
 		return err
 	}
-	return s.sessionRepo.RevokeUserSessions(ctx, userObjID)
+	if err := s.sessionRepo.RevokeUserSessions(ctx, userObjID); err != nil {
+		return err
+	}
+
+	// 吊销已签发的访问令牌，使登出立即生效
+	return s.revocation.RevokeUser(ctx, claims.UserID, "logout")
 }
 
-// generateTokens 生成Token对
-func (s *authService) generateTokens(ctx context.Context, user *models.User) (*TokenData, error) {
-	// 提取用户角色和权限
-	roles := make([]string, len(user.Roles))
-	var permissions []string
-	permissionSet := make(map[string]bool) // 用于去重
+// IssueTokens 为已完成认证的用户签发Token，供OAuth/OIDC等授权流程复用会话机制
+func (s *authService) IssueTokens(ctx context.Context, userID string, opts *IssueOptions) (*TokenData, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if user.Status != "active" {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	return s.generateTokens(ctx, user, opts, nil)
+}
 
-	for i, role := range user.Roles {
-		roles[i] = role.RoleName
+// IssueServiceToken 为服务账号签发访问令牌，供客户端凭证模式使用
+// 服务账号没有会话和刷新令牌，无法完成多因素认证，要求多因素认证的角色不生效
+func (s *authService) IssueServiceToken(ctx context.Context, client *models.OAuthClient, scope string) (*TokenData, error) {
+	grants := resolveGrants(s.roleRepo, s.sodRepo, client.Roles, false, nil)
+
+	accessToken, accessClaims, err := s.jwtManager.GenerateAccessToken(&jwt.Claims{
+		UserID:           client.ClientID,
+		Username:         client.Name,
+		Roles:            grants.roles,
+		Permissions:      grants.permissions,
+		Scope:            scope,
+		ClientID:         client.ClientID,
+		SubjectType:      jwt.SubjectTypeService,
+		RegisteredClaims: grants.tokenClaims(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenData{
+		AccessToken: accessToken,
+		ExpiresIn:   accessClaims.ExpiresAt.Unix() - time.Now().Unix(),
+		TokenType:   "Bearer",
+		ExpiresAt:   accessClaims.ExpiresAt.Time,
+		UserID:      client.ClientID,
+		Roles:       grants.roles,
+	}, nil
+}
+
+// generateTokens 生成Token对，parent为轮换前的会话，首次登录时为nil
+func (s *authService) generateTokens(ctx context.Context, user *models.User, opts *IssueOptions, parent *models.Session) (*TokenData, error) {
+	if opts == nil {
+		opts = &IssueOptions{}
+	}
 
-		// 从角色中提取权限
-		rolePermissions, err := s.roleRepo.GetRolePermissions(role.RoleID.Hex())
+	var tenantID primitive.ObjectID
+	if opts.tenantID != nil {
+		tenantID = *opts.tenantID
+	} else {
+		resolved, err := s.resolveTenant(ctx, user, opts.Tenant, parent)
 		if err != nil {
-			continue // 忽略错误，继续处理其他角色
+			return nil, err
 		}
+		tenantID = resolved
+	}
+
+	// 激活的角色属于原租户，切换租户后需要重新选择
+	activeRoles := opts.ActiveRoles
+	if parent != nil && sessionTenant(parent) != tenantID {
+		activeRoles = nil
+	}
+
+	mfaVerified := containsMethod(opts.AuthMethods, AuthMethodMFA)
 
-		// 添加权限到集合中（去重）
-		for _, perm := range rolePermissions {
-			permKey := perm.Resource + ":" + perm.Action
-			if !permissionSet[permKey] {
-				permissionSet[permKey] = true
-				permissions = append(permissions, permKey)
-			}
+	// 提取用户在该租户中直接分配和所属用户组的角色和权限，要求多因素认证的角色仅在完成多因素认证的会话中生效
+	grants := resolveGrants(s.roleRepo, s.sodRepo, effectiveAssignments(ctx, s.groupRepo, user, tenantID), mfaVerified, activeRoles)
+	roles, permissions := grants.roles, grants.permissions
+
+	// 新登录受并发会话数限制，轮换不产生新的登录
+	if parent == nil {
+		if err := s.enforceSessionLimit(ctx, user, grants.maxSessions); err != nil {
+			return nil, err
 		}
 	}
 
-	// 生成Access Token
-	accessToken, accessClaims, err := s.jwtManager.GenerateAccessToken(user.ID.Hex(), user.Username, roles, permissions)
-	if err != nil {
-		return nil, err
+	// 轮换产生的会话沿用原会话的登录时间
+	loginAt := time.Now()
+	if parent != nil {
+		loginAt = sessionLoginAt(parent)
 	}
 
 	// 生成Refresh Token
@@ -336,15 +712,53 @@ func (s *authService) generateTokens(ctx context.Context, user *models.User) (*T
 		return nil, err
 	}
 
+	// 生成Access Token，绑定到会话以便随会话一起吊销
+	accessToken, accessClaims, err := s.jwtManager.GenerateAccessToken(&jwt.Claims{
+		UserID:           user.ID.Hex(),
+		Username:         user.Username,
+		Roles:            roles,
+		Permissions:      permissions,
+		Department:       user.Profile.Department,
+		Scope:            opts.Scope,
+		ClientID:         opts.ClientID,
+		SessionID:        refreshClaims.JTI,
+		AuthMethods:      opts.AuthMethods,
+		AuthTime:         loginAt.Unix(),
+		SubjectType:      jwt.SubjectTypeUser,
+		TenantID:         tenantHex(tenantID),
+		RegisteredClaims: grants.tokenClaims(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建会话记录
 	session := &models.Session{
 		SessionID:      refreshClaims.JTI,
 		UserID:         user.ID,
+		ClientID:       opts.ClientID,
+		Scope:          opts.Scope,
+		AuthMethods:    opts.AuthMethods,
+		ActiveRoles:    activeRoles,
+		DeviceInfo:     opts.Device,
+		LoginAt:        loginAt,
 		ExpiresAt:      refreshClaims.ExpiresAt.Time,
 		CreatedAt:      time.Now(),
 		LastAccessedAt: time.Now(),
 		IsRevoked:      false,
 	}
+	if !tenantID.IsZero() {
+		session.TenantID = &tenantID
+	}
+
+	// 新登录开启新的令牌族，轮换产生的会话加入原令牌族，沿用登录时间和设备信息
+	if parent != nil {
+		session.FamilyID = sessionFamilyID(parent)
+		session.ParentID = parent.SessionID
+		session.DeviceInfo = parent.DeviceInfo
+	} else {
+		session.FamilyID = session.SessionID
+	}
 
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
 		return nil, err
@@ -357,14 +771,23 @@ func (s *authService) generateTokens(ctx context.Context, user *models.User) (*T
 		TokenType:    "Bearer",
 		ExpiresAt:    accessClaims.ExpiresAt.Time,
 		UserID:       user.ID.Hex(),
+		SessionID:    session.SessionID,
+		Roles:        roles,
 	}, nil
 }
 
-// checkPermission 检查权限
-func (s *authService) checkPermission(permissions []string, resource, action string) bool {
-	requiredPermission := resource + ":" + action
-	for _, perm := range permissions {
-		if perm == requiredPermission {
+// sessionFamilyID 获取会话所属的令牌族，兼容未记录令牌族的历史会话
+func sessionFamilyID(session *models.Session) string {
+	if session.FamilyID != "" {
+		return session.FamilyID
+	}
+	return session.SessionID
+}
+
+// containsMethod 判断认证方式列表中是否包含指定方式
+func containsMethod(methods []string, target string) bool {
+	for _, method := range methods {
+		if method == target {
 			return true
 		}
 	}
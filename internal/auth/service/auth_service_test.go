@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"authcenter/internal/config"
+	"authcenter/internal/models"
+	"authcenter/internal/testutil"
+	verificationService "authcenter/internal/verification/service"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/password"
+)
+
+// testPassword 测试用户的密码，符合testSecurity的密码策略
+const testPassword = "Correct-Horse-1"
+
+// testSecurity 测试使用的安全配置
+var testSecurity = config.SecurityConfig{
+	MaxLoginAttempts:  3,
+	LockoutDuration:   15 * time.Minute,
+	PasswordMinLength: 8,
+	PasswordMaxLength: 72,
+	PasswordHistory:   3,
+}
+
+// testEnv 使用内存仓储的认证服务
+type testEnv struct {
+	svc         *authService
+	users       *testutil.Users
+	sessions    *testutil.Sessions
+	attempts    *testutil.LoginAttempts
+	roles       *testutil.Roles
+	sod         *testutil.SoDRules
+	tenants     *testutil.Tenants
+	groups      *testutil.Groups
+	revocations *testutil.Revocations
+	tokens      *testutil.VerificationTokens
+	mailbox     *testutil.Mailbox
+	jwt         jwt.Manager
+}
+
+// newTestEnv 创建认证服务，security为nil时使用testSecurity
+func newTestEnv(t *testing.T, security *config.SecurityConfig) *testEnv {
+	t.Helper()
+
+	if security == nil {
+		security = &testSecurity
+	}
+
+	// 降低argon2id的开销，加快测试
+	passwords, err := password.NewManager(password.Config{
+		Algorithm:     password.AlgorithmArgon2id,
+		Argon2Memory:  64,
+		Argon2Time:    1,
+		Argon2Threads: 1,
+	})
+	if err != nil {
+		t.Fatalf("password.NewManager: %v", err)
+	}
+
+	env := &testEnv{
+		users:       testutil.NewUsers(),
+		sessions:    testutil.NewSessions(),
+		attempts:    testutil.NewLoginAttempts(),
+		roles:       testutil.NewRoles(),
+		sod:         testutil.NewSoDRules(),
+		tenants:     testutil.NewTenants(),
+		groups:      testutil.NewGroups(),
+		revocations: testutil.NewRevocations(),
+		tokens:      testutil.NewVerificationTokens(),
+		mailbox:     testutil.NewMailbox(),
+		jwt:         jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour, "test"),
+	}
+
+	env.users.SetGroups(env.groups)
+
+	email := verificationService.NewEmailService(env.tokens, env.mailbox, config.MailConfig{
+		PasswordResetURL:        "https://auth.example.com/reset",
+		EmailVerifyURL:          "https://auth.example.com/verify",
+		PasswordResetExpire:     30 * time.Minute,
+		EmailVerificationExpire: 24 * time.Hour,
+	})
+	revocation := NewRevocationService(env.revocations, 15*time.Minute)
+
+	env.svc = NewAuthService(env.users, env.sessions, env.attempts, env.roles, env.sod, env.tenants, env.groups,
+		env.jwt, passwords, nil, revocation, nil, nil, nil, email, *security,
+	).(*authService)
+
+	return env
+}
+
+// createUser 创建设置了testPassword的用户
+func (e *testEnv) createUser(t *testing.T, user *models.User) *models.User {
+	t.Helper()
+
+	hash, err := e.svc.passwords.HashPassword(testPassword)
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	user.PasswordHash = hash
+	return e.users.Put(user)
+}
+
+// 刷新令牌不重新认证用户，访问令牌的auth_time沿用登录时间
+func TestRefreshTokenKeepsAuthTime(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice"})
+
+	issued, err := env.svc.IssueTokens(ctx, user.ID.Hex(), &IssueOptions{AuthMethods: []string{AuthMethodPassword}})
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+
+	// 模拟一小时前的登录
+	session, err := env.sessions.GetBySessionID(ctx, issued.SessionID)
+	if err != nil {
+		t.Fatalf("GetBySessionID: %v", err)
+	}
+	loginAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	session.LoginAt = loginAt
+	if err := env.sessions.Update(ctx, session); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	refreshed, err := env.svc.RefreshToken(ctx, issued.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	claims, err := env.jwt.ValidateAccessToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	if claims.AuthTime != loginAt.Unix() {
+		t.Fatalf("auth_time = %d，期望登录时间 %d", claims.AuthTime, loginAt.Unix())
+	}
+}
+
+// accessTokenRevoked 访问令牌是否已被吊销
+func (e *testEnv) accessTokenRevoked(t *testing.T, accessToken string) bool {
+	t.Helper()
+
+	claims, err := e.jwt.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	return e.svc.revocation.IsRevoked(claims)
+}
+
+// 已轮换的刷新令牌再次使用时吊销整个令牌族，包括最新的刷新令牌和已签发的访问令牌
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice"})
+	other, err := env.svc.IssueTokens(ctx, user.ID.Hex(), nil)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+
+	first, err := env.svc.IssueTokens(ctx, user.ID.Hex(), nil)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	second, err := env.svc.RefreshToken(ctx, first.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	if _, err := env.svc.RefreshToken(ctx, first.RefreshToken, ""); err == nil {
+		t.Fatal("已轮换的刷新令牌再次刷新成功")
+	}
+
+	if _, err := env.svc.RefreshToken(ctx, second.RefreshToken, ""); err == nil {
+		t.Error("重放后令牌族中最新的刷新令牌仍然可用")
+	}
+	if !env.accessTokenRevoked(t, first.AccessToken) || !env.accessTokenRevoked(t, second.AccessToken) {
+		t.Error("重放后令牌族签发的访问令牌未被吊销")
+	}
+
+	// 同一用户的其他登录不受影响
+	if env.accessTokenRevoked(t, other.AccessToken) {
+		t.Error("其他登录的访问令牌被吊销")
+	}
+	if _, err := env.svc.RefreshToken(ctx, other.RefreshToken, ""); err != nil {
+		t.Errorf("其他登录的刷新令牌不可用: %v", err)
+	}
+}
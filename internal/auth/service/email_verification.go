@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	verificationService "authcenter/internal/verification/service"
+	"authcenter/pkg/logger"
+)
+
+// ChangeEmailRequest 修改邮箱请求，设置了密码的用户需提供当前密码
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password,omitempty"`
+}
+
+// VerifyEmail 使用邮件中的令牌验证邮箱
+func (s *authService) VerifyEmail(ctx context.Context, token string) error {
+	record, err := s.email.ConsumeToken(ctx, token, verificationService.PurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	verified, err := s.userRepo.MarkEmailVerified(record.UserID.Hex(), record.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return errors.New("邮箱已变更，请重新验证")
+	}
+
+	logger.SecurityEvent("email_verified", map[string]interface{}{
+		"user_id": record.UserID.Hex(),
+		"email":   record.Email,
+	})
+
+	return nil
+}
+
+// ResendEmailVerification 重新发送验证邮件
+func (s *authService) ResendEmailVerification(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	if user.Email == "" {
+		return errors.New("用户未设置邮箱")
+	}
+	if user.EmailVerified {
+		return errors.New("邮箱已验证")
+	}
+
+	return s.email.SendEmailVerification(ctx, user)
+}
+
+// ChangeEmail 修改邮箱，新邮箱需要重新验证
+func (s *authService) ChangeEmail(ctx context.Context, userID string, req *ChangeEmailRequest) error {
+	email := strings.TrimSpace(req.Email)
+	if !strings.Contains(email, "@") {
+		return errors.New("邮箱格式错误")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	if user.PasswordHash != "" && !s.checkPassword(user, req.Password) {
+		return errors.New("密码错误")
+	}
+
+	if strings.EqualFold(user.Email, email) {
+		return errors.New("新邮箱与当前邮箱相同")
+	}
+
+	existingUser, _ := s.userRepo.GetByEmail(email)
+	if existingUser != nil {
+		return errors.New("邮箱已被注册")
+	}
+
+	if err := s.userRepo.UpdateEmail(userID, email); err != nil {
+		return err
+	}
+
+	// 发往旧邮箱的重置密码和验证链接随之失效，避免旧邮箱的持有者借此接管账号
+	if err := s.email.InvalidateTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("email_changed", map[string]interface{}{
+		"user_id":   userID,
+		"old_email": user.Email,
+		"new_email": email,
+	})
+
+	user.Email = email
+	user.EmailVerified = false
+	return s.email.SendEmailVerification(ctx, user)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"authcenter/internal/models"
+	"authcenter/pkg/logger"
+)
+
+// lockoutEnabled 是否启用账户锁定
+func (s *authService) lockoutEnabled() bool {
+	return s.security.MaxLoginAttempts > 0 && s.security.LockoutDuration > 0
+}
+
+// checkLockout 检查用户是否处于锁定状态，锁定到期时自动解锁
+// 管理员手动锁定的用户没有解锁时间，只能由管理员解锁
+func (s *authService) checkLockout(user *models.User) error {
+	if user.Status != "locked" {
+		return nil
+	}
+
+	if user.LockedUntil == nil {
+		return errors.New("账户已被锁定，请联系管理员")
+	}
+
+	if time.Now().Before(*user.LockedUntil) {
+		return errors.New("账户已被锁定，请于" + user.LockedUntil.Format("2006-01-02 15:04:05") + "后重试")
+	}
+
+	released, err := s.userRepo.ReleaseLockout(user.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if !released {
+		return errors.New("账户已被锁定，请联系管理员")
+	}
+	user.Status = "active"
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+
+	logger.SecurityEvent("account_unlocked", map[string]interface{}{
+		"user_id": user.ID.Hex(),
+		"reason":  "lockout_expired",
+	})
+
+	return nil
+}
+
+// recordLoginFailure 记录用户登录失败，达到阈值时锁定账户
+// cause为失败原因，未触发锁定时原样返回给调用方
+func (s *authService) recordLoginFailure(user *models.User, ip string, cause error) error {
+	attempts, err := s.userRepo.IncrementFailedLogins(user.ID.Hex())
+	if err != nil {
+		logger.Error("记录登录失败次数失败: %v", err)
+		return cause
+	}
+
+	logger.SecurityEvent("login_failed", map[string]interface{}{
+		"user_id":  user.ID.Hex(),
+		"username": user.Username,
+		"ip":       ip,
+		"attempts": attempts,
+		"reason":   cause.Error(),
+	})
+
+	if !s.lockoutEnabled() || attempts < s.security.MaxLoginAttempts {
+		return cause
+	}
+
+	until := time.Now().Add(s.security.LockoutDuration)
+	locked, err := s.userRepo.Lock(user.ID.Hex(), until)
+	if err != nil {
+		logger.Error("锁定账户失败: %v", err)
+		return cause
+	}
+	if !locked {
+		return cause // 用户已被禁用或锁定，保持原状态
+	}
+
+	logger.SecurityEvent("account_locked", map[string]interface{}{
+		"user_id":      user.ID.Hex(),
+		"username":     user.Username,
+		"ip":           ip,
+		"attempts":     attempts,
+		"locked_until": until,
+	})
+
+	return errors.New("登录失败次数过多，账户已被锁定")
+}
+
+// recordLoginSuccess 登录成功后清零失败次数
+func (s *authService) recordLoginSuccess(user *models.User) {
+	if user.FailedLoginAttempts == 0 {
+		return
+	}
+	if err := s.userRepo.ResetFailedLogins(user.ID.Hex()); err != nil {
+		logger.Error("清零登录失败次数失败: %v", err)
+	}
+}
+
+// checkIdentifierLockout 检查不存在用户的登录标识是否被锁定
+func (s *authService) checkIdentifierLockout(ctx context.Context, identifier string) error {
+	attempt, err := s.loginAttemptRepo.Get(ctx, normalizeIdentifier(identifier))
+	if err != nil {
+		return nil
+	}
+
+	if attempt.LockedUntil != nil && time.Now().Before(*attempt.LockedUntil) {
+		return errors.New("账户已被锁定，请于" + attempt.LockedUntil.Format("2006-01-02 15:04:05") + "后重试")
+	}
+
+	return nil
+}
+
+// recordIdentifierFailure 记录不存在用户的登录失败，与真实用户使用相同的锁定策略，避免借此探测账户
+func (s *authService) recordIdentifierFailure(ctx context.Context, identifier, ip string) {
+	if identifier == "" {
+		return
+	}
+	identifier = normalizeIdentifier(identifier)
+
+	attempt, err := s.loginAttemptRepo.RecordFailure(ctx, identifier, s.security.LockoutDuration)
+	if err != nil {
+		logger.Error("记录登录失败次数失败: %v", err)
+		return
+	}
+
+	logger.SecurityEvent("login_failed", map[string]interface{}{
+		"identifier": identifier,
+		"ip":         ip,
+		"attempts":   attempt.FailedCount,
+		"reason":     "user_not_found",
+	})
+
+	if !s.lockoutEnabled() || attempt.FailedCount < s.security.MaxLoginAttempts {
+		return
+	}
+
+	until := time.Now().Add(s.security.LockoutDuration)
+	if err := s.loginAttemptRepo.Lock(ctx, identifier, until); err != nil {
+		logger.Error("锁定登录标识失败: %v", err)
+		return
+	}
+
+	logger.SecurityEvent("account_locked", map[string]interface{}{
+		"identifier":   identifier,
+		"ip":           ip,
+		"attempts":     attempt.FailedCount,
+		"locked_until": until,
+		"reason":       "user_not_found",
+	})
+}
+
+// normalizeIdentifier 统一登录标识格式
+func normalizeIdentifier(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"authcenter/internal/models"
+)
+
+// login 使用用户名和密码登录
+func (e *testEnv) login(username, password string) (*LoginResult, error) {
+	return e.svc.Login(context.Background(), &LoginRequest{Type: "username", Username: username, Password: password})
+}
+
+func TestLoginLocksAccountAfterMaxAttempts(t *testing.T) {
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice"})
+
+	for i := 0; i < testSecurity.MaxLoginAttempts; i++ {
+		if _, err := env.login("alice", "wrong-password"); err == nil {
+			t.Fatal("错误的密码登录成功")
+		}
+	}
+
+	got := env.users.Get(user.ID)
+	if got.Status != "locked" || got.LockedUntil == nil {
+		t.Fatalf("状态 = %s（解锁时间 %v），期望被锁定并设置解锁时间", got.Status, got.LockedUntil)
+	}
+	if _, err := env.login("alice", testPassword); err == nil {
+		t.Fatal("锁定期间使用正确的密码登录成功")
+	}
+}
+
+// 锁定到期后使用正确的密码登录时自动解锁并清零失败次数
+func TestLoginReleasesExpiredLockout(t *testing.T) {
+	env := newTestEnv(t, nil)
+	lockedUntil := time.Now().Add(-time.Minute)
+	user := env.createUser(t, &models.User{
+		Username:            "alice",
+		Status:              "locked",
+		LockedUntil:         &lockedUntil,
+		FailedLoginAttempts: testSecurity.MaxLoginAttempts,
+	})
+
+	result, err := env.login("alice", testPassword)
+	if err != nil {
+		t.Fatalf("锁定到期后登录失败: %v", err)
+	}
+	if result.TokenData == nil {
+		t.Fatal("未签发令牌")
+	}
+
+	got := env.users.Get(user.ID)
+	if got.Status != "active" || got.LockedUntil != nil || got.FailedLoginAttempts != 0 {
+		t.Fatalf("状态 = %s，解锁时间 %v，失败次数 %d，期望已解锁", got.Status, got.LockedUntil, got.FailedLoginAttempts)
+	}
+}
+
+// 管理员锁定和禁用的账户不会因登录失败被改为可自动解锁的锁定
+func TestLoginLockoutKeepsAdministrativeStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+	}{
+		{"管理员锁定", "locked"},
+		{"已禁用", "inactive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, nil)
+			user := env.createUser(t, &models.User{Username: "alice", Status: tt.status})
+
+			for i := 0; i < testSecurity.MaxLoginAttempts+1; i++ {
+				if _, err := env.login("alice", "wrong-password"); err == nil {
+					t.Fatal("错误的密码登录成功")
+				}
+			}
+			if _, err := env.login("alice", testPassword); err == nil {
+				t.Fatal("使用正确的密码登录成功")
+			}
+
+			got := env.users.Get(user.ID)
+			if got.Status != tt.status || got.LockedUntil != nil {
+				t.Fatalf("状态 = %s（解锁时间 %v），期望保持 %s", got.Status, got.LockedUntil, tt.status)
+			}
+		})
+	}
+}
+
+// 不存在的登录标识与真实用户使用相同的锁定策略
+func TestLoginLocksUnknownIdentifier(t *testing.T) {
+	env := newTestEnv(t, nil)
+
+	for i := 0; i < testSecurity.MaxLoginAttempts; i++ {
+		if _, err := env.login("Nobody", "wrong-password"); err == nil {
+			t.Fatal("不存在的用户登录成功")
+		}
+	}
+
+	attempt, err := env.attempts.Get(context.Background(), "nobody")
+	if err != nil {
+		t.Fatalf("未记录登录失败: %v", err)
+	}
+	if attempt.LockedUntil == nil || !attempt.LockedUntil.After(time.Now()) {
+		t.Fatalf("登录标识未被锁定: %+v", attempt)
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"authcenter/internal/models"
+	userRepo "authcenter/internal/user/repository"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/totp"
+	"authcenter/pkg/utils"
+)
+
+// 认证方式（RFC 8176），记录在访问令牌的amr声明中
+const (
+	AuthMethodPassword = "pwd" // 密码
+	AuthMethodSMS      = "sms" // 短信验证码
+	AuthMethodOTP      = "otp" // 一次性密码，包括TOTP和恢复码
+	AuthMethodMFA      = "mfa" // 已完成多因素认证
+)
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// MFAService 多因素认证服务接口
+type MFAService interface {
+	// BeginTOTPEnrollment 生成待确认的TOTP密钥
+	BeginTOTPEnrollment(ctx context.Context, userID string) (*TOTPEnrollment, error)
+
+	// ConfirmTOTPEnrollment 校验验证码后启用多因素认证，返回恢复码明文
+	ConfirmTOTPEnrollment(ctx context.Context, userID, code string) ([]string, error)
+
+	// RegenerateRecoveryCodes 重新生成恢复码，原有恢复码全部失效
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+
+	// DisableMFA 用户停用多因素认证，需提供当前验证码
+	DisableMFA(ctx context.Context, userID, code string) error
+
+	// ResetMFA 管理员重置用户的多因素认证
+	ResetMFA(ctx context.Context, userID, operatorID string) error
+
+	// Verify 校验TOTP验证码或恢复码
+	Verify(ctx context.Context, user *models.User, code, recoveryCode string) error
+}
+
+// TOTPEnrollment TOTP注册信息
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// mfaService 多因素认证服务实现
+type mfaService struct {
+	userRepo userRepo.UserRepository
+	issuer   string // 验证器应用中显示的服务名称
+}
+
+// NewMFAService 创建多因素认证服务
+func NewMFAService(userRepo userRepo.UserRepository, issuer string) MFAService {
+	return &mfaService{
+		userRepo: userRepo,
+		issuer:   issuer,
+	}
+}
+
+// BeginTOTPEnrollment 生成待确认的TOTP密钥
+func (s *mfaService) BeginTOTPEnrollment(ctx context.Context, userID string) (*TOTPEnrollment, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if user.MFA.Enabled {
+		return nil, errors.New("已启用多因素认证")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.MFA.PendingSecret = secret
+	if err := s.userRepo.UpdateMFA(userID, &user.MFA); err != nil {
+		return nil, err
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, account, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment 校验验证码后启用多因素认证
+func (s *mfaService) ConfirmTOTPEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if user.MFA.Enabled {
+		return nil, errors.New("已启用多因素认证")
+	}
+	if user.MFA.PendingSecret == "" {
+		return nil, errors.New("请先生成TOTP密钥")
+	}
+
+	counter, ok := totp.Validate(code, user.MFA.PendingSecret, time.Now())
+	if !ok {
+		return nil, errors.New("验证码错误")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	mfa := &models.MFASettings{
+		Enabled:         true,
+		TOTPSecret:      user.MFA.PendingSecret,
+		RecoveryCodes:   hashes,
+		LastUsedCounter: counter,
+		EnabledAt:       &now,
+	}
+	if err := s.userRepo.UpdateMFA(userID, mfa); err != nil {
+		return nil, err
+	}
+
+	logger.SecurityEvent("mfa_enabled", map[string]interface{}{
+		"user_id": userID,
+		"method":  "totp",
+	})
+
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if !user.MFA.Enabled {
+		return nil, errors.New("未启用多因素认证")
+	}
+
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	// 重新读取以保留验证时写入的时间步
+	user, err = s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	user.MFA.RecoveryCodes = hashes
+	if err := s.userRepo.UpdateMFA(userID, &user.MFA); err != nil {
+		return nil, err
+	}
+
+	logger.SecurityEvent("mfa_recovery_codes_regenerated", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return codes, nil
+}
+
+// DisableMFA 用户停用多因素认证
+func (s *mfaService) DisableMFA(ctx context.Context, userID, code string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	if !user.MFA.Enabled {
+		return errors.New("未启用多因素认证")
+	}
+
+	if err := s.verifyTOTP(user, code); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateMFA(userID, &models.MFASettings{}); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("mfa_disabled", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return nil
+}
+
+// ResetMFA 管理员重置用户的多因素认证
+func (s *mfaService) ResetMFA(ctx context.Context, userID, operatorID string) error {
+	if err := s.userRepo.UpdateMFA(userID, &models.MFASettings{}); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("mfa_reset", map[string]interface{}{
+		"user_id":     userID,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// Verify 校验TOTP验证码或恢复码
+func (s *mfaService) Verify(ctx context.Context, user *models.User, code, recoveryCode string) error {
+	if !user.MFA.Enabled {
+		return errors.New("未启用多因素认证")
+	}
+
+	if code != "" {
+		return s.verifyTOTP(user, code)
+	}
+
+	if recoveryCode == "" {
+		return errors.New("验证码不能为空")
+	}
+
+	consumed, err := s.userRepo.ConsumeRecoveryCode(user.ID.Hex(), utils.HashToken(normalizeRecoveryCode(recoveryCode)))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errors.New("恢复码无效或已使用")
+	}
+
+	logger.SecurityEvent("mfa_recovery_code_used", map[string]interface{}{
+		"user_id":   user.ID.Hex(),
+		"remaining": len(user.MFA.RecoveryCodes) - 1,
+	})
+
+	return nil
+}
+
+// verifyTOTP 校验TOTP验证码，同一验证码只能使用一次
+func (s *mfaService) verifyTOTP(user *models.User, code string) error {
+	counter, ok := totp.Validate(code, user.MFA.TOTPSecret, time.Now())
+	if !ok {
+		return errors.New("验证码错误")
+	}
+
+	fresh, err := s.userRepo.UseTOTPCounter(user.ID.Hex(), counter)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.New("验证码已使用")
+	}
+
+	return nil
+}
+
+// generateRecoveryCodes 生成恢复码，返回明文和用于存储的摘要
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := hex.EncodeToString(buf)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = utils.HashToken(raw)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode 统一恢复码格式，忽略大小写、空格和分隔符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	sessionRepo "authcenter/internal/auth/repository"
+	"authcenter/internal/config"
+	"authcenter/internal/models"
+	userRepo "authcenter/internal/user/repository"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/utils"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuthMethodHardwareKey 硬件密钥认证方式（RFC 8176），通行密钥登录时记录
+const AuthMethodHardwareKey = "hwk"
+
+// WebAuthn仪式用途
+const (
+	webAuthnPurposeRegistration = "registration"
+	webAuthnPurposeLogin        = "login"
+)
+
+// maxPasskeyNameLength 通行密钥名称的最大长度
+const maxPasskeyNameLength = 64
+
+// PasskeyService 通行密钥服务接口
+type PasskeyService interface {
+	// BeginRegistration 开始注册仪式，返回传给navigator.credentials.create的参数
+	BeginRegistration(ctx context.Context, userID string) (*PasskeyChallenge, error)
+
+	// FinishRegistration 校验认证器返回的凭证并保存
+	FinishRegistration(ctx context.Context, userID, sessionID, name string, credential []byte) (*models.WebAuthnCredential, error)
+
+	// BeginLogin 开始可发现凭证登录仪式，返回传给navigator.credentials.get的参数
+	BeginLogin(ctx context.Context) (*PasskeyChallenge, error)
+
+	// ValidateLogin 校验登录断言，返回凭证所属用户
+	ValidateLogin(ctx context.Context, sessionID string, credential []byte) (*models.User, error)
+
+	// ListPasskeys 获取用户的通行密钥
+	ListPasskeys(ctx context.Context, userID string) ([]models.WebAuthnCredential, error)
+
+	// RemovePasskey 删除用户的通行密钥
+	RemovePasskey(ctx context.Context, userID, credentialID string) error
+}
+
+// PasskeyChallenge WebAuthn仪式参数，完成仪式时需回传SessionID
+type PasskeyChallenge struct {
+	SessionID string      `json:"session_id"`
+	Options   interface{} `json:"options"`
+}
+
+// passkeyService 通行密钥服务实现
+type passkeyService struct {
+	userRepo    userRepo.UserRepository
+	sessionRepo sessionRepo.WebAuthnSessionRepository
+	webAuthn    *webauthn.WebAuthn
+	timeout     time.Duration
+}
+
+// NewPasskeyService 创建通行密钥服务
+// 注册和登录均要求用户验证（PIN或生物特征），并创建可发现凭证以支持免用户名登录
+func NewPasskeyService(
+	userRepo userRepo.UserRepository,
+	sessionRepo sessionRepo.WebAuthnSessionRepository,
+	cfg config.WebAuthnConfig,
+) (PasskeyService, error) {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPDisplayName,
+		RPOrigins:     cfg.RPOrigins,
+		Timeout:       int(cfg.Timeout.Milliseconds()),
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			UserVerification:   protocol.VerificationRequired,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &passkeyService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		webAuthn:    webAuthn,
+		timeout:     cfg.Timeout,
+	}, nil
+}
+
+// BeginRegistration 开始注册仪式
+func (s *passkeyService) BeginRegistration(ctx context.Context, userID string) (*PasskeyChallenge, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	// 排除已注册的凭证，避免同一认证器重复注册
+	owner := &webAuthnUser{user: user}
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.WebAuthnCredentials))
+	for _, credential := range owner.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	options, data, err := s.webAuthn.BeginRegistration(owner, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := s.saveSession(ctx, webAuthnPurposeRegistration, &user.ID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyChallenge{SessionID: sessionID, Options: options}, nil
+}
+
+// FinishRegistration 校验认证器返回的凭证并保存
+func (s *passkeyService) FinishRegistration(ctx context.Context, userID, sessionID, name string, credential []byte) (*models.WebAuthnCredential, error) {
+	session, err := s.sessionRepo.Consume(ctx, sessionID, webAuthnPurposeRegistration)
+	if err != nil {
+		return nil, errors.New("注册请求无效或已过期")
+	}
+	if session.UserID == nil || session.UserID.Hex() != userID {
+		return nil, errors.New("注册请求无效或已过期")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "通行密钥 " + time.Now().Format("2006-01-02")
+	}
+	if len([]rune(name)) > maxPasskeyNameLength {
+		return nil, errors.New("通行密钥名称过长")
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(credential))
+	if err != nil {
+		return nil, errors.New("凭证格式错误")
+	}
+
+	created, err := s.webAuthn.CreateCredential(&webAuthnUser{user: user}, sessionData(session), parsed)
+	if err != nil {
+		return nil, errors.New("凭证校验失败")
+	}
+
+	transports := make([]string, 0, len(created.Transport))
+	for _, transport := range created.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	passkey := &models.WebAuthnCredential{
+		CredentialID:    base64.RawURLEncoding.EncodeToString(created.ID),
+		Name:            name,
+		PublicKey:       created.PublicKey,
+		AttestationType: created.AttestationType,
+		AAGUID:          created.Authenticator.AAGUID,
+		SignCount:       created.Authenticator.SignCount,
+		Transports:      transports,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.userRepo.AddWebAuthnCredential(userID, passkey); err != nil {
+		return nil, err
+	}
+
+	logger.SecurityEvent("passkey_registered", map[string]interface{}{
+		"user_id":       userID,
+		"credential_id": passkey.CredentialID,
+	})
+
+	return passkey, nil
+}
+
+// BeginLogin 开始可发现凭证登录仪式
+func (s *passkeyService) BeginLogin(ctx context.Context) (*PasskeyChallenge, error) {
+	options, data, err := s.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := s.saveSession(ctx, webAuthnPurposeLogin, nil, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyChallenge{SessionID: sessionID, Options: options}, nil
+}
+
+// ValidateLogin 校验登录断言
+func (s *passkeyService) ValidateLogin(ctx context.Context, sessionID string, credential []byte) (*models.User, error) {
+	session, err := s.sessionRepo.Consume(ctx, sessionID, webAuthnPurposeLogin)
+	if err != nil {
+		return nil, errors.New("登录请求无效或已过期")
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(credential))
+	if err != nil {
+		return nil, errors.New("凭证格式错误")
+	}
+
+	// 用户句柄即注册时写入认证器的用户ID
+	var user *models.User
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		if len(userHandle) != len(primitive.ObjectID{}) {
+			return nil, errors.New("invalid user handle")
+		}
+		var id primitive.ObjectID
+		copy(id[:], userHandle)
+
+		found, err := s.userRepo.GetByID(id.Hex())
+		if err != nil {
+			return nil, err
+		}
+		user = found
+		return &webAuthnUser{user: found}, nil
+	}
+
+	validated, err := s.webAuthn.ValidateDiscoverableLogin(handler, sessionData(session), parsed)
+	if err != nil {
+		logger.SecurityEvent("passkey_login_failed", map[string]interface{}{
+			"credential_id": base64.RawURLEncoding.EncodeToString(parsed.RawID),
+			"reason":        err.Error(),
+		})
+		return nil, errors.New("通行密钥验证失败")
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(validated.ID)
+
+	// 签名计数器未递增说明认证器可能被克隆
+	if validated.Authenticator.CloneWarning {
+		logger.SecurityEvent("passkey_clone_detected", map[string]interface{}{
+			"user_id":       user.ID.Hex(),
+			"credential_id": credentialID,
+		})
+		return nil, errors.New("通行密钥验证失败")
+	}
+
+	if err := s.userRepo.UpdateWebAuthnCredentialUsage(user.ID.Hex(), credentialID, validated.Authenticator.SignCount); err != nil {
+		logger.Error("更新通行密钥使用记录失败: %v", err)
+	}
+
+	return user, nil
+}
+
+// ListPasskeys 获取用户的通行密钥
+func (s *passkeyService) ListPasskeys(ctx context.Context, userID string) ([]models.WebAuthnCredential, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if user.WebAuthnCredentials == nil {
+		return []models.WebAuthnCredential{}, nil
+	}
+	return user.WebAuthnCredentials, nil
+}
+
+// RemovePasskey 删除用户的通行密钥
+func (s *passkeyService) RemovePasskey(ctx context.Context, userID, credentialID string) error {
+	if err := s.userRepo.RemoveWebAuthnCredential(userID, credentialID); err != nil {
+		return errors.New("通行密钥不存在")
+	}
+
+	logger.SecurityEvent("passkey_removed", map[string]interface{}{
+		"user_id":       userID,
+		"credential_id": credentialID,
+	})
+
+	return nil
+}
+
+// saveSession 保存仪式数据，返回回传给客户端的会话ID
+func (s *passkeyService) saveSession(ctx context.Context, purpose string, userID *primitive.ObjectID, data *webauthn.SessionData) (string, error) {
+	sessionID, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	session := &models.WebAuthnSession{
+		SessionID:        sessionID,
+		Purpose:          purpose,
+		UserID:           userID,
+		Challenge:        data.Challenge,
+		UserVerification: string(data.UserVerification),
+		ExpiresAt:        now.Add(s.timeout),
+		CreatedAt:        now,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return "", err
+	}
+
+	return sessionID, nil
+}
+
+// sessionData 还原WebAuthn库所需的仪式数据
+func sessionData(session *models.WebAuthnSession) webauthn.SessionData {
+	data := webauthn.SessionData{
+		Challenge:        session.Challenge,
+		UserVerification: protocol.UserVerificationRequirement(session.UserVerification),
+	}
+	if session.UserID != nil {
+		data.UserID = session.UserID[:]
+	}
+	return data
+}
+
+// webAuthnUser 将用户模型适配为WebAuthn库的用户接口
+type webAuthnUser struct {
+	user *models.User
+}
+
+// WebAuthnID 用户句柄，使用ObjectID的原始字节，不包含个人信息
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+// WebAuthnName 用户名
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+// WebAuthnDisplayName 显示名称
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+// WebAuthnIcon 头像，规范已废弃该字段
+func (u *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+// WebAuthnCredentials 用户已注册的凭证
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.user.WebAuthnCredentials))
+	for _, passkey := range u.user.WebAuthnCredentials {
+		id, err := base64.RawURLEncoding.DecodeString(passkey.CredentialID)
+		if err != nil {
+			continue
+		}
+
+		transports := make([]protocol.AuthenticatorTransport, 0, len(passkey.Transports))
+		for _, transport := range passkey.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+
+		credentials = append(credentials, webauthn.Credential{
+			ID:              id,
+			PublicKey:       passkey.PublicKey,
+			AttestationType: passkey.AttestationType,
+			Transport:       transports,
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    passkey.AAGUID,
+				SignCount: passkey.SignCount,
+			},
+		})
+	}
+	return credentials
+}
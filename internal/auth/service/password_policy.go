@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/config"
+	"authcenter/internal/models"
+	"authcenter/pkg/breach"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/password"
+)
+
+// ChangePasswordRequest 修改密码请求，未设置密码的用户无需提供原密码
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password,omitempty"`
+	NewPassword string `json:"new_password" binding:"required"`
+	IP          string `json:"-"`
+}
+
+// ChangeExpiredPasswordRequest 登录时修改过期密码的请求
+type ChangeExpiredPasswordRequest struct {
+	PasswordChangeToken string `json:"password_change_token" binding:"required"`
+	NewPassword         string `json:"new_password" binding:"required"`
+	IP                  string `json:"-"`
+	UserAgent           string `json:"-"`
+}
+
+// newPasswordPolicy 根据安全配置创建密码策略，breachChecker为nil时不检查泄露密码
+func newPasswordPolicy(security config.SecurityConfig, breachChecker breach.Checker) *password.Policy {
+	return &password.Policy{
+		MinLength:        security.PasswordMinLength,
+		MaxLength:        security.PasswordMaxLength,
+		RequireUppercase: security.PasswordRequireUpper,
+		RequireLowercase: security.PasswordRequireLower,
+		RequireDigit:     security.PasswordRequireDigit,
+		RequireSymbol:    security.PasswordRequireSymbol,
+		DisallowUserInfo: security.PasswordRejectUserInfo,
+		HistoryCount:     security.PasswordHistory,
+		Breach:           breachChecker,
+	}
+}
+
+// validatePassword 按密码策略校验新密码，违反策略时返回*password.PolicyError
+func (s *authService) validatePassword(newPassword string, user *models.User) error {
+	info := &password.UserInfo{
+		Username: user.Username,
+		Email:    user.Email,
+	}
+	if user.PasswordHash != "" {
+		info.History = append([]string{user.PasswordHash}, user.PasswordHistory...)
+	}
+
+	return s.passwordPolicy.Validate(newPassword, info)
+}
+
+// setPassword 校验并保存新密码，原密码进入历史记录
+func (s *authService) setPassword(user *models.User, newPassword string) error {
+	if err := s.validatePassword(newPassword, user); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwords.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	var history []string
+	if s.security.PasswordHistory > 0 && user.PasswordHash != "" {
+		history = append([]string{user.PasswordHash}, user.PasswordHistory...)
+		if len(history) > s.security.PasswordHistory {
+			history = history[:s.security.PasswordHistory]
+		}
+	}
+
+	return s.userRepo.UpdatePassword(user.ID.Hex(), hashedPassword, history)
+}
+
+// checkPassword 验证用户密码，成功且摘要的算法或参数已过时时按当前配置重新生成
+// 明文密码只在验证成功时可用，借此逐步迁移摘要算法而无需用户重置密码
+func (s *authService) checkPassword(user *models.User, plain string) bool {
+	if user.PasswordHash == "" || s.passwords.CheckPassword(plain, user.PasswordHash) != nil {
+		return false
+	}
+
+	if s.passwords.NeedsRehash(user.PasswordHash) {
+		hashedPassword, err := s.passwords.HashPassword(plain)
+		if err == nil {
+			err = s.userRepo.UpdatePasswordHash(user.ID.Hex(), user.PasswordHash, hashedPassword)
+		}
+		if err != nil {
+			// 升级失败不影响本次验证，下次登录时重试
+			logger.Error("升级密码摘要失败: %v", err)
+		} else {
+			user.PasswordHash = hashedPassword
+		}
+	}
+
+	return true
+}
+
+// passwordExpired 密码是否超过最长使用时间，早于该功能创建的用户以注册时间计算
+func (s *authService) passwordExpired(user *models.User) bool {
+	if s.security.PasswordMaxAge <= 0 || user.PasswordHash == "" {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+
+	return time.Since(changedAt) > s.security.PasswordMaxAge
+}
+
+// passwordChangeChallenge 密码过期时返回修改密码的挑战，否则返回nil
+func (s *authService) passwordChangeChallenge(user *models.User, authMethods []string) (*LoginResult, error) {
+	if !containsMethod(authMethods, AuthMethodPassword) || !s.passwordExpired(user) {
+		return nil, nil
+	}
+
+	token, err := s.jwtManager.GeneratePasswordChangeToken(user.ID.Hex(), authMethods)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.SecurityEvent("password_expired", map[string]interface{}{
+		"user_id": user.ID.Hex(),
+	})
+
+	return &LoginResult{
+		PasswordChangeRequired: true,
+		PasswordChangeToken:    token,
+	}, nil
+}
+
+// ChangePassword 修改当前用户的密码，修改后吊销全部会话和访问令牌
+func (s *authService) ChangePassword(ctx context.Context, userID string, req *ChangePasswordRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	if user.PasswordHash != "" && !s.checkPassword(user, req.OldPassword) {
+		return errors.New("原密码错误")
+	}
+
+	if err := s.setPassword(user, req.NewPassword); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.RevokeUserSessions(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.revocation.RevokeUser(ctx, userID, "password_changed"); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("password_changed", map[string]interface{}{
+		"user_id": userID,
+		"ip":      req.IP,
+	})
+
+	return nil
+}
+
+// ChangeExpiredPassword 登录时修改过期密码，成功后签发Token
+func (s *authService) ChangeExpiredPassword(ctx context.Context, req *ChangeExpiredPasswordRequest) (*TokenData, error) {
+	claims, err := s.jwtManager.ValidatePasswordChangeToken(req.PasswordChangeToken)
+	if err != nil {
+		return nil, errors.New("修改密码令牌无效或已过期")
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if err := s.checkLockout(user); err != nil {
+		return nil, err
+	}
+	if user.Status != "active" {
+		return nil, errors.New("用户已被禁用")
+	}
+
+	// 密码修改后挑战令牌随即失效
+	if !s.passwordExpired(user) {
+		return nil, errors.New("修改密码令牌无效或已过期")
+	}
+
+	if err := s.setPassword(user, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	logger.SecurityEvent("password_changed", map[string]interface{}{
+		"user_id": claims.UserID,
+		"ip":      req.IP,
+		"reason":  "expired",
+	})
+
+	return s.generateTokens(ctx, user, &IssueOptions{
+		AuthMethods: claims.AuthMethods,
+		Device:      newDeviceInfo(req.IP, req.UserAgent),
+	}, nil)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	verificationService "authcenter/internal/verification/service"
+	"authcenter/pkg/logger"
+)
+
+// ForgotPasswordRequest 忘记密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+	IP    string `json:"-"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	IP       string `json:"-"`
+}
+
+// ForgotPassword 发送重置密码邮件
+// 邮箱未注册或用户已禁用时同样返回成功，避免借此探测邮箱是否注册
+func (s *authService) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil || user == nil || user.Status == "inactive" {
+		logger.SecurityEvent("password_reset_requested", map[string]interface{}{
+			"email":  normalizeIdentifier(req.Email),
+			"ip":     req.IP,
+			"result": "ignored",
+		})
+		return nil
+	}
+
+	if err := s.email.SendPasswordReset(ctx, user); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("password_reset_requested", map[string]interface{}{
+		"user_id": user.ID.Hex(),
+		"ip":      req.IP,
+		"result":  "sent",
+	})
+
+	return nil
+}
+
+// ResetPassword 使用邮件中的令牌设置新密码
+// 重置后吊销用户的全部会话和访问令牌，并清除因登录失败触发的锁定
+func (s *authService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	// 先检查与用户无关的规则，避免密码不合规时白白消耗令牌
+	if err := s.passwordPolicy.Validate(req.Password, nil); err != nil {
+		return err
+	}
+
+	token, err := s.email.ConsumeToken(ctx, req.Token, verificationService.PurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	userID := token.UserID.Hex()
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if user.Status == "inactive" {
+		return errors.New("用户已被禁用")
+	}
+	// 令牌发往的邮箱已不是用户当前的邮箱
+	if !strings.EqualFold(token.Email, user.Email) {
+		return errors.New("链接无效或已过期")
+	}
+
+	if err := s.setPassword(user, req.Password); err != nil {
+		return err
+	}
+
+	// 能够打开重置邮件即证明拥有该邮箱
+	if _, err := s.userRepo.MarkEmailVerified(userID, token.Email); err != nil {
+		logger.Error("标记邮箱已验证失败: %v", err)
+	}
+
+	// 管理员锁定的用户没有解锁时间，仍需管理员解锁
+	if user.Status == "locked" && user.LockedUntil != nil {
+		if _, err := s.userRepo.ReleaseLockout(userID); err != nil {
+			logger.Error("解锁账户失败: %v", err)
+		}
+	} else {
+		s.recordLoginSuccess(user)
+	}
+
+	if err := s.sessionRepo.RevokeUserSessions(ctx, token.UserID); err != nil {
+		return err
+	}
+	if err := s.revocation.RevokeUser(ctx, userID, "password_reset"); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("password_reset", map[string]interface{}{
+		"user_id": userID,
+		"ip":      req.IP,
+	})
+
+	return nil
+}
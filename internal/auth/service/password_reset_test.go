@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"authcenter/internal/models"
+)
+
+const newPassword = "Battery-Staple-2"
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice", Email: "alice@example.com"})
+
+	if err := env.svc.ForgotPassword(ctx, &ForgotPasswordRequest{Email: user.Email}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	token := env.mailbox.LastToken(user.Email)
+	if token == "" {
+		t.Fatal("未发送重置密码邮件")
+	}
+
+	if err := env.svc.ResetPassword(ctx, &ResetPasswordRequest{Token: token, Password: newPassword}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if got := env.users.Get(user.ID); env.svc.passwords.CheckPassword(newPassword, got.PasswordHash) != nil {
+		t.Fatal("密码未更新")
+	}
+
+	if err := env.svc.ResetPassword(ctx, &ResetPasswordRequest{Token: token, Password: "Another-Pass-3"}); err == nil {
+		t.Fatal("重置令牌被重复使用")
+	}
+}
+
+// 令牌发出后邮箱被修改，令牌不能用于重置新邮箱所属账户的密码
+func TestResetPasswordRejectsTokenForPreviousEmail(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice", Email: "alice@example.com"})
+
+	if err := env.svc.ForgotPassword(ctx, &ForgotPasswordRequest{Email: user.Email}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	token := env.mailbox.LastToken(user.Email)
+
+	// 绕过ChangeEmail直接修改邮箱，令牌仍未作废
+	if err := env.users.UpdateEmail(user.ID.Hex(), "bob@example.com"); err != nil {
+		t.Fatalf("UpdateEmail: %v", err)
+	}
+
+	if err := env.svc.ResetPassword(ctx, &ResetPasswordRequest{Token: token, Password: newPassword}); err == nil {
+		t.Fatal("发往旧邮箱的重置令牌重置了密码")
+	}
+	if got := env.users.Get(user.ID); env.svc.passwords.CheckPassword(testPassword, got.PasswordHash) != nil {
+		t.Fatal("密码被修改")
+	}
+}
+
+func TestChangeEmailInvalidatesMailedTokens(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice", Email: "alice@example.com"})
+
+	if err := env.svc.ResendEmailVerification(ctx, user.ID.Hex()); err != nil {
+		t.Fatalf("ResendEmailVerification: %v", err)
+	}
+	verifyToken := env.mailbox.LastToken(user.Email)
+
+	if err := env.svc.ForgotPassword(ctx, &ForgotPasswordRequest{Email: user.Email}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	resetToken := env.mailbox.LastToken(user.Email)
+
+	const newEmail = "alice@corp.example.com"
+	if err := env.svc.ChangeEmail(ctx, user.ID.Hex(), &ChangeEmailRequest{Email: newEmail, Password: testPassword}); err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+
+	if err := env.svc.ResetPassword(ctx, &ResetPasswordRequest{Token: resetToken, Password: newPassword}); err == nil {
+		t.Fatal("修改邮箱后发往旧邮箱的重置令牌仍然有效")
+	}
+	if err := env.svc.VerifyEmail(ctx, verifyToken); err == nil {
+		t.Fatal("修改邮箱后发往旧邮箱的验证令牌仍然有效")
+	}
+
+	if err := env.svc.VerifyEmail(ctx, env.mailbox.LastToken(newEmail)); err != nil {
+		t.Fatalf("验证新邮箱失败: %v", err)
+	}
+	if got := env.users.Get(user.ID); !got.EmailVerified || got.Email != newEmail {
+		t.Fatalf("邮箱 = %s（已验证: %v），期望 %s 已验证", got.Email, got.EmailVerified, newEmail)
+	}
+}
+
+// 重置密码后吊销用户的全部会话和已签发的访问令牌
+func TestResetPasswordRevokesSessions(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice", Email: "alice@example.com"})
+
+	issued, err := env.svc.IssueTokens(ctx, user.ID.Hex(), nil)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	if err := env.svc.ForgotPassword(ctx, &ForgotPasswordRequest{Email: user.Email}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	if err := env.svc.ResetPassword(ctx, &ResetPasswordRequest{Token: env.mailbox.LastToken(user.Email), Password: newPassword}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	if !env.accessTokenRevoked(t, issued.AccessToken) {
+		t.Error("重置密码前签发的访问令牌未被吊销")
+	}
+	if _, err := env.svc.RefreshToken(ctx, issued.RefreshToken, ""); err == nil {
+		t.Error("重置密码前的会话仍可刷新")
+	}
+}
+
+// 验证令牌只能使用一次，不同用途的令牌不能互用
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice", Email: "alice@example.com"})
+
+	if err := env.svc.ForgotPassword(ctx, &ForgotPasswordRequest{Email: user.Email}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	resetToken := env.mailbox.LastToken(user.Email)
+	if err := env.svc.VerifyEmail(ctx, resetToken); err == nil {
+		t.Fatal("重置密码令牌验证了邮箱")
+	}
+
+	if err := env.svc.ResendEmailVerification(ctx, user.ID.Hex()); err != nil {
+		t.Fatalf("ResendEmailVerification: %v", err)
+	}
+	verifyToken := env.mailbox.LastToken(user.Email)
+	if err := env.svc.ResetPassword(ctx, &ResetPasswordRequest{Token: verifyToken, Password: newPassword}); err == nil {
+		t.Fatal("邮箱验证令牌重置了密码")
+	}
+
+	if err := env.svc.VerifyEmail(ctx, verifyToken); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if !env.users.Get(user.ID).EmailVerified {
+		t.Fatal("邮箱未标记为已验证")
+	}
+	if err := env.svc.VerifyEmail(ctx, verifyToken); err == nil {
+		t.Fatal("验证令牌被重复使用")
+	}
+	if err := env.svc.ResendEmailVerification(ctx, user.ID.Hex()); err == nil {
+		t.Fatal("邮箱已验证后仍重新发送验证邮件")
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"authcenter/internal/auth/repository"
+	"authcenter/internal/models"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/logger"
+)
+
+// 吊销类型
+const (
+	RevocationTypeToken   = "token"   // 单个访问令牌
+	RevocationTypeSession = "session" // 会话签发的所有访问令牌
+	RevocationTypeUser    = "user"    // 用户在吊销时间之前获得的所有访问令牌
+)
+
+// syncClockSkew 增量同步时向前多取的时间，容忍实例间的时钟偏差
+const syncClockSkew = 5 * time.Second
+
+// RevocationService 访问令牌吊销服务接口
+// 吊销记录持久化到MongoDB，并在进程内缓存，认证中间件只查询缓存
+type RevocationService interface {
+	// RevokeToken 吊销单个访问令牌
+	RevokeToken(ctx context.Context, jti, reason string) error
+
+	// RevokeSession 吊销会话签发的所有访问令牌
+	RevokeSession(ctx context.Context, sessionID, reason string) error
+
+	// RevokeSessions 吊销多个会话签发的访问令牌，跳过访问令牌均已过期的会话
+	RevokeSessions(ctx context.Context, sessions []*models.Session, reason string) error
+
+	// RevokeUser 吊销用户当前持有的所有访问令牌
+	RevokeUser(ctx context.Context, userID, reason string) error
+
+	// IsRevoked 检查访问令牌是否已被吊销
+	IsRevoked(claims *jwt.Claims) bool
+
+	// Start 启动后台同步，从数据库加载其他实例写入的吊销记录
+	Start(interval time.Duration)
+}
+
+// revocationEntry 缓存中的吊销记录
+type revocationEntry struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+// revocationService 访问令牌吊销服务实现
+type revocationService struct {
+	revocationRepo repository.RevocationRepository
+	tokenTTL       time.Duration // 访问令牌有效期，超过该时长的吊销记录不再有意义
+
+	mutex    sync.RWMutex
+	entries  map[string]revocationEntry
+	lastSync time.Time
+}
+
+// NewRevocationService 创建访问令牌吊销服务
+func NewRevocationService(revocationRepo repository.RevocationRepository, accessTokenExpire time.Duration) RevocationService {
+	return &revocationService{
+		revocationRepo: revocationRepo,
+		tokenTTL:       accessTokenExpire,
+		entries:        make(map[string]revocationEntry),
+	}
+}
+
+// RevokeToken 吊销单个访问令牌
+func (s *revocationService) RevokeToken(ctx context.Context, jti, reason string) error {
+	return s.revoke(ctx, RevocationTypeToken, jti, reason)
+}
+
+// RevokeSession 吊销会话签发的所有访问令牌
+func (s *revocationService) RevokeSession(ctx context.Context, sessionID, reason string) error {
+	return s.revoke(ctx, RevocationTypeSession, sessionID, reason)
+}
+
+// RevokeSessions 吊销多个会话签发的访问令牌
+// 访问令牌在会话创建时签发，创建时间早于访问令牌有效期的会话无需再写入吊销记录
+func (s *revocationService) RevokeSessions(ctx context.Context, sessions []*models.Session, reason string) error {
+	for _, session := range sessions {
+		if time.Since(session.CreatedAt) > s.tokenTTL {
+			continue
+		}
+		if err := s.revoke(ctx, RevocationTypeSession, session.SessionID, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeUser 吊销用户当前持有的所有访问令牌
+func (s *revocationService) RevokeUser(ctx context.Context, userID, reason string) error {
+	return s.revoke(ctx, RevocationTypeUser, userID, reason)
+}
+
+// IsRevoked 检查访问令牌是否已被吊销
+func (s *revocationService) IsRevoked(claims *jwt.Claims) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	now := time.Now()
+
+	if claims.JTI != "" {
+		if entry, ok := s.entries[cacheKey(RevocationTypeToken, claims.JTI)]; ok && now.Before(entry.expiresAt) {
+			return true
+		}
+	}
+
+	if claims.SessionID != "" {
+		if entry, ok := s.entries[cacheKey(RevocationTypeSession, claims.SessionID)]; ok && now.Before(entry.expiresAt) {
+			return true
+		}
+	}
+
+	// 用户级吊销只影响吊销时间之前签发的令牌，之后重新登录获得的令牌不受影响
+	// 令牌的签发时间精确到秒，无法区分与吊销同一秒内签发的令牌的先后，按吊销之前签发处理
+	if entry, ok := s.entries[cacheKey(RevocationTypeUser, claims.UserID)]; ok && now.Before(entry.expiresAt) {
+		if claims.IssuedAt == nil || !claims.IssuedAt.Time.After(entry.revokedAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Start 启动后台同步
+func (s *revocationService) Start(interval time.Duration) {
+	if err := s.sync(); err != nil {
+		logger.Error("加载令牌吊销记录失败: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.sync(); err != nil {
+				logger.Error("同步令牌吊销记录失败: %v", err)
+			}
+		}
+	}()
+}
+
+// revoke 写入吊销记录并立即更新本地缓存
+func (s *revocationService) revoke(ctx context.Context, revocationType, value, reason string) error {
+	// 与令牌签发时间（iat）的精度一致
+	now := time.Now().Truncate(time.Second)
+	record := &models.RevokedToken{
+		Type:      revocationType,
+		Value:     value,
+		Reason:    reason,
+		RevokedAt: now,
+		ExpiresAt: now.Add(s.tokenTTL),
+	}
+
+	if err := s.revocationRepo.Create(ctx, record); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.put(record)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// sync 增量加载吊销记录并清理过期缓存
+func (s *revocationService) sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s.mutex.RLock()
+	since := s.lastSync
+	s.mutex.RUnlock()
+
+	if !since.IsZero() {
+		since = since.Add(-syncClockSkew)
+	}
+
+	startedAt := time.Now()
+	records, err := s.revocationRepo.ListSince(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, record := range records {
+		s.put(record)
+	}
+
+	for key, entry := range s.entries {
+		if startedAt.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.lastSync = startedAt
+	return nil
+}
+
+// put 写入缓存，同一对象保留最新的吊销时间，调用方需持有写锁
+func (s *revocationService) put(record *models.RevokedToken) {
+	key := cacheKey(record.Type, record.Value)
+	if existing, ok := s.entries[key]; ok && existing.revokedAt.After(record.RevokedAt) {
+		return
+	}
+
+	s.entries[key] = revocationEntry{
+		revokedAt: record.RevokedAt.Truncate(time.Second),
+		expiresAt: record.ExpiresAt,
+	}
+}
+
+// cacheKey 生成缓存键
+func cacheKey(revocationType, value string) string {
+	return revocationType + ":" + value
+}
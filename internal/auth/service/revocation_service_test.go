@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"authcenter/internal/models"
+	"authcenter/internal/testutil"
+	"authcenter/pkg/jwt"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// issuedAt 返回签发时间为t的声明
+func issuedAt(t time.Time) gojwt.RegisteredClaims {
+	return gojwt.RegisteredClaims{IssuedAt: gojwt.NewNumericDate(t)}
+}
+
+func TestRevocationServiceTokenAndSession(t *testing.T) {
+	ctx := context.Background()
+	svc := NewRevocationService(testutil.NewRevocations(), 15*time.Minute)
+
+	if err := svc.RevokeToken(ctx, "jti-1", "logout"); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if err := svc.RevokeSession(ctx, "session-1", "logout"); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		claims *jwt.Claims
+		want   bool
+	}{
+		{"吊销的令牌", &jwt.Claims{UserID: "user-1", JTI: "jti-1"}, true},
+		{"其他令牌", &jwt.Claims{UserID: "user-1", JTI: "jti-2"}, false},
+		{"吊销的会话签发的令牌", &jwt.Claims{UserID: "user-1", JTI: "jti-3", SessionID: "session-1"}, true},
+		{"其他会话签发的令牌", &jwt.Claims{UserID: "user-1", JTI: "jti-4", SessionID: "session-2"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := svc.IsRevoked(tt.claims); got != tt.want {
+				t.Fatalf("IsRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 用户级吊销影响吊销时间及之前签发的令牌，与吊销同一秒内签发的令牌同样视为已吊销
+func TestRevocationServiceUserIssuedAt(t *testing.T) {
+	ctx := context.Background()
+	repo := testutil.NewRevocations()
+	svc := NewRevocationService(repo, 15*time.Minute)
+
+	if err := svc.RevokeUser(ctx, "user-1", "password_reset"); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+	records, _ := repo.ListSince(ctx, time.Time{})
+	if len(records) != 1 {
+		t.Fatalf("吊销记录数 = %d，期望 1", len(records))
+	}
+	revokedAt := records[0].RevokedAt
+
+	tests := []struct {
+		name   string
+		claims *jwt.Claims
+		want   bool
+	}{
+		{"吊销之前签发", &jwt.Claims{UserID: "user-1", RegisteredClaims: issuedAt(revokedAt.Add(-time.Minute))}, true},
+		{"与吊销同一秒签发", &jwt.Claims{UserID: "user-1", RegisteredClaims: issuedAt(revokedAt.Add(999 * time.Millisecond))}, true},
+		{"吊销之后签发", &jwt.Claims{UserID: "user-1", RegisteredClaims: issuedAt(revokedAt.Add(time.Second))}, false},
+		{"没有签发时间", &jwt.Claims{UserID: "user-1"}, true},
+		{"其他用户", &jwt.Claims{UserID: "user-2", RegisteredClaims: issuedAt(revokedAt.Add(-time.Minute))}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := svc.IsRevoked(tt.claims); got != tt.want {
+				t.Fatalf("IsRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 吊销记录在访问令牌有效期后失效
+func TestRevocationServiceExpiry(t *testing.T) {
+	ctx := context.Background()
+	svc := NewRevocationService(testutil.NewRevocations(), 0)
+
+	if err := svc.RevokeToken(ctx, "jti-1", "logout"); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if svc.IsRevoked(&jwt.Claims{JTI: "jti-1"}) {
+		t.Fatal("过期的吊销记录仍然生效")
+	}
+}
+
+// 访问令牌均已过期的会话无需写入吊销记录
+func TestRevocationServiceRevokeSessions(t *testing.T) {
+	ctx := context.Background()
+	repo := testutil.NewRevocations()
+	svc := NewRevocationService(repo, 15*time.Minute)
+
+	sessions := []*models.Session{
+		{SessionID: "recent", CreatedAt: time.Now().Add(-time.Minute)},
+		{SessionID: "old", CreatedAt: time.Now().Add(-time.Hour)},
+	}
+	if err := svc.RevokeSessions(ctx, sessions, "logout"); err != nil {
+		t.Fatalf("RevokeSessions: %v", err)
+	}
+
+	if !repo.Has(RevocationTypeSession, "recent") {
+		t.Error("访问令牌仍在有效期内的会话未被吊销")
+	}
+	if repo.Has(RevocationTypeSession, "old") {
+		t.Error("访问令牌均已过期的会话写入了吊销记录")
+	}
+}
+
+// 其他实例写入的吊销记录在同步后生效
+func TestRevocationServiceSync(t *testing.T) {
+	ctx := context.Background()
+	repo := testutil.NewRevocations()
+	writer := NewRevocationService(repo, 15*time.Minute)
+	reader := NewRevocationService(repo, 15*time.Minute).(*revocationService)
+
+	if err := writer.RevokeSession(ctx, "session-1", "logout"); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+
+	claims := &jwt.Claims{SessionID: "session-1"}
+	if reader.IsRevoked(claims) {
+		t.Fatal("同步前即已生效")
+	}
+	if err := reader.sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !reader.IsRevoked(claims) {
+		t.Fatal("同步后吊销记录未生效")
+	}
+}
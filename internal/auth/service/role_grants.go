@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	groupRepo "authcenter/internal/group/repository"
+	"authcenter/internal/models"
+	roleRepo "authcenter/internal/role/repository"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/rbac"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// roleGrants 用户或服务账号在一次认证中生效的角色和权限
+type roleGrants struct {
+	roles       []string   // 分配的角色和继承的祖先角色，已去重
+	permissions []string   // resource:action，已去重
+	maxSessions int        // 分配的角色中最大的并发会话数上限，为0时未设置
+	expiresAt   *time.Time // 生效的限时角色分配中最早的过期时间，为空时没有限时分配
+}
+
+// roleEntry 一个生效的角色分配
+type roleEntry struct {
+	assignment models.UserRole
+	roles      []*models.Role // 分配的角色及其继承的祖先角色，第一个为分配的角色
+}
+
+// resolveGrants 计算角色分配生效的角色和权限，角色继承其祖先角色的权限
+// 要求多因素认证的角色仅在mfaVerified为true时生效，作为祖先角色被继承时同样如此；已过期的角色分配不生效
+// 受同一动态职责分离规则约束的多个角色分配中，只有activeRoles中激活的生效，未激活任何一个时全部不生效
+func resolveGrants(roles roleRepo.RoleRepository, sod roleRepo.SoDRepository, assignments []models.UserRole, mfaVerified bool, activeRoles []string) *roleGrants {
+	grants := &roleGrants{
+		roles: make([]string, 0, len(assignments)),
+	}
+	roleSet := make(map[string]bool)       // 用于去重
+	permissionSet := make(map[string]bool) // 用于去重
+
+	grant := func(role *models.Role) {
+		if !roleSet[role.Name] {
+			roleSet[role.Name] = true
+			grants.roles = append(grants.roles, role.Name)
+		}
+
+		// 添加权限到集合中（去重）
+		for _, perm := range role.Permissions {
+			permKey := rbac.Permission(perm.Resource, perm.Action)
+			if !permissionSet[permKey] {
+				permissionSet[permKey] = true
+				grants.permissions = append(grants.permissions, permKey)
+			}
+		}
+	}
+
+	entries := resolveEntries(roles, assignments, mfaVerified)
+	entries = applyDynamicSoD(sod, entries, activeRoles)
+
+	for _, entry := range entries {
+		for _, role := range entry.roles {
+			grant(role)
+		}
+
+		userRole, role := entry.assignment, entry.roles[0]
+		if userRole.ExpiresAt != nil && (grants.expiresAt == nil || userRole.ExpiresAt.Before(*grants.expiresAt)) {
+			grants.expiresAt = userRole.ExpiresAt
+		}
+		if role.MaxSessions > grants.maxSessions {
+			grants.maxSessions = role.MaxSessions
+		}
+	}
+
+	return grants
+}
+
+// effectiveAssignments 用户在租户中的角色分配，包括直接分配的角色和所属用户组的角色
+// 组成员关系在签发令牌时计算，成员变更在刷新Token后生效；用户组无法加载时只返回直接分配的角色
+func effectiveAssignments(ctx context.Context, groups groupRepo.GroupRepository, user *models.User, tenantID primitive.ObjectID) []models.UserRole {
+	assignments := user.RolesIn(tenantID)
+	if groups == nil {
+		return assignments
+	}
+
+	memberOf, err := groups.ForTenant(tenantID).FindByMember(ctx, user)
+	if err != nil {
+		logger.Error("获取用户所属用户组失败: %v", err)
+		return assignments
+	}
+
+	// 直接分配和多个组分配的同一角色只保留一个，直接分配优先
+	now := time.Now()
+	seen := make(map[primitive.ObjectID]bool, len(assignments))
+	for _, userRole := range assignments {
+		if !userRole.Expired(now) {
+			seen[userRole.RoleID] = true
+		}
+	}
+	for _, group := range memberOf {
+		for _, groupRole := range group.RolesIn(tenantID) {
+			if seen[groupRole.RoleID] {
+				continue
+			}
+			seen[groupRole.RoleID] = true
+			assignments = append(assignments, groupRole)
+		}
+	}
+
+	return assignments
+}
+
+// resolveEntries 加载生效的角色分配及其继承的祖先角色
+func resolveEntries(roles roleRepo.RoleRepository, assignments []models.UserRole, mfaVerified bool) []*roleEntry {
+	entries := make([]*roleEntry, 0, len(assignments))
+
+	now := time.Now()
+	for _, userRole := range assignments {
+		if userRole.Expired(now) {
+			continue
+		}
+
+		role, err := roles.GetByID(userRole.RoleID.Hex())
+		if err != nil {
+			continue // 忽略错误，继续处理其他角色
+		}
+
+		if role.RequireMFA && !mfaVerified {
+			continue
+		}
+
+		entry := &roleEntry{assignment: userRole, roles: []*models.Role{role}}
+		entries = append(entries, entry)
+
+		if len(role.ParentIDs) == 0 {
+			continue
+		}
+		ancestors, err := roles.GetAncestors(userRole.RoleID.Hex())
+		if err != nil {
+			continue
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.RequireMFA && !mfaVerified {
+				continue
+			}
+			entry.roles = append(entry.roles, ancestor)
+		}
+	}
+
+	return entries
+}
+
+// applyDynamicSoD 按动态职责分离规则过滤角色分配，规则无法加载时不做过滤
+func applyDynamicSoD(sod roleRepo.SoDRepository, entries []*roleEntry, activeRoles []string) []*roleEntry {
+	if sod == nil || len(entries) < 2 {
+		return entries
+	}
+
+	rules, err := sod.List(roleRepo.SoDDynamic)
+	if err != nil {
+		logger.Error("获取动态职责分离规则失败: %v", err)
+		return entries
+	}
+
+	active := make(map[string]bool, len(activeRoles))
+	for _, name := range activeRoles {
+		active[name] = true
+	}
+
+	dropped := make(map[*roleEntry]bool)
+	for _, rule := range rules {
+		touching := make([]*roleEntry, 0)
+		for _, entry := range entries {
+			if !dropped[entry] && len(sodRuleRoles(rule, entry)) > 0 {
+				touching = append(touching, entry)
+			}
+		}
+		if len(sodRuleRoles(rule, touching...)) <= 1 {
+			continue
+		}
+
+		selected := make([]*roleEntry, 0, len(touching))
+		for _, entry := range touching {
+			if active[entry.roles[0].Name] {
+				selected = append(selected, entry)
+			} else {
+				dropped[entry] = true
+			}
+		}
+
+		// 激活的角色之间仍然冲突时全部不生效
+		if len(sodRuleRoles(rule, selected...)) > 1 {
+			for _, entry := range selected {
+				dropped[entry] = true
+			}
+		}
+	}
+
+	if len(dropped) == 0 {
+		return entries
+	}
+
+	remaining := make([]*roleEntry, 0, len(entries)-len(dropped))
+	for _, entry := range entries {
+		if !dropped[entry] {
+			remaining = append(remaining, entry)
+		}
+	}
+	return remaining
+}
+
+// sodRuleRoles 返回规则中被这些角色分配（含继承的祖先角色）覆盖的角色
+func sodRuleRoles(rule *models.SoDRule, entries ...*roleEntry) map[primitive.ObjectID]bool {
+	covered := make(map[primitive.ObjectID]bool)
+	for _, id := range rule.RoleIDs {
+		for _, entry := range entries {
+			for _, role := range entry.roles {
+				if role.ID == id {
+					covered[id] = true
+				}
+			}
+		}
+	}
+	return covered
+}
+
+// tokenClaims 访问令牌的标准声明，存在限时角色分配时令牌不晚于其中最早的过期时间失效
+func (g *roleGrants) tokenClaims() gojwt.RegisteredClaims {
+	if g.expiresAt == nil {
+		return gojwt.RegisteredClaims{}
+	}
+	return gojwt.RegisteredClaims{ExpiresAt: gojwt.NewNumericDate(*g.expiresAt)}
+}
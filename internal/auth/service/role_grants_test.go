@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	groupRepo "authcenter/internal/group/repository"
+	"authcenter/internal/models"
+	roleRepo "authcenter/internal/role/repository"
+	"authcenter/internal/testutil"
+	"authcenter/pkg/jwt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sodFixture 受同一动态职责分离规则约束的出纳和审计角色，高级出纳继承出纳
+type sodFixture struct {
+	cashier       *models.Role
+	seniorCashier *models.Role
+	auditor       *models.Role
+	viewer        *models.Role
+}
+
+// newSoDFixture 创建角色和动态职责分离规则
+func (e *testEnv) newSoDFixture(t *testing.T) *sodFixture {
+	t.Helper()
+
+	f := &sodFixture{
+		cashier: e.roles.Put(&models.Role{Name: "cashier", Permissions: []models.RolePermission{{Resource: "payment", Action: "CREATE"}}}),
+		auditor: e.roles.Put(&models.Role{Name: "auditor", Permissions: []models.RolePermission{{Resource: "payment", Action: "AUDIT"}}}),
+		viewer:  e.roles.Put(&models.Role{Name: "viewer", Permissions: []models.RolePermission{{Resource: "payment", Action: "READ"}}}),
+	}
+	f.seniorCashier = e.roles.Put(&models.Role{Name: "senior-cashier", ParentIDs: []primitive.ObjectID{f.cashier.ID}})
+
+	if err := e.sod.Create(&models.SoDRule{
+		Name:    "payment",
+		Type:    roleRepo.SoDDynamic,
+		RoleIDs: []primitive.ObjectID{f.cashier.ID, f.auditor.ID},
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return f
+}
+
+// assign 用户直接分配的角色
+func assign(roles ...*models.Role) []models.UserRole {
+	assignments := make([]models.UserRole, 0, len(roles))
+	for _, role := range roles {
+		assignments = append(assignments, models.UserRole{RoleID: role.ID, RoleName: role.Name})
+	}
+	return assignments
+}
+
+// accessClaims 解析访问令牌，角色按名称排序
+func (e *testEnv) accessClaims(t *testing.T, accessToken string) *jwt.Claims {
+	t.Helper()
+
+	claims, err := e.jwt.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	sort.Strings(claims.Roles)
+	return claims
+}
+
+// 受动态职责分离规则约束的角色只有激活的生效，未激活时全部不生效，继承的角色同样受约束
+func TestDynamicSoDActiveRoles(t *testing.T) {
+	env := newTestEnv(t, nil)
+	f := env.newSoDFixture(t)
+	user := env.createUser(t, &models.User{
+		Username: "alice",
+		Roles:    assign(f.seniorCashier, f.auditor, f.viewer),
+	})
+
+	tests := []struct {
+		name        string
+		activeRoles []string
+		want        []string
+	}{
+		{"未激活", nil, []string{"viewer"}},
+		{"激活审计", []string{"auditor"}, []string{"auditor", "viewer"}},
+		{"激活高级出纳", []string{"senior-cashier"}, []string{"cashier", "senior-cashier", "viewer"}},
+		{"同时激活冲突的角色", []string{"senior-cashier", "auditor"}, []string{"viewer"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issued, err := env.svc.IssueTokens(context.Background(), user.ID.Hex(), &IssueOptions{ActiveRoles: tt.activeRoles})
+			if err != nil {
+				t.Fatalf("IssueTokens: %v", err)
+			}
+			if got := env.accessClaims(t, issued.AccessToken).Roles; !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("角色 = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// 切换激活的角色后吊销当前访问令牌，刷新后按新的激活角色签发
+func TestSetActiveRoles(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	f := env.newSoDFixture(t)
+	user := env.createUser(t, &models.User{Username: "alice", Roles: assign(f.seniorCashier, f.auditor)})
+	other := env.createUser(t, &models.User{Username: "bob"})
+
+	issued, err := env.svc.IssueTokens(ctx, user.ID.Hex(), nil)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+
+	rejected := []struct {
+		name   string
+		userID string
+		roles  []string
+	}{
+		{"违反职责分离规则", user.ID.Hex(), []string{"senior-cashier", "auditor"}},
+		{"未分配的角色", user.ID.Hex(), []string{"viewer"}},
+		{"其他用户的会话", other.ID.Hex(), []string{"auditor"}},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := env.svc.SetActiveRoles(ctx, tt.userID, issued.SessionID, &SetActiveRolesRequest{Roles: tt.roles}); err == nil {
+				t.Fatal("设置激活的角色成功，期望被拒绝")
+			}
+		})
+	}
+	if env.accessTokenRevoked(t, issued.AccessToken) {
+		t.Fatal("设置失败时吊销了访问令牌")
+	}
+
+	if err := env.svc.SetActiveRoles(ctx, user.ID.Hex(), issued.SessionID, &SetActiveRolesRequest{Roles: []string{"auditor"}}); err != nil {
+		t.Fatalf("SetActiveRoles: %v", err)
+	}
+	if !env.accessTokenRevoked(t, issued.AccessToken) {
+		t.Error("切换激活的角色后原访问令牌未被吊销")
+	}
+
+	refreshed, err := env.svc.RefreshToken(ctx, issued.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	if got, want := env.accessClaims(t, refreshed.AccessToken).Roles, []string{"auditor"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("刷新后的角色 = %v，期望 %v", got, want)
+	}
+}
+
+// 令牌包括手动组和匹配的动态组的角色，租户的用户组只在该租户中生效，组的角色同样受动态职责分离规则约束
+func TestGroupRolesInTokens(t *testing.T) {
+	env := newTestEnv(t, nil)
+	f := env.newSoDFixture(t)
+	tenantID := primitive.NewObjectID()
+	user := env.createUser(t, &models.User{
+		Username: "alice",
+		Profile:  models.UserProfile{Department: "finance"},
+		Tenants:  []models.TenantMembership{{TenantID: tenantID}},
+		Roles:    assign(f.auditor),
+	})
+	other := env.createUser(t, &models.User{Username: "bob", Profile: models.UserProfile{Department: "sales"}})
+
+	env.groups.Put(&models.Group{Name: "tellers", Type: groupRepo.GroupManual, Members: []primitive.ObjectID{user.ID}, Roles: assign(f.cashier)})
+	env.groups.Put(&models.Group{Name: "finance", Type: groupRepo.GroupDynamic, Department: "finance", Roles: assign(f.viewer, f.auditor)})
+	env.groups.ForTenant(tenantID).(*testutil.Groups).Put(&models.Group{
+		Name:       "tenant-finance",
+		Type:       groupRepo.GroupDynamic,
+		Department: "finance",
+		Roles:      assign(f.seniorCashier),
+	})
+
+	tests := []struct {
+		name        string
+		userID      string
+		tenant      *primitive.ObjectID
+		activeRoles []string
+		want        []string
+	}{
+		{"冲突的角色未激活", user.ID.Hex(), nil, nil, []string{"viewer"}},
+		{"激活组获得的角色", user.ID.Hex(), nil, []string{"cashier"}, []string{"cashier", "viewer"}},
+		{"租户的用户组", user.ID.Hex(), &tenantID, []string{"senior-cashier"}, []string{"cashier", "senior-cashier", "viewer"}},
+		{"不匹配动态组", other.ID.Hex(), nil, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issued, err := env.svc.IssueTokens(context.Background(), tt.userID, &IssueOptions{ActiveRoles: tt.activeRoles, tenantID: tt.tenant})
+			if err != nil {
+				t.Fatalf("IssueTokens: %v", err)
+			}
+			got := env.accessClaims(t, issued.AccessToken).Roles
+			if got == nil {
+				got = []string{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("角色 = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
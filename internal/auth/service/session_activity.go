@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"authcenter/internal/auth/repository"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/logger"
+)
+
+// SessionActivityTracker 记录访问令牌的使用，更新所属会话的最近访问时间
+type SessionActivityTracker interface {
+	// Touch 记录一次访问，同一会话在间隔内只写入一次数据库
+	Touch(claims *jwt.Claims)
+}
+
+// sessionActivityTracker 会话活动记录实现
+type sessionActivityTracker struct {
+	sessionRepo repository.SessionRepository
+	interval    time.Duration
+
+	mutex       sync.Mutex
+	lastTouched map[string]time.Time
+	lastSweep   time.Time
+}
+
+// NewSessionActivityTracker 创建会话活动记录器，interval为0时不记录
+func NewSessionActivityTracker(sessionRepo repository.SessionRepository, interval time.Duration) SessionActivityTracker {
+	return &sessionActivityTracker{
+		sessionRepo: sessionRepo,
+		interval:    interval,
+		lastTouched: make(map[string]time.Time),
+	}
+}
+
+// Touch 记录一次访问，异步写入数据库，不阻塞请求
+func (t *sessionActivityTracker) Touch(claims *jwt.Claims) {
+	if t.interval <= 0 || claims.SessionID == "" {
+		return
+	}
+
+	now := time.Now()
+
+	t.mutex.Lock()
+	if last, ok := t.lastTouched[claims.SessionID]; ok && now.Sub(last) < t.interval {
+		t.mutex.Unlock()
+		return
+	}
+	t.lastTouched[claims.SessionID] = now
+
+	// 超过间隔的记录不再起节流作用，定期清理避免缓存无限增长
+	if now.Sub(t.lastSweep) > t.interval {
+		for sessionID, last := range t.lastTouched {
+			if now.Sub(last) >= t.interval {
+				delete(t.lastTouched, sessionID)
+			}
+		}
+		t.lastSweep = now
+	}
+	t.mutex.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := t.sessionRepo.TouchSession(ctx, claims.SessionID, now); err != nil {
+			logger.Error("更新会话访问时间失败: %v", err)
+		}
+	}()
+}
+
+// SessionCleaner 会话清理服务接口
+type SessionCleaner interface {
+	// Start 启动后台任务，定期吊销空闲会话并清理过期会话
+	Start(interval time.Duration)
+}
+
+// sessionCleaner 会话清理服务实现
+type sessionCleaner struct {
+	sessionRepo repository.SessionRepository
+	idleTimeout time.Duration
+}
+
+// NewSessionCleaner 创建会话清理服务，idleTimeout为0时不吊销空闲会话
+func NewSessionCleaner(sessionRepo repository.SessionRepository, idleTimeout time.Duration) SessionCleaner {
+	return &sessionCleaner{
+		sessionRepo: sessionRepo,
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Start 启动后台清理
+func (c *sessionCleaner) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			c.cleanup()
+		}
+	}()
+}
+
+// cleanup 执行一次清理
+func (c *sessionCleaner) cleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if c.idleTimeout > 0 {
+		count, err := c.sessionRepo.RevokeIdleSessions(ctx, time.Now().Add(-c.idleTimeout))
+		if err != nil {
+			logger.Error("吊销空闲会话失败: %v", err)
+		} else if count > 0 {
+			logger.Info("已吊销%d个空闲会话", count)
+		}
+	}
+
+	if err := c.sessionRepo.CleanupExpiredSessions(ctx); err != nil {
+		logger.Error("清理过期会话失败: %v", err)
+	}
+}
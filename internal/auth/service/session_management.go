@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"authcenter/internal/models"
+	roleRepo "authcenter/internal/role/repository"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 并发会话数达到上限时的处理策略
+const (
+	SessionLimitPolicyEvictOldest = "evict_oldest" // 吊销最早登录的会话
+	SessionLimitPolicyReject      = "reject"       // 拒绝新登录
+)
+
+// SessionInfo 用户可见的登录会话
+// 刷新Token会轮换底层会话，对外以令牌族ID标识一次登录，刷新后保持不变
+type SessionInfo struct {
+	ID             string            `json:"id"`
+	ClientID       string            `json:"client_id,omitempty"`
+	DeviceInfo     models.DeviceInfo `json:"device_info"`
+	AuthMethods    []string          `json:"auth_methods,omitempty"`
+	ActiveRoles    []string          `json:"active_roles,omitempty"`
+	LoginAt        time.Time         `json:"login_at"`
+	LastAccessedAt time.Time         `json:"last_accessed_at"`
+	ExpiresAt      time.Time         `json:"expires_at"`
+	Current        bool              `json:"current"`
+}
+
+// SetActiveRolesRequest 设置会话激活的角色请求
+type SetActiveRolesRequest struct {
+	Roles []string `json:"roles"`
+}
+
+// ListSessions 列出用户的有效会话，currentSessionID为当前访问令牌所属的会话
+func (s *authService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]*SessionInfo, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("用户ID格式错误")
+	}
+
+	sessions, err := s.sessionRepo.GetByUserID(ctx, userObjID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentFamilyID := s.currentFamilyID(ctx, currentSessionID)
+
+	result := make([]*SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		if s.sessionIdle(session) {
+			continue
+		}
+
+		familyID := sessionFamilyID(session)
+		result = append(result, &SessionInfo{
+			ID:             familyID,
+			ClientID:       session.ClientID,
+			DeviceInfo:     session.DeviceInfo,
+			AuthMethods:    session.AuthMethods,
+			ActiveRoles:    session.ActiveRoles,
+			LoginAt:        sessionLoginAt(session),
+			LastAccessedAt: session.LastAccessedAt,
+			ExpiresAt:      session.ExpiresAt,
+			Current:        familyID == currentFamilyID,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastAccessedAt.After(result[j].LastAccessedAt)
+	})
+
+	return result, nil
+}
+
+// RevokeSession 吊销用户的指定会话，id为ListSessions返回的会话ID
+func (s *authService) RevokeSession(ctx context.Context, userID, id string) error {
+	sessions, err := s.sessionRepo.GetFamily(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 || sessions[0].UserID.Hex() != userID {
+		return errors.New("会话不存在")
+	}
+
+	if err := s.revokeSessionFamily(ctx, id, sessions, "session_revoked"); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("session_revoked", map[string]interface{}{
+		"user_id":   userID,
+		"family_id": id,
+	})
+
+	return nil
+}
+
+// RevokeOtherSessions 吊销当前会话以外的全部会话，返回吊销的会话数
+func (s *authService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, errors.New("用户ID格式错误")
+	}
+
+	sessions, err := s.sessionRepo.GetByUserID(ctx, userObjID)
+	if err != nil {
+		return 0, err
+	}
+
+	currentFamilyID := s.currentFamilyID(ctx, currentSessionID)
+
+	revoked := 0
+	for _, session := range sessions {
+		if sessionFamilyID(session) == currentFamilyID {
+			continue
+		}
+
+		if err := s.revokeLogin(ctx, session, "session_revoked"); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+
+	logger.SecurityEvent("other_sessions_revoked", map[string]interface{}{
+		"user_id": userID,
+		"count":   revoked,
+	})
+
+	return revoked, nil
+}
+
+// enforceSessionLimit 新登录前检查并发会话数，roleLimit为用户角色设置的上限，优先于全局配置
+// 达到上限时按策略拒绝本次登录或吊销最早登录的会话
+func (s *authService) enforceSessionLimit(ctx context.Context, user *models.User, roleLimit int) error {
+	limit := s.security.MaxSessions
+	if roleLimit > 0 {
+		limit = roleLimit
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	sessions, err := s.sessionRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	active := make([]*models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if !s.sessionIdle(session) {
+			active = append(active, session)
+		}
+	}
+	if len(active) < limit {
+		return nil
+	}
+
+	if s.security.SessionLimitPolicy == SessionLimitPolicyReject {
+		logger.SecurityEvent("session_limit_reached", map[string]interface{}{
+			"user_id": user.ID.Hex(),
+			"limit":   limit,
+		})
+		return errors.New("登录设备数已达上限，请先退出其他设备")
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		return sessionLoginAt(active[i]).Before(sessionLoginAt(active[j]))
+	})
+
+	for _, session := range active[:len(active)-limit+1] {
+		if err := s.revokeLogin(ctx, session, "session_evicted"); err != nil {
+			return err
+		}
+
+		logger.SecurityEvent("session_evicted", map[string]interface{}{
+			"user_id":   user.ID.Hex(),
+			"family_id": sessionFamilyID(session),
+			"limit":     limit,
+		})
+	}
+
+	return nil
+}
+
+// sessionIdle 会话是否超过空闲时长
+func (s *authService) sessionIdle(session *models.Session) bool {
+	return s.security.SessionIdleTimeout > 0 && time.Since(session.LastAccessedAt) > s.security.SessionIdleTimeout
+}
+
+// revokeCurrentSession 吊销访问令牌所属的会话及其令牌族
+func (s *authService) revokeCurrentSession(ctx context.Context, claims *jwt.Claims, reason string) error {
+	session, err := s.sessionRepo.GetBySessionID(ctx, claims.SessionID)
+	if err != nil || session.UserID.Hex() != claims.UserID {
+		return errors.New("会话不存在")
+	}
+
+	return s.revokeLogin(ctx, session, reason)
+}
+
+// revokeLogin 吊销会话所属的整个令牌族，即该会话对应的一次登录
+func (s *authService) revokeLogin(ctx context.Context, session *models.Session, reason string) error {
+	familyID := sessionFamilyID(session)
+	family, err := s.sessionRepo.GetFamily(ctx, familyID)
+	if err != nil {
+		return err
+	}
+
+	return s.revokeSessionFamily(ctx, familyID, family, reason)
+}
+
+// revokeSessionFamily 吊销令牌族中的全部会话，以及这些会话签发的尚未过期的访问令牌
+// 轮换前的会话签发的访问令牌可能仍在有效期内，需要一并吊销
+func (s *authService) revokeSessionFamily(ctx context.Context, familyID string, family []*models.Session, reason string) error {
+	if err := s.sessionRepo.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+
+	// 早于令牌族创建的会话没有family_id，需要单独吊销
+	for _, session := range family {
+		if session.FamilyID == "" {
+			if err := s.sessionRepo.RevokeSession(ctx, session.SessionID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.revocation.RevokeSessions(ctx, family, reason)
+}
+
+// currentFamilyID 返回当前会话所属的令牌族ID，会话不存在时返回空字符串
+func (s *authService) currentFamilyID(ctx context.Context, currentSessionID string) string {
+	if currentSessionID == "" {
+		return ""
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(ctx, currentSessionID)
+	if err != nil {
+		return ""
+	}
+	return sessionFamilyID(session)
+}
+
+// sessionLoginAt 会话的登录时间，早于该字段创建的会话以会话创建时间代替
+func sessionLoginAt(session *models.Session) time.Time {
+	if session.LoginAt.IsZero() {
+		return session.CreatedAt
+	}
+	return session.LoginAt
+}
+
+// newDeviceInfo 根据客户端IP和User-Agent生成设备信息
+func newDeviceInfo(ip, userAgent string) models.DeviceInfo {
+	return models.DeviceInfo{
+		UserAgent:  userAgent,
+		IP:         ip,
+		DeviceType: utils.ParseDeviceType(userAgent),
+	}
+}
+
+// SetActiveRoles 设置当前会话激活的角色，受同一动态职责分离规则约束的角色中最多激活一个
+// 会话已签发的访问令牌随即吊销，刷新Token后按新的激活角色签发
+func (s *authService) SetActiveRoles(ctx context.Context, userID, sessionID string, req *SetActiveRolesRequest) error {
+	if sessionID == "" {
+		return errors.New("当前令牌不属于登录会话")
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(ctx, sessionID)
+	if err != nil || session.IsRevoked || session.UserID.Hex() != userID {
+		return errors.New("会话不存在")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	entries := resolveEntries(s.roleRepo, effectiveAssignments(ctx, s.groupRepo, user, sessionTenant(session)), containsMethod(session.AuthMethods, AuthMethodMFA))
+	byName := make(map[string]*roleEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.roles[0].Name] = entry
+	}
+
+	names := make([]string, 0, len(req.Roles))
+	selected := make([]*roleEntry, 0, len(req.Roles))
+	seen := make(map[string]bool, len(req.Roles))
+	for _, name := range req.Roles {
+		entry, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("角色未分配或当前会话中不生效: %s", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		selected = append(selected, entry)
+	}
+
+	rules, err := s.sodRepo.List(roleRepo.SoDDynamic)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if len(sodRuleRoles(rule, selected...)) > 1 {
+			return fmt.Errorf("所选角色违反职责分离规则: %s", rule.Name)
+		}
+	}
+
+	if err := s.sessionRepo.SetActiveRoles(ctx, sessionID, names); err != nil {
+		return err
+	}
+
+	if err := s.revocation.RevokeSession(ctx, sessionID, "active_roles_changed"); err != nil {
+		return err
+	}
+
+	logger.Audit("active_roles_changed", map[string]interface{}{
+		"user_id":      userID,
+		"session_id":   sessionID,
+		"active_roles": names,
+	})
+
+	return nil
+}
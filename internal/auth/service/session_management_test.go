@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"authcenter/internal/models"
+)
+
+// issue 为用户签发一次新登录的令牌
+func (e *testEnv) issue(t *testing.T, user *models.User) *TokenData {
+	t.Helper()
+
+	issued, err := e.svc.IssueTokens(context.Background(), user.ID.Hex(), nil)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	return issued
+}
+
+// 空闲超时的会话刷新失败，并吊销整个令牌族
+func TestRefreshTokenRejectsIdleSession(t *testing.T) {
+	ctx := context.Background()
+	security := testSecurity
+	security.SessionIdleTimeout = 30 * time.Minute
+	env := newTestEnv(t, &security)
+	user := env.createUser(t, &models.User{Username: "alice"})
+
+	issued := env.issue(t, user)
+	env.sessions.SetLastAccessedAt(issued.SessionID, time.Now().Add(-time.Hour))
+
+	if _, err := env.svc.RefreshToken(ctx, issued.RefreshToken, ""); err == nil {
+		t.Fatal("空闲超时的会话刷新成功")
+	}
+	if !env.accessTokenRevoked(t, issued.AccessToken) {
+		t.Error("空闲超时的会话签发的访问令牌未被吊销")
+	}
+
+	sessions, err := env.svc.ListSessions(ctx, user.ID.Hex(), "")
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("会话列表包含 %d 个会话，期望空闲会话已失效", len(sessions))
+	}
+}
+
+// 达到并发会话上限时吊销最早登录的会话，刷新令牌不计为新登录
+func TestSessionLimitEvictsOldest(t *testing.T) {
+	ctx := context.Background()
+	security := testSecurity
+	security.MaxSessions = 2
+	security.SessionLimitPolicy = SessionLimitPolicyEvictOldest
+	env := newTestEnv(t, &security)
+	user := env.createUser(t, &models.User{Username: "alice"})
+
+	oldest := env.issue(t, user)
+	second := env.issue(t, user)
+	refreshed, err := env.svc.RefreshToken(ctx, second.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("达到上限时刷新令牌失败: %v", err)
+	}
+	if env.accessTokenRevoked(t, oldest.AccessToken) {
+		t.Fatal("刷新令牌吊销了其他会话")
+	}
+
+	newest := env.issue(t, user)
+
+	if !env.accessTokenRevoked(t, oldest.AccessToken) {
+		t.Error("最早登录的会话未被吊销")
+	}
+	if _, err := env.svc.RefreshToken(ctx, oldest.RefreshToken, ""); err == nil {
+		t.Error("被踢出的会话刷新成功")
+	}
+	if env.accessTokenRevoked(t, refreshed.AccessToken) || env.accessTokenRevoked(t, newest.AccessToken) {
+		t.Error("未达到上限的会话被吊销")
+	}
+}
+
+// 达到并发会话上限时按reject策略拒绝新登录，空闲会话不计入上限
+func TestSessionLimitRejects(t *testing.T) {
+	security := testSecurity
+	security.MaxSessions = 1
+	security.SessionLimitPolicy = SessionLimitPolicyReject
+	security.SessionIdleTimeout = 30 * time.Minute
+	env := newTestEnv(t, &security)
+	user := env.createUser(t, &models.User{Username: "alice"})
+
+	first := env.issue(t, user)
+	if _, err := env.svc.IssueTokens(context.Background(), user.ID.Hex(), nil); err == nil {
+		t.Fatal("达到上限后新登录成功")
+	}
+	if env.accessTokenRevoked(t, first.AccessToken) {
+		t.Error("拒绝新登录时吊销了已有会话")
+	}
+
+	env.sessions.SetLastAccessedAt(first.SessionID, time.Now().Add(-time.Hour))
+	env.issue(t, user)
+}
+
+func TestListAndRevokeSessions(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	user := env.createUser(t, &models.User{Username: "alice"})
+	other := env.createUser(t, &models.User{Username: "bob"})
+
+	first := env.issue(t, user)
+	current := env.issue(t, user)
+	third := env.issue(t, user)
+
+	// 刷新后会话ID保持不变，仍标记为当前会话
+	refreshed, err := env.svc.RefreshToken(ctx, current.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	sessions, err := env.svc.ListSessions(ctx, user.ID.Hex(), refreshed.SessionID)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("会话数 = %d，期望 3", len(sessions))
+	}
+	var currentID string
+	for _, session := range sessions {
+		if session.Current {
+			currentID = session.ID
+		}
+	}
+	if currentID != current.SessionID {
+		t.Fatalf("当前会话ID = %q，期望登录时的会话ID %q", currentID, current.SessionID)
+	}
+
+	if err := env.svc.RevokeSession(ctx, other.ID.Hex(), first.SessionID); err == nil {
+		t.Fatal("吊销了其他用户的会话")
+	}
+	if err := env.svc.RevokeSession(ctx, user.ID.Hex(), first.SessionID); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if !env.accessTokenRevoked(t, first.AccessToken) {
+		t.Error("吊销的会话签发的访问令牌仍然有效")
+	}
+
+	revoked, err := env.svc.RevokeOtherSessions(ctx, user.ID.Hex(), refreshed.SessionID)
+	if err != nil {
+		t.Fatalf("RevokeOtherSessions: %v", err)
+	}
+	if revoked != 1 || !env.accessTokenRevoked(t, third.AccessToken) {
+		t.Errorf("吊销了 %d 个其他会话，期望只吊销剩余的 1 个", revoked)
+	}
+	if env.accessTokenRevoked(t, refreshed.AccessToken) {
+		t.Error("当前会话被吊销")
+	}
+}
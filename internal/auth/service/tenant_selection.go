@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// resolveTenant 确定签发令牌的租户，平台用户（不属于任何租户）返回零值
+// 请求指定租户时要求用户是其成员；未指定时沿用轮换前会话的租户，新登录使用用户加入的第一个租户
+func (s *authService) resolveTenant(ctx context.Context, user *models.User, requested string, parent *models.Session) (primitive.ObjectID, error) {
+	if requested != "" {
+		tenant, err := s.tenantRepo.GetByName(ctx, requested)
+		if err != nil || !user.MemberOf(tenant.ID) {
+			return primitive.NilObjectID, errors.New("无权访问该租户")
+		}
+		return tenant.ID, nil
+	}
+
+	if parent != nil && parent.TenantID != nil {
+		if !user.MemberOf(*parent.TenantID) {
+			return primitive.NilObjectID, errors.New("用户已不属于该租户")
+		}
+		return *parent.TenantID, nil
+	}
+
+	if len(user.Tenants) > 0 {
+		return user.Tenants[0].TenantID, nil
+	}
+
+	return primitive.NilObjectID, nil
+}
+
+// sessionTenant 会话的当前租户，平台会话返回零值
+func sessionTenant(session *models.Session) primitive.ObjectID {
+	if session.TenantID == nil {
+		return primitive.NilObjectID
+	}
+	return *session.TenantID
+}
+
+// tenantHex 令牌中的租户ID，平台令牌为空
+func tenantHex(tenantID primitive.ObjectID) string {
+	if tenantID.IsZero() {
+		return ""
+	}
+	return tenantID.Hex()
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// tenantFixture 两个租户、各租户的角色和一个全局角色，alice加入了两个租户
+type tenantFixture struct {
+	acme, globex *models.Tenant
+	user         *models.User
+}
+
+// newTenantFixture 创建租户、角色和用户，租户角色只在所属租户中生效
+func (e *testEnv) newTenantFixture(t *testing.T) *tenantFixture {
+	t.Helper()
+
+	f := &tenantFixture{
+		acme:   e.tenants.Put(&models.Tenant{Name: "acme"}),
+		globex: e.tenants.Put(&models.Tenant{Name: "globex"}),
+	}
+	e.tenants.Put(&models.Tenant{Name: "initech"})
+
+	global := e.roles.Put(&models.Role{Name: "member"})
+	acmeAdmin := e.roles.Put(&models.Role{Name: "acme-admin", TenantID: &f.acme.ID})
+	globexViewer := e.roles.Put(&models.Role{Name: "globex-viewer", TenantID: &f.globex.ID})
+
+	f.user = e.createUser(t, &models.User{
+		Username: "alice",
+		Tenants:  []models.TenantMembership{{TenantID: f.acme.ID}, {TenantID: f.globex.ID}},
+		Roles: []models.UserRole{
+			{RoleID: global.ID, RoleName: global.Name},
+			{RoleID: acmeAdmin.ID, RoleName: acmeAdmin.Name, TenantID: &f.acme.ID},
+			{RoleID: globexViewer.ID, RoleName: globexViewer.Name, TenantID: &f.globex.ID},
+		},
+	})
+	return f
+}
+
+// 令牌只携带所选租户的角色和全局角色，只能选择已加入的租户，平台用户获得不限定租户的令牌
+func TestIssueTokensSelectsTenant(t *testing.T) {
+	env := newTestEnv(t, nil)
+	f := env.newTenantFixture(t)
+	platform := env.createUser(t, &models.User{Username: "root"})
+
+	tests := []struct {
+		name      string
+		userID    string
+		tenant    string
+		wantTID   string
+		wantRoles []string
+	}{
+		{"默认第一个租户", f.user.ID.Hex(), "", f.acme.ID.Hex(), []string{"acme-admin", "member"}},
+		{"指定租户", f.user.ID.Hex(), "globex", f.globex.ID.Hex(), []string{"globex-viewer", "member"}},
+		{"平台用户", platform.ID.Hex(), "", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issued, err := env.svc.IssueTokens(context.Background(), tt.userID, &IssueOptions{Tenant: tt.tenant})
+			if err != nil {
+				t.Fatalf("IssueTokens: %v", err)
+			}
+			claims := env.accessClaims(t, issued.AccessToken)
+			if claims.TenantID != tt.wantTID || !reflect.DeepEqual(claims.Roles, tt.wantRoles) {
+				t.Fatalf("租户 = %q，角色 = %v，期望 %q、%v", claims.TenantID, claims.Roles, tt.wantTID, tt.wantRoles)
+			}
+		})
+	}
+
+	for _, tenant := range []string{"initech", "unknown"} {
+		if _, err := env.svc.IssueTokens(context.Background(), f.user.ID.Hex(), &IssueOptions{Tenant: tenant}); err == nil {
+			t.Errorf("选择未加入的租户 %s 成功", tenant)
+		}
+	}
+}
+
+// 刷新令牌时切换租户，未指定时沿用当前租户
+func TestRefreshTokenSwitchesTenant(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	f := env.newTenantFixture(t)
+
+	issued, err := env.svc.IssueTokens(ctx, f.user.ID.Hex(), nil)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	switched, err := env.svc.RefreshToken(ctx, issued.RefreshToken, "globex")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+	refreshed, err := env.svc.RefreshToken(ctx, switched.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("RefreshToken: %v", err)
+	}
+
+	claims := env.accessClaims(t, refreshed.AccessToken)
+	if want := []string{"globex-viewer", "member"}; claims.TenantID != f.globex.ID.Hex() || !reflect.DeepEqual(claims.Roles, want) {
+		t.Fatalf("租户 = %q，角色 = %v，期望沿用 globex", claims.TenantID, claims.Roles)
+	}
+
+	// 用户退出当前租户后不能继续刷新
+	f.user.Tenants = []models.TenantMembership{{TenantID: f.acme.ID}}
+	env.users.Put(f.user)
+	if _, err := env.svc.RefreshToken(ctx, refreshed.RefreshToken, ""); err == nil {
+		t.Fatal("用户退出租户后刷新成功")
+	}
+}
+
+// 切换到无权访问的租户失败时不轮换会话，原刷新令牌仍可使用，不会被当作重放吊销令牌族
+func TestRefreshTokenInvalidTenantKeepsSession(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	f := env.newTenantFixture(t)
+
+	issued, err := env.svc.IssueTokens(ctx, f.user.ID.Hex(), nil)
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+
+	for _, tenant := range []string{"initech", primitive.NewObjectID().Hex()} {
+		if _, err := env.svc.RefreshToken(ctx, issued.RefreshToken, tenant); err == nil {
+			t.Fatalf("切换到无权访问的租户 %s 成功", tenant)
+		}
+	}
+
+	refreshed, err := env.svc.RefreshToken(ctx, issued.RefreshToken, "")
+	if err != nil {
+		t.Fatalf("切换租户失败后原刷新令牌不可用: %v", err)
+	}
+	if env.accessClaims(t, refreshed.AccessToken).TenantID != f.acme.ID.Hex() {
+		t.Fatal("切换租户失败后租户发生了变化")
+	}
+	if env.accessTokenRevoked(t, refreshed.AccessToken) {
+		t.Fatal("切换租户失败被当作刷新令牌重放")
+	}
+}
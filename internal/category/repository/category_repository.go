@@ -43,12 +43,16 @@ type CategoryRepository interface {
 
 	// UpdateDocumentCount 更新文档数量
 	UpdateDocumentCount(id string, count int64) error
+
+	// ForTenant 返回只访问指定租户类别的仓储，创建的类别属于该租户；tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) CategoryRepository
 }
 
 // categoryRepository 类别仓储实现
 type categoryRepository struct {
 	db         *mongo.Database
 	collection *mongo.Collection
+	tenantID   primitive.ObjectID // 非零值时只访问该租户的类别
 }
 
 // NewCategoryRepository 创建类别仓储
@@ -59,6 +63,30 @@ func NewCategoryRepository(db *mongo.Database) CategoryRepository {
 	}
 }
 
+// ForTenant 返回只访问指定租户类别的仓储
+func (r *categoryRepository) ForTenant(tenantID primitive.ObjectID) CategoryRepository {
+	scoped := *r
+	scoped.tenantID = tenantID
+	return &scoped
+}
+
+// scope 为查询条件加上租户限定
+func (r *categoryRepository) scope(filter bson.M) bson.M {
+	if !r.tenantID.IsZero() {
+		filter["tenant_id"] = r.tenantID
+	}
+	return filter
+}
+
+// scopeName 按名称查询的租户限定，不限定租户时只查找不属于任何租户的类别
+func (r *categoryRepository) scopeName(filter bson.M) bson.M {
+	if r.tenantID.IsZero() {
+		filter["tenant_id"] = bson.M{"$exists": false}
+		return filter
+	}
+	return r.scope(filter)
+}
+
 // Create 创建类别
 func (r *categoryRepository) Create(category *models.Category) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -74,6 +102,11 @@ func (r *categoryRepository) Create(category *models.Category) error {
 		category.Status = "active"
 	}
 
+	if !r.tenantID.IsZero() {
+		tenantID := r.tenantID
+		category.TenantID = &tenantID
+	}
+
 	// 如果有父类别，需要更新父类别的children字段
 	if category.ParentID != nil {
 		// 插入类别
@@ -83,12 +116,15 @@ func (r *categoryRepository) Create(category *models.Category) error {
 		}
 		category.ID = result.InsertedID.(primitive.ObjectID)
 
-		// 更新父类别的children字段
-		_, err = r.collection.UpdateOne(
+		// 更新父类别的children字段，限定租户时父类别须属于同一租户
+		parent, err := r.collection.UpdateOne(
 			ctx,
-			bson.M{"_id": *category.ParentID},
+			r.scope(bson.M{"_id": *category.ParentID}),
 			bson.M{"$addToSet": bson.M{"children": category.ID}},
 		)
+		if err == nil && parent.MatchedCount == 0 {
+			err = errors.New("parent category not found")
+		}
 		if err != nil {
 			// 如果更新父类别失败，删除刚创建的类别
 			r.collection.DeleteOne(ctx, bson.M{"_id": category.ID})
@@ -117,7 +153,7 @@ func (r *categoryRepository) GetByID(id string) (*models.Category, error) {
 	}
 
 	var category models.Category
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&category)
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&category)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("category not found")
@@ -134,7 +170,7 @@ func (r *categoryRepository) GetByName(name string) (*models.Category, error) {
 	defer cancel()
 
 	var category models.Category
-	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&category)
+	err := r.collection.FindOne(ctx, r.scopeName(bson.M{"name": name})).Decode(&category)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("category not found")
@@ -160,7 +196,7 @@ func (r *categoryRepository) List(page, pageSize int) ([]*models.Category, int64
 	findOptions.SetSort(bson.D{{Key: "level", Value: 1}, {Key: "sort_order", Value: 1}}) // 按级别和排序顺序
 
 	// 查询类别
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{}), findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -172,7 +208,7 @@ func (r *categoryRepository) List(page, pageSize int) ([]*models.Category, int64
 	}
 
 	// 获取总数
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, r.scope(bson.M{}))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -196,7 +232,7 @@ func (r *categoryRepository) Update(id string, data *models.Category) error {
 	// 创建更新文档
 	updateDoc := bson.M{"$set": data}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, updateDoc)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), updateDoc)
 	if err != nil {
 		return err
 	}
@@ -219,7 +255,7 @@ func (r *categoryRepository) Delete(id string) error {
 	}
 
 	// 检查是否有子类别
-	childCount, err := r.collection.CountDocuments(ctx, bson.M{"parent_id": objectID})
+	childCount, err := r.collection.CountDocuments(ctx, r.scope(bson.M{"parent_id": objectID}))
 	if err != nil {
 		return err
 	}
@@ -230,7 +266,7 @@ func (r *categoryRepository) Delete(id string) error {
 
 	// 获取类别信息以便更新父类别
 	var category models.Category
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&category)
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&category)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return errors.New("category not found")
@@ -239,7 +275,7 @@ func (r *categoryRepository) Delete(id string) error {
 	}
 
 	// 删除类别
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	result, err := r.collection.DeleteOne(ctx, r.scope(bson.M{"_id": objectID}))
 	if err != nil {
 		return err
 	}
@@ -252,7 +288,7 @@ func (r *categoryRepository) Delete(id string) error {
 	if category.ParentID != nil {
 		_, err = r.collection.UpdateOne(
 			ctx,
-			bson.M{"_id": *category.ParentID},
+			r.scope(bson.M{"_id": *category.ParentID}),
 			bson.M{"$pull": bson.M{"children": objectID}},
 		)
 		// 这里不返回错误，因为类别已经删除成功
@@ -271,7 +307,7 @@ func (r *categoryRepository) GetByParent(parentID string) ([]*models.Category, e
 		return nil, errors.New("invalid parent ID format")
 	}
 
-	cursor, err := r.collection.Find(ctx, bson.M{"parent_id": objectID}, options.Find().SetSort(bson.D{{Key: "sort_order", Value: 1}}))
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{"parent_id": objectID}), options.Find().SetSort(bson.D{{Key: "sort_order", Value: 1}}))
 	if err != nil {
 		return nil, err
 	}
@@ -290,7 +326,7 @@ func (r *categoryRepository) GetRootCategories() ([]*models.Category, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := r.collection.Find(ctx, bson.M{"parent_id": nil}, options.Find().SetSort(bson.D{{Key: "sort_order", Value: 1}}))
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{"parent_id": nil}), options.Find().SetSort(bson.D{{Key: "sort_order", Value: 1}}))
 	if err != nil {
 		return nil, err
 	}
@@ -309,7 +345,7 @@ func (r *categoryRepository) GetCategoryTree() ([]*models.Category, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "level", Value: 1}, {Key: "sort_order", Value: 1}}))
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{}), options.Find().SetSort(bson.D{{Key: "level", Value: 1}, {Key: "sort_order", Value: 1}}))
 	if err != nil {
 		return nil, err
 	}
@@ -340,7 +376,7 @@ func (r *categoryRepository) UpdateDocumentCount(id string, count int64) error {
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
 	if err != nil {
 		return err
 	}
@@ -12,8 +12,14 @@ type Config struct {
 	Server      ServerConfig      `mapstructure:"server"`
 	MongoDB     MongoDBConfig     `mapstructure:"mongodb"`
 	JWT         JWTConfig         `mapstructure:"jwt"`
+	OAuth       OAuthConfig       `mapstructure:"oauth"`
+	WebAuthn    WebAuthnConfig    `mapstructure:"webauthn"`
+	SMS         SMSConfig         `mapstructure:"sms"`
+	Mail        MailConfig        `mapstructure:"mail"`
+	Breach      BreachConfig      `mapstructure:"breach"`
 	Security    SecurityConfig    `mapstructure:"security"`
 	Performance PerformanceConfig `mapstructure:"performance"`
+	Policy      PolicyConfig      `mapstructure:"policy"`
 }
 
 // ServerConfig 服务器配置
@@ -33,18 +39,90 @@ type MongoDBConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret             string        `mapstructure:"secret"`
-	AccessTokenExpire  time.Duration `mapstructure:"access_token_expire"`
-	RefreshTokenExpire time.Duration `mapstructure:"refresh_token_expire"`
-	Issuer             string        `mapstructure:"issuer"`
+	Secret              string        `mapstructure:"secret"`
+	AccessTokenExpire   time.Duration `mapstructure:"access_token_expire"`
+	RefreshTokenExpire  time.Duration `mapstructure:"refresh_token_expire"`
+	Issuer              string        `mapstructure:"issuer"`
+	Algorithm           string        `mapstructure:"algorithm"`             // HS256, RS256, ES256, EdDSA
+	KeyFile             string        `mapstructure:"key_file"`              // 非对称密钥存储文件
+	KeyRotationInterval time.Duration `mapstructure:"key_rotation_interval"` // 签名密钥轮换间隔
+}
+
+// OAuthConfig OAuth2/OpenID Connect配置
+type OAuthConfig struct {
+	IssuerURL               string        `mapstructure:"issuer_url"` // 对外访问地址，作为ID Token的签发者
+	LoginURL                string        `mapstructure:"login_url"`  // 未登录用户授权时跳转的登录页面
+	AuthorizationCodeExpire time.Duration `mapstructure:"authorization_code_expire"`
+}
+
+// WebAuthnConfig 通行密钥配置
+type WebAuthnConfig struct {
+	RPID          string        `mapstructure:"rp_id"`           // 依赖方ID，通常为站点域名
+	RPDisplayName string        `mapstructure:"rp_display_name"` // 认证器中显示的服务名称
+	RPOrigins     []string      `mapstructure:"rp_origins"`      // 允许发起仪式的页面来源
+	Timeout       time.Duration `mapstructure:"timeout"`         // 注册和登录仪式的有效期
+}
+
+// SMSConfig 短信验证码配置
+type SMSConfig struct {
+	Provider        string        `mapstructure:"provider"`           // log, file
+	OutboxFile      string        `mapstructure:"outbox_file"`        // provider为file时写入的文件
+	CodeLength      int           `mapstructure:"code_length"`        // 验证码位数
+	CodeExpire      time.Duration `mapstructure:"code_expire"`        // 验证码有效期
+	MaxAttempts     int           `mapstructure:"max_attempts"`       // 单个验证码允许的校验失败次数
+	SendInterval    time.Duration `mapstructure:"send_interval"`      // 同一手机号两次发送的最小间隔
+	MaxSendsPerHour int           `mapstructure:"max_sends_per_hour"` // 同一手机号每小时最多发送次数
+}
+
+// MailConfig 邮件配置
+type MailConfig struct {
+	Provider                string        `mapstructure:"provider"` // smtp, file
+	SMTPHost                string        `mapstructure:"smtp_host"`
+	SMTPPort                int           `mapstructure:"smtp_port"`
+	Username                string        `mapstructure:"username"`
+	Password                string        `mapstructure:"password"`
+	From                    string        `mapstructure:"from"`
+	OutboxFile              string        `mapstructure:"outbox_file"`        // provider为file时写入的文件
+	PasswordResetURL        string        `mapstructure:"password_reset_url"` // 重置密码页面，令牌以token参数附加
+	EmailVerifyURL          string        `mapstructure:"email_verify_url"`   // 邮箱验证页面，令牌以token参数附加
+	PasswordResetExpire     time.Duration `mapstructure:"password_reset_expire"`
+	EmailVerificationExpire time.Duration `mapstructure:"email_verification_expire"`
+}
+
+// BreachConfig 泄露密码检查配置，数据全部在本地，不访问外部服务
+type BreachConfig struct {
+	Source   string `mapstructure:"source"`    // 空为不检查；dump: HIBP导出文件或前缀目录；bloom: cmd/breach-filter构建的布隆过滤器
+	Path     string `mapstructure:"path"`      // 导出文件、前缀目录或过滤器文件的路径
+	MinCount int    `mapstructure:"min_count"` // 泄露次数不低于该值才拒绝，仅对dump生效，bloom在构建时指定
 }
 
 // SecurityConfig 安全配置
 type SecurityConfig struct {
-	MaxLoginAttempts       int           `mapstructure:"max_login_attempts"`
-	LockoutDuration        time.Duration `mapstructure:"lockout_duration"`
-	PasswordMinLength      int           `mapstructure:"password_min_length"`
-	SessionCleanupInterval time.Duration `mapstructure:"session_cleanup_interval"`
+	MaxLoginAttempts         int           `mapstructure:"max_login_attempts"`
+	LockoutDuration          time.Duration `mapstructure:"lockout_duration"`
+	PasswordMinLength        int           `mapstructure:"password_min_length"`
+	PasswordMaxLength        int           `mapstructure:"password_max_length"` // 最大字节数，bcrypt只使用前72个字节
+	PasswordRequireUpper     bool          `mapstructure:"password_require_upper"`
+	PasswordRequireLower     bool          `mapstructure:"password_require_lower"`
+	PasswordRequireDigit     bool          `mapstructure:"password_require_digit"`
+	PasswordRequireSymbol    bool          `mapstructure:"password_require_symbol"`
+	PasswordRejectUserInfo   bool          `mapstructure:"password_reject_user_info"` // 禁止密码包含用户名或邮箱前缀
+	PasswordHistory          int           `mapstructure:"password_history"`          // 禁止重复使用的历史密码数量
+	PasswordMaxAge           time.Duration `mapstructure:"password_max_age"`          // 密码最长使用时间，到期后登录需先修改密码，为0时不限制
+	PasswordHashAlgorithm    string        `mapstructure:"password_hash_algorithm"`   // 新密码的摘要算法：argon2id或bcrypt，旧摘要在登录成功后自动升级
+	BcryptCost               int           `mapstructure:"bcrypt_cost"`
+	Argon2Memory             uint32        `mapstructure:"argon2_memory"` // 单位KiB
+	Argon2Time               uint32        `mapstructure:"argon2_time"`
+	Argon2Threads            uint8         `mapstructure:"argon2_threads"`
+	SessionCleanupInterval   time.Duration `mapstructure:"session_cleanup_interval"`
+	SessionIdleTimeout       time.Duration `mapstructure:"session_idle_timeout"`        // 会话超过该时长无活动即失效，为0时不限制
+	SessionTouchInterval     time.Duration `mapstructure:"session_touch_interval"`      // 使用访问令牌时更新会话活动时间的最小间隔，为0时只在刷新Token时更新
+	MaxSessions              int           `mapstructure:"max_sessions"`                // 每个用户的最大并发会话数，角色可单独设置，为0时不限制
+	SessionLimitPolicy       string        `mapstructure:"session_limit_policy"`        // 达到上限时：evict_oldest踢出最早登录的会话，reject拒绝新登录
+	AccessTokenMaxLifetime   time.Duration `mapstructure:"access_token_max_lifetime"`   // 个人访问令牌的最长有效期，为0时不限制
+	RevocationSyncInterval   time.Duration `mapstructure:"revocation_sync_interval"`    // 多实例间同步令牌吊销记录的间隔
+	RoleGrantCleanupInterval time.Duration `mapstructure:"role_grant_cleanup_interval"` // 移除过期的限时角色分配的间隔
+	ElevationRequestTTL      time.Duration `mapstructure:"elevation_request_ttl"`       // 临时提权申请等待审批的最长时间
 }
 
 // PerformanceConfig 性能配置
@@ -54,6 +132,28 @@ type PerformanceConfig struct {
 	MaxQueryTime         time.Duration `mapstructure:"max_query_time"`
 }
 
+// PolicyConfig 属性访问控制策略配置
+type PolicyConfig struct {
+	Rules []PolicyRuleConfig `mapstructure:"rules"`
+}
+
+// PolicyRuleConfig 策略规则，资源类型和操作为 * 时匹配任意值
+type PolicyRuleConfig struct {
+	Name       string                  `mapstructure:"name"`
+	Effect     string                  `mapstructure:"effect"` // allow, deny
+	Resources  []string                `mapstructure:"resources"`
+	Actions    []string                `mapstructure:"actions"`
+	Conditions []PolicyConditionConfig `mapstructure:"conditions"`
+}
+
+// PolicyConditionConfig 策略条件，属性与values中的值或ref引用的属性比较
+type PolicyConditionConfig struct {
+	Attribute string   `mapstructure:"attribute"` // 如 subject.department、resource.owner、environment.ip
+	Operator  string   `mapstructure:"operator"`  // eq, ne, in, not_in, cidr, gte, lte
+	Values    []string `mapstructure:"values"`
+	Ref       string   `mapstructure:"ref"` // 如 subject.id
+}
+
 // Load 加载配置
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
@@ -100,12 +200,65 @@ func setDefaults() {
 	viper.SetDefault("jwt.refresh_token_expire", "168h")
 	viper.SetDefault("jwt.issuer", "AuthCenter")
 	viper.SetDefault("jwt.secret", "change-this-secret-in-production")
+	viper.SetDefault("jwt.algorithm", "HS256")
+	viper.SetDefault("jwt.key_file", "./data/jwt_keys.json")
+	viper.SetDefault("jwt.key_rotation_interval", "720h")
+
+	viper.SetDefault("oauth.issuer_url", "http://localhost:8080")
+	viper.SetDefault("oauth.login_url", "/test/index.html")
+	viper.SetDefault("oauth.authorization_code_expire", "5m")
+
+	viper.SetDefault("webauthn.rp_id", "localhost")
+	viper.SetDefault("webauthn.rp_display_name", "AuthCenter")
+	viper.SetDefault("webauthn.rp_origins", []string{"http://localhost:8080"})
+	viper.SetDefault("webauthn.timeout", "5m")
+
+	viper.SetDefault("sms.provider", "log")
+	viper.SetDefault("sms.outbox_file", "./data/sms_outbox.jsonl")
+	viper.SetDefault("sms.code_length", 6)
+	viper.SetDefault("sms.code_expire", "5m")
+	viper.SetDefault("sms.max_attempts", 5)
+	viper.SetDefault("sms.send_interval", "60s")
+	viper.SetDefault("sms.max_sends_per_hour", 10)
+
+	viper.SetDefault("mail.provider", "file")
+	viper.SetDefault("mail.smtp_port", 587)
+	viper.SetDefault("mail.from", "AuthCenter <no-reply@localhost>")
+	viper.SetDefault("mail.outbox_file", "./data/mail_outbox.jsonl")
+	viper.SetDefault("mail.password_reset_url", "http://localhost:8080/test/index.html#reset-password")
+	viper.SetDefault("mail.email_verify_url", "http://localhost:8080/test/index.html#verify-email")
+	viper.SetDefault("mail.password_reset_expire", "30m")
+	viper.SetDefault("mail.email_verification_expire", "24h")
+
+	viper.SetDefault("breach.source", "")
+	viper.SetDefault("breach.path", "./data/breach.bloom")
+	viper.SetDefault("breach.min_count", 1)
 
 	viper.SetDefault("security.max_login_attempts", 5)
 	viper.SetDefault("security.lockout_duration", "30m")
 	viper.SetDefault("security.password_min_length", 8)
-	viper.SetDefault("security.session_cleanup_interval", "1h")
+	viper.SetDefault("security.password_max_length", 72)
+	viper.SetDefault("security.password_require_upper", true)
+	viper.SetDefault("security.password_require_lower", true)
+	viper.SetDefault("security.password_require_digit", true)
+	viper.SetDefault("security.password_require_symbol", false)
+	viper.SetDefault("security.password_reject_user_info", true)
+	viper.SetDefault("security.password_history", 5)
+	viper.SetDefault("security.password_max_age", "0s")
+	viper.SetDefault("security.password_hash_algorithm", "argon2id")
 	viper.SetDefault("security.bcrypt_cost", 12)
+	viper.SetDefault("security.argon2_memory", 65536)
+	viper.SetDefault("security.argon2_time", 3)
+	viper.SetDefault("security.argon2_threads", 2)
+	viper.SetDefault("security.session_cleanup_interval", "1h")
+	viper.SetDefault("security.session_idle_timeout", "0s")
+	viper.SetDefault("security.session_touch_interval", "1m")
+	viper.SetDefault("security.max_sessions", 0)
+	viper.SetDefault("security.session_limit_policy", "evict_oldest")
+	viper.SetDefault("security.access_token_max_lifetime", "8760h")
+	viper.SetDefault("security.revocation_sync_interval", "10s")
+	viper.SetDefault("security.role_grant_cleanup_interval", "10m")
+	viper.SetDefault("security.elevation_request_ttl", "24h")
 
 	viper.SetDefault("performance.enable_text_search", true)
 	viper.SetDefault("performance.cache_user_permissions", true)
@@ -19,6 +19,11 @@ func createIndexes() error {
 		return err
 	}
 
+	// 租户集合索引
+	if err := createTenantIndexes(ctx); err != nil {
+		return err
+	}
+
 	// 角色集合索引
 	if err := createRoleIndexes(ctx); err != nil {
 		return err
@@ -49,6 +54,66 @@ func createIndexes() error {
 		return err
 	}
 
+	// 登录失败记录集合索引
+	if err := createLoginAttemptIndexes(ctx); err != nil {
+		return err
+	}
+
+	// 短信验证码集合索引
+	if err := createVerificationCodeIndexes(ctx); err != nil {
+		return err
+	}
+
+	// 邮件令牌集合索引
+	if err := createVerificationTokenIndexes(ctx); err != nil {
+		return err
+	}
+
+	// WebAuthn仪式集合索引
+	if err := createWebAuthnSessionIndexes(ctx); err != nil {
+		return err
+	}
+
+	// 令牌吊销记录集合索引
+	if err := createRevokedTokenIndexes(ctx); err != nil {
+		return err
+	}
+
+	// 个人访问令牌集合索引
+	if err := createAccessTokenIndexes(ctx); err != nil {
+		return err
+	}
+
+	// 临时提权申请集合索引
+	if err := createElevationRequestIndexes(ctx); err != nil {
+		return err
+	}
+
+	// 职责分离规则集合索引
+	if err := createSoDRuleIndexes(ctx); err != nil {
+		return err
+	}
+
+	// 用户组集合索引
+	if err := createGroupIndexes(ctx); err != nil {
+		return err
+	}
+
+	// OAuth客户端集合索引
+	if err := createOAuthClientIndexes(ctx); err != nil {
+		return err
+	}
+
+	// OAuth授权码集合索引
+	if err := createAuthorizationCodeIndexes(ctx); err != nil {
+		return err
+	}
+
+	// 客户端断言集合索引
+	if err := createClientAssertionIndexes(ctx); err != nil {
+		return err
+	}
+
 	// AI助手会话集合索引
 	if err := createAISessionIndexes(ctx); err != nil {
 		return err
@@ -82,9 +147,36 @@ func createUserIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "roles.role_id", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "tenants.tenant_id", Value: 1}},
+		},
+		{
+			// 清理过期的限时角色分配
+			Keys:    bson.D{{Key: "roles.expires_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 		{
 			Keys: bson.D{{Key: "status", Value: 1}},
 		},
+		{
+			Keys:    bson.D{{Key: "webauthn_credentials.credential_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createTenantIndexes 创建租户集合索引
+func createTenantIndexes(ctx context.Context) error {
+	collection := GetCollection("tenants")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
 	}
 
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
@@ -95,9 +187,12 @@ func createUserIndexes(ctx context.Context) error {
 func createRoleIndexes(ctx context.Context) error {
 	collection := GetCollection("roles")
 
+	// 角色名改为在租户内唯一，全局角色之间仍然唯一
+	dropLegacyIndex(ctx, collection, "name_1")
+
 	indexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "name", Value: 1}},
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		{
@@ -141,6 +236,9 @@ func createCategoryIndexes(ctx context.Context) error {
 	collection := GetCollection("categories")
 
 	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+		},
 		{
 			Keys: bson.D{{Key: "parent_id", Value: 1}},
 		},
@@ -166,9 +264,12 @@ func createCategoryIndexes(ctx context.Context) error {
 func createTagIndexes(ctx context.Context) error {
 	collection := GetCollection("tags")
 
+	// 标签名改为在租户内唯一
+	dropLegacyIndex(ctx, collection, "name_1")
+
 	indexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "name", Value: 1}},
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		{
@@ -234,6 +335,9 @@ func createSessionIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "user_id", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "family_id", Value: 1}},
+		},
 		{
 			Keys:    bson.D{{Key: "expires_at", Value: 1}},
 			Options: options.Index().SetExpireAfterSeconds(0),
@@ -241,6 +345,258 @@ func createSessionIndexes(ctx context.Context) error {
 		{
 			Keys: bson.D{{Key: "is_revoked", Value: 1}},
 		},
+		{
+			// 定期吊销空闲会话
+			Keys: bson.D{{Key: "is_revoked", Value: 1}, {Key: "last_accessed_at", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createLoginAttemptIndexes 创建登录失败记录集合索引
+func createLoginAttemptIndexes(ctx context.Context) error {
+	collection := GetCollection("login_attempts")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "identifier", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createVerificationCodeIndexes 创建短信验证码集合索引
+func createVerificationCodeIndexes(ctx context.Context) error {
+	collection := GetCollection("verification_codes")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "target", Value: 1}, {Key: "purpose", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "purge_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createVerificationTokenIndexes 创建邮件令牌集合索引
+func createVerificationTokenIndexes(ctx context.Context) error {
+	collection := GetCollection("verification_tokens")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createWebAuthnSessionIndexes 创建WebAuthn仪式集合索引
+func createWebAuthnSessionIndexes(ctx context.Context) error {
+	collection := GetCollection("webauthn_sessions")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "session_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createRevokedTokenIndexes 创建令牌吊销记录集合索引
+func createRevokedTokenIndexes(ctx context.Context) error {
+	collection := GetCollection("revoked_tokens")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "revoked_at", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createAccessTokenIndexes 创建个人访问令牌集合索引
+func createAccessTokenIndexes(ctx context.Context) error {
+	collection := GetCollection("personal_access_tokens")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			// 过期的令牌保留30天，便于用户在列表中看到令牌已过期
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(30 * 24 * 3600),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createElevationRequestIndexes 创建临时提权申请集合索引，申请作为审计记录长期保留
+func createElevationRequestIndexes(ctx context.Context) error {
+	collection := GetCollection("elevation_requests")
+
+	indexes := []mongo.IndexModel{
+		{
+			// 同一用户对同一角色只能有一个待审批的申请
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "role_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": "pending"}),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createSoDRuleIndexes 创建职责分离规则集合索引
+func createSoDRuleIndexes(ctx context.Context) error {
+	collection := GetCollection("sod_rules")
+
+	// 规则名改为在租户内唯一
+	dropLegacyIndex(ctx, collection, "name_1")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// 分配角色时按类型和角色查找相关规则
+			Keys: bson.D{{Key: "type", Value: 1}, {Key: "role_ids", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createGroupIndexes 创建用户组集合索引
+func createGroupIndexes(ctx context.Context) error {
+	collection := GetCollection("groups")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// 签发令牌时查找用户所属的手动组
+			Keys: bson.D{{Key: "members", Value: 1}},
+		},
+		{
+			// 签发令牌时按部门和职位匹配动态组
+			Keys: bson.D{{Key: "type", Value: 1}, {Key: "department", Value: 1}, {Key: "position", Value: 1}},
+		},
+		{
+			// 删除角色时从用户组中移除
+			Keys: bson.D{{Key: "roles.role_id", Value: 1}},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createOAuthClientIndexes 创建OAuth客户端集合索引
+func createOAuthClientIndexes(ctx context.Context) error {
+	collection := GetCollection("oauth_clients")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "client_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createAuthorizationCodeIndexes 创建OAuth授权码集合索引
+func createAuthorizationCodeIndexes(ctx context.Context) error {
+	collection := GetCollection("oauth_authorization_codes")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// createClientAssertionIndexes 创建客户端断言集合索引
+func createClientAssertionIndexes(ctx context.Context) error {
+	collection := GetCollection("oauth_client_assertions")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "client_id", Value: 1}, {Key: "jti", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
 	}
 
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
@@ -288,3 +644,8 @@ func createAIMessageIndexes(ctx context.Context) error {
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
 	return err
 }
+
+// dropLegacyIndex 删除已被替换的旧索引，索引不存在时忽略
+func dropLegacyIndex(ctx context.Context, collection *mongo.Collection, name string) {
+	_, _ = collection.Indexes().DropOne(ctx, name)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+
+	"authcenter/internal/elevation/service"
+	"authcenter/internal/middleware"
+	"authcenter/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DecisionRequest 审批请求
+type DecisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// ElevationHandler 临时提权处理器
+type ElevationHandler struct {
+	elevationService service.ElevationService
+}
+
+// NewElevationHandler 创建临时提权处理器
+func NewElevationHandler(elevationService service.ElevationService) *ElevationHandler {
+	return &ElevationHandler{
+		elevationService: elevationService,
+	}
+}
+
+// RequestElevation 申请临时提权
+func (h *ElevationHandler) RequestElevation(c *gin.Context) {
+	var req service.CreateElevationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	elevation, err := h.elevationService.ForTenant(middleware.TenantID(c)).Request(c, c.GetString("user_id"), &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "申请临时提权失败", err.Error())
+		return
+	}
+
+	response.Success(c, elevation)
+}
+
+// ListMyElevations 获取当前用户的提权申请
+func (h *ElevationHandler) ListMyElevations(c *gin.Context) {
+	elevations, err := h.elevationService.ForTenant(middleware.TenantID(c)).ListMine(c, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "获取提权申请失败", err.Error())
+		return
+	}
+
+	response.Success(c, elevations)
+}
+
+// CancelElevation 撤回当前用户待审批的提权申请
+func (h *ElevationHandler) CancelElevation(c *gin.Context) {
+	if err := h.elevationService.ForTenant(middleware.TenantID(c)).Cancel(c, c.GetString("user_id"), c.Param("id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "撤回提权申请失败", err.Error())
+		return
+	}
+
+	response.Success(c, "撤回成功")
+}
+
+// ListPendingElevations 获取待审批的提权申请
+func (h *ElevationHandler) ListPendingElevations(c *gin.Context) {
+	elevations, err := h.elevationService.ForTenant(middleware.TenantID(c)).ListPending(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "获取提权申请失败", err.Error())
+		return
+	}
+
+	response.Success(c, elevations)
+}
+
+// ApproveElevation 审批通过提权申请
+func (h *ElevationHandler) ApproveElevation(c *gin.Context) {
+	var req DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	elevation, err := h.elevationService.ForTenant(middleware.TenantID(c)).Approve(c, c.Param("id"), c.GetString("user_id"), req.Comment)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "审批失败", err.Error())
+		return
+	}
+
+	response.Success(c, elevation)
+}
+
+// DenyElevation 拒绝提权申请
+func (h *ElevationHandler) DenyElevation(c *gin.Context) {
+	var req DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	elevation, err := h.elevationService.ForTenant(middleware.TenantID(c)).Deny(c, c.Param("id"), c.GetString("user_id"), req.Comment)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "审批失败", err.Error())
+		return
+	}
+
+	response.Success(c, elevation)
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 提权申请状态
+const (
+	StatusPending   = "pending"
+	StatusApproved  = "approved"
+	StatusDenied    = "denied"
+	StatusCancelled = "cancelled"
+	StatusExpired   = "expired"
+)
+
+// ErrPendingExists 用户对同一角色已有待审批的申请
+var ErrPendingExists = errors.New("elevation request already pending")
+
+// ElevationRepository 临时提权申请数据访问接口
+type ElevationRepository interface {
+	// Create 保存申请，同一用户对同一角色已有待审批的申请时返回ErrPendingExists
+	Create(ctx context.Context, req *models.ElevationRequest) error
+
+	// GetByID 通过ID获取申请
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.ElevationRequest, error)
+
+	// ListByUser 获取用户的申请，按创建时间倒序
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*models.ElevationRequest, error)
+
+	// ListPending 获取尚未过期的待审批申请，按创建时间正序
+	ListPending(ctx context.Context, now time.Time) ([]*models.ElevationRequest, error)
+
+	// Decide 将待审批的申请更新为req中的状态和审批信息，申请已不是待审批状态时返回false
+	Decide(ctx context.Context, req *models.ElevationRequest) (bool, error)
+
+	// Reopen 将已通过的申请恢复为待审批，用于授予角色失败时回滚
+	Reopen(ctx context.Context, id primitive.ObjectID) error
+
+	// ExpireStale 将用户对角色超过审批期限的待审批申请标记为过期
+	ExpireStale(ctx context.Context, userID, roleID primitive.ObjectID, now time.Time) error
+
+	// ForTenant 返回只访问指定租户角色的申请的仓储；tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) ElevationRepository
+}
+
+// elevationRepository 临时提权申请仓储实现
+type elevationRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	tenantID   primitive.ObjectID // 非零值时只访问该租户角色的申请
+}
+
+// NewElevationRepository 创建临时提权申请仓储
+func NewElevationRepository(db *mongo.Database) ElevationRepository {
+	return &elevationRepository{
+		db:         db,
+		collection: db.Collection("elevation_requests"),
+	}
+}
+
+// ForTenant 返回只访问指定租户角色的申请的仓储
+func (r *elevationRepository) ForTenant(tenantID primitive.ObjectID) ElevationRepository {
+	scoped := *r
+	scoped.tenantID = tenantID
+	return &scoped
+}
+
+// scope 为查询条件加上租户限定
+func (r *elevationRepository) scope(filter bson.M) bson.M {
+	if !r.tenantID.IsZero() {
+		filter["tenant_id"] = r.tenantID
+	}
+	return filter
+}
+
+// Create 保存申请
+func (r *elevationRepository) Create(ctx context.Context, req *models.ElevationRequest) error {
+	result, err := r.collection.InsertOne(ctx, req)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrPendingExists
+		}
+		return err
+	}
+
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		req.ID = id
+	}
+	return nil
+}
+
+// GetByID 通过ID获取申请
+func (r *elevationRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.ElevationRequest, error) {
+	var req models.ElevationRequest
+	err := r.collection.FindOne(ctx, r.scope(bson.M{"_id": id})).Decode(&req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("elevation request not found")
+		}
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// ListByUser 获取用户的申请
+func (r *elevationRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*models.ElevationRequest, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return r.find(ctx, r.scope(bson.M{"user_id": userID}), opts)
+}
+
+// ListPending 获取尚未过期的待审批申请
+func (r *elevationRepository) ListPending(ctx context.Context, now time.Time) ([]*models.ElevationRequest, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	return r.find(ctx, r.scope(bson.M{"status": StatusPending, "expires_at": bson.M{"$gt": now}}), opts)
+}
+
+// Decide 更新待审批申请的状态和审批信息
+func (r *elevationRepository) Decide(ctx context.Context, req *models.ElevationRequest) (bool, error) {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		r.scope(bson.M{"_id": req.ID, "status": StatusPending}),
+		bson.M{"$set": bson.M{
+			"status":           req.Status,
+			"approver_id":      req.ApproverID,
+			"approver_name":    req.ApproverName,
+			"comment":          req.Comment,
+			"decided_at":       req.DecidedAt,
+			"grant_expires_at": req.GrantExpiresAt,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// Reopen 将已通过的申请恢复为待审批
+func (r *elevationRepository) Reopen(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		r.scope(bson.M{"_id": id, "status": StatusApproved}),
+		bson.M{
+			"$set":   bson.M{"status": StatusPending},
+			"$unset": bson.M{"approver_id": "", "approver_name": "", "comment": "", "decided_at": "", "grant_expires_at": ""},
+		},
+	)
+	return err
+}
+
+// ExpireStale 将超过审批期限的待审批申请标记为过期
+func (r *elevationRepository) ExpireStale(ctx context.Context, userID, roleID primitive.ObjectID, now time.Time) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "role_id": roleID, "status": StatusPending, "expires_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"status": StatusExpired}},
+	)
+	return err
+}
+
+// find 按条件查询申请
+func (r *elevationRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*models.ElevationRequest, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	requests := make([]*models.ElevationRequest, 0)
+	if err = cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
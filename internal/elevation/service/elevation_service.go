@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"authcenter/internal/elevation/repository"
+	"authcenter/internal/models"
+	roleRepo "authcenter/internal/role/repository"
+	roleService "authcenter/internal/role/service"
+	userRepo "authcenter/internal/user/repository"
+	"authcenter/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxJustificationLength 申请理由的最大字符数
+const maxJustificationLength = 1000
+
+// CreateElevationRequest 创建临时提权申请请求
+type CreateElevationRequest struct {
+	RoleID        string `json:"role_id" binding:"required"`
+	Duration      int64  `json:"duration" binding:"required"` // 提权时长（秒），不能超过角色允许的最长时间
+	Justification string `json:"justification" binding:"required"`
+}
+
+// ElevationService 临时提权业务逻辑接口
+type ElevationService interface {
+	// Request 申请临时拥有角色，审批通过后按申请的时长授予
+	Request(ctx context.Context, userID string, req *CreateElevationRequest) (*models.ElevationRequest, error)
+
+	// ListMine 获取用户自己的申请
+	ListMine(ctx context.Context, userID string) ([]*models.ElevationRequest, error)
+
+	// Cancel 撤回用户自己待审批的申请
+	Cancel(ctx context.Context, userID, id string) error
+
+	// ListPending 获取待审批的申请
+	ListPending(ctx context.Context) ([]*models.ElevationRequest, error)
+
+	// Approve 审批通过并授予限时角色，审批人不能是申请人
+	Approve(ctx context.Context, id, approverID, comment string) (*models.ElevationRequest, error)
+
+	// Deny 拒绝申请，审批人不能是申请人
+	Deny(ctx context.Context, id, approverID, comment string) (*models.ElevationRequest, error)
+
+	// ForTenant 返回只处理指定租户角色申请的服务，tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) ElevationService
+}
+
+// elevationService 临时提权服务实现
+type elevationService struct {
+	elevationRepo repository.ElevationRepository
+	userRepo      userRepo.UserRepository
+	roleRepo      roleRepo.RoleRepository
+	requestTTL    time.Duration
+	tenantID      primitive.ObjectID // 非零值时只处理该租户角色的申请
+}
+
+// NewElevationService 创建临时提权服务，requestTTL为申请等待审批的最长时间
+func NewElevationService(
+	elevationRepo repository.ElevationRepository,
+	userRepo userRepo.UserRepository,
+	roleRepo roleRepo.RoleRepository,
+	requestTTL time.Duration,
+) ElevationService {
+	return &elevationService{
+		elevationRepo: elevationRepo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		requestTTL:    requestTTL,
+	}
+}
+
+// ForTenant 返回只处理指定租户角色申请的服务
+func (s *elevationService) ForTenant(tenantID primitive.ObjectID) ElevationService {
+	scoped := *s
+	scoped.tenantID = tenantID
+	scoped.elevationRepo = s.elevationRepo.ForTenant(tenantID)
+	scoped.userRepo = s.userRepo.ForTenant(tenantID)
+	return &scoped
+}
+
+// Request 申请临时拥有角色
+func (s *elevationService) Request(ctx context.Context, userID string, req *CreateElevationRequest) (*models.ElevationRequest, error) {
+	justification := strings.TrimSpace(req.Justification)
+	if justification == "" {
+		return nil, errors.New("申请理由不能为空")
+	}
+	if len([]rune(justification)) > maxJustificationLength {
+		return nil, errors.New("申请理由过长")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	role, err := s.roleRepo.ForTenant(s.tenantID).GetByID(req.RoleID)
+	if err != nil {
+		return nil, errors.New("角色不存在")
+	}
+	if role.Elevation == nil {
+		return nil, errors.New("该角色不允许临时提权")
+	}
+	if req.Duration <= 0 || req.Duration > role.Elevation.MaxDuration {
+		return nil, errors.New("提权时长超出角色允许的范围")
+	}
+
+	now := time.Now()
+	if grant := findGrant(user.Roles, role.ID, now); grant != nil && grant.ExpiresAt == nil {
+		return nil, errors.New("已拥有该角色")
+	}
+
+	// 超过审批期限的旧申请不再占用待审批名额
+	if err := s.elevationRepo.ExpireStale(ctx, user.ID, role.ID, now); err != nil {
+		return nil, err
+	}
+
+	elevation := &models.ElevationRequest{
+		UserID:        user.ID,
+		Username:      user.Username,
+		RoleID:        role.ID,
+		RoleName:      role.Name,
+		TenantID:      role.TenantID,
+		Duration:      req.Duration,
+		Justification: justification,
+		Status:        repository.StatusPending,
+		ExpiresAt:     now.Add(s.requestTTL),
+		CreatedAt:     now,
+	}
+	if err := s.elevationRepo.Create(ctx, elevation); err != nil {
+		if err == repository.ErrPendingExists {
+			return nil, errors.New("该角色已有待审批的申请")
+		}
+		return nil, err
+	}
+
+	logger.Audit("elevation_requested", map[string]interface{}{
+		"request_id":    elevation.ID.Hex(),
+		"user_id":       userID,
+		"role_id":       role.ID.Hex(),
+		"role_name":     role.Name,
+		"duration":      req.Duration,
+		"justification": justification,
+	})
+
+	return elevation, nil
+}
+
+// ListMine 获取用户自己的申请
+func (s *elevationService) ListMine(ctx context.Context, userID string) ([]*models.ElevationRequest, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("无效的用户ID")
+	}
+
+	return s.elevationRepo.ListByUser(ctx, userObjID)
+}
+
+// Cancel 撤回用户自己待审批的申请
+func (s *elevationService) Cancel(ctx context.Context, userID, id string) error {
+	elevation, err := s.getElevation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if elevation.UserID.Hex() != userID {
+		return errors.New("申请不存在")
+	}
+
+	now := time.Now()
+	elevation.Status = repository.StatusCancelled
+	elevation.DecidedAt = &now
+
+	ok, err := s.elevationRepo.Decide(ctx, elevation)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("申请已处理")
+	}
+
+	logger.Audit("elevation_cancelled", map[string]interface{}{
+		"request_id": id,
+		"user_id":    userID,
+		"role_id":    elevation.RoleID.Hex(),
+		"role_name":  elevation.RoleName,
+	})
+
+	return nil
+}
+
+// ListPending 获取待审批的申请
+func (s *elevationService) ListPending(ctx context.Context) ([]*models.ElevationRequest, error) {
+	return s.elevationRepo.ListPending(ctx, time.Now())
+}
+
+// Approve 审批通过并授予限时角色
+// 先将申请更新为已通过，防止重复审批，授予角色失败时恢复为待审批
+func (s *elevationService) Approve(ctx context.Context, id, approverID, comment string) (*models.ElevationRequest, error) {
+	elevation, err := s.getElevation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	approver, role, err := s.checkApprover(ctx, elevation, approverID)
+	if err != nil {
+		return nil, err
+	}
+	if role.Elevation == nil || elevation.Duration > role.Elevation.MaxDuration {
+		return nil, errors.New("申请的时长超出角色当前允许的范围")
+	}
+
+	user, err := s.userRepo.GetByID(elevation.UserID.Hex())
+	if err != nil {
+		return nil, errors.New("申请人不存在")
+	}
+	if user.Status != "active" {
+		return nil, errors.New("申请人已被禁用")
+	}
+
+	now := time.Now()
+	grantExpiresAt := now.Add(time.Duration(elevation.Duration) * time.Second)
+	if grant := findGrant(user.Roles, role.ID, now); grant != nil {
+		if grant.ExpiresAt == nil {
+			return nil, errors.New("申请人已拥有该角色")
+		}
+		// 不缩短已有的限时授权
+		if grant.ExpiresAt.After(grantExpiresAt) {
+			grantExpiresAt = *grant.ExpiresAt
+		}
+	}
+
+	elevation.Status = repository.StatusApproved
+	elevation.ApproverID = &approver.ID
+	elevation.ApproverName = approver.Username
+	elevation.Comment = strings.TrimSpace(comment)
+	elevation.DecidedAt = &now
+	elevation.GrantExpiresAt = &grantExpiresAt
+
+	ok, err := s.elevationRepo.Decide(ctx, elevation)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("申请已处理")
+	}
+
+	if err := s.userRepo.AssignRole(user.ID.Hex(), role.ID.Hex(), approverID, &grantExpiresAt); err != nil {
+		if reopenErr := s.elevationRepo.Reopen(ctx, elevation.ID); reopenErr != nil {
+			logger.Error("恢复提权申请失败: %s, %v", id, reopenErr)
+		}
+		var violation *userRepo.SoDViolationError
+		if errors.As(err, &violation) {
+			logger.SecurityEvent("sod_violation_blocked", map[string]interface{}{
+				"request_id":  id,
+				"user_id":     user.ID.Hex(),
+				"role_id":     role.ID.Hex(),
+				"operator_id": approverID,
+				"rule":        violation.Rule,
+			})
+			return nil, fmt.Errorf("违反职责分离规则: %s", violation.Rule)
+		}
+		return nil, err
+	}
+
+	logger.Audit("elevation_approved", map[string]interface{}{
+		"request_id":  id,
+		"user_id":     user.ID.Hex(),
+		"role_id":     role.ID.Hex(),
+		"role_name":   role.Name,
+		"approver_id": approverID,
+		"comment":     elevation.Comment,
+		"expires_at":  grantExpiresAt.Format(time.RFC3339),
+	})
+
+	return elevation, nil
+}
+
+// Deny 拒绝申请
+func (s *elevationService) Deny(ctx context.Context, id, approverID, comment string) (*models.ElevationRequest, error) {
+	elevation, err := s.getElevation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	approver, _, err := s.checkApprover(ctx, elevation, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	elevation.Status = repository.StatusDenied
+	elevation.ApproverID = &approver.ID
+	elevation.ApproverName = approver.Username
+	elevation.Comment = strings.TrimSpace(comment)
+	elevation.DecidedAt = &now
+
+	ok, err := s.elevationRepo.Decide(ctx, elevation)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("申请已处理")
+	}
+
+	logger.Audit("elevation_denied", map[string]interface{}{
+		"request_id":  id,
+		"user_id":     elevation.UserID.Hex(),
+		"role_id":     elevation.RoleID.Hex(),
+		"role_name":   elevation.RoleName,
+		"approver_id": approverID,
+		"comment":     elevation.Comment,
+	})
+
+	return elevation, nil
+}
+
+// getElevation 通过ID获取申请
+func (s *elevationService) getElevation(ctx context.Context, id string) (*models.ElevationRequest, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("无效的申请ID")
+	}
+
+	elevation, err := s.elevationRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, errors.New("申请不存在")
+	}
+
+	return elevation, nil
+}
+
+// checkApprover 检查申请是否待审批以及审批人能否审批
+// 审批人不能是申请人，须直接拥有角色指定的审批角色之一，且能够分配该角色
+func (s *elevationService) checkApprover(ctx context.Context, elevation *models.ElevationRequest, approverID string) (*models.User, *models.Role, error) {
+	if elevation.Status != repository.StatusPending {
+		return nil, nil, errors.New("申请已处理")
+	}
+
+	now := time.Now()
+	if !elevation.ExpiresAt.After(now) {
+		elevation.Status = repository.StatusExpired
+		if ok, err := s.elevationRepo.Decide(ctx, elevation); err == nil && ok {
+			logger.Audit("elevation_expired", map[string]interface{}{
+				"request_id": elevation.ID.Hex(),
+				"user_id":    elevation.UserID.Hex(),
+				"role_id":    elevation.RoleID.Hex(),
+				"role_name":  elevation.RoleName,
+			})
+		}
+		return nil, nil, errors.New("申请已过期")
+	}
+
+	if elevation.UserID.Hex() == approverID {
+		logger.SecurityEvent("elevation_self_approval_denied", map[string]interface{}{
+			"request_id": elevation.ID.Hex(),
+			"user_id":    approverID,
+			"role_id":    elevation.RoleID.Hex(),
+		})
+		return nil, nil, errors.New("不能审批自己的申请")
+	}
+
+	approver, err := s.userRepo.GetByID(approverID)
+	if err != nil {
+		return nil, nil, errors.New("审批人不存在")
+	}
+
+	role, err := s.roleRepo.ForTenant(s.tenantID).GetByID(elevation.RoleID.Hex())
+	if err != nil {
+		return nil, nil, errors.New("角色不存在")
+	}
+
+	// 审批人按其在租户中生效的角色判断，包括全局角色
+	approverRoles := approver.RolesIn(s.tenantID)
+	if role.Elevation != nil && len(role.Elevation.ApproverRoles) > 0 && !holdsAnyRole(approverRoles, role.Elevation.ApproverRoles, now) {
+		return nil, nil, errors.New("不是该角色指定的审批人")
+	}
+
+	if err := roleService.CheckAssignable(s.roleRepo, approverRoles, role); err != nil {
+		logger.SecurityEvent("elevation_approval_denied", map[string]interface{}{
+			"request_id":  elevation.ID.Hex(),
+			"role_id":     role.ID.Hex(),
+			"role_level":  role.Level,
+			"approver_id": approverID,
+		})
+		return nil, nil, err
+	}
+
+	return approver, role, nil
+}
+
+// findGrant 查找用户对角色未过期的分配
+func findGrant(assignments []models.UserRole, roleID primitive.ObjectID, now time.Time) *models.UserRole {
+	for i := range assignments {
+		if assignments[i].RoleID == roleID && !assignments[i].Expired(now) {
+			return &assignments[i]
+		}
+	}
+	return nil
+}
+
+// holdsAnyRole 用户是否直接拥有未过期的任一角色
+func holdsAnyRole(assignments []models.UserRole, roleIDs []primitive.ObjectID, now time.Time) bool {
+	for _, roleID := range roleIDs {
+		if findGrant(assignments, roleID, now) != nil {
+			return true
+		}
+	}
+	return false
+}
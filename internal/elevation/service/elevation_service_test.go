@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"authcenter/internal/elevation/repository"
+	"authcenter/internal/models"
+	"authcenter/internal/testutil"
+	userRepo "authcenter/internal/user/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// elevationTestEnv 使用内存仓储的临时提权服务
+// dba角色允许提权一小时，须由拥有approver角色的用户审批
+type elevationTestEnv struct {
+	svc        ElevationService
+	elevations *testutil.Elevations
+	users      *testutil.Users
+	roles      *testutil.Roles
+	dba        *models.Role
+	requester  *models.User
+	approver   *models.User
+}
+
+func newElevationTestEnv(t *testing.T) *elevationTestEnv {
+	t.Helper()
+
+	roles := testutil.NewRoles()
+	approverRole := roles.Put(&models.Role{Name: "approver", Level: 10})
+	env := &elevationTestEnv{
+		elevations: testutil.NewElevations(),
+		users:      testutil.NewUsers(),
+		roles:      roles,
+		dba: roles.Put(&models.Role{
+			Name:      "dba",
+			Level:     5,
+			Elevation: &models.RoleElevation{MaxDuration: 3600, ApproverRoles: []primitive.ObjectID{approverRole.ID}},
+		}),
+	}
+	env.requester = env.users.Put(&models.User{Username: "alice"})
+	env.approver = env.users.Put(&models.User{
+		Username: "bob",
+		Roles:    []models.UserRole{{RoleID: approverRole.ID, RoleName: approverRole.Name}},
+	})
+	env.svc = NewElevationService(env.elevations, env.users, roles, time.Hour)
+
+	return env
+}
+
+// request 申请临时拥有dba角色
+func (e *elevationTestEnv) request(t *testing.T, duration int64) *models.ElevationRequest {
+	t.Helper()
+
+	elevation, err := e.svc.Request(context.Background(), e.requester.ID.Hex(), &CreateElevationRequest{
+		RoleID:        e.dba.ID.Hex(),
+		Duration:      duration,
+		Justification: "修复线上数据",
+	})
+	if err != nil {
+		t.Fatalf("Request: %v", err)
+	}
+	return elevation
+}
+
+// grant 用户当前对dba角色的分配
+func (e *elevationTestEnv) grant(user *models.User) *models.UserRole {
+	return findGrant(e.users.Get(user.ID).Roles, e.dba.ID, time.Now())
+}
+
+func TestRequestElevationRejected(t *testing.T) {
+	ctx := context.Background()
+	env := newElevationTestEnv(t)
+	plain := env.roles.Put(&models.Role{Name: "plain"})
+	permanent := env.users.Put(&models.User{
+		Username: "carol",
+		Roles:    []models.UserRole{{RoleID: env.dba.ID, RoleName: env.dba.Name}},
+	})
+
+	tests := []struct {
+		name   string
+		userID string
+		req    *CreateElevationRequest
+	}{
+		{"理由为空", env.requester.ID.Hex(), &CreateElevationRequest{RoleID: env.dba.ID.Hex(), Duration: 60, Justification: " "}},
+		{"角色不允许提权", env.requester.ID.Hex(), &CreateElevationRequest{RoleID: plain.ID.Hex(), Duration: 60, Justification: "x"}},
+		{"角色不存在", env.requester.ID.Hex(), &CreateElevationRequest{RoleID: primitive.NewObjectID().Hex(), Duration: 60, Justification: "x"}},
+		{"超过最长时间", env.requester.ID.Hex(), &CreateElevationRequest{RoleID: env.dba.ID.Hex(), Duration: 3601, Justification: "x"}},
+		{"时长为0", env.requester.ID.Hex(), &CreateElevationRequest{RoleID: env.dba.ID.Hex(), Duration: 0, Justification: "x"}},
+		{"已长期拥有该角色", permanent.ID.Hex(), &CreateElevationRequest{RoleID: env.dba.ID.Hex(), Duration: 60, Justification: "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.svc.Request(ctx, tt.userID, tt.req); err == nil {
+				t.Fatal("申请成功，期望被拒绝")
+			}
+		})
+	}
+
+	env.request(t, 60)
+	if _, err := env.svc.Request(ctx, env.requester.ID.Hex(), &CreateElevationRequest{RoleID: env.dba.ID.Hex(), Duration: 60, Justification: "x"}); err == nil {
+		t.Fatal("已有待审批的申请时再次申请成功")
+	}
+}
+
+// 审批通过后授予限时角色，申请只能审批一次
+func TestApproveElevation(t *testing.T) {
+	ctx := context.Background()
+	env := newElevationTestEnv(t)
+	elevation := env.request(t, 600)
+
+	approved, err := env.svc.Approve(ctx, elevation.ID.Hex(), env.approver.ID.Hex(), " ok ")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if approved.Status != repository.StatusApproved || approved.Comment != "ok" {
+		t.Fatalf("状态 = %s，意见 = %q", approved.Status, approved.Comment)
+	}
+
+	grant := env.grant(env.requester)
+	if grant == nil || grant.ExpiresAt == nil {
+		t.Fatal("未授予限时角色")
+	}
+	if remaining := time.Until(*grant.ExpiresAt); remaining <= 590*time.Second || remaining > 600*time.Second {
+		t.Fatalf("授权剩余 %v，期望约 10 分钟", remaining)
+	}
+
+	if _, err := env.svc.Approve(ctx, elevation.ID.Hex(), env.approver.ID.Hex(), ""); err == nil {
+		t.Fatal("重复审批成功")
+	}
+	if _, err := env.svc.Deny(ctx, elevation.ID.Hex(), env.approver.ID.Hex(), ""); err == nil {
+		t.Fatal("已通过的申请被拒绝")
+	}
+}
+
+// 审批不缩短已有的限时授权
+func TestApproveElevationKeepsLongerGrant(t *testing.T) {
+	ctx := context.Background()
+	env := newElevationTestEnv(t)
+	elevation := env.request(t, 60)
+
+	longer := time.Now().Add(30 * time.Minute)
+	if err := env.users.AssignRole(env.requester.ID.Hex(), env.dba.ID.Hex(), env.approver.ID.Hex(), &longer); err != nil {
+		t.Fatalf("AssignRole: %v", err)
+	}
+
+	if _, err := env.svc.Approve(ctx, elevation.ID.Hex(), env.approver.ID.Hex(), ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if grant := env.grant(env.requester); grant == nil || !grant.ExpiresAt.Equal(longer) {
+		t.Fatalf("授权 = %+v，期望保持到 %v", grant, longer)
+	}
+}
+
+func TestApproveElevationRejectedApprover(t *testing.T) {
+	ctx := context.Background()
+	env := newElevationTestEnv(t)
+	elevation := env.request(t, 60)
+
+	bystander := env.users.Put(&models.User{Username: "carol"})
+
+	// 指定的审批角色级别低于申请的角色时不能审批
+	juniorRole := env.roles.Put(&models.Role{Name: "junior-approver", Level: 1})
+	env.dba.Elevation.ApproverRoles = append(env.dba.Elevation.ApproverRoles, juniorRole.ID)
+	env.roles.Put(env.dba)
+	junior := env.users.Put(&models.User{
+		Username: "dave",
+		Roles:    []models.UserRole{{RoleID: juniorRole.ID, RoleName: juniorRole.Name}},
+	})
+
+	tests := []struct {
+		name       string
+		approverID string
+	}{
+		{"申请人自己", env.requester.ID.Hex()},
+		{"不是指定的审批人", bystander.ID.Hex()},
+		{"审批人级别不足", junior.ID.Hex()},
+		{"审批人不存在", primitive.NewObjectID().Hex()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.svc.Approve(ctx, elevation.ID.Hex(), tt.approverID, ""); err == nil {
+				t.Fatal("审批成功，期望被拒绝")
+			}
+			if _, err := env.svc.Deny(ctx, elevation.ID.Hex(), tt.approverID, ""); err == nil {
+				t.Fatal("拒绝成功，期望被拒绝")
+			}
+		})
+	}
+
+	if env.grant(env.requester) != nil {
+		t.Fatal("审批被拒绝时授予了角色")
+	}
+	stored, _ := env.elevations.GetByID(ctx, elevation.ID)
+	if stored.Status != repository.StatusPending {
+		t.Fatalf("状态 = %s，期望仍待审批", stored.Status)
+	}
+}
+
+// 超过审批期限的申请不能审批，并被标记为过期
+func TestApproveExpiredElevation(t *testing.T) {
+	ctx := context.Background()
+	env := newElevationTestEnv(t)
+	env.svc = NewElevationService(env.elevations, env.users, env.roles, time.Nanosecond)
+	elevation := env.request(t, 60)
+
+	if _, err := env.svc.Approve(ctx, elevation.ID.Hex(), env.approver.ID.Hex(), ""); err == nil {
+		t.Fatal("过期的申请审批成功")
+	}
+	if env.grant(env.requester) != nil {
+		t.Fatal("过期的申请授予了角色")
+	}
+	stored, _ := env.elevations.GetByID(ctx, elevation.ID)
+	if stored.Status != repository.StatusExpired {
+		t.Fatalf("状态 = %s，期望已过期", stored.Status)
+	}
+
+	// 过期的申请不再占用待审批名额
+	env.request(t, 60)
+}
+
+// 授予角色违反职责分离规则时审批失败，申请恢复为待审批
+func TestApproveElevationSoDViolationReopens(t *testing.T) {
+	ctx := context.Background()
+	env := newElevationTestEnv(t)
+	elevation := env.request(t, 60)
+
+	env.svc = NewElevationService(env.elevations, &violatingUsers{Users: env.users}, env.roles, time.Hour)
+
+	if _, err := env.svc.Approve(ctx, elevation.ID.Hex(), env.approver.ID.Hex(), ""); err == nil {
+		t.Fatal("违反职责分离规则时审批成功")
+	}
+	stored, _ := env.elevations.GetByID(ctx, elevation.ID)
+	if stored.Status != repository.StatusPending || stored.ApproverID != nil {
+		t.Fatalf("状态 = %s，审批人 = %v，期望恢复为待审批", stored.Status, stored.ApproverID)
+	}
+}
+
+// violatingUsers 分配角色时总是报告违反静态职责分离规则的用户仓储
+type violatingUsers struct {
+	*testutil.Users
+}
+
+// AssignRole 返回*SoDViolationError
+func (r *violatingUsers) AssignRole(userID, roleID string, grantedBy string, expiresAt *time.Time) error {
+	return &userRepo.SoDViolationError{Rule: "dba-auditor", Username: "alice"}
+}
+
+// 拒绝和撤回后申请不能再被审批，只有申请人能撤回
+func TestDenyAndCancelElevation(t *testing.T) {
+	ctx := context.Background()
+	env := newElevationTestEnv(t)
+
+	denied := env.request(t, 60)
+	if _, err := env.svc.Deny(ctx, denied.ID.Hex(), env.approver.ID.Hex(), "不需要"); err != nil {
+		t.Fatalf("Deny: %v", err)
+	}
+	if _, err := env.svc.Approve(ctx, denied.ID.Hex(), env.approver.ID.Hex(), ""); err == nil {
+		t.Fatal("已拒绝的申请审批成功")
+	}
+
+	cancelled := env.request(t, 60)
+	if err := env.svc.Cancel(ctx, env.approver.ID.Hex(), cancelled.ID.Hex()); err == nil {
+		t.Fatal("撤回了其他用户的申请")
+	}
+	if err := env.svc.Cancel(ctx, env.requester.ID.Hex(), cancelled.ID.Hex()); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if _, err := env.svc.Approve(ctx, cancelled.ID.Hex(), env.approver.ID.Hex(), ""); err == nil {
+		t.Fatal("已撤回的申请审批成功")
+	}
+
+	if env.grant(env.requester) != nil {
+		t.Fatal("未通过的申请授予了角色")
+	}
+	pending, err := env.svc.ListPending(ctx)
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("待审批的申请 %d 个，期望 0 个", len(pending))
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+
+	"authcenter/internal/group/service"
+	"authcenter/internal/middleware"
+	"authcenter/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddMemberRequest 添加用户组成员请求
+type AddMemberRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// AssignRoleRequest 为用户组分配角色请求
+type AssignRoleRequest struct {
+	RoleID string `json:"role_id" binding:"required"`
+}
+
+// GroupHandler 用户组处理器
+type GroupHandler struct {
+	groupService service.GroupService
+}
+
+// NewGroupHandler 创建用户组处理器
+func NewGroupHandler(groupService service.GroupService) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+	}
+}
+
+// ListGroups 获取用户组列表
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	groups, err := h.groupService.ForTenant(middleware.TenantID(c)).ListGroups(c)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取用户组列表失败", err.Error())
+		return
+	}
+
+	response.Success(c, groups)
+}
+
+// GetGroup 获取用户组详情
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	group, err := h.groupService.ForTenant(middleware.TenantID(c)).GetGroup(c, c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "用户组不存在", err.Error())
+		return
+	}
+
+	response.Success(c, group)
+}
+
+// CreateGroup 创建用户组
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req service.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	group, err := h.groupService.ForTenant(middleware.TenantID(c)).CreateGroup(c, &req, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "创建用户组失败", err.Error())
+		return
+	}
+
+	response.Success(c, group)
+}
+
+// UpdateGroup 更新用户组
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	var req service.UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	group, err := h.groupService.ForTenant(middleware.TenantID(c)).UpdateGroup(c, c.Param("id"), &req, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "更新用户组失败", err.Error())
+		return
+	}
+
+	response.Success(c, group)
+}
+
+// DeleteGroup 删除用户组
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	if err := h.groupService.ForTenant(middleware.TenantID(c)).DeleteGroup(c, c.Param("id"), c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusNotFound, "删除用户组失败", err.Error())
+		return
+	}
+
+	response.Success(c, "删除成功")
+}
+
+// AddMember 将用户加入手动组
+func (h *GroupHandler) AddMember(c *gin.Context) {
+	var req AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.groupService.ForTenant(middleware.TenantID(c)).AddMember(c, c.Param("id"), req.UserID, c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "添加用户组成员失败", err.Error())
+		return
+	}
+
+	response.Success(c, "添加成功")
+}
+
+// RemoveMember 将用户移出手动组
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	if err := h.groupService.ForTenant(middleware.TenantID(c)).RemoveMember(c, c.Param("id"), c.Param("user_id"), c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "移除用户组成员失败", err.Error())
+		return
+	}
+
+	response.Success(c, "移除成功")
+}
+
+// AssignRole 为用户组分配角色
+func (h *GroupHandler) AssignRole(c *gin.Context) {
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.groupService.ForTenant(middleware.TenantID(c)).AssignRole(c, c.Param("id"), req.RoleID, c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "分配角色失败", err.Error())
+		return
+	}
+
+	response.Success(c, "分配成功")
+}
+
+// RemoveRole 移除用户组的角色
+func (h *GroupHandler) RemoveRole(c *gin.Context) {
+	if err := h.groupService.ForTenant(middleware.TenantID(c)).RemoveRole(c, c.Param("id"), c.Param("role_id"), c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "移除角色失败", err.Error())
+		return
+	}
+
+	response.Success(c, "移除成功")
+}
@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 用户组类型
+const (
+	GroupManual  = "manual"
+	GroupDynamic = "dynamic"
+)
+
+// ErrGroupExists 组名已被使用
+var ErrGroupExists = errors.New("group name already exists")
+
+// GroupRepository 用户组数据访问接口
+type GroupRepository interface {
+	// Create 创建用户组，组名已被使用时返回ErrGroupExists
+	Create(ctx context.Context, group *models.Group) error
+
+	// GetByID 通过ID获取用户组
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Group, error)
+
+	// List 获取全部用户组，按名称排序
+	List(ctx context.Context) ([]*models.Group, error)
+
+	// Update 更新用户组的名称、描述和动态组的匹配条件
+	Update(ctx context.Context, group *models.Group) error
+
+	// Delete 删除用户组
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// AddMember 将用户加入手动组，已是成员时不重复加入
+	AddMember(ctx context.Context, id, userID primitive.ObjectID) error
+
+	// RemoveMember 将用户移出手动组
+	RemoveMember(ctx context.Context, id, userID primitive.ObjectID) error
+
+	// AssignRole 为用户组分配角色，已分配时替换原分配
+	AssignRole(ctx context.Context, id primitive.ObjectID, role models.UserRole) error
+
+	// RemoveRole 移除用户组的角色
+	RemoveRole(ctx context.Context, id, roleID primitive.ObjectID) error
+
+	// FindByMember 获取用户所属的手动组和资料匹配的动态组
+	// 限定租户时包括该租户和不属于任何租户的组，不限定租户时只包括不属于任何租户的组
+	FindByMember(ctx context.Context, user *models.User) ([]*models.Group, error)
+
+	// ForTenant 返回只访问指定租户用户组的仓储，创建的组属于该租户；tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) GroupRepository
+}
+
+// groupRepository 用户组仓储实现
+type groupRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	tenantID   primitive.ObjectID // 非零值时只访问该租户的用户组
+}
+
+// NewGroupRepository 创建用户组仓储
+func NewGroupRepository(db *mongo.Database) GroupRepository {
+	return &groupRepository{
+		db:         db,
+		collection: db.Collection("groups"),
+	}
+}
+
+// MemberFilter 用户所属的手动组和资料匹配的动态组的查询条件，为空的匹配条件不限
+func MemberFilter(user *models.User) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"type": GroupManual, "members": user.ID},
+		bson.M{
+			"type":       GroupDynamic,
+			"department": bson.M{"$in": bson.A{nil, "", user.Profile.Department}},
+			"position":   bson.M{"$in": bson.A{nil, "", user.Profile.Position}},
+		},
+	}}
+}
+
+// AllTenantsMemberFilter 用户在所属的全部租户中以及不属于任何租户的用户组的查询条件
+// 静态职责分离规则约束用户在所有租户中的全部角色，检查时使用
+func AllTenantsMemberFilter(user *models.User) bson.M {
+	tenants := bson.A{nil}
+	for _, membership := range user.Tenants {
+		tenants = append(tenants, membership.TenantID)
+	}
+
+	filter := MemberFilter(user)
+	filter["tenant_id"] = bson.M{"$in": tenants}
+	return filter
+}
+
+// MembersFilter 用户组成员在users集合中的查询条件，租户的用户组只包括该租户的成员
+func MembersFilter(group *models.Group) bson.M {
+	filter := bson.M{}
+	if group.Type == GroupManual {
+		members := group.Members
+		if members == nil {
+			members = []primitive.ObjectID{}
+		}
+		filter["_id"] = bson.M{"$in": members}
+	} else {
+		if group.Department != "" {
+			filter["profile.department"] = group.Department
+		}
+		if group.Position != "" {
+			filter["profile.position"] = group.Position
+		}
+	}
+
+	if group.TenantID != nil {
+		filter["tenants.tenant_id"] = *group.TenantID
+	}
+	return filter
+}
+
+// ForTenant 返回只访问指定租户用户组的仓储
+func (r *groupRepository) ForTenant(tenantID primitive.ObjectID) GroupRepository {
+	scoped := *r
+	scoped.tenantID = tenantID
+	return &scoped
+}
+
+// scope 为查询条件加上租户限定
+func (r *groupRepository) scope(filter bson.M) bson.M {
+	if !r.tenantID.IsZero() {
+		filter["tenant_id"] = r.tenantID
+	}
+	return filter
+}
+
+// Create 创建用户组
+func (r *groupRepository) Create(ctx context.Context, group *models.Group) error {
+	if !r.tenantID.IsZero() {
+		tenantID := r.tenantID
+		group.TenantID = &tenantID
+	}
+	if group.Roles == nil {
+		group.Roles = []models.UserRole{}
+	}
+
+	result, err := r.collection.InsertOne(ctx, group)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrGroupExists
+		}
+		return err
+	}
+
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		group.ID = id
+	}
+	return nil
+}
+
+// GetByID 通过ID获取用户组
+func (r *groupRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Group, error) {
+	var group models.Group
+	err := r.collection.FindOne(ctx, r.scope(bson.M{"_id": id})).Decode(&group)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("group not found")
+		}
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// List 获取全部用户组
+func (r *groupRepository) List(ctx context.Context) ([]*models.Group, error) {
+	return r.find(ctx, r.scope(bson.M{}))
+}
+
+// Update 更新用户组
+func (r *groupRepository) Update(ctx context.Context, group *models.Group) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":        group.Name,
+			"description": group.Description,
+			"department":  group.Department,
+			"position":    group.Position,
+			"updated_at":  time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": group.ID}), update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrGroupExists
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("group not found")
+	}
+
+	return nil
+}
+
+// Delete 删除用户组
+func (r *groupRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, r.scope(bson.M{"_id": id}))
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("group not found")
+	}
+
+	return nil
+}
+
+// AddMember 将用户加入手动组
+func (r *groupRepository) AddMember(ctx context.Context, id, userID primitive.ObjectID) error {
+	update := bson.M{
+		"$addToSet": bson.M{"members": userID},
+		"$set":      bson.M{"updated_at": time.Now()},
+	}
+
+	return r.updateManual(ctx, id, update)
+}
+
+// RemoveMember 将用户移出手动组
+func (r *groupRepository) RemoveMember(ctx context.Context, id, userID primitive.ObjectID) error {
+	update := bson.M{
+		"$pull": bson.M{"members": userID},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	return r.updateManual(ctx, id, update)
+}
+
+// updateManual 更新手动组
+func (r *groupRepository) updateManual(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": id, "type": GroupManual}), update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("group not found")
+	}
+
+	return nil
+}
+
+// AssignRole 为用户组分配角色
+func (r *groupRepository) AssignRole(ctx context.Context, id primitive.ObjectID, role models.UserRole) error {
+	filter := r.scope(bson.M{"_id": id})
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{
+		"$pull": bson.M{"roles": bson.M{"role_id": role.RoleID}},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("group not found")
+	}
+
+	_, err = r.collection.UpdateOne(ctx, filter, bson.M{
+		"$push": bson.M{"roles": role},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	return err
+}
+
+// RemoveRole 移除用户组的角色
+func (r *groupRepository) RemoveRole(ctx context.Context, id, roleID primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		r.scope(bson.M{"_id": id, "roles.role_id": roleID}),
+		bson.M{
+			"$pull": bson.M{"roles": bson.M{"role_id": roleID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("group role not found")
+	}
+
+	return nil
+}
+
+// FindByMember 获取用户所属的用户组
+func (r *groupRepository) FindByMember(ctx context.Context, user *models.User) ([]*models.Group, error) {
+	filter := MemberFilter(user)
+	if r.tenantID.IsZero() {
+		filter["tenant_id"] = bson.M{"$exists": false}
+	} else {
+		filter["tenant_id"] = bson.M{"$in": bson.A{nil, r.tenantID}}
+	}
+
+	return r.find(ctx, filter)
+}
+
+// find 按条件查询用户组
+func (r *groupRepository) find(ctx context.Context, filter bson.M) ([]*models.Group, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	groups := make([]*models.Group, 0)
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
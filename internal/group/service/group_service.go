@@ -0,0 +1,496 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"authcenter/internal/group/repository"
+	"authcenter/internal/models"
+	roleRepo "authcenter/internal/role/repository"
+	roleService "authcenter/internal/role/service"
+	userRepo "authcenter/internal/user/repository"
+	"authcenter/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateGroupRequest 创建用户组请求
+type CreateGroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`       // manual, dynamic
+	Department  string `json:"department"` // 动态组匹配的部门
+	Position    string `json:"position"`   // 动态组匹配的职位
+}
+
+// UpdateGroupRequest 更新用户组请求，组的类型不能修改
+type UpdateGroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Department  string `json:"department"`
+	Position    string `json:"position"`
+}
+
+// GroupService 用户组业务逻辑接口
+// 成员和角色的变更不吊销已签发的访问令牌，成员刷新Token后生效
+type GroupService interface {
+	// ListGroups 获取全部用户组
+	ListGroups(ctx context.Context) ([]*models.Group, error)
+
+	// GetGroup 获取用户组
+	GetGroup(ctx context.Context, id string) (*models.Group, error)
+
+	// CreateGroup 创建用户组，动态组至少需要部门和职位之一
+	CreateGroup(ctx context.Context, req *CreateGroupRequest, operatorID string) (*models.Group, error)
+
+	// UpdateGroup 更新用户组，动态组按新条件匹配的成员不能因此违反静态职责分离规则
+	UpdateGroup(ctx context.Context, id string, req *UpdateGroupRequest, operatorID string) (*models.Group, error)
+
+	// DeleteGroup 删除用户组
+	DeleteGroup(ctx context.Context, id, operatorID string) error
+
+	// AddMember 将用户加入手动组，用户获得组的角色后不能违反静态职责分离规则
+	AddMember(ctx context.Context, id, userID, operatorID string) error
+
+	// RemoveMember 将用户移出手动组
+	RemoveMember(ctx context.Context, id, userID, operatorID string) error
+
+	// AssignRole 为用户组分配角色，角色级别不能高于操作者
+	// 组的角色之间以及每个成员的全部角色（直接分配和所属各组的）之间不能违反静态职责分离规则
+	AssignRole(ctx context.Context, id, roleID, operatorID string) error
+
+	// RemoveRole 移除用户组的角色
+	RemoveRole(ctx context.Context, id, roleID, operatorID string) error
+
+	// ForTenant 返回只管理指定租户用户组的服务，tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) GroupService
+}
+
+// groupService 用户组服务实现
+type groupService struct {
+	groupRepo repository.GroupRepository
+	userRepo  userRepo.UserRepository
+	roleRepo  roleRepo.RoleRepository
+	sodRepo   roleRepo.SoDRepository
+	tenantID  primitive.ObjectID // 非零值时只管理该租户的用户组
+}
+
+// NewGroupService 创建用户组服务
+func NewGroupService(groupRepo repository.GroupRepository, userRepo userRepo.UserRepository, roleRepo roleRepo.RoleRepository, sodRepo roleRepo.SoDRepository) GroupService {
+	return &groupService{
+		groupRepo: groupRepo,
+		userRepo:  userRepo,
+		roleRepo:  roleRepo,
+		sodRepo:   sodRepo,
+	}
+}
+
+// ForTenant 返回只管理指定租户用户组的服务
+func (s *groupService) ForTenant(tenantID primitive.ObjectID) GroupService {
+	scoped := *s
+	scoped.tenantID = tenantID
+	scoped.groupRepo = s.groupRepo.ForTenant(tenantID)
+	scoped.userRepo = s.userRepo.ForTenant(tenantID)
+	return &scoped
+}
+
+// ListGroups 获取全部用户组
+func (s *groupService) ListGroups(ctx context.Context) ([]*models.Group, error) {
+	return s.groupRepo.List(ctx)
+}
+
+// GetGroup 获取用户组
+func (s *groupService) GetGroup(ctx context.Context, id string) (*models.Group, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("无效的用户组ID")
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, errors.New("用户组不存在")
+	}
+
+	return group, nil
+}
+
+// CreateGroup 创建用户组
+func (s *groupService) CreateGroup(ctx context.Context, req *CreateGroupRequest, operatorID string) (*models.Group, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("用户组名称不能为空")
+	}
+
+	operatorObjID, err := primitive.ObjectIDFromHex(operatorID)
+	if err != nil {
+		return nil, errors.New("无效的操作者ID")
+	}
+
+	group := &models.Group{
+		Name:        name,
+		Description: req.Description,
+		Type:        req.Type,
+		CreatedBy:   operatorObjID,
+	}
+
+	switch req.Type {
+	case repository.GroupManual:
+	case repository.GroupDynamic:
+		group.Department = strings.TrimSpace(req.Department)
+		group.Position = strings.TrimSpace(req.Position)
+		if group.Department == "" && group.Position == "" {
+			return nil, errors.New("动态组至少需要部门或职位条件")
+		}
+	default:
+		return nil, errors.New("无效的用户组类型")
+	}
+
+	now := time.Now()
+	group.CreatedAt = now
+	group.UpdatedAt = now
+
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		if errors.Is(err, repository.ErrGroupExists) {
+			return nil, errors.New("用户组名称已存在")
+		}
+		return nil, err
+	}
+
+	logger.Audit("group_created", map[string]interface{}{
+		"group_id":    group.ID.Hex(),
+		"name":        group.Name,
+		"type":        group.Type,
+		"department":  group.Department,
+		"position":    group.Position,
+		"operator_id": operatorID,
+	})
+
+	return group, nil
+}
+
+// UpdateGroup 更新用户组
+func (s *groupService) UpdateGroup(ctx context.Context, id string, req *UpdateGroupRequest, operatorID string) (*models.Group, error) {
+	group, err := s.GetGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("用户组名称不能为空")
+	}
+	group.Name = name
+	group.Description = req.Description
+
+	if group.Type == repository.GroupDynamic {
+		group.Department = strings.TrimSpace(req.Department)
+		group.Position = strings.TrimSpace(req.Position)
+		if group.Department == "" && group.Position == "" {
+			return nil, errors.New("动态组至少需要部门或职位条件")
+		}
+
+		// 匹配条件变化后新匹配的用户获得组的角色
+		if err := s.checkMembersSoD(group, groupRoleIDs(group), map[string]interface{}{
+			"group_id":    id,
+			"department":  group.Department,
+			"position":    group.Position,
+			"operator_id": operatorID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		if errors.Is(err, repository.ErrGroupExists) {
+			return nil, errors.New("用户组名称已存在")
+		}
+		return nil, err
+	}
+
+	logger.Audit("group_updated", map[string]interface{}{
+		"group_id":    id,
+		"name":        group.Name,
+		"department":  group.Department,
+		"position":    group.Position,
+		"operator_id": operatorID,
+	})
+
+	return group, nil
+}
+
+// DeleteGroup 删除用户组
+func (s *groupService) DeleteGroup(ctx context.Context, id, operatorID string) error {
+	group, err := s.GetGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.Delete(ctx, group.ID); err != nil {
+		return err
+	}
+
+	logger.Audit("group_deleted", map[string]interface{}{
+		"group_id":    id,
+		"name":        group.Name,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// AddMember 将用户加入手动组，限定租户时用户须是该租户的成员
+func (s *groupService) AddMember(ctx context.Context, id, userID, operatorID string) error {
+	group, err := s.getManualGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	// 组的角色与用户直接分配和其他组获得的角色一起检查
+	err = s.userRepo.CheckSeparationOfDuties([]primitive.ObjectID{user.ID}, groupRoleIDs(group))
+	if err := sodViolation(err, map[string]interface{}{
+		"group_id":    id,
+		"user_id":     userID,
+		"operator_id": operatorID,
+	}); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.AddMember(ctx, group.ID, user.ID); err != nil {
+		return err
+	}
+
+	logger.Audit("group_member_added", map[string]interface{}{
+		"group_id":    id,
+		"user_id":     userID,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// RemoveMember 将用户移出手动组
+func (s *groupService) RemoveMember(ctx context.Context, id, userID, operatorID string) error {
+	group, err := s.getManualGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("无效的用户ID")
+	}
+
+	if err := s.groupRepo.RemoveMember(ctx, group.ID, userObjID); err != nil {
+		return err
+	}
+
+	logger.Audit("group_member_removed", map[string]interface{}{
+		"group_id":    id,
+		"user_id":     userID,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// AssignRole 为用户组分配角色
+func (s *groupService) AssignRole(ctx context.Context, id, roleID, operatorID string) error {
+	group, err := s.GetGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	operator, err := s.userRepo.GetByID(operatorID)
+	if err != nil {
+		return errors.New("操作者不存在")
+	}
+
+	// 租户的用户组只能分配该租户的角色，操作者的级别按其在租户中生效的角色计算，包括全局角色
+	role, err := s.roleRepo.ForTenant(s.tenantID).GetByID(roleID)
+	if err != nil {
+		return errors.New("角色不存在")
+	}
+
+	if err := roleService.CheckAssignable(s.roleRepo, operator.RolesIn(s.tenantID), role); err != nil {
+		logger.SecurityEvent("role_assignment_denied", map[string]interface{}{
+			"group_id":    id,
+			"role_id":     roleID,
+			"role_level":  role.Level,
+			"operator_id": operatorID,
+		})
+		return err
+	}
+
+	if rule, err := s.checkSeparationOfDuties(group, role); err != nil {
+		return err
+	} else if rule != "" {
+		logger.SecurityEvent("sod_violation_blocked", map[string]interface{}{
+			"group_id":    id,
+			"role_id":     roleID,
+			"operator_id": operatorID,
+			"rule":        rule,
+		})
+		return fmt.Errorf("违反职责分离规则: %s", rule)
+	}
+
+	// 组的成员获得该角色，与成员直接分配和其他组获得的角色一起检查
+	if err := s.checkMembersSoD(group, []primitive.ObjectID{role.ID}, map[string]interface{}{
+		"group_id":    id,
+		"role_id":     roleID,
+		"operator_id": operatorID,
+	}); err != nil {
+		return err
+	}
+
+	assignment := models.UserRole{
+		RoleID:    role.ID,
+		RoleName:  role.Name,
+		GrantedBy: operator.ID,
+		GrantedAt: time.Now(),
+		TenantID:  role.TenantID,
+	}
+	if err := s.groupRepo.AssignRole(ctx, group.ID, assignment); err != nil {
+		return err
+	}
+
+	logger.Audit("group_role_assigned", map[string]interface{}{
+		"group_id":    id,
+		"role_id":     roleID,
+		"role_name":   role.Name,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// RemoveRole 移除用户组的角色
+func (s *groupService) RemoveRole(ctx context.Context, id, roleID, operatorID string) error {
+	group, err := s.GetGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	roleObjID, err := primitive.ObjectIDFromHex(roleID)
+	if err != nil {
+		return errors.New("无效的角色ID")
+	}
+
+	if err := s.groupRepo.RemoveRole(ctx, group.ID, roleObjID); err != nil {
+		return errors.New("用户组未分配该角色")
+	}
+
+	logger.Audit("group_role_removed", map[string]interface{}{
+		"group_id":    id,
+		"role_id":     roleID,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// getManualGroup 获取手动组，动态组的成员由匹配条件决定，不能直接维护
+func (s *groupService) getManualGroup(ctx context.Context, id string) (*models.Group, error) {
+	group, err := s.GetGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.Type != repository.GroupManual {
+		return nil, errors.New("动态组的成员由部门和职位决定，不能手动维护")
+	}
+
+	return group, nil
+}
+
+// checkMembersSoD 检查用户组的成员获得roleIDs后是否违反静态职责分离规则，event为违反时记录的安全事件
+func (s *groupService) checkMembersSoD(group *models.Group, roleIDs []primitive.ObjectID, event map[string]interface{}) error {
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	members, err := s.userRepo.FindGroupMembers(group)
+	if err != nil {
+		return err
+	}
+
+	memberIDs := make([]primitive.ObjectID, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.ID)
+	}
+
+	return sodViolation(s.userRepo.CheckSeparationOfDuties(memberIDs, roleIDs), event)
+}
+
+// sodViolation 将违反静态职责分离规则的错误转换为业务错误并记录安全事件，其他错误原样返回
+func sodViolation(err error, event map[string]interface{}) error {
+	var violation *userRepo.SoDViolationError
+	if !errors.As(err, &violation) {
+		return err
+	}
+
+	event["rule"] = violation.Rule
+	event["username"] = violation.Username
+	logger.SecurityEvent("sod_violation_blocked", event)
+
+	return fmt.Errorf("用户%s将违反职责分离规则: %s", violation.Username, violation.Rule)
+}
+
+// groupRoleIDs 用户组分配的角色
+func groupRoleIDs(group *models.Group) []primitive.ObjectID {
+	roleIDs := make([]primitive.ObjectID, 0, len(group.Roles))
+	for _, assignment := range group.Roles {
+		roleIDs = append(roleIDs, assignment.RoleID)
+	}
+	return roleIDs
+}
+
+// checkSeparationOfDuties 检查分配角色后组的角色（含继承的祖先角色）是否违反静态职责分离规则，返回违反的规则名
+// 重新分配同一角色时替换原分配，不与其自身冲突
+func (s *groupService) checkSeparationOfDuties(group *models.Group, role *models.Role) (string, error) {
+	rules, err := s.sodRepo.List(roleRepo.SoDStatic)
+	if err != nil {
+		return "", err
+	}
+	if len(rules) == 0 {
+		return "", nil
+	}
+
+	held := make(map[primitive.ObjectID]bool)
+	hold := func(roleID primitive.ObjectID) {
+		held[roleID] = true
+		if ancestors, err := s.roleRepo.GetAncestors(roleID.Hex()); err == nil {
+			for _, ancestor := range ancestors {
+				held[ancestor.ID] = true
+			}
+		}
+	}
+
+	hold(role.ID)
+	for _, assignment := range group.Roles {
+		if assignment.RoleID != role.ID {
+			hold(assignment.RoleID)
+		}
+	}
+
+	for _, rule := range rules {
+		count := 0
+		for _, roleID := range rule.RoleIDs {
+			if held[roleID] {
+				count++
+			}
+		}
+		if count > 1 {
+			return rule.Name, nil
+		}
+	}
+
+	return "", nil
+}
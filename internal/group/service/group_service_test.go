@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"authcenter/internal/group/repository"
+	"authcenter/internal/models"
+	roleRepo "authcenter/internal/role/repository"
+	"authcenter/internal/testutil"
+	userRepo "authcenter/internal/user/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sodCheckingUsers 记录静态职责分离检查的用户仓储，violator获得角色后报告违反规则
+// 跨用户组的检查由MongoDB聚合实现，这里只验证服务检查了正确的用户和角色
+type sodCheckingUsers struct {
+	*testutil.Users
+
+	violator primitive.ObjectID
+	checked  map[primitive.ObjectID][]primitive.ObjectID // 被检查的用户和新获得的角色
+}
+
+// CheckSeparationOfDuties 记录检查并在检查到violator时返回*SoDViolationError
+func (r *sodCheckingUsers) CheckSeparationOfDuties(userIDs, roleIDs []primitive.ObjectID) error {
+	for _, userID := range userIDs {
+		r.checked[userID] = roleIDs
+	}
+	for _, userID := range userIDs {
+		if userID == r.violator {
+			return &userRepo.SoDViolationError{Rule: "payment", Username: "violator"}
+		}
+	}
+	return nil
+}
+
+// ForTenant 内存仓储不限定租户
+func (r *sodCheckingUsers) ForTenant(tenantID primitive.ObjectID) userRepo.UserRepository {
+	return r
+}
+
+// groupTestEnv 使用内存仓储的用户组服务
+type groupTestEnv struct {
+	svc      GroupService
+	groups   *testutil.Groups
+	users    *sodCheckingUsers
+	operator *models.User
+	cashier  *models.Role
+	auditor  *models.Role
+}
+
+func newGroupTestEnv(t *testing.T) *groupTestEnv {
+	t.Helper()
+
+	roles := testutil.NewRoles()
+	sod := testutil.NewSoDRules()
+	env := &groupTestEnv{
+		groups:  testutil.NewGroups(),
+		users:   &sodCheckingUsers{Users: testutil.NewUsers(), checked: make(map[primitive.ObjectID][]primitive.ObjectID)},
+		cashier: roles.Put(&models.Role{Name: "cashier"}),
+		auditor: roles.Put(&models.Role{Name: "auditor"}),
+	}
+	env.users.SetGroups(env.groups)
+	env.operator = env.users.Put(&models.User{Username: "admin"})
+
+	if err := sod.Create(&models.SoDRule{
+		Name:    "payment",
+		Type:    roleRepo.SoDStatic,
+		RoleIDs: []primitive.ObjectID{env.cashier.ID, env.auditor.ID},
+	}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	env.svc = NewGroupService(env.groups, env.users, roles, sod)
+	return env
+}
+
+// userRoles 角色分配
+func userRoles(roles ...*models.Role) []models.UserRole {
+	assignments := make([]models.UserRole, 0, len(roles))
+	for _, role := range roles {
+		assignments = append(assignments, models.UserRole{RoleID: role.ID, RoleName: role.Name})
+	}
+	return assignments
+}
+
+// checkedUsers 被检查的用户名，已排序
+func (e *groupTestEnv) checkedUsers() []string {
+	names := make([]string, 0, len(e.users.checked))
+	for userID := range e.users.checked {
+		names = append(names, e.users.Get(userID).Username)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 加入手动组时以组的角色检查该用户，违反规则时不加入
+func TestAddMemberChecksSoD(t *testing.T) {
+	ctx := context.Background()
+	env := newGroupTestEnv(t)
+	group := env.groups.Put(&models.Group{Name: "auditors", Type: repository.GroupManual, Roles: userRoles(env.auditor)})
+	alice := env.users.Put(&models.User{Username: "alice"})
+	violator := env.users.Put(&models.User{Username: "violator", Roles: userRoles(env.cashier)})
+	env.users.violator = violator.ID
+
+	if err := env.svc.AddMember(ctx, group.ID.Hex(), alice.ID.Hex(), env.operator.ID.Hex()); err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+	if got := env.users.checked[alice.ID]; !reflect.DeepEqual(got, []primitive.ObjectID{env.auditor.ID}) {
+		t.Fatalf("检查的角色 = %v，期望组的角色", got)
+	}
+
+	if err := env.svc.AddMember(ctx, group.ID.Hex(), violator.ID.Hex(), env.operator.ID.Hex()); err == nil {
+		t.Fatal("违反职责分离规则的用户加入成功")
+	}
+	stored, _ := env.groups.GetByID(ctx, group.ID)
+	if !reflect.DeepEqual(stored.Members, []primitive.ObjectID{alice.ID}) {
+		t.Fatalf("组成员 = %v，期望只有 alice", stored.Members)
+	}
+}
+
+// 为组分配角色时检查组的角色之间以及全部成员，包括匹配动态组的用户
+func TestAssignGroupRoleChecksSoD(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("组的角色之间冲突", func(t *testing.T) {
+		env := newGroupTestEnv(t)
+		group := env.groups.Put(&models.Group{Name: "finance", Type: repository.GroupManual, Roles: userRoles(env.cashier)})
+		if err := env.svc.AssignRole(ctx, group.ID.Hex(), env.auditor.ID.Hex(), env.operator.ID.Hex()); err == nil {
+			t.Fatal("分配与组的角色冲突的角色成功")
+		}
+	})
+
+	t.Run("成员冲突", func(t *testing.T) {
+		env := newGroupTestEnv(t)
+		env.users.Put(&models.User{Username: "alice", Profile: models.UserProfile{Department: "finance"}})
+		violator := env.users.Put(&models.User{Username: "violator", Profile: models.UserProfile{Department: "finance"}})
+		env.users.Put(&models.User{Username: "bob", Profile: models.UserProfile{Department: "sales"}})
+		group := env.groups.Put(&models.Group{Name: "finance", Type: repository.GroupDynamic, Department: "finance"})
+
+		if err := env.svc.AssignRole(ctx, group.ID.Hex(), env.auditor.ID.Hex(), env.operator.ID.Hex()); err != nil {
+			t.Fatalf("AssignRole: %v", err)
+		}
+		if got, want := env.checkedUsers(), []string{"alice", "violator"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("检查的用户 = %v，期望 %v", got, want)
+		}
+
+		env.users.violator = violator.ID
+		if err := env.svc.AssignRole(ctx, group.ID.Hex(), env.cashier.ID.Hex(), env.operator.ID.Hex()); err == nil {
+			t.Fatal("成员违反职责分离规则时分配成功")
+		}
+		stored, _ := env.groups.GetByID(ctx, group.ID)
+		if len(stored.Roles) != 1 || stored.Roles[0].RoleID != env.auditor.ID {
+			t.Fatalf("组的角色 = %v，期望只有 auditor", stored.Roles)
+		}
+	})
+}
+
+// 修改动态组的匹配条件时以组的角色检查新匹配的用户，违反规则时不修改
+func TestUpdateDynamicGroupChecksSoD(t *testing.T) {
+	ctx := context.Background()
+	env := newGroupTestEnv(t)
+	violator := env.users.Put(&models.User{Username: "violator", Profile: models.UserProfile{Department: "sales"}})
+	env.users.violator = violator.ID
+	group := env.groups.Put(&models.Group{Name: "finance", Type: repository.GroupDynamic, Department: "finance", Roles: userRoles(env.auditor)})
+
+	if _, err := env.svc.UpdateGroup(ctx, group.ID.Hex(), &UpdateGroupRequest{Name: "finance", Department: "sales"}, env.operator.ID.Hex()); err == nil {
+		t.Fatal("新匹配的用户违反职责分离规则时修改成功")
+	}
+	stored, _ := env.groups.GetByID(ctx, group.ID)
+	if stored.Department != "finance" {
+		t.Fatalf("部门条件 = %q，期望保持 finance", stored.Department)
+	}
+}
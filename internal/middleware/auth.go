@@ -1,24 +1,54 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"authcenter/pkg/jwt"
+	"authcenter/pkg/policy"
+	"authcenter/pkg/rbac"
 	"authcenter/pkg/response"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// TokenRevocationChecker 访问令牌吊销检查接口
+type TokenRevocationChecker interface {
+	IsRevoked(claims *jwt.Claims) bool
+}
+
+// SessionActivityTracker 会话活动记录接口
+type SessionActivityTracker interface {
+	Touch(claims *jwt.Claims)
+}
+
+// AccessTokenAuthenticator 个人访问令牌认证接口
+type AccessTokenAuthenticator interface {
+	IsAccessToken(token string) bool
+	Authenticate(ctx context.Context, token, ip string) (*jwt.Claims, error)
+}
+
 // AuthMiddleware 认证中间件结构
 type AuthMiddleware struct {
-	jwtManager jwt.Manager
+	jwtManager        jwt.Manager
+	revocationChecker TokenRevocationChecker
+	activityTracker   SessionActivityTracker
+	accessTokens      AccessTokenAuthenticator
+	policyEngine      *policy.Engine
 }
 
-// NewAuthMiddleware 创建认证中间件
-func NewAuthMiddleware(jwtManager jwt.Manager) *AuthMiddleware {
+// NewAuthMiddleware 创建认证中间件，activityTracker为nil时不记录会话活动，accessTokens为nil时不接受个人访问令牌，
+// policyEngine为nil时RequireAuthorization只检查权限
+func NewAuthMiddleware(jwtManager jwt.Manager, revocationChecker TokenRevocationChecker, activityTracker SessionActivityTracker, accessTokens AccessTokenAuthenticator, policyEngine *policy.Engine) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtManager: jwtManager,
+		jwtManager:        jwtManager,
+		revocationChecker: revocationChecker,
+		activityTracker:   activityTracker,
+		accessTokens:      accessTokens,
+		policyEngine:      policyEngine,
 	}
 }
 
@@ -32,6 +62,11 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
+		if m.isAccessToken(token) {
+			m.requireAccessToken(c, token)
+			return
+		}
+
 		claims, err := m.jwtManager.ValidateAccessToken(token)
 		if err != nil {
 			response.Error(c, http.StatusUnauthorized, "无效的Token", err.Error())
@@ -39,11 +74,168 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		// 将用户信息设置到上下文
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("roles", claims.Roles)
-		c.Set("permissions", claims.Permissions)
+		if m.isRevoked(claims) {
+			response.Error(c, http.StatusUnauthorized, "Token已被吊销", "")
+			c.Abort()
+			return
+		}
+
+		if err := m.setClaims(c, claims); err != nil {
+			response.Error(c, http.StatusUnauthorized, "无效的Token", err.Error())
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OptionalAuth 可选认证的中间件，携带有效Token时设置用户信息，否则以匿名身份继续
+// 用于OAuth授权等需要用户登录态的页面，不接受个人访问令牌
+func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := m.extractToken(c)
+		if token != "" {
+			if claims, err := m.jwtManager.ValidateAccessToken(token); err == nil && !m.isRevoked(claims) {
+				// 租户无法解析时不设置用户信息，以匿名身份继续
+				_ = m.setClaims(c, claims)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSubjectType 要求令牌主体为指定类型的中间件，用于区分用户和服务账号，须在RequireAuth之后使用
+// RequireRole只比较角色名，需要限定只有用户或只有服务账号可以访问时与其组合使用
+func (m *AuthMiddleware) RequireSubjectType(subjectTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subjectType := c.GetString("subject_type")
+		for _, allowed := range subjectTypes {
+			if subjectType == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		response.Error(c, http.StatusForbidden, "权限不足", "需要主体类型: "+strings.Join(subjectTypes, ", "))
+		c.Abort()
+	}
+}
+
+// RejectAccessTokens 拒绝个人访问令牌的中间件，用于修改密码、管理令牌等账号操作，须在RequireAuth之后使用
+func (m *AuthMiddleware) RejectAccessTokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := c.Get("claims"); ok && claims.(*jwt.Claims).TokenType == jwt.TokenTypePersonalAccess {
+			response.Error(c, http.StatusForbidden, "个人访问令牌不能用于此操作", "")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RejectClientTokens 拒绝签发给OAuth客户端的令牌的中间件，包括第三方应用获得的用户令牌和服务账号令牌，须在RequireAuth之后使用
+// 用于OAuth授权等只接受第一方登录会话的操作，避免受限令牌借此换取完整权限的会话
+func (m *AuthMiddleware) RejectClientTokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := c.Get("claims"); ok && !FirstPartySession(claims.(*jwt.Claims)) {
+			response.Error(c, http.StatusForbidden, "第三方应用令牌不能用于此操作", "")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// FirstPartySession 令牌是否为用户在第一方登录获得的会话令牌，个人访问令牌、服务账号令牌和第三方应用令牌都不是
+func FirstPartySession(claims *jwt.Claims) bool {
+	return claims.TokenType != jwt.TokenTypePersonalAccess &&
+		!claims.IsServiceAccount() &&
+		claims.ClientID == "" &&
+		claims.Scope == ""
+}
+
+// isAccessToken 是否为个人访问令牌
+func (m *AuthMiddleware) isAccessToken(token string) bool {
+	return m.accessTokens != nil && m.accessTokens.IsAccessToken(token)
+}
+
+// requireAccessToken 校验个人访问令牌
+// 令牌长期有效，只接受Authorization头，避免出现在访问日志和Referer中
+func (m *AuthMiddleware) requireAccessToken(c *gin.Context, token string) {
+	if !strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ") {
+		response.Error(c, http.StatusUnauthorized, "个人访问令牌只能通过Authorization头传递", "")
+		c.Abort()
+		return
+	}
+
+	claims, err := m.accessTokens.Authenticate(c.Request.Context(), token, c.ClientIP())
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "无效的访问令牌", err.Error())
+		c.Abort()
+		return
+	}
+
+	if err := m.setClaims(c, claims); err != nil {
+		response.Error(c, http.StatusUnauthorized, "无效的访问令牌", err.Error())
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// isRevoked 检查访问令牌是否已被吊销
+func (m *AuthMiddleware) isRevoked(claims *jwt.Claims) bool {
+	return m.revocationChecker != nil && m.revocationChecker.IsRevoked(claims)
+}
+
+// setClaims 将用户信息设置到上下文，并记录会话活动
+// 令牌携带的租户无法解析时返回错误，不能当作不限定租户的令牌放行
+func (m *AuthMiddleware) setClaims(c *gin.Context, claims *jwt.Claims) error {
+	var tenantID primitive.ObjectID
+	if claims.TenantID != "" {
+		parsed, err := primitive.ObjectIDFromHex(claims.TenantID)
+		if err != nil {
+			return errors.New("invalid tenant")
+		}
+		tenantID = parsed
+	}
+
+	if m.activityTracker != nil {
+		m.activityTracker.Touch(claims)
+	}
+
+	c.Set("claims", claims)
+	c.Set("user_id", claims.UserID)
+	c.Set("subject_type", claims.PrincipalType())
+	c.Set("username", claims.Username)
+	c.Set("roles", claims.Roles)
+	c.Set("permissions", claims.Permissions)
+	c.Set("scope", claims.Scope)
+	c.Set("tenant_id", tenantID)
+	return nil
+}
+
+// TenantID 获取当前令牌的租户，平台令牌返回零值，须在RequireAuth之后使用
+func TenantID(c *gin.Context) primitive.ObjectID {
+	if value, ok := c.Get("tenant_id"); ok {
+		return value.(primitive.ObjectID)
+	}
+	return primitive.NilObjectID
+}
+
+// RequirePlatformScope 要求不限定租户的平台令牌的中间件，用于租户管理等跨租户的操作，须在RequireAuth之后使用
+func (m *AuthMiddleware) RequirePlatformScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !TenantID(c).IsZero() {
+			response.Error(c, http.StatusForbidden, "权限不足", "需要平台令牌")
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -107,20 +299,9 @@ func (m *AuthMiddleware) RequirePermission(resource, action string) gin.HandlerF
 			return
 		}
 
-		// 构建所需权限
-		requiredPermission := resource + ":" + action
-
-		// 检查用户是否具有所需权限
-		hasPermission := false
-		for _, perm := range userPermissions {
-			if perm == requiredPermission {
-				hasPermission = true
-				break
-			}
-		}
-
-		if !hasPermission {
-			response.Error(c, http.StatusForbidden, "权限不足", "需要权限: "+requiredPermission)
+		// 检查用户是否具有所需权限，支持通配符和父资源
+		if !rbac.Allowed(userPermissions, resource, action) {
+			response.Error(c, http.StatusForbidden, "权限不足", "需要权限: "+rbac.Permission(resource, action))
 			c.Abort()
 			return
 		}
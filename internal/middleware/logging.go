@@ -16,14 +16,14 @@ func LoggingMiddleware() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		// 自定义日志格式
 		logData := map[string]interface{}{
-			"timestamp":    param.TimeStamp.Format(time.RFC3339),
-			"status":       param.StatusCode,
-			"latency":      param.Latency.String(),
-			"client_ip":    param.ClientIP,
-			"method":       param.Method,
-			"path":         param.Path,
-			"user_agent":   param.Request.UserAgent(),
-			"error":        param.ErrorMessage,
+			"timestamp":  param.TimeStamp.Format(time.RFC3339),
+			"status":     param.StatusCode,
+			"latency":    param.Latency.String(),
+			"client_ip":  param.ClientIP,
+			"method":     param.Method,
+			"path":       param.Path,
+			"user_agent": param.Request.UserAgent(),
+			"error":      param.ErrorMessage,
 		}
 
 		// 添加请求ID（如果存在）
@@ -54,16 +54,16 @@ func AuditMiddleware() gin.HandlerFunc {
 
 		// 记录审计日志
 		duration := time.Since(start)
-		
+
 		auditLog := map[string]interface{}{
-			"timestamp":    start.Format(time.RFC3339),
-			"method":       c.Request.Method,
-			"path":         c.Request.URL.Path,
-			"query":        c.Request.URL.RawQuery,
-			"status":       c.Writer.Status(),
-			"duration_ms":  duration.Milliseconds(),
-			"client_ip":    c.ClientIP(),
-			"user_agent":   c.Request.UserAgent(),
+			"timestamp":   start.Format(time.RFC3339),
+			"method":      c.Request.Method,
+			"path":        c.Request.URL.Path,
+			"query":       c.Request.URL.RawQuery,
+			"status":      c.Writer.Status(),
+			"duration_ms": duration.Milliseconds(),
+			"client_ip":   c.ClientIP(),
+			"user_agent":  c.Request.UserAgent(),
 		}
 
 		// 添加用户信息（如果已认证）
@@ -73,6 +73,9 @@ func AuditMiddleware() gin.HandlerFunc {
 		if username, exists := c.Get("username"); exists {
 			auditLog["username"] = username
 		}
+		if subjectType, exists := c.Get("subject_type"); exists {
+			auditLog["subject_type"] = subjectType
+		}
 
 		// 添加请求ID
 		if requestID, exists := c.Get("request_id"); exists {
@@ -97,7 +100,7 @@ func AuditMiddleware() gin.HandlerFunc {
 		}
 
 		// 输出审计日志
-		logger.Info("audit", auditLog)
+		logger.Audit("request", auditLog)
 	}
 }
 
@@ -146,30 +149,28 @@ func SecurityEventMiddleware() gin.HandlerFunc {
 		// 记录认证失败
 		if status == 401 && (path == "/api/v1/auth/login" || path == "/api/v1/auth/verify") {
 			securityEvent := map[string]interface{}{
-				"event_type":   "auth_failure",
-				"timestamp":    time.Now().Format(time.RFC3339),
-				"client_ip":    c.ClientIP(),
-				"user_agent":   c.Request.UserAgent(),
-				"path":         path,
-				"status":       status,
+				"timestamp":  time.Now().Format(time.RFC3339),
+				"client_ip":  c.ClientIP(),
+				"user_agent": c.Request.UserAgent(),
+				"path":       path,
+				"status":     status,
 			}
-			
+
 			if requestID, exists := c.Get("request_id"); exists {
 				securityEvent["request_id"] = requestID
 			}
 
-			logger.Warn("security_event", securityEvent)
+			logger.SecurityEvent("auth_failure", securityEvent)
 		}
 
 		// 记录权限不足
 		if status == 403 {
 			securityEvent := map[string]interface{}{
-				"event_type":   "access_denied",
-				"timestamp":    time.Now().Format(time.RFC3339),
-				"client_ip":    c.ClientIP(),
-				"user_agent":   c.Request.UserAgent(),
-				"path":         path,
-				"status":       status,
+				"timestamp":  time.Now().Format(time.RFC3339),
+				"client_ip":  c.ClientIP(),
+				"user_agent": c.Request.UserAgent(),
+				"path":       path,
+				"status":     status,
 			}
 
 			if userID, exists := c.Get("user_id"); exists {
@@ -179,25 +180,24 @@ func SecurityEventMiddleware() gin.HandlerFunc {
 				securityEvent["request_id"] = requestID
 			}
 
-			logger.Warn("security_event", securityEvent)
+			logger.SecurityEvent("access_denied", securityEvent)
 		}
 
 		// 记录频率限制
 		if status == 429 {
 			securityEvent := map[string]interface{}{
-				"event_type":   "rate_limit_exceeded",
-				"timestamp":    time.Now().Format(time.RFC3339),
-				"client_ip":    c.ClientIP(),
-				"user_agent":   c.Request.UserAgent(),
-				"path":         path,
-				"status":       status,
+				"timestamp":  time.Now().Format(time.RFC3339),
+				"client_ip":  c.ClientIP(),
+				"user_agent": c.Request.UserAgent(),
+				"path":       path,
+				"status":     status,
 			}
 
 			if requestID, exists := c.Get("request_id"); exists {
 				securityEvent["request_id"] = requestID
 			}
 
-			logger.Warn("security_event", securityEvent)
+			logger.SecurityEvent("rate_limit_exceeded", securityEvent)
 		}
 	}
 }
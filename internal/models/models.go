@@ -15,19 +15,104 @@ type User struct {
 	PasswordHash string             `bson:"password_hash" json:"-"`
 	Status       string             `bson:"status" json:"status"` // active, inactive, locked
 	Roles        []UserRole         `bson:"roles" json:"roles"`
+	Tenants      []TenantMembership `bson:"tenants,omitempty" json:"tenants,omitempty"` // 所属租户，为空时为平台用户
 	Profile      UserProfile        `bson:"profile" json:"profile"`
 	LoginHistory LoginHistory       `bson:"login_history" json:"login_history"`
 	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+
+	EmailVerified bool `bson:"email_verified" json:"email_verified"` // 邮箱已通过验证，变更邮箱后需重新验证
+
+	PasswordChangedAt *time.Time `bson:"password_changed_at,omitempty" json:"password_changed_at,omitempty"`
+	PasswordHistory   []string   `bson:"password_history,omitempty" json:"-"` // 之前使用过的密码摘要，从新到旧排列
+
+	FailedLoginAttempts int        `bson:"failed_login_attempts" json:"failed_login_attempts"`
+	LockedUntil         *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"` // 自动解锁时间，管理员锁定时为空
+
+	MFA MFASettings `bson:"mfa" json:"mfa"`
+
+	WebAuthnCredentials []WebAuthnCredential `bson:"webauthn_credentials,omitempty" json:"-"`
+}
+
+// MemberOf 用户是否属于指定租户
+func (u *User) MemberOf(tenantID primitive.ObjectID) bool {
+	for _, membership := range u.Tenants {
+		if membership.TenantID == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// RolesIn 在指定租户中生效的角色分配，包括全局角色；tenantID为零值时只有全局角色生效
+func (u *User) RolesIn(tenantID primitive.ObjectID) []UserRole {
+	return rolesIn(u.Roles, tenantID)
+}
+
+// rolesIn 筛选在指定租户中生效的角色分配
+func rolesIn(assignments []UserRole, tenantID primitive.ObjectID) []UserRole {
+	roles := make([]UserRole, 0, len(assignments))
+	for _, role := range assignments {
+		if role.TenantID == nil || *role.TenantID == tenantID {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// MFASettings 多因素认证设置
+type MFASettings struct {
+	Enabled         bool       `bson:"enabled" json:"enabled"`
+	TOTPSecret      string     `bson:"totp_secret,omitempty" json:"-"`
+	PendingSecret   string     `bson:"pending_secret,omitempty" json:"-"` // 已生成但尚未确认的密钥
+	RecoveryCodes   []string   `bson:"recovery_codes,omitempty" json:"-"` // 恢复码摘要，使用后移除
+	LastUsedCounter int64      `bson:"last_used_counter" json:"-"`        // 最近使用的TOTP时间步，防止验证码重放
+	EnabledAt       *time.Time `bson:"enabled_at,omitempty" json:"enabled_at,omitempty"`
+}
+
+// WebAuthnCredential 通行密钥（WebAuthn凭证）
+type WebAuthnCredential struct {
+	CredentialID    string     `bson:"credential_id" json:"id"` // 凭证ID，base64url编码
+	Name            string     `bson:"name" json:"name"`
+	PublicKey       []byte     `bson:"public_key" json:"-"`
+	AttestationType string     `bson:"attestation_type" json:"attestation_type"`
+	AAGUID          []byte     `bson:"aaguid" json:"-"`
+	SignCount       uint32     `bson:"sign_count" json:"-"` // 签名计数器，用于发现克隆的认证器
+	Transports      []string   `bson:"transports,omitempty" json:"transports,omitempty"`
+	CreatedAt       time.Time  `bson:"created_at" json:"created_at"`
+	LastUsedAt      *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
 }
 
 // UserRole 用户角色
 type UserRole struct {
-	RoleID    primitive.ObjectID `bson:"role_id" json:"role_id"`
-	RoleName  string             `bson:"role_name" json:"role_name"`
-	GrantedBy primitive.ObjectID `bson:"granted_by" json:"granted_by"`
-	GrantedAt time.Time          `bson:"granted_at" json:"granted_at"`
-	ExpiresAt *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	RoleID    primitive.ObjectID  `bson:"role_id" json:"role_id"`
+	RoleName  string              `bson:"role_name" json:"role_name"`
+	GrantedBy primitive.ObjectID  `bson:"granted_by" json:"granted_by"`
+	GrantedAt time.Time           `bson:"granted_at" json:"granted_at"`
+	ExpiresAt *time.Time          `bson:"expires_at,omitempty" json:"expires_at,omitempty"` // 为空时长期有效
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`   // 角色所属租户，全局角色为空
+}
+
+// Expired 角色分配在指定时间是否已过期
+func (r *UserRole) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !r.ExpiresAt.After(now)
+}
+
+// TenantMembership 用户所属的租户
+type TenantMembership struct {
+	TenantID primitive.ObjectID `bson:"tenant_id" json:"tenant_id"`
+	JoinedAt time.Time          `bson:"joined_at" json:"joined_at"`
+}
+
+// Tenant 租户（组织），租户之间的用户、角色、类别和标签互相隔离
+type Tenant struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"` // 唯一标识，登录和切换租户时使用
+	DisplayName string             `bson:"display_name" json:"display_name"`
+	Description string             `bson:"description" json:"description"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 // UserProfile 用户资料
@@ -46,15 +131,81 @@ type LoginHistory struct {
 
 // Role 角色模型
 type Role struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name        string             `bson:"name" json:"name"`
-	DisplayName string             `bson:"display_name" json:"display_name"`
-	Description string             `bson:"description" json:"description"`
-	Level       int                `bson:"level" json:"level"`
-	Status      string             `bson:"status" json:"status"`
-	Permissions []RolePermission   `bson:"permissions" json:"permissions"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name        string               `bson:"name" json:"name"`
+	DisplayName string               `bson:"display_name" json:"display_name"`
+	Description string               `bson:"description" json:"description"`
+	TenantID    *primitive.ObjectID  `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`   // 所属租户，为空时为全局角色，在所有租户中生效
+	Level       int                  `bson:"level" json:"level"`                               // 级别越高权限越大，只能分配不高于自己级别的角色
+	ParentIDs   []primitive.ObjectID `bson:"parent_ids,omitempty" json:"parent_ids,omitempty"` // 父角色，本角色继承其全部权限，父角色级别不能高于本角色
+	Status      string               `bson:"status" json:"status"`
+	RequireMFA  bool                 `bson:"require_mfa" json:"require_mfa"`                       // 仅在完成多因素认证的会话中生效
+	MaxSessions int                  `bson:"max_sessions,omitempty" json:"max_sessions,omitempty"` // 拥有该角色的用户的最大并发会话数，多个角色取最大值
+	Elevation   *RoleElevation       `bson:"elevation,omitempty" json:"elevation,omitempty"`       // 临时提权设置，为空时不能申请
+	Permissions []RolePermission     `bson:"permissions" json:"permissions"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// RoleElevation 角色的临时提权设置
+type RoleElevation struct {
+	MaxDuration   int64                `bson:"max_duration" json:"max_duration"`                         // 单次提权的最长时间（秒）
+	ApproverRoles []primitive.ObjectID `bson:"approver_roles,omitempty" json:"approver_roles,omitempty"` // 审批人须直接拥有其中之一，为空时不限制
+}
+
+// ElevationRequest 临时提权申请，审批通过后以限时角色分配的形式授予
+type ElevationRequest struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID         primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Username       string              `bson:"username" json:"username"`
+	RoleID         primitive.ObjectID  `bson:"role_id" json:"role_id"`
+	RoleName       string              `bson:"role_name" json:"role_name"`
+	TenantID       *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"` // 角色所属租户
+	Duration       int64               `bson:"duration" json:"duration"`                       // 申请的提权时长（秒），自审批通过时开始计算
+	Justification  string              `bson:"justification" json:"justification"`
+	Status         string              `bson:"status" json:"status"` // pending, approved, denied, cancelled, expired
+	ApproverID     *primitive.ObjectID `bson:"approver_id,omitempty" json:"approver_id,omitempty"`
+	ApproverName   string              `bson:"approver_name,omitempty" json:"approver_name,omitempty"`
+	Comment        string              `bson:"comment,omitempty" json:"comment,omitempty"` // 审批意见
+	DecidedAt      *time.Time          `bson:"decided_at,omitempty" json:"decided_at,omitempty"`
+	GrantExpiresAt *time.Time          `bson:"grant_expires_at,omitempty" json:"grant_expires_at,omitempty"` // 授予的角色分配的过期时间
+	ExpiresAt      time.Time           `bson:"expires_at" json:"expires_at"`                                 // 超过该时间未审批的申请失效
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// SoDRule 职责分离规则，同一规则中的角色互斥
+// static规则禁止同一用户同时被分配其中两个及以上的角色，dynamic规则允许分配但同一会话中只能激活其中一个
+type SoDRule struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name        string               `bson:"name" json:"name"`
+	Description string               `bson:"description" json:"description"`
+	Type        string               `bson:"type" json:"type"` // static, dynamic
+	RoleIDs     []primitive.ObjectID `bson:"role_ids" json:"role_ids"`
+	TenantID    *primitive.ObjectID  `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	CreatedBy   primitive.ObjectID   `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+}
+
+// Group 用户组，成员获得分配给组的角色
+// 手动组的成员由管理员维护，动态组按用户资料的部门和职位自动匹配，为空的条件不限
+type Group struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name        string               `bson:"name" json:"name"`
+	Description string               `bson:"description" json:"description"`
+	Type        string               `bson:"type" json:"type"`                                 // manual, dynamic
+	Members     []primitive.ObjectID `bson:"members,omitempty" json:"members,omitempty"`       // 手动组的成员
+	Department  string               `bson:"department,omitempty" json:"department,omitempty"` // 动态组匹配的部门
+	Position    string               `bson:"position,omitempty" json:"position,omitempty"`     // 动态组匹配的职位
+	Roles       []UserRole           `bson:"roles" json:"roles"`                               // 分配给组的角色
+	TenantID    *primitive.ObjectID  `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`   // 所属租户，为空时在所有租户中生效
+	CreatedBy   primitive.ObjectID   `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// RolesIn 分配给组的角色中在指定租户生效的，规则同User.RolesIn
+func (g *Group) RolesIn(tenantID primitive.ObjectID) []UserRole {
+	return rolesIn(g.Roles, tenantID)
 }
 
 // RolePermission 角色权限
@@ -80,6 +231,7 @@ type Permission struct {
 type Category struct {
 	ID            primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
 	Name          string               `bson:"name" json:"name"`
+	TenantID      *primitive.ObjectID  `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
 	ParentID      *primitive.ObjectID  `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
 	Path          string               `bson:"path" json:"path"`
 	Level         int                  `bson:"level" json:"level"`
@@ -94,28 +246,114 @@ type Category struct {
 
 // Tag 标签模型
 type Tag struct {
-	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name          string             `bson:"name" json:"name"`
-	Color         string             `bson:"color" json:"color"`
-	Description   string             `bson:"description" json:"description"`
-	UsageCount    int64              `bson:"usage_count" json:"usage_count"`
-	CreatedBy     primitive.ObjectID `bson:"created_by" json:"created_by"`
-	CreatedByName string             `bson:"created_by_name" json:"created_by_name"`
-	RelatedTags   []string           `bson:"related_tags" json:"related_tags"`
-	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
-	LastUsedAt    time.Time          `bson:"last_used_at" json:"last_used_at"`
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name          string              `bson:"name" json:"name"`
+	TenantID      *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Color         string              `bson:"color" json:"color"`
+	Description   string              `bson:"description" json:"description"`
+	UsageCount    int64               `bson:"usage_count" json:"usage_count"`
+	CreatedBy     primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedByName string              `bson:"created_by_name" json:"created_by_name"`
+	RelatedTags   []string            `bson:"related_tags" json:"related_tags"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+	LastUsedAt    time.Time           `bson:"last_used_at" json:"last_used_at"`
 }
 
 // Session 会话模型
 type Session struct {
-	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	SessionID      string             `bson:"session_id" json:"session_id"`
-	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
-	DeviceInfo     DeviceInfo         `bson:"device_info" json:"device_info"`
-	ExpiresAt      time.Time          `bson:"expires_at" json:"expires_at"`
-	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
-	LastAccessedAt time.Time          `bson:"last_accessed_at" json:"last_accessed_at"`
-	IsRevoked      bool               `bson:"is_revoked" json:"is_revoked"`
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	SessionID      string              `bson:"session_id" json:"session_id"`
+	FamilyID       string              `bson:"family_id" json:"family_id"`                     // 刷新令牌族，同一次登录轮换产生的会话共享
+	ParentID       string              `bson:"parent_id,omitempty" json:"parent_id,omitempty"` // 轮换前的会话
+	UserID         primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	ClientID       string              `bson:"client_id,omitempty" json:"client_id,omitempty"` // OAuth客户端ID，第一方登录为空
+	TenantID       *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"` // 会话的当前租户，轮换时沿用
+	Scope          string              `bson:"scope,omitempty" json:"scope,omitempty"`
+	DeviceInfo     DeviceInfo          `bson:"device_info" json:"device_info"`
+	ExpiresAt      time.Time           `bson:"expires_at" json:"expires_at"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+	LastAccessedAt time.Time           `bson:"last_accessed_at" json:"last_accessed_at"`
+	IsRevoked      bool                `bson:"is_revoked" json:"is_revoked"`
+	LoginAt        time.Time           `bson:"login_at" json:"login_at"`                             // 登录时间，轮换时沿用
+	AuthMethods    []string            `bson:"auth_methods,omitempty" json:"auth_methods,omitempty"` // 登录时完成的认证方式，轮换时沿用
+	RotatedAt      *time.Time          `bson:"rotated_at,omitempty" json:"rotated_at,omitempty"`     // 刷新令牌被轮换的时间
+	ActiveRoles    []string            `bson:"active_roles,omitempty" json:"active_roles,omitempty"` // 受动态职责分离规则约束时会话激活的角色
+}
+
+// RevokedToken 访问令牌吊销记录
+type RevokedToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type      string             `bson:"type" json:"type"`   // token, session, user
+	Value     string             `bson:"value" json:"value"` // 对应的JTI、会话ID或用户ID
+	Reason    string             `bson:"reason" json:"reason"`
+	RevokedAt time.Time          `bson:"revoked_at" json:"revoked_at"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"` // 受影响的访问令牌均已过期后自动清理
+}
+
+// LoginAttempt 不存在用户的登录失败记录，按登录标识统计
+type LoginAttempt struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Identifier   string             `bson:"identifier" json:"identifier"` // 用户名、邮箱或手机号
+	FailedCount  int                `bson:"failed_count" json:"failed_count"`
+	LastFailedAt time.Time          `bson:"last_failed_at" json:"last_failed_at"`
+	LockedUntil  *time.Time         `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	ExpiresAt    time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// PersonalAccessToken 个人访问令牌，供脚本和CI使用，只保存令牌摘要
+type PersonalAccessToken struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Name        string              `bson:"name" json:"name"`
+	TokenHash   string              `bson:"token_hash" json:"-"`
+	TokenPrefix string              `bson:"token_prefix" json:"token_prefix"`                     // 令牌开头几位，便于用户辨认
+	Permissions []string            `bson:"permissions" json:"permissions"`                       // resource:action，使用时再与用户当前权限取交集
+	AuthMethods []string            `bson:"auth_methods,omitempty" json:"auth_methods,omitempty"` // 创建令牌的会话完成的认证方式
+	TenantID    *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`       // 创建令牌时的租户，令牌只在该租户中生效
+	ExpiresAt   time.Time           `bson:"expires_at" json:"expires_at"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	LastUsedAt  *time.Time          `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	LastUsedIP  string              `bson:"last_used_ip,omitempty" json:"last_used_ip,omitempty"`
+	RevokedAt   *time.Time          `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// WebAuthnSession 进行中的WebAuthn注册或登录仪式，只能使用一次
+type WebAuthnSession struct {
+	ID               primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	SessionID        string              `bson:"session_id" json:"session_id"`
+	Purpose          string              `bson:"purpose" json:"purpose"`                     // registration, login
+	UserID           *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"` // 可发现凭证登录时为空
+	Challenge        string              `bson:"challenge" json:"-"`
+	UserVerification string              `bson:"user_verification" json:"user_verification"`
+	ExpiresAt        time.Time           `bson:"expires_at" json:"expires_at"`
+	CreatedAt        time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// VerificationCode 短信验证码，每个接收方和用途保留一条记录，重新发送时覆盖
+type VerificationCode struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Target          string             `bson:"target" json:"target"`   // 接收验证码的手机号
+	Purpose         string             `bson:"purpose" json:"purpose"` // login, register
+	CodeHash        string             `bson:"code_hash" json:"-"`
+	Attempts        int                `bson:"attempts" json:"attempts"` // 校验失败次数
+	Used            bool               `bson:"used" json:"used"`
+	ExpiresAt       time.Time          `bson:"expires_at" json:"expires_at"`
+	SentAt          time.Time          `bson:"sent_at" json:"sent_at"`
+	SendCount       int                `bson:"send_count" json:"send_count"`               // 当前限流窗口内的发送次数
+	WindowStartedAt time.Time          `bson:"window_started_at" json:"window_started_at"` // 限流窗口开始时间
+	PurgeAt         time.Time          `bson:"purge_at" json:"-"`                          // 验证码和限流窗口均失效后自动清理
+}
+
+// VerificationToken 邮件中发送的一次性令牌，用于重置密码和验证邮箱
+type VerificationToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Purpose   string             `bson:"purpose" json:"purpose"`                 // password_reset, email_verification
+	Email     string             `bson:"email,omitempty" json:"email,omitempty"` // 发送时的邮箱，邮箱变更后旧令牌失效
+	Used      bool               `bson:"used" json:"used"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 }
 
 // DeviceInfo 设备信息
@@ -125,6 +363,53 @@ type DeviceInfo struct {
 	DeviceType string `bson:"device_type" json:"device_type"` // web, mobile, api
 }
 
+// OAuthClient OAuth2/OIDC客户端模型
+type OAuthClient struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ClientID         string             `bson:"client_id" json:"client_id"`
+	ClientSecretHash string             `bson:"client_secret_hash,omitempty" json:"-"`
+	Name             string             `bson:"name" json:"name"`
+	Public           bool               `bson:"public" json:"public"` // 公开客户端（SPA、移动端）无密钥，必须使用PKCE
+	RedirectURIs     []string           `bson:"redirect_uris" json:"redirect_uris"`
+	GrantTypes       []string           `bson:"grant_types" json:"grant_types"`
+	Scopes           []string           `bson:"scopes" json:"scopes"`
+	AuthMethod       string             `bson:"token_endpoint_auth_method,omitempty" json:"token_endpoint_auth_method,omitempty"` // client_secret_basic, private_key_jwt, none，为空时按Public推断
+	PublicKey        string             `bson:"public_key,omitempty" json:"public_key,omitempty"`                                 // private_key_jwt认证使用的PEM公钥
+	Roles            []UserRole         `bson:"roles,omitempty" json:"roles,omitempty"`                                           // 客户端凭证模式下服务账号拥有的角色
+	Status           string             `bson:"status" json:"status"`                                                             // active, disabled
+	CreatedBy        primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ClientAssertion 已使用的客户端断言，用于防止private_key_jwt断言重放
+type ClientAssertion struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ClientID  string             `bson:"client_id" json:"client_id"`
+	JTI       string             `bson:"jti" json:"jti"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// AuthorizationCode OAuth2授权码模型
+type AuthorizationCode struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CodeHash            string             `bson:"code_hash" json:"-"`
+	ClientID            string             `bson:"client_id" json:"client_id"`
+	UserID              primitive.ObjectID `bson:"user_id" json:"user_id"`
+	RedirectURI         string             `bson:"redirect_uri" json:"redirect_uri"`
+	RedirectURIOmitted  bool               `bson:"redirect_uri_omitted,omitempty" json:"-"` // 授权请求未携带redirect_uri，令牌请求可省略
+	Scope               string             `bson:"scope" json:"scope"`
+	Nonce               string             `bson:"nonce,omitempty" json:"nonce,omitempty"`
+	CodeChallenge       string             `bson:"code_challenge,omitempty" json:"-"`
+	CodeChallengeMethod string             `bson:"code_challenge_method,omitempty" json:"code_challenge_method,omitempty"`
+	AuthTime            time.Time          `bson:"auth_time" json:"auth_time"`
+	AuthMethods         []string           `bson:"auth_methods,omitempty" json:"auth_methods,omitempty"` // 用户登录时完成的认证方式
+	SessionID           string             `bson:"session_id,omitempty" json:"session_id,omitempty"`     // 兑换后生成的会话，授权码被重放时据此吊销
+	Used                bool               `bson:"used" json:"used"`
+	ExpiresAt           time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
+}
+
 // AISession AI助手会话模型
 type AISession struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -0,0 +1,320 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"authcenter/internal/middleware"
+	"authcenter/internal/oauth/service"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthHandler OAuth2/OpenID Connect处理器
+// 协议端点按RFC 6749/OIDC规范直接输出JSON，管理端点使用统一响应格式
+type OAuthHandler struct {
+	oauthService service.OAuthService
+	loginURL     string
+}
+
+// NewOAuthHandler 创建OAuth2/OpenID Connect处理器
+func NewOAuthHandler(oauthService service.OAuthService, loginURL string) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+		loginURL:     loginURL,
+	}
+}
+
+// Discovery 获取OpenID Provider配置
+func (h *OAuthHandler) Discovery(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.oauthService.Discovery())
+}
+
+// Authorize 授权端点，已登录用户直接重定向到回调地址，否则跳转登录页面
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req service.AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	client, err := h.oauthService.ValidateAuthorizeRequest(c, &req)
+	if err != nil {
+		if client == nil {
+			response.Error(c, http.StatusBadRequest, "授权请求无效", err.Error())
+			return
+		}
+		c.Redirect(http.StatusFound, errorRedirectURL(&req, err))
+		return
+	}
+
+	userID := sessionUserID(c)
+	if userID == "" {
+		// 登录完成后由登录页面跳转回当前授权地址
+		c.Redirect(http.StatusFound, service.BuildRedirectURL(h.loginURL, map[string]string{
+			"return_to": c.Request.URL.RequestURI(),
+		}))
+		return
+	}
+
+	authTime, authMethods := authContext(c)
+	redirectURL, err := h.oauthService.Authorize(c, &req, userID, authTime, authMethods)
+	if err != nil {
+		c.Redirect(http.StatusFound, errorRedirectURL(&req, err))
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+// AuthorizeConsent 供登录页面提交授权，返回回调地址由前端跳转
+func (h *OAuthHandler) AuthorizeConsent(c *gin.Context) {
+	var req service.AuthorizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	client, err := h.oauthService.ValidateAuthorizeRequest(c, &req)
+	if err != nil {
+		if client == nil {
+			response.Error(c, http.StatusBadRequest, "授权请求无效", err.Error())
+			return
+		}
+		response.Success(c, gin.H{"redirect_to": errorRedirectURL(&req, err)})
+		return
+	}
+
+	authTime, authMethods := authContext(c)
+	redirectURL, err := h.oauthService.Authorize(c, &req, c.GetString("user_id"), authTime, authMethods)
+	if err != nil {
+		response.Success(c, gin.H{"redirect_to": errorRedirectURL(&req, err)})
+		return
+	}
+
+	response.Success(c, gin.H{"redirect_to": redirectURL})
+}
+
+// Token 令牌端点
+func (h *OAuthHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req service.TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		writeOAuthError(c, &service.Error{Code: service.ErrInvalidRequest, Description: err.Error()})
+		return
+	}
+	bindClientCredentials(c, &req.ClientID, &req.ClientSecret)
+
+	resp, err := h.oauthService.Token(c, &req)
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Introspect 令牌内省端点（RFC 7662）
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req service.IntrospectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		writeOAuthError(c, &service.Error{Code: service.ErrInvalidRequest, Description: err.Error()})
+		return
+	}
+	bindClientCredentials(c, &req.ClientID, &req.ClientSecret)
+
+	resp, err := h.oauthService.Introspect(c, &req)
+	if err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Revoke 令牌吊销端点（RFC 7009），成功时返回空响应体
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req service.RevokeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		writeOAuthError(c, &service.Error{Code: service.ErrInvalidRequest, Description: err.Error()})
+		return
+	}
+	bindClientCredentials(c, &req.ClientID, &req.ClientSecret)
+
+	if err := h.oauthService.Revoke(c, &req); err != nil {
+		writeOAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// UserInfo 用户信息端点
+func (h *OAuthHandler) UserInfo(c *gin.Context) {
+	info, err := h.oauthService.UserInfo(c, c.GetString("user_id"), c.GetString("scope"), middleware.TenantID(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, service.Error{Code: "invalid_token", Description: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// CreateClient 注册客户端
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	var req service.CreateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	credentials, err := h.oauthService.CreateClient(c, &req, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "注册客户端失败", err.Error())
+		return
+	}
+
+	response.Success(c, credentials)
+}
+
+// ListClients 获取客户端列表
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	clients, total, err := h.oauthService.ListClients(c, page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取客户端列表失败", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"items": clients, "total": total})
+}
+
+// DeleteClient 删除客户端
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	if err := h.oauthService.DeleteClient(c, c.Param("client_id")); err != nil {
+		response.Error(c, http.StatusNotFound, "删除客户端失败", err.Error())
+		return
+	}
+
+	response.Success(c, "删除成功")
+}
+
+// AssignClientRoleRequest 为服务账号分配角色请求
+type AssignClientRoleRequest struct {
+	RoleID string `json:"role_id" binding:"required"`
+}
+
+// AssignClientRole 为服务账号分配角色
+func (h *OAuthHandler) AssignClientRole(c *gin.Context) {
+	var req AssignClientRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.oauthService.AssignClientRole(c, c.Param("client_id"), req.RoleID, c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "分配角色失败", err.Error())
+		return
+	}
+
+	response.Success(c, "分配成功")
+}
+
+// RemoveClientRole 移除服务账号的角色
+func (h *OAuthHandler) RemoveClientRole(c *gin.Context) {
+	if err := h.oauthService.RemoveClientRole(c, c.Param("client_id"), c.Param("role_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "移除角色失败", err.Error())
+		return
+	}
+
+	response.Success(c, "移除成功")
+}
+
+// bindClientCredentials 优先使用HTTP Basic认证中的客户端凭证（RFC 6749 2.3.1）
+func bindClientCredentials(c *gin.Context, clientID, clientSecret *string) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return
+	}
+
+	if id, err := url.QueryUnescape(username); err == nil {
+		*clientID = id
+	}
+	if secret, err := url.QueryUnescape(password); err == nil {
+		*clientSecret = secret
+	}
+}
+
+// writeOAuthError 输出OAuth2错误响应
+func writeOAuthError(c *gin.Context, err error) {
+	var oauthErr *service.Error
+	if !errors.As(err, &oauthErr) {
+		oauthErr = &service.Error{Code: service.ErrServerError, Description: err.Error()}
+	}
+
+	if oauthErr.Code == service.ErrInvalidClient {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	c.JSON(oauthErr.StatusCode(), oauthErr)
+}
+
+// errorRedirectURL 构建携带错误信息的回调地址
+func errorRedirectURL(req *service.AuthorizeRequest, err error) string {
+	params := map[string]string{"state": req.State}
+
+	var oauthErr *service.Error
+	if errors.As(err, &oauthErr) {
+		params["error"] = oauthErr.Code
+		params["error_description"] = oauthErr.Description
+	} else {
+		params["error"] = service.ErrServerError
+	}
+
+	return service.BuildRedirectURL(req.RedirectURI, params)
+}
+
+// sessionUserID 第一方登录会话的用户ID，未登录或持有个人访问令牌、服务账号令牌、第三方应用令牌时为空
+func sessionUserID(c *gin.Context) string {
+	value, exists := c.Get("claims")
+	if !exists {
+		return ""
+	}
+	claims, ok := value.(*jwt.Claims)
+	if !ok || !middleware.FirstPartySession(claims) {
+		return ""
+	}
+	return claims.UserID
+}
+
+// authContext 以会话的登录时间作为用户认证时间，并沿用令牌中的认证方式
+// 刷新令牌不会重新认证用户，不能使用访问令牌的签发时间；未携带登录时间的历史令牌以签发时间代替
+func authContext(c *gin.Context) (time.Time, []string) {
+	if value, exists := c.Get("claims"); exists {
+		if claims, ok := value.(*jwt.Claims); ok {
+			if claims.AuthTime > 0 {
+				return time.Unix(claims.AuthTime, 0), claims.AuthMethods
+			}
+			if claims.IssuedAt != nil {
+				return claims.IssuedAt.Time, claims.AuthMethods
+			}
+		}
+	}
+	return time.Now(), nil
+}
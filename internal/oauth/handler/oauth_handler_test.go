@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"authcenter/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuthContext(t *testing.T) {
+	loginAt := time.Unix(1700000000, 0)
+	issuedAt := loginAt.Add(2 * time.Hour)
+
+	tests := []struct {
+		name   string
+		claims *jwt.Claims
+		want   time.Time
+	}{
+		{"使用登录时间", &jwt.Claims{AuthTime: loginAt.Unix(), RegisteredClaims: gojwt.RegisteredClaims{IssuedAt: gojwt.NewNumericDate(issuedAt)}}, loginAt},
+		{"历史令牌使用签发时间", &jwt.Claims{RegisteredClaims: gojwt.RegisteredClaims{IssuedAt: gojwt.NewNumericDate(issuedAt)}}, issuedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Set("claims", tt.claims)
+
+			got, _ := authContext(c)
+			if !got.Equal(tt.want) {
+				t.Fatalf("authContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
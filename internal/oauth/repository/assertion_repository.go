@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrAssertionReplayed 客户端断言已被使用
+var ErrAssertionReplayed = errors.New("client assertion already used")
+
+// ClientAssertionRepository 客户端断言数据访问接口
+type ClientAssertionRepository interface {
+	// Use 记录断言已被使用，同一客户端的同一jti再次使用时返回ErrAssertionReplayed
+	Use(ctx context.Context, assertion *models.ClientAssertion) error
+}
+
+// clientAssertionRepository 客户端断言仓储实现
+type clientAssertionRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewClientAssertionRepository 创建客户端断言仓储
+func NewClientAssertionRepository(db *mongo.Database) ClientAssertionRepository {
+	return &clientAssertionRepository{
+		db:         db,
+		collection: db.Collection("oauth_client_assertions"),
+	}
+}
+
+// Use 记录断言已被使用，依赖client_id和jti上的唯一索引检测重放
+func (r *clientAssertionRepository) Use(ctx context.Context, assertion *models.ClientAssertion) error {
+	_, err := r.collection.InsertOne(ctx, assertion)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAssertionReplayed
+	}
+	return err
+}
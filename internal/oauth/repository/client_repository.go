@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ClientRepository OAuth客户端数据访问接口
+type ClientRepository interface {
+	// Create 创建客户端
+	Create(ctx context.Context, client *models.OAuthClient) error
+
+	// GetByClientID 通过客户端ID获取客户端
+	GetByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error)
+
+	// List 获取客户端列表
+	List(ctx context.Context, page, pageSize int) ([]*models.OAuthClient, int64, error)
+
+	// Update 更新客户端
+	Update(ctx context.Context, client *models.OAuthClient) error
+
+	// Delete 删除客户端
+	Delete(ctx context.Context, clientID string) error
+
+	// AssignRole 为服务账号分配角色，已拥有该角色时不重复添加
+	AssignRole(ctx context.Context, clientID string, role models.UserRole) error
+
+	// RemoveRole 移除服务账号的角色
+	RemoveRole(ctx context.Context, clientID string, roleID primitive.ObjectID) error
+}
+
+// clientRepository OAuth客户端仓储实现
+type clientRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewClientRepository 创建OAuth客户端仓储
+func NewClientRepository(db *mongo.Database) ClientRepository {
+	return &clientRepository{
+		db:         db,
+		collection: db.Collection("oauth_clients"),
+	}
+}
+
+// Create 创建客户端
+func (r *clientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	now := time.Now()
+	client.CreatedAt = now
+	client.UpdatedAt = now
+
+	// 默认状态为激活
+	if client.Status == "" {
+		client.Status = "active"
+	}
+
+	result, err := r.collection.InsertOne(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	client.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetByClientID 通过客户端ID获取客户端
+func (r *clientRepository) GetByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.collection.FindOne(ctx, bson.M{"client_id": clientID}).Decode(&client)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("client not found")
+		}
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// List 获取客户端列表
+func (r *clientRepository) List(ctx context.Context, page, pageSize int) ([]*models.OAuthClient, int64, error) {
+	skip := (page - 1) * pageSize
+
+	findOptions := options.Find()
+	findOptions.SetLimit(int64(pageSize))
+	findOptions.SetSkip(int64(skip))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var clients []*models.OAuthClient
+	if err = cursor.All(ctx, &clients); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return clients, total, nil
+}
+
+// Update 更新客户端
+func (r *clientRepository) Update(ctx context.Context, client *models.OAuthClient) error {
+	client.UpdatedAt = time.Now()
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"client_id": client.ClientID},
+		bson.M{"$set": client},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("client not found")
+	}
+
+	return nil
+}
+
+// Delete 删除客户端
+func (r *clientRepository) Delete(ctx context.Context, clientID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"client_id": clientID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("client not found")
+	}
+
+	return nil
+}
+
+// AssignRole 为服务账号分配角色
+func (r *clientRepository) AssignRole(ctx context.Context, clientID string, role models.UserRole) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"client_id": clientID, "roles.role_id": bson.M{"$ne": role.RoleID}},
+		bson.M{
+			"$push": bson.M{"roles": role},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := r.GetByClientID(ctx, clientID); err != nil {
+			return err
+		}
+		return errors.New("role already assigned")
+	}
+
+	return nil
+}
+
+// RemoveRole 移除服务账号的角色
+func (r *clientRepository) RemoveRole(ctx context.Context, clientID string, roleID primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"client_id": clientID, "roles.role_id": roleID},
+		bson.M{
+			"$pull": bson.M{"roles": bson.M{"role_id": roleID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("client role not found")
+	}
+
+	return nil
+}
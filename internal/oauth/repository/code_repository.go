@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrCodeAlreadyUsed 授权码已被使用
+var ErrCodeAlreadyUsed = errors.New("authorization code already used")
+
+// AuthorizationCodeRepository 授权码数据访问接口
+type AuthorizationCodeRepository interface {
+	// Create 保存授权码
+	Create(ctx context.Context, code *models.AuthorizationCode) error
+
+	// GetByHash 获取授权码，包括已兑换的授权码
+	GetByHash(ctx context.Context, codeHash string) (*models.AuthorizationCode, error)
+
+	// Consume 兑换授权码，同一授权码只能成功兑换一次
+	// 已被兑换时返回ErrCodeAlreadyUsed以及原授权码记录
+	Consume(ctx context.Context, codeHash string) (*models.AuthorizationCode, error)
+
+	// SetSessionID 记录授权码兑换后生成的会话
+	SetSessionID(ctx context.Context, codeHash, sessionID string) error
+}
+
+// authorizationCodeRepository 授权码仓储实现
+type authorizationCodeRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewAuthorizationCodeRepository 创建授权码仓储
+func NewAuthorizationCodeRepository(db *mongo.Database) AuthorizationCodeRepository {
+	return &authorizationCodeRepository{
+		db:         db,
+		collection: db.Collection("oauth_authorization_codes"),
+	}
+}
+
+// Create 保存授权码
+func (r *authorizationCodeRepository) Create(ctx context.Context, code *models.AuthorizationCode) error {
+	code.CreatedAt = time.Now()
+	code.Used = false
+
+	_, err := r.collection.InsertOne(ctx, code)
+	return err
+}
+
+// GetByHash 获取授权码
+func (r *authorizationCodeRepository) GetByHash(ctx context.Context, codeHash string) (*models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+	err := r.collection.FindOne(ctx, bson.M{"code_hash": codeHash}).Decode(&code)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("authorization code not found")
+		}
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// Consume 兑换授权码
+func (r *authorizationCodeRepository) Consume(ctx context.Context, codeHash string) (*models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"code_hash": codeHash, "used": false},
+		bson.M{"$set": bson.M{"used": true}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&code)
+	if err == nil {
+		return &code, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	// 区分授权码不存在和授权码被重放
+	err = r.collection.FindOne(ctx, bson.M{"code_hash": codeHash}).Decode(&code)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("authorization code not found")
+		}
+		return nil, err
+	}
+
+	return &code, ErrCodeAlreadyUsed
+}
+
+// SetSessionID 记录授权码兑换后生成的会话
+func (r *authorizationCodeRepository) SetSessionID(ctx context.Context, codeHash, sessionID string) error {
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"code_hash": codeHash},
+		bson.M{"$set": bson.M{"session_id": sessionID}},
+	)
+	return err
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+	"authcenter/internal/oauth/repository"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/utils"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// 令牌端点客户端认证方式
+const (
+	AuthMethodClientSecretBasic = "client_secret_basic"
+	AuthMethodClientSecretPost  = "client_secret_post"
+	AuthMethodPrivateKeyJWT     = "private_key_jwt" // RFC 7523，客户端用私钥签名的JWT断言认证
+	AuthMethodNone              = "none"
+)
+
+// ClientAssertionTypeJWTBearer private_key_jwt认证的断言类型
+const ClientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// maxClientAssertionLifetime 客户端断言的最长有效期，已使用的断言保存到过期为止以防重放
+const maxClientAssertionLifetime = 5 * time.Minute
+
+// clientAssertionLeeway 校验断言时间时允许的时钟偏差
+const clientAssertionLeeway = 30 * time.Second
+
+// clientAssertionSigningMethods 客户端断言允许的签名算法，不接受共享密钥算法
+var clientAssertionSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// ClientAuthentication 客户端认证参数（RFC 6749 2.3、RFC 7523）
+type ClientAuthentication struct {
+	ClientID            string `form:"client_id"`
+	ClientSecret        string `form:"client_secret"`
+	ClientAssertionType string `form:"client_assertion_type"`
+	ClientAssertion     string `form:"client_assertion"`
+}
+
+// AuthenticateClient 认证客户端，公开客户端只校验client_id
+func (s *oauthService) AuthenticateClient(ctx context.Context, auth *ClientAuthentication) (*models.OAuthClient, error) {
+	if auth.ClientAssertionType != "" || auth.ClientAssertion != "" {
+		return s.authenticateClientAssertion(ctx, auth)
+	}
+
+	if auth.ClientID == "" {
+		return nil, newError(ErrInvalidClient, "缺少客户端凭证")
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, auth.ClientID)
+	if err != nil || client.Status != "active" {
+		return nil, newError(ErrInvalidClient, "客户端认证失败")
+	}
+
+	switch clientAuthMethod(client) {
+	case AuthMethodNone:
+		return client, nil
+	case AuthMethodPrivateKeyJWT:
+		return nil, newError(ErrInvalidClient, "客户端必须使用private_key_jwt认证")
+	}
+
+	if auth.ClientSecret == "" || !utils.CheckToken(auth.ClientSecret, client.ClientSecretHash) {
+		return nil, newError(ErrInvalidClient, "客户端认证失败")
+	}
+
+	return client, nil
+}
+
+// authenticateClientAssertion 校验客户端断言（RFC 7523 3）
+// iss和sub必须为client_id，aud为令牌端点或签发者地址，jti只能使用一次
+func (s *oauthService) authenticateClientAssertion(ctx context.Context, auth *ClientAuthentication) (*models.OAuthClient, error) {
+	if auth.ClientAssertionType != ClientAssertionTypeJWTBearer {
+		return nil, newError(ErrInvalidClient, "不支持的client_assertion_type")
+	}
+	if auth.ClientAssertion == "" {
+		return nil, newError(ErrInvalidClient, "缺少client_assertion")
+	}
+
+	// 先不验签读取iss，确定客户端后再用其公钥验签
+	unverified := &gojwt.RegisteredClaims{}
+	if _, _, err := gojwt.NewParser().ParseUnverified(auth.ClientAssertion, unverified); err != nil {
+		return nil, newError(ErrInvalidClient, "无效的客户端断言")
+	}
+	clientID := unverified.Issuer
+	if clientID == "" || (auth.ClientID != "" && auth.ClientID != clientID) {
+		return nil, newError(ErrInvalidClient, "客户端断言与client_id不匹配")
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil || client.Status != "active" || clientAuthMethod(client) != AuthMethodPrivateKeyJWT {
+		return nil, newError(ErrInvalidClient, "客户端认证失败")
+	}
+
+	publicKey, err := parsePublicKey(client.PublicKey)
+	if err != nil {
+		logger.Error("解析客户端公钥失败: %s, %v", clientID, err)
+		return nil, newError(ErrInvalidClient, "客户端认证失败")
+	}
+
+	claims := &gojwt.RegisteredClaims{}
+	_, err = gojwt.ParseWithClaims(auth.ClientAssertion, claims, func(*gojwt.Token) (interface{}, error) {
+		return publicKey, nil
+	},
+		gojwt.WithValidMethods(clientAssertionSigningMethods),
+		gojwt.WithIssuer(clientID),
+		gojwt.WithSubject(clientID),
+		gojwt.WithLeeway(clientAssertionLeeway),
+	)
+	if err != nil {
+		return nil, newError(ErrInvalidClient, "客户端断言校验失败")
+	}
+
+	if !s.assertionAudienceValid(claims.Audience) {
+		return nil, newError(ErrInvalidClient, "客户端断言的aud无效")
+	}
+	if claims.ExpiresAt == nil || claims.ExpiresAt.After(time.Now().Add(maxClientAssertionLifetime+clientAssertionLeeway)) {
+		return nil, newError(ErrInvalidClient, "客户端断言缺少exp或有效期过长")
+	}
+	if claims.ID == "" {
+		return nil, newError(ErrInvalidClient, "客户端断言缺少jti")
+	}
+
+	err = s.assertionRepo.Use(ctx, &models.ClientAssertion{
+		ClientID:  clientID,
+		JTI:       claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time.Add(clientAssertionLeeway),
+	})
+	if err == repository.ErrAssertionReplayed {
+		logger.SecurityEvent("client_assertion_replayed", map[string]interface{}{
+			"client_id": clientID,
+			"jti":       claims.ID,
+		})
+		return nil, newError(ErrInvalidClient, "客户端断言已被使用")
+	}
+	if err != nil {
+		return nil, newError(ErrServerError, "记录客户端断言失败")
+	}
+
+	return client, nil
+}
+
+// assertionAudienceValid 断言的aud须包含令牌端点或签发者地址
+func (s *oauthService) assertionAudienceValid(audience []string) bool {
+	issuer := s.issuer()
+	return containsString(audience, issuer+"/oauth/token") || containsString(audience, issuer)
+}
+
+// clientAuthMethod 客户端的认证方式，兼容未记录认证方式的历史客户端
+func clientAuthMethod(client *models.OAuthClient) string {
+	if client.AuthMethod != "" {
+		return client.AuthMethod
+	}
+	if client.Public {
+		return AuthMethodNone
+	}
+	return AuthMethodClientSecretBasic
+}
+
+// parsePublicKey 解析PEM格式的RSA、ECDSA或Ed25519公钥
+func parsePublicKey(pemData string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, errors.New("公钥不是PEM格式")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, errors.New("不支持的公钥类型")
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"authcenter/internal/models"
+	roleService "authcenter/internal/role/service"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateClientRequest 注册客户端请求
+type CreateClientRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris,omitempty"` // 授权码模式必填
+	Public       bool     `json:"public"`
+	GrantTypes   []string `json:"grant_types,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	AuthMethod   string   `json:"token_endpoint_auth_method,omitempty"` // client_secret_basic（默认）或private_key_jwt
+	PublicKey    string   `json:"public_key,omitempty"`                 // private_key_jwt使用的PEM公钥
+}
+
+// ClientCredentials 客户端凭证，密钥仅在注册时返回一次
+type ClientCredentials struct {
+	ClientID     string              `json:"client_id"`
+	ClientSecret string              `json:"client_secret,omitempty"`
+	Client       *models.OAuthClient `json:"client"`
+}
+
+// supportedGrantTypes 客户端可申请的授权类型
+var supportedGrantTypes = []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials}
+
+// supportedScopes 客户端可申请的授权范围
+var supportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone, ScopeOfflineAccess}
+
+// CreateClient 注册客户端
+// 只使用客户端凭证模式的机密客户端即服务账号，无需回调地址，通过角色授权
+func (s *oauthService) CreateClient(ctx context.Context, req *CreateClientRequest, createdBy string) (*ClientCredentials, error) {
+	grantTypes := req.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken}
+	}
+	for _, grantType := range grantTypes {
+		if !containsString(supportedGrantTypes, grantType) {
+			return nil, errors.New("不支持的授权类型: " + grantType)
+		}
+	}
+	if req.Public && containsString(grantTypes, GrantTypeClientCredentials) {
+		return nil, errors.New("公开客户端不能使用客户端凭证模式")
+	}
+
+	if containsString(grantTypes, GrantTypeAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return nil, errors.New("至少需要一个回调地址")
+	}
+	for _, redirectURI := range req.RedirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || !u.IsAbs() || u.Fragment != "" {
+			return nil, errors.New("无效的回调地址: " + redirectURI)
+		}
+	}
+
+	authMethod, err := createClientAuthMethod(req)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 && containsString(grantTypes, GrantTypeAuthorizationCode) {
+		scopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone}
+	}
+	for _, scope := range scopes {
+		if !containsString(supportedScopes, scope) {
+			return nil, errors.New("不支持的授权范围: " + scope)
+		}
+	}
+
+	clientID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &models.OAuthClient{
+		ClientID:     clientID,
+		Name:         req.Name,
+		Public:       req.Public,
+		RedirectURIs: req.RedirectURIs,
+		GrantTypes:   grantTypes,
+		Scopes:       scopes,
+		AuthMethod:   authMethod,
+	}
+
+	if creatorID, err := primitive.ObjectIDFromHex(createdBy); err == nil {
+		client.CreatedBy = creatorID
+	}
+
+	credentials := &ClientCredentials{ClientID: clientID, Client: client}
+
+	// 使用密钥认证的机密客户端生成密钥，仅保存摘要
+	switch authMethod {
+	case AuthMethodPrivateKeyJWT:
+		client.PublicKey = req.PublicKey
+	case AuthMethodClientSecretBasic:
+		secret, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		client.ClientSecretHash = utils.HashToken(secret)
+		credentials.ClientSecret = secret
+	}
+
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+// ListClients 获取客户端列表
+func (s *oauthService) ListClients(ctx context.Context, page, pageSize int) ([]*models.OAuthClient, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.clientRepo.List(ctx, page, pageSize)
+}
+
+// DeleteClient 删除客户端，服务账号已签发的访问令牌一并失效
+func (s *oauthService) DeleteClient(ctx context.Context, clientID string) error {
+	if err := s.clientRepo.Delete(ctx, clientID); err != nil {
+		return err
+	}
+
+	return s.revocation.RevokeUser(ctx, clientID, "client_deleted")
+}
+
+// AssignClientRole 为服务账号分配角色，新角色在下次获取令牌时生效
+func (s *oauthService) AssignClientRole(ctx context.Context, clientID, roleID, grantedBy string) error {
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return errors.New("客户端不存在")
+	}
+	if !containsString(client.GrantTypes, GrantTypeClientCredentials) {
+		return errors.New("只能为使用客户端凭证模式的服务账号分配角色")
+	}
+
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return errors.New("角色不存在")
+	}
+
+	operator, err := s.userRepo.GetByID(grantedBy)
+	if err != nil {
+		return errors.New("操作者不存在")
+	}
+	if err := roleService.CheckAssignable(s.roleRepo, operator.Roles, role); err != nil {
+		return err
+	}
+
+	userRole := models.UserRole{
+		RoleID:    role.ID,
+		RoleName:  role.Name,
+		GrantedBy: operator.ID,
+		GrantedAt: time.Now(),
+	}
+
+	if err := s.clientRepo.AssignRole(ctx, clientID, userRole); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("client_role_assigned", map[string]interface{}{
+		"client_id":  clientID,
+		"role_id":    roleID,
+		"granted_by": grantedBy,
+	})
+
+	return nil
+}
+
+// RemoveClientRole 移除服务账号的角色
+// 访问令牌中携带了角色和权限，需吊销后由服务账号重新获取
+func (s *oauthService) RemoveClientRole(ctx context.Context, clientID, roleID string) error {
+	roleObjID, err := primitive.ObjectIDFromHex(roleID)
+	if err != nil {
+		return errors.New("无效的角色ID")
+	}
+
+	if err := s.clientRepo.RemoveRole(ctx, clientID, roleObjID); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("client_role_removed", map[string]interface{}{
+		"client_id": clientID,
+		"role_id":   roleID,
+	})
+
+	return s.revocation.RevokeUser(ctx, clientID, "role_removed")
+}
+
+// createClientAuthMethod 校验注册请求的认证方式，公开客户端固定为none
+func createClientAuthMethod(req *CreateClientRequest) (string, error) {
+	if req.Public {
+		if req.AuthMethod != "" && req.AuthMethod != AuthMethodNone {
+			return "", errors.New("公开客户端不能使用" + req.AuthMethod + "认证")
+		}
+		return AuthMethodNone, nil
+	}
+
+	switch req.AuthMethod {
+	case "", AuthMethodClientSecretBasic, AuthMethodClientSecretPost:
+		return AuthMethodClientSecretBasic, nil
+	case AuthMethodPrivateKeyJWT:
+		if _, err := parsePublicKey(req.PublicKey); err != nil {
+			return "", errors.New("无效的公钥: " + err.Error())
+		}
+		return AuthMethodPrivateKeyJWT, nil
+	default:
+		return "", errors.New("不支持的认证方式: " + req.AuthMethod)
+	}
+}
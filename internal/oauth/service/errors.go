@@ -0,0 +1,48 @@
+package service
+
+import "net/http"
+
+// OAuth2错误码（RFC 6749）
+const (
+	ErrInvalidRequest          = "invalid_request"
+	ErrInvalidClient           = "invalid_client"
+	ErrInvalidGrant            = "invalid_grant"
+	ErrUnauthorizedClient      = "unauthorized_client"
+	ErrUnsupportedGrantType    = "unsupported_grant_type"
+	ErrUnsupportedResponseType = "unsupported_response_type"
+	ErrInvalidScope            = "invalid_scope"
+	ErrAccessDenied            = "access_denied"
+	ErrServerError             = "server_error"
+	ErrUnsupportedTokenType    = "unsupported_token_type" // RFC 7009
+)
+
+// Error OAuth2错误响应
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// StatusCode 获取错误对应的HTTP状态码
+func (e *Error) StatusCode() int {
+	switch e.Code {
+	case ErrInvalidClient:
+		return http.StatusUnauthorized
+	case ErrServerError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// newError 创建OAuth2错误
+func newError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
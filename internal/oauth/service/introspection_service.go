@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/logger"
+)
+
+// 令牌类型提示（RFC 7662/RFC 7009）
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// IntrospectRequest 令牌内省请求
+type IntrospectRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientAuthentication
+}
+
+// IntrospectionResponse 令牌内省响应，令牌无效时只返回active=false
+type IntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Nbf       int64    `json:"nbf,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	SubType   string   `json:"sub_type,omitempty"` // user, service
+	Aud       []string `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+}
+
+// RevokeRequest 令牌吊销请求
+type RevokeRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientAuthentication
+}
+
+// Introspect 令牌内省（RFC 7662），仅允许机密客户端调用
+func (s *oauthService) Introspect(ctx context.Context, req *IntrospectRequest) (*IntrospectionResponse, error) {
+	client, err := s.AuthenticateClient(ctx, &req.ClientAuthentication)
+	if err != nil {
+		return nil, err
+	}
+	if client.Public {
+		return nil, newError(ErrInvalidClient, "公开客户端不能调用内省端点")
+	}
+
+	if req.Token == "" {
+		return nil, newError(ErrInvalidRequest, "缺少token")
+	}
+
+	claims, tokenType := s.parseToken(ctx, req.Token, req.TokenTypeHint)
+	if claims == nil {
+		return &IntrospectionResponse{Active: false}, nil
+	}
+
+	resp := &IntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		Username:  claims.Username,
+		TokenType: tokenType,
+		Sub:       claims.UserID,
+		SubType:   claims.PrincipalType(),
+		Aud:       claims.Audience,
+		Iss:       claims.Issuer,
+		Jti:       claims.JTI,
+		SessionID: claims.SessionID,
+	}
+	if claims.ExpiresAt != nil {
+		resp.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.NotBefore != nil {
+		resp.Nbf = claims.NotBefore.Unix()
+	}
+
+	return resp, nil
+}
+
+// Revoke 吊销令牌（RFC 7009）
+// 无效或已吊销的令牌同样视为成功，避免泄露令牌状态
+func (s *oauthService) Revoke(ctx context.Context, req *RevokeRequest) error {
+	client, err := s.AuthenticateClient(ctx, &req.ClientAuthentication)
+	if err != nil {
+		return err
+	}
+
+	if req.Token == "" {
+		return newError(ErrInvalidRequest, "缺少token")
+	}
+	if req.TokenTypeHint != "" && req.TokenTypeHint != TokenTypeHintAccessToken && req.TokenTypeHint != TokenTypeHintRefreshToken {
+		return newError(ErrUnsupportedTokenType, "不支持的令牌类型")
+	}
+
+	claims, tokenType := s.parseToken(ctx, req.Token, req.TokenTypeHint)
+	if claims == nil {
+		return nil
+	}
+
+	if claims.ClientID != client.ClientID {
+		return newError(ErrUnauthorizedClient, "令牌不属于该客户端")
+	}
+
+	switch tokenType {
+	case TokenTypeHintRefreshToken:
+		// 吊销刷新令牌时一并吊销同一会话签发的访问令牌
+		if err := s.sessionRepo.RevokeSession(ctx, claims.JTI); err != nil {
+			return newError(ErrServerError, "吊销会话失败")
+		}
+		if err := s.revocation.RevokeSession(ctx, claims.JTI, "oauth_revoke"); err != nil {
+			return newError(ErrServerError, "吊销访问令牌失败")
+		}
+	case TokenTypeHintAccessToken:
+		if err := s.revocation.RevokeToken(ctx, claims.JTI, "oauth_revoke"); err != nil {
+			return newError(ErrServerError, "吊销访问令牌失败")
+		}
+	}
+
+	logger.SecurityEvent("token_revoked", map[string]interface{}{
+		"user_id":    claims.UserID,
+		"client_id":  client.ClientID,
+		"token_type": tokenType,
+		"jti":        claims.JTI,
+	})
+
+	return nil
+}
+
+// parseToken 按类型提示解析令牌，提示不匹配时尝试另一种类型
+// 令牌无效、已过期或已吊销时返回nil
+func (s *oauthService) parseToken(ctx context.Context, token, hint string) (*jwt.Claims, string) {
+	order := []string{TokenTypeHintAccessToken, TokenTypeHintRefreshToken}
+	if hint == TokenTypeHintRefreshToken {
+		order = []string{TokenTypeHintRefreshToken, TokenTypeHintAccessToken}
+	}
+
+	for _, tokenType := range order {
+		var claims *jwt.Claims
+		if tokenType == TokenTypeHintAccessToken {
+			claims = s.parseAccessToken(token)
+		} else {
+			claims = s.parseRefreshToken(ctx, token)
+		}
+		if claims != nil {
+			return claims, tokenType
+		}
+	}
+
+	return nil, ""
+}
+
+// parseAccessToken 解析访问令牌并检查吊销列表
+func (s *oauthService) parseAccessToken(token string) *jwt.Claims {
+	claims, err := s.jwtManager.ValidateAccessToken(token)
+	if err != nil || s.revocation.IsRevoked(claims) {
+		return nil
+	}
+	return claims
+}
+
+// parseRefreshToken 解析刷新令牌并检查会话状态
+// 刷新令牌不携带客户端和授权范围，从会话中补全
+func (s *oauthService) parseRefreshToken(ctx context.Context, token string) *jwt.Claims {
+	claims, err := s.jwtManager.ValidateRefreshToken(token)
+	if err != nil {
+		return nil
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(ctx, claims.JTI)
+	if err != nil || session.IsRevoked {
+		return nil
+	}
+
+	claims.ClientID = session.ClientID
+	claims.Scope = session.Scope
+	claims.SessionID = session.SessionID
+	return claims
+}
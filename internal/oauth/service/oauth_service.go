@@ -0,0 +1,640 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"time"
+
+	sessionRepo "authcenter/internal/auth/repository"
+	authService "authcenter/internal/auth/service"
+	"authcenter/internal/config"
+	"authcenter/internal/models"
+	"authcenter/internal/oauth/repository"
+	roleRepo "authcenter/internal/role/repository"
+	userRepo "authcenter/internal/user/repository"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/utils"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 授权类型
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials" // 服务账号以自身身份获取访问令牌
+)
+
+// 授权范围
+const (
+	ScopeOpenID        = "openid"
+	ScopeProfile       = "profile"
+	ScopeEmail         = "email"
+	ScopePhone         = "phone"
+	ScopeOfflineAccess = "offline_access"
+)
+
+// PKCE校验方法
+const (
+	CodeChallengeS256  = "S256"
+	CodeChallengePlain = "plain"
+)
+
+// OAuthService OAuth2/OpenID Connect服务接口
+type OAuthService interface {
+	// Discovery 获取OpenID Provider配置
+	Discovery() *DiscoveryDocument
+
+	// ValidateAuthorizeRequest 校验授权请求
+	// 客户端或回调地址无效时返回的客户端为nil，此时不能重定向到回调地址
+	ValidateAuthorizeRequest(ctx context.Context, req *AuthorizeRequest) (*models.OAuthClient, error)
+
+	// Authorize 为已登录用户签发授权码，返回携带授权码的回调地址
+	// authTime和authMethods来自用户的登录会话
+	Authorize(ctx context.Context, req *AuthorizeRequest, userID string, authTime time.Time, authMethods []string) (string, error)
+
+	// Token 令牌端点
+	Token(ctx context.Context, req *TokenRequest) (*TokenResponse, error)
+
+	// UserInfo 获取用户信息声明，scope为空时视为第一方Token返回全部声明
+	UserInfo(ctx context.Context, userID, scope string, tenantID primitive.ObjectID) (map[string]interface{}, error)
+
+	// Introspect 令牌内省（RFC 7662）
+	Introspect(ctx context.Context, req *IntrospectRequest) (*IntrospectionResponse, error)
+
+	// Revoke 吊销令牌（RFC 7009）
+	Revoke(ctx context.Context, req *RevokeRequest) error
+
+	// AuthenticateClient 认证客户端，支持客户端密钥和private_key_jwt断言
+	AuthenticateClient(ctx context.Context, auth *ClientAuthentication) (*models.OAuthClient, error)
+
+	// CreateClient 注册客户端
+	CreateClient(ctx context.Context, req *CreateClientRequest, createdBy string) (*ClientCredentials, error)
+
+	// ListClients 获取客户端列表
+	ListClients(ctx context.Context, page, pageSize int) ([]*models.OAuthClient, int64, error)
+
+	// DeleteClient 删除客户端
+	DeleteClient(ctx context.Context, clientID string) error
+
+	// AssignClientRole 为服务账号分配角色
+	AssignClientRole(ctx context.Context, clientID, roleID, grantedBy string) error
+
+	// RemoveClientRole 移除服务账号的角色，已签发的访问令牌立即失效
+	RemoveClientRole(ctx context.Context, clientID, roleID string) error
+}
+
+// oauthService OAuth2/OpenID Connect服务实现
+type oauthService struct {
+	clientRepo    repository.ClientRepository
+	codeRepo      repository.AuthorizationCodeRepository
+	assertionRepo repository.ClientAssertionRepository
+	userRepo      userRepo.UserRepository
+	roleRepo      roleRepo.RoleRepository
+	sessionRepo   sessionRepo.SessionRepository
+	authService   authService.AuthService
+	revocation    authService.RevocationService
+	jwtManager    jwt.Manager
+	algorithm     string
+	config        config.OAuthConfig
+}
+
+// AuthorizeRequest 授权请求
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+
+	redirectURIOmitted bool // 请求未携带redirect_uri，使用了客户端注册的唯一回调地址
+}
+
+// TokenRequest 令牌请求
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientAuthentication
+}
+
+// TokenResponse 令牌响应
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// DiscoveryDocument OpenID Provider配置
+type DiscoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	TokenEndpointAuthSigningAlgs      []string `json:"token_endpoint_auth_signing_alg_values_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// NewOAuthService 创建OAuth2/OpenID Connect服务
+func NewOAuthService(
+	clientRepo repository.ClientRepository,
+	codeRepo repository.AuthorizationCodeRepository,
+	assertionRepo repository.ClientAssertionRepository,
+	userRepo userRepo.UserRepository,
+	roleRepo roleRepo.RoleRepository,
+	sessionRepo sessionRepo.SessionRepository,
+	authService authService.AuthService,
+	revocation authService.RevocationService,
+	jwtManager jwt.Manager,
+	algorithm string,
+	cfg config.OAuthConfig,
+) OAuthService {
+	if algorithm == "" || algorithm == jwt.AlgorithmHS256 {
+		logger.Warn("ID Token使用HS256共享密钥签名，OIDC客户端无法独立验证，建议将jwt.algorithm设置为RS256/ES256/EdDSA")
+	}
+
+	return &oauthService{
+		clientRepo:    clientRepo,
+		codeRepo:      codeRepo,
+		assertionRepo: assertionRepo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		sessionRepo:   sessionRepo,
+		authService:   authService,
+		revocation:    revocation,
+		jwtManager:    jwtManager,
+		algorithm:     algorithm,
+		config:        cfg,
+	}
+}
+
+// Discovery 获取OpenID Provider配置
+func (s *oauthService) Discovery() *DiscoveryDocument {
+	issuer := s.issuer()
+	algorithm := s.algorithm
+	if algorithm == "" {
+		algorithm = jwt.AlgorithmHS256
+	}
+
+	return &DiscoveryDocument{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserInfoEndpoint:                  issuer + "/oauth/userinfo",
+		IntrospectionEndpoint:             issuer + "/oauth/introspect",
+		RevocationEndpoint:                issuer + "/oauth/revoke",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{algorithm},
+		ScopesSupported:                   []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone, ScopeOfflineAccess},
+		TokenEndpointAuthMethodsSupported: []string{AuthMethodClientSecretBasic, AuthMethodClientSecretPost, AuthMethodPrivateKeyJWT, AuthMethodNone},
+		TokenEndpointAuthSigningAlgs:      clientAssertionSigningMethods,
+		GrantTypesSupported:               supportedGrantTypes,
+		CodeChallengeMethodsSupported:     []string{CodeChallengeS256, CodeChallengePlain},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "sid",
+			"preferred_username", "picture", "email", "phone_number", "roles",
+		},
+	}
+}
+
+// ValidateAuthorizeRequest 校验授权请求
+func (s *oauthService) ValidateAuthorizeRequest(ctx context.Context, req *AuthorizeRequest) (*models.OAuthClient, error) {
+	if req.ClientID == "" {
+		return nil, newError(ErrInvalidRequest, "缺少client_id")
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
+	if err != nil || client.Status != "active" {
+		return nil, newError(ErrInvalidClient, "客户端不存在或已禁用")
+	}
+
+	// 回调地址必须与注册值完全一致，仅注册一个时可省略
+	if req.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		req.RedirectURI = client.RedirectURIs[0]
+		req.redirectURIOmitted = true
+	}
+	if !containsString(client.RedirectURIs, req.RedirectURI) {
+		return nil, newError(ErrInvalidRequest, "redirect_uri未注册")
+	}
+
+	// 以下错误可通过回调地址返回给客户端
+	if req.ResponseType != "code" {
+		return client, newError(ErrUnsupportedResponseType, "仅支持授权码模式")
+	}
+
+	if !containsString(client.GrantTypes, GrantTypeAuthorizationCode) {
+		return client, newError(ErrUnauthorizedClient, "客户端未被授权使用授权码模式")
+	}
+
+	if req.Scope == "" {
+		req.Scope = ScopeOpenID
+	}
+	for _, scope := range strings.Fields(req.Scope) {
+		if !containsString(client.Scopes, scope) {
+			return client, newError(ErrInvalidScope, "客户端无权申请授权范围: "+scope)
+		}
+	}
+
+	if req.CodeChallenge != "" {
+		if req.CodeChallengeMethod == "" {
+			req.CodeChallengeMethod = CodeChallengePlain
+		}
+		if req.CodeChallengeMethod != CodeChallengeS256 && req.CodeChallengeMethod != CodeChallengePlain {
+			return client, newError(ErrInvalidRequest, "不支持的code_challenge_method")
+		}
+	}
+
+	// 公开客户端无法保管密钥，必须使用S256方式的PKCE
+	if client.Public && (req.CodeChallenge == "" || req.CodeChallengeMethod != CodeChallengeS256) {
+		return client, newError(ErrInvalidRequest, "公开客户端必须使用S256方式的PKCE")
+	}
+
+	return client, nil
+}
+
+// Authorize 为已登录用户签发授权码
+func (s *oauthService) Authorize(ctx context.Context, req *AuthorizeRequest, userID string, authTime time.Time, authMethods []string) (string, error) {
+	if _, err := s.ValidateAuthorizeRequest(ctx, req); err != nil {
+		return "", err
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", newError(ErrAccessDenied, "无效的用户")
+	}
+
+	code, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", newError(ErrServerError, "生成授权码失败")
+	}
+
+	authCode := &models.AuthorizationCode{
+		CodeHash:            utils.HashToken(code),
+		ClientID:            req.ClientID,
+		UserID:              userObjID,
+		RedirectURI:         req.RedirectURI,
+		RedirectURIOmitted:  req.redirectURIOmitted,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            authTime,
+		AuthMethods:         authMethods,
+		ExpiresAt:           time.Now().Add(s.config.AuthorizationCodeExpire),
+	}
+
+	if err := s.codeRepo.Create(ctx, authCode); err != nil {
+		return "", newError(ErrServerError, "保存授权码失败")
+	}
+
+	return BuildRedirectURL(req.RedirectURI, map[string]string{
+		"code":  code,
+		"state": req.State,
+	}), nil
+}
+
+// Token 令牌端点
+func (s *oauthService) Token(ctx context.Context, req *TokenRequest) (*TokenResponse, error) {
+	client, err := s.AuthenticateClient(ctx, &req.ClientAuthentication)
+	if err != nil {
+		return nil, err
+	}
+
+	if !containsString(client.GrantTypes, req.GrantType) {
+		switch req.GrantType {
+		case GrantTypeAuthorizationCode, GrantTypeRefreshToken, GrantTypeClientCredentials:
+			return nil, newError(ErrUnauthorizedClient, "客户端未被授权使用该授权类型")
+		default:
+			return nil, newError(ErrUnsupportedGrantType, "不支持的授权类型")
+		}
+	}
+
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, client, req)
+	case GrantTypeRefreshToken:
+		return s.refreshToken(ctx, client, req)
+	case GrantTypeClientCredentials:
+		return s.clientCredentials(ctx, client, req)
+	default:
+		return nil, newError(ErrUnsupportedGrantType, "不支持的授权类型")
+	}
+}
+
+// exchangeAuthorizationCode 使用授权码换取令牌
+func (s *oauthService) exchangeAuthorizationCode(ctx context.Context, client *models.OAuthClient, req *TokenRequest) (*TokenResponse, error) {
+	if req.Code == "" {
+		return nil, newError(ErrInvalidRequest, "缺少code")
+	}
+
+	// 先校验授权码与本次请求的绑定再兑换，避免其他客户端或错误的code_verifier使授权码作废
+	codeHash := utils.HashToken(req.Code)
+	code, err := s.codeRepo.GetByHash(ctx, codeHash)
+	if err != nil {
+		return nil, newError(ErrInvalidGrant, "无效的授权码")
+	}
+	if code.ClientID != client.ClientID {
+		return nil, newError(ErrInvalidGrant, "授权码不属于该客户端")
+	}
+	if code.Used {
+		s.handleCodeReuse(ctx, client, code)
+		return nil, newError(ErrInvalidGrant, "授权码已被使用")
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return nil, newError(ErrInvalidGrant, "授权码已过期")
+	}
+	// RFC 6749 4.1.3：授权请求携带了redirect_uri时令牌请求必须携带相同的值
+	if (!code.RedirectURIOmitted || req.RedirectURI != "") && code.RedirectURI != req.RedirectURI {
+		return nil, newError(ErrInvalidGrant, "redirect_uri不匹配")
+	}
+	if code.CodeChallenge != "" && !verifyCodeChallenge(code.CodeChallenge, code.CodeChallengeMethod, req.CodeVerifier) {
+		return nil, newError(ErrInvalidGrant, "code_verifier校验失败")
+	}
+
+	// 条件更新保证并发请求中只有一个兑换成功
+	code, err = s.codeRepo.Consume(ctx, codeHash)
+	if err == repository.ErrCodeAlreadyUsed {
+		s.handleCodeReuse(ctx, client, code)
+		return nil, newError(ErrInvalidGrant, "授权码已被使用")
+	}
+	if err != nil {
+		return nil, newError(ErrInvalidGrant, "无效的授权码")
+	}
+
+	tokenData, err := s.authService.IssueTokens(ctx, code.UserID.Hex(), &authService.IssueOptions{
+		ClientID:    client.ClientID,
+		Scope:       code.Scope,
+		AuthMethods: code.AuthMethods,
+	})
+	if err != nil {
+		return nil, newError(ErrInvalidGrant, err.Error())
+	}
+
+	if err := s.codeRepo.SetSessionID(ctx, codeHash, tokenData.SessionID); err != nil {
+		logger.Error("记录授权码会话失败: %v", err)
+	}
+
+	resp := &TokenResponse{
+		AccessToken:  tokenData.AccessToken,
+		TokenType:    tokenData.TokenType,
+		ExpiresIn:    tokenData.ExpiresIn,
+		RefreshToken: tokenData.RefreshToken,
+		Scope:        code.Scope,
+	}
+
+	if hasScope(code.Scope, ScopeOpenID) {
+		idToken, err := s.generateIDToken(client, code, tokenData)
+		if err != nil {
+			return nil, newError(ErrServerError, "生成ID Token失败")
+		}
+		resp.IDToken = idToken
+	}
+
+	return resp, nil
+}
+
+// handleCodeReuse 处理授权码重放：吊销该授权码已签发的令牌并记录安全事件（RFC 6749 4.1.2）
+// 兑换生成的会话是令牌族的第一个会话，吊销整个令牌族，包括轮换产生的会话和尚未过期的访问令牌
+func (s *oauthService) handleCodeReuse(ctx context.Context, client *models.OAuthClient, code *models.AuthorizationCode) {
+	if code.SessionID != "" {
+		if err := s.authService.RevokeSession(ctx, code.UserID.Hex(), code.SessionID); err != nil {
+			logger.Error("吊销重放授权码的会话失败: %v", err)
+		}
+	}
+
+	logger.SecurityEvent("authorization_code_reuse", map[string]interface{}{
+		"user_id":    code.UserID.Hex(),
+		"client_id":  client.ClientID,
+		"session_id": code.SessionID,
+	})
+}
+
+// refreshToken 使用刷新令牌换取新令牌
+func (s *oauthService) refreshToken(ctx context.Context, client *models.OAuthClient, req *TokenRequest) (*TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, newError(ErrInvalidRequest, "缺少refresh_token")
+	}
+
+	claims, err := s.jwtManager.ValidateRefreshToken(req.RefreshToken)
+	if err != nil {
+		return nil, newError(ErrInvalidGrant, "无效的refresh_token")
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(ctx, claims.JTI)
+	if err != nil || session.ClientID != client.ClientID {
+		return nil, newError(ErrInvalidGrant, "refresh_token不属于该客户端")
+	}
+
+	tokenData, err := s.authService.RefreshToken(ctx, req.RefreshToken, "")
+	if err != nil {
+		return nil, newError(ErrInvalidGrant, err.Error())
+	}
+
+	return &TokenResponse{
+		AccessToken:  tokenData.AccessToken,
+		TokenType:    tokenData.TokenType,
+		ExpiresIn:    tokenData.ExpiresIn,
+		RefreshToken: tokenData.RefreshToken,
+		Scope:        session.Scope,
+	}, nil
+}
+
+// clientCredentials 服务账号以自身身份获取访问令牌（RFC 6749 4.4）
+// 令牌主体为客户端，不签发刷新令牌和ID Token
+func (s *oauthService) clientCredentials(ctx context.Context, client *models.OAuthClient, req *TokenRequest) (*TokenResponse, error) {
+	if client.Public {
+		return nil, newError(ErrUnauthorizedClient, "公开客户端不能使用客户端凭证模式")
+	}
+
+	for _, scope := range strings.Fields(req.Scope) {
+		if scope == ScopeOpenID || scope == ScopeOfflineAccess || !containsString(client.Scopes, scope) {
+			return nil, newError(ErrInvalidScope, "客户端无权申请授权范围: "+scope)
+		}
+	}
+
+	tokenData, err := s.authService.IssueServiceToken(ctx, client, req.Scope)
+	if err != nil {
+		return nil, newError(ErrServerError, "签发访问令牌失败")
+	}
+
+	logger.SecurityEvent("service_token_issued", map[string]interface{}{
+		"client_id": client.ClientID,
+		"roles":     tokenData.Roles,
+	})
+
+	return &TokenResponse{
+		AccessToken: tokenData.AccessToken,
+		TokenType:   tokenData.TokenType,
+		ExpiresIn:   tokenData.ExpiresIn,
+		Scope:       req.Scope,
+	}, nil
+}
+
+// generateIDToken 生成ID Token
+func (s *oauthService) generateIDToken(client *models.OAuthClient, code *models.AuthorizationCode, tokenData *authService.TokenData) (string, error) {
+	user, err := s.userRepo.GetByID(code.UserID.Hex())
+	if err != nil {
+		return "", err
+	}
+
+	claims := &jwt.IDTokenClaims{
+		Nonce:           code.Nonce,
+		AuthTime:        code.AuthTime.Unix(),
+		SessionID:       tokenData.SessionID,
+		AccessTokenHash: accessTokenHash(tokenData.AccessToken),
+		RegisteredClaims: gojwt.RegisteredClaims{
+			Issuer:   s.issuer(),
+			Subject:  user.ID.Hex(),
+			Audience: gojwt.ClaimStrings{client.ClientID},
+		},
+	}
+
+	if hasScope(code.Scope, ScopeProfile) {
+		claims.PreferredUsername = user.Username
+		claims.Picture = user.Profile.Avatar
+		claims.Roles = tokenData.Roles
+	}
+	if hasScope(code.Scope, ScopeEmail) {
+		claims.Email = user.Email
+	}
+	if hasScope(code.Scope, ScopePhone) {
+		claims.PhoneNumber = user.Phone
+	}
+
+	return s.jwtManager.GenerateIDToken(claims)
+}
+
+// UserInfo 获取用户信息声明，角色只包括令牌所属租户中生效的
+func (s *oauthService) UserInfo(ctx context.Context, userID, scope string, tenantID primitive.ObjectID) (map[string]interface{}, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 第一方Token未携带scope，视为拥有全部授权范围
+	all := scope == ""
+
+	info := map[string]interface{}{
+		"sub": user.ID.Hex(),
+	}
+
+	if all || hasScope(scope, ScopeProfile) {
+		info["preferred_username"] = user.Username
+		info["updated_at"] = user.UpdatedAt.Unix()
+		if user.Profile.Avatar != "" {
+			info["picture"] = user.Profile.Avatar
+		}
+		if user.Profile.Department != "" {
+			info["department"] = user.Profile.Department
+		}
+		if user.Profile.Position != "" {
+			info["position"] = user.Profile.Position
+		}
+
+		assignments := user.RolesIn(tenantID)
+		roles := make([]string, 0, len(assignments))
+		now := time.Now()
+		for _, role := range assignments {
+			if role.Expired(now) {
+				continue
+			}
+			roles = append(roles, role.RoleName)
+		}
+		info["roles"] = roles
+	}
+	if (all || hasScope(scope, ScopeEmail)) && user.Email != "" {
+		info["email"] = user.Email
+	}
+	if (all || hasScope(scope, ScopePhone)) && user.Phone != "" {
+		info["phone_number"] = user.Phone
+	}
+
+	return info, nil
+}
+
+// issuer 获取OIDC签发者地址
+func (s *oauthService) issuer() string {
+	return strings.TrimRight(s.config.IssuerURL, "/")
+}
+
+// BuildRedirectURL 在回调地址上追加查询参数，忽略空值
+func BuildRedirectURL(redirectURI string, params map[string]string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// verifyCodeChallenge 校验PKCE
+func verifyCodeChallenge(challenge, method, verifier string) bool {
+	// RFC 7636: code_verifier长度为43~128
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	expected := verifier
+	if method == CodeChallengeS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// accessTokenHash 计算at_hash：访问令牌SHA-256摘要左半部分的Base64URL编码
+func accessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+// hasScope 判断授权范围中是否包含指定范围
+func hasScope(scope, target string) bool {
+	return containsString(strings.Fields(scope), target)
+}
+
+// containsString 判断字符串切片中是否包含指定值
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	authService "authcenter/internal/auth/service"
+	"authcenter/internal/config"
+	"authcenter/internal/models"
+	"authcenter/internal/testutil"
+	"authcenter/pkg/jwt"
+	"authcenter/pkg/utils"
+)
+
+// RFC 7636 附录B的示例
+const (
+	rfcVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfcChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge string
+		method    string
+		verifier  string
+		want      bool
+	}{
+		{"S256 RFC 7636附录B", rfcChallenge, CodeChallengeS256, rfcVerifier, true},
+		{"S256 验证码不匹配", rfcChallenge, CodeChallengeS256, strings.Replace(rfcVerifier, "d", "e", 1), false},
+		{"plain 匹配", rfcVerifier, CodeChallengePlain, rfcVerifier, true},
+		{"plain 不匹配", rfcVerifier, CodeChallengePlain, strings.Replace(rfcVerifier, "d", "e", 1), false},
+		{"S256质询按plain校验", rfcChallenge, CodeChallengePlain, rfcVerifier, false},
+		{"plain质询按S256校验", rfcVerifier, CodeChallengeS256, rfcVerifier, false},
+		{"验证码长度不足43", strings.Repeat("a", 42), CodeChallengePlain, strings.Repeat("a", 42), false},
+		{"验证码长度43", strings.Repeat("a", 43), CodeChallengePlain, strings.Repeat("a", 43), true},
+		{"验证码长度128", strings.Repeat("a", 128), CodeChallengePlain, strings.Repeat("a", 128), true},
+		{"验证码长度超过128", strings.Repeat("a", 129), CodeChallengePlain, strings.Repeat("a", 129), false},
+		{"空验证码", "", CodeChallengePlain, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.challenge, tt.method, tt.verifier); got != tt.want {
+				t.Fatalf("verifyCodeChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// testRedirectURI 测试客户端的回调地址
+const testRedirectURI = "https://app.example.com/callback"
+
+// oauthTestEnv 使用内存仓储的OAuth服务
+type oauthTestEnv struct {
+	svc        *oauthService
+	users      *testutil.Users
+	sessions   *testutil.Sessions
+	codes      *testutil.AuthorizationCodes
+	revocation authService.RevocationService
+	jwt        jwt.Manager
+	client     *models.OAuthClient
+	user       *models.User
+}
+
+func newOAuthTestEnv(t *testing.T) *oauthTestEnv {
+	t.Helper()
+
+	env := &oauthTestEnv{
+		users:    testutil.NewUsers(),
+		sessions: testutil.NewSessions(),
+		codes:    testutil.NewAuthorizationCodes(),
+		jwt:      jwt.NewManager("test-secret", 15*time.Minute, 24*time.Hour, "test"),
+		client: &models.OAuthClient{
+			ClientID:     "client-1",
+			Public:       true,
+			RedirectURIs: []string{testRedirectURI},
+			Status:       "active",
+		},
+	}
+	env.user = env.users.Put(&models.User{Username: "alice", Email: "alice@example.com"})
+	env.revocation = authService.NewRevocationService(testutil.NewRevocations(), 15*time.Minute)
+
+	auth := authService.NewAuthService(env.users, env.sessions, nil, nil, nil, nil, nil,
+		env.jwt, nil, nil, env.revocation, nil, nil, nil, nil, config.SecurityConfig{},
+	)
+	env.svc = NewOAuthService(nil, env.codes, nil, env.users, nil, env.sessions, auth, env.revocation,
+		env.jwt, jwt.AlgorithmHS256, config.OAuthConfig{IssuerURL: "https://auth.example.com"},
+	).(*oauthService)
+
+	return env
+}
+
+// issueCode 保存一个使用PKCE S256的授权码，返回授权码明文
+func (e *oauthTestEnv) issueCode(t *testing.T, code string) string {
+	t.Helper()
+
+	err := e.codes.Create(context.Background(), &models.AuthorizationCode{
+		CodeHash:            utils.HashToken(code),
+		ClientID:            e.client.ClientID,
+		UserID:              e.user.ID,
+		RedirectURI:         testRedirectURI,
+		Scope:               "profile offline_access",
+		CodeChallenge:       rfcChallenge,
+		CodeChallengeMethod: CodeChallengeS256,
+		AuthTime:            time.Now(),
+		AuthMethods:         []string{authService.AuthMethodPassword},
+		ExpiresAt:           time.Now().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("保存授权码失败: %v", err)
+	}
+	return code
+}
+
+// revoked 访问令牌是否已被吊销
+func (e *oauthTestEnv) revoked(t *testing.T, accessToken string) bool {
+	t.Helper()
+
+	claims, err := e.jwt.ValidateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken: %v", err)
+	}
+	return e.revocation.IsRevoked(claims)
+}
+
+// 授权码被重放时吊销兑换产生的整个令牌族，包括轮换后的会话和尚未过期的访问令牌
+func TestAuthorizationCodeReplayRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	env := newOAuthTestEnv(t)
+	code := env.issueCode(t, "code-1")
+	exchange := &TokenRequest{Code: code, RedirectURI: testRedirectURI, CodeVerifier: rfcVerifier}
+
+	first, err := env.svc.exchangeAuthorizationCode(ctx, env.client, exchange)
+	if err != nil {
+		t.Fatalf("兑换授权码失败: %v", err)
+	}
+	rotated, err := env.svc.refreshToken(ctx, env.client, &TokenRequest{RefreshToken: first.RefreshToken})
+	if err != nil {
+		t.Fatalf("刷新令牌失败: %v", err)
+	}
+
+	if _, err := env.svc.exchangeAuthorizationCode(ctx, env.client, exchange); err == nil {
+		t.Fatal("重放的授权码兑换成功")
+	}
+
+	for _, session := range env.sessions.All() {
+		if !session.IsRevoked {
+			t.Errorf("会话 %s 未被吊销", session.SessionID)
+		}
+	}
+	if !env.revoked(t, first.AccessToken) {
+		t.Error("兑换授权码获得的访问令牌未被吊销")
+	}
+	if !env.revoked(t, rotated.AccessToken) {
+		t.Error("轮换后获得的访问令牌未被吊销")
+	}
+	if _, err := env.svc.refreshToken(ctx, env.client, &TokenRequest{RefreshToken: rotated.RefreshToken}); err == nil {
+		t.Error("轮换后获得的刷新令牌仍然可用")
+	}
+}
+
+// 校验失败的兑换请求不消耗授权码，合法客户端随后仍可兑换
+func TestAuthorizationCodeNotConsumedOnFailedExchange(t *testing.T) {
+	ctx := context.Background()
+	env := newOAuthTestEnv(t)
+	code := env.issueCode(t, "code-1")
+	other := &models.OAuthClient{ClientID: "client-2", Public: true, RedirectURIs: []string{testRedirectURI}}
+
+	tests := []struct {
+		name   string
+		client *models.OAuthClient
+		req    *TokenRequest
+	}{
+		{"其他客户端", other, &TokenRequest{Code: code, RedirectURI: testRedirectURI, CodeVerifier: rfcVerifier}},
+		{"redirect_uri不匹配", env.client, &TokenRequest{Code: code, RedirectURI: "https://evil.example.com/callback", CodeVerifier: rfcVerifier}},
+		{"code_verifier错误", env.client, &TokenRequest{Code: code, RedirectURI: testRedirectURI, CodeVerifier: strings.Repeat("a", 43)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.svc.exchangeAuthorizationCode(ctx, tt.client, tt.req); err == nil {
+				t.Fatal("兑换成功")
+			}
+			if env.codes.Used(utils.HashToken(code)) {
+				t.Fatal("授权码被消耗")
+			}
+		})
+	}
+
+	if _, err := env.svc.exchangeAuthorizationCode(ctx, env.client, &TokenRequest{Code: code, RedirectURI: testRedirectURI, CodeVerifier: rfcVerifier}); err != nil {
+		t.Fatalf("合法客户端兑换失败: %v", err)
+	}
+}
@@ -3,11 +3,24 @@ package handler
 import (
 	"net/http"
 
+	"authcenter/internal/middleware"
 	"authcenter/internal/role/service"
+	"authcenter/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
+// SetParentsRequest 设置父角色请求，为空时取消继承
+type SetParentsRequest struct {
+	ParentIDs []string `json:"parent_ids"`
+}
+
+// SetElevationRequest 设置临时提权请求，max_duration为0时不再允许申请
+type SetElevationRequest struct {
+	MaxDuration     int64    `json:"max_duration"`      // 单次提权的最长时间（秒）
+	ApproverRoleIDs []string `json:"approver_role_ids"` // 审批人须拥有的角色，为空时不限制
+}
+
 // RoleHandler 角色处理器
 type RoleHandler struct {
 	roleService service.RoleService
@@ -54,3 +67,35 @@ func (h *RoleHandler) AssignPermission(c *gin.Context) {
 func (h *RoleHandler) RemovePermission(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "remove permission - not implemented"})
 }
+
+// SetParents 设置角色的父角色
+func (h *RoleHandler) SetParents(c *gin.Context) {
+	var req SetParentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.roleService.ForTenant(middleware.TenantID(c)).SetParents(c.Param("id"), req.ParentIDs, c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "设置父角色失败", err.Error())
+		return
+	}
+
+	response.Success(c, "设置成功")
+}
+
+// SetElevation 设置角色的临时提权
+func (h *RoleHandler) SetElevation(c *gin.Context) {
+	var req SetElevationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.roleService.ForTenant(middleware.TenantID(c)).SetElevation(c.Param("id"), req.MaxDuration, req.ApproverRoleIDs, c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "设置临时提权失败", err.Error())
+		return
+	}
+
+	response.Success(c, "设置成功")
+}
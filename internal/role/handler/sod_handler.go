@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+
+	"authcenter/internal/middleware"
+	"authcenter/internal/role/service"
+	"authcenter/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SoDHandler 职责分离规则处理器
+type SoDHandler struct {
+	sodService service.SoDService
+}
+
+// NewSoDHandler 创建职责分离规则处理器
+func NewSoDHandler(sodService service.SoDService) *SoDHandler {
+	return &SoDHandler{
+		sodService: sodService,
+	}
+}
+
+// ListRules 获取职责分离规则
+func (h *SoDHandler) ListRules(c *gin.Context) {
+	rules, err := h.sodService.ForTenant(middleware.TenantID(c)).ListRules()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取职责分离规则失败", err.Error())
+		return
+	}
+
+	response.Success(c, rules)
+}
+
+// CreateRule 创建职责分离规则
+func (h *SoDHandler) CreateRule(c *gin.Context) {
+	var req service.CreateSoDRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	rule, err := h.sodService.ForTenant(middleware.TenantID(c)).CreateRule(&req, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "创建职责分离规则失败", err.Error())
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// DeleteRule 删除职责分离规则
+func (h *SoDHandler) DeleteRule(c *gin.Context) {
+	if err := h.sodService.ForTenant(middleware.TenantID(c)).DeleteRule(c.Param("id"), c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusNotFound, "删除职责分离规则失败", err.Error())
+		return
+	}
+
+	response.Success(c, "删除成功")
+}
+
+// ListViolations 列出当前违反静态职责分离规则的用户
+func (h *SoDHandler) ListViolations(c *gin.Context) {
+	violations, err := h.sodService.ForTenant(middleware.TenantID(c)).ListViolations()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "检查职责分离规则失败", err.Error())
+		return
+	}
+
+	response.Success(c, violations)
+}
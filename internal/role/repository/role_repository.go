@@ -20,7 +20,7 @@ type RoleRepository interface {
 	// GetByID 通过ID获取角色
 	GetByID(id string) (*models.Role, error)
 
-	// GetByName 通过名称获取角色
+	// GetByName 通过名称获取角色，角色名称在租户内唯一，不限定租户时查找全局角色
 	GetByName(name string) (*models.Role, error)
 
 	// List 获取角色列表
@@ -43,12 +43,28 @@ type RoleRepository interface {
 
 	// GetRoleUsers 获取角色下的用户
 	GetRoleUsers(roleID string) ([]*models.User, error)
+
+	// GetAncestors 获取角色的全部祖先角色，不包括角色本身，存在环时每个角色只返回一次
+	GetAncestors(id string) ([]*models.Role, error)
+
+	// GetDescendants 获取继承该角色的全部子孙角色，不包括角色本身
+	GetDescendants(id string) ([]*models.Role, error)
+
+	// SetParents 设置角色的父角色
+	SetParents(id string, parentIDs []primitive.ObjectID) error
+
+	// SetElevation 设置角色的临时提权设置，为nil时不再允许申请
+	SetElevation(id string, elevation *models.RoleElevation) error
+
+	// ForTenant 返回只访问指定租户角色的仓储，创建的角色属于该租户；tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) RoleRepository
 }
 
 // roleRepository 角色仓储实现
 type roleRepository struct {
 	db         *mongo.Database
 	collection *mongo.Collection
+	tenantID   primitive.ObjectID // 非零值时只访问该租户的角色
 }
 
 // NewRoleRepository 创建角色仓储
@@ -59,6 +75,30 @@ func NewRoleRepository(db *mongo.Database) RoleRepository {
 	}
 }
 
+// ForTenant 返回只访问指定租户角色的仓储
+func (r *roleRepository) ForTenant(tenantID primitive.ObjectID) RoleRepository {
+	scoped := *r
+	scoped.tenantID = tenantID
+	return &scoped
+}
+
+// scope 为查询条件加上租户限定
+func (r *roleRepository) scope(filter bson.M) bson.M {
+	if !r.tenantID.IsZero() {
+		filter["tenant_id"] = r.tenantID
+	}
+	return filter
+}
+
+// scopeName 按名称查询的租户限定，不限定租户时只查找全局角色
+func (r *roleRepository) scopeName(filter bson.M) bson.M {
+	if r.tenantID.IsZero() {
+		filter["tenant_id"] = bson.M{"$exists": false}
+		return filter
+	}
+	return r.scope(filter)
+}
+
 // Create 创建角色
 func (r *roleRepository) Create(role *models.Role) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -74,6 +114,11 @@ func (r *roleRepository) Create(role *models.Role) error {
 		role.Status = "active"
 	}
 
+	if !r.tenantID.IsZero() {
+		tenantID := r.tenantID
+		role.TenantID = &tenantID
+	}
+
 	// 插入角色
 	result, err := r.collection.InsertOne(ctx, role)
 	if err != nil {
@@ -96,7 +141,7 @@ func (r *roleRepository) GetByID(id string) (*models.Role, error) {
 	}
 
 	var role models.Role
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&role)
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&role)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("role not found")
@@ -113,7 +158,7 @@ func (r *roleRepository) GetByName(name string) (*models.Role, error) {
 	defer cancel()
 
 	var role models.Role
-	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&role)
+	err := r.collection.FindOne(ctx, r.scopeName(bson.M{"name": name})).Decode(&role)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("role not found")
@@ -139,7 +184,7 @@ func (r *roleRepository) List(page, pageSize int) ([]*models.Role, int64, error)
 	findOptions.SetSort(bson.D{{Key: "level", Value: -1}, {Key: "created_at", Value: -1}}) // 按级别和创建时间排序
 
 	// 查询角色
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{}), findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -151,7 +196,7 @@ func (r *roleRepository) List(page, pageSize int) ([]*models.Role, int64, error)
 	}
 
 	// 获取总数
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, r.scope(bson.M{}))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -175,7 +220,7 @@ func (r *roleRepository) Update(id string, data *models.Role) error {
 	// 创建更新文档
 	updateDoc := bson.M{"$set": data}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, updateDoc)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), updateDoc)
 	if err != nil {
 		return err
 	}
@@ -197,7 +242,7 @@ func (r *roleRepository) Delete(id string) error {
 		return errors.New("invalid role ID format")
 	}
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	result, err := r.collection.DeleteOne(ctx, r.scope(bson.M{"_id": objectID}))
 	if err != nil {
 		return err
 	}
@@ -206,7 +251,39 @@ func (r *roleRepository) Delete(id string) error {
 		return errors.New("role not found")
 	}
 
-	return nil
+	// 从子角色的父角色中移除
+	_, err = r.collection.UpdateMany(
+		ctx,
+		bson.M{"parent_ids": objectID},
+		bson.M{
+			"$pull": bson.M{"parent_ids": objectID},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// 从职责分离规则中移除
+	_, err = r.db.Collection("sod_rules").UpdateMany(
+		ctx,
+		bson.M{"role_ids": objectID},
+		bson.M{"$pull": bson.M{"role_ids": objectID}},
+	)
+	if err != nil {
+		return err
+	}
+
+	// 从用户组的角色中移除
+	_, err = r.db.Collection("groups").UpdateMany(
+		ctx,
+		bson.M{"roles.role_id": objectID},
+		bson.M{
+			"$pull": bson.M{"roles": bson.M{"role_id": objectID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	return err
 }
 
 // AssignPermission 为角色分配权限
@@ -246,7 +323,7 @@ func (r *roleRepository) AssignPermission(roleID, permissionID string) error {
 		"$set":      bson.M{"updated_at": time.Now()},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": roleObjectID}, update)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": roleObjectID}), update)
 	if err != nil {
 		return err
 	}
@@ -279,7 +356,7 @@ func (r *roleRepository) RemovePermission(roleID, permissionID string) error {
 		"$set":  bson.M{"updated_at": time.Now()},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": roleObjectID}, update)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": roleObjectID}), update)
 	if err != nil {
 		return err
 	}
@@ -302,7 +379,7 @@ func (r *roleRepository) GetRolePermissions(roleID string) ([]models.RolePermiss
 	}
 
 	var role models.Role
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&role)
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&role)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("role not found")
@@ -323,9 +400,13 @@ func (r *roleRepository) GetRoleUsers(roleID string) ([]*models.User, error) {
 		return nil, errors.New("invalid role ID format")
 	}
 
-	// 查询拥有该角色的用户
+	// 查询拥有该角色的用户，限定租户时只查询该租户的成员
+	filter := bson.M{"roles.role_id": objectID}
+	if !r.tenantID.IsZero() {
+		filter["tenants.tenant_id"] = r.tenantID
+	}
 	userCollection := r.db.Collection("users")
-	cursor, err := userCollection.Find(ctx, bson.M{"roles.role_id": objectID})
+	cursor, err := userCollection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -338,3 +419,133 @@ func (r *roleRepository) GetRoleUsers(roleID string) ([]*models.User, error) {
 
 	return users, nil
 }
+
+// GetAncestors 获取角色的全部祖先角色
+func (r *roleRepository) GetAncestors(id string) ([]*models.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid role ID format")
+	}
+
+	// $graphLookup不会重复访问同一角色，父角色存在环时也能结束
+	pipeline := []bson.M{
+		{"$match": r.scope(bson.M{"_id": objectID})},
+		{"$graphLookup": bson.M{
+			"from":             "roles",
+			"startWith":        "$parent_ids",
+			"connectFromField": "parent_ids",
+			"connectToField":   "_id",
+			"as":               "ancestors",
+		}},
+		{"$unwind": "$ancestors"},
+		{"$replaceRoot": bson.M{"newRoot": "$ancestors"}},
+		{"$match": bson.M{"_id": bson.M{"$ne": objectID}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ancestors := make([]*models.Role, 0)
+	if err = cursor.All(ctx, &ancestors); err != nil {
+		return nil, err
+	}
+
+	return ancestors, nil
+}
+
+// GetDescendants 获取继承该角色的全部子孙角色
+func (r *roleRepository) GetDescendants(id string) ([]*models.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid role ID format")
+	}
+
+	pipeline := []bson.M{
+		{"$match": r.scope(bson.M{"_id": objectID})},
+		{"$graphLookup": bson.M{
+			"from":             "roles",
+			"startWith":        "$_id",
+			"connectFromField": "_id",
+			"connectToField":   "parent_ids",
+			"as":               "descendants",
+		}},
+		{"$unwind": "$descendants"},
+		{"$replaceRoot": bson.M{"newRoot": "$descendants"}},
+		{"$match": bson.M{"_id": bson.M{"$ne": objectID}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	descendants := make([]*models.Role, 0)
+	if err = cursor.All(ctx, &descendants); err != nil {
+		return nil, err
+	}
+
+	return descendants, nil
+}
+
+// SetParents 设置角色的父角色
+func (r *roleRepository) SetParents(id string, parentIDs []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid role ID format")
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		r.scope(bson.M{"_id": objectID}),
+		bson.M{"$set": bson.M{"parent_ids": parentIDs, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("role not found")
+	}
+
+	return nil
+}
+
+// SetElevation 设置角色的临时提权设置
+func (r *roleRepository) SetElevation(id string, elevation *models.RoleElevation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid role ID format")
+	}
+
+	update := bson.M{"$set": bson.M{"elevation": elevation, "updated_at": time.Now()}}
+	if elevation == nil {
+		update = bson.M{"$unset": bson.M{"elevation": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("role not found")
+	}
+
+	return nil
+}
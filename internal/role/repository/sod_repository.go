@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 职责分离规则类型
+const (
+	SoDStatic  = "static"
+	SoDDynamic = "dynamic"
+)
+
+// SoDRepository 职责分离规则数据访问接口
+type SoDRepository interface {
+	// Create 创建规则
+	Create(rule *models.SoDRule) error
+
+	// GetByID 通过ID获取规则
+	GetByID(id string) (*models.SoDRule, error)
+
+	// List 获取指定类型的规则，ruleType为空时返回全部规则
+	List(ruleType string) ([]*models.SoDRule, error)
+
+	// Delete 删除规则
+	Delete(id string) error
+
+	// ForTenant 返回只访问指定租户规则的仓储，创建的规则属于该租户；tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) SoDRepository
+}
+
+// sodRepository 职责分离规则仓储实现
+type sodRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	tenantID   primitive.ObjectID // 非零值时只访问该租户的规则
+}
+
+// NewSoDRepository 创建职责分离规则仓储
+func NewSoDRepository(db *mongo.Database) SoDRepository {
+	return &sodRepository{
+		db:         db,
+		collection: db.Collection("sod_rules"),
+	}
+}
+
+// ForTenant 返回只访问指定租户规则的仓储
+func (r *sodRepository) ForTenant(tenantID primitive.ObjectID) SoDRepository {
+	scoped := *r
+	scoped.tenantID = tenantID
+	return &scoped
+}
+
+// scope 为查询条件加上租户限定
+func (r *sodRepository) scope(filter bson.M) bson.M {
+	if !r.tenantID.IsZero() {
+		filter["tenant_id"] = r.tenantID
+	}
+	return filter
+}
+
+// Create 创建规则
+func (r *sodRepository) Create(rule *models.SoDRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rule.CreatedAt = time.Now()
+	if !r.tenantID.IsZero() {
+		tenantID := r.tenantID
+		rule.TenantID = &tenantID
+	}
+
+	result, err := r.collection.InsertOne(ctx, rule)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("sod rule name already exists")
+		}
+		return err
+	}
+
+	rule.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetByID 通过ID获取规则
+func (r *sodRepository) GetByID(id string) (*models.SoDRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid sod rule ID format")
+	}
+
+	var rule models.SoDRule
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&rule)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("sod rule not found")
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// List 获取指定类型的规则
+func (r *sodRepository) List(ruleType string) ([]*models.SoDRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := r.scope(bson.M{})
+	if ruleType != "" {
+		filter["type"] = ruleType
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules := make([]*models.SoDRule, 0)
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Delete 删除规则
+func (r *sodRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid sod rule ID format")
+	}
+
+	result, err := r.collection.DeleteOne(ctx, r.scope(bson.M{"_id": objectID}))
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("sod rule not found")
+	}
+
+	return nil
+}
@@ -1,7 +1,14 @@
 package service
 
 import (
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
 	"authcenter/internal/role/repository"
+	"authcenter/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // RoleService 角色业务逻辑接口
@@ -29,6 +36,16 @@ type RoleService interface {
 
 	// GetRolePermissions 获取角色权限
 	GetRolePermissions(roleID string) (interface{}, error)
+
+	// SetParents 设置角色的父角色，角色继承父角色及其祖先的权限
+	// 父角色级别不能高于本角色，且不能形成环
+	SetParents(roleID string, parentIDs []string, operatorID string) error
+
+	// SetElevation 设置角色的临时提权，maxDuration为单次提权的最长秒数，为0时不再允许申请
+	SetElevation(roleID string, maxDuration int64, approverRoleIDs []string, operatorID string) error
+
+	// ForTenant 返回只管理指定租户角色的服务，tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) RoleService
 }
 
 // roleService 角色服务实现
@@ -43,6 +60,13 @@ func NewRoleService(roleRepo repository.RoleRepository) RoleService {
 	}
 }
 
+// ForTenant 返回只管理指定租户角色的服务
+func (s *roleService) ForTenant(tenantID primitive.ObjectID) RoleService {
+	return &roleService{
+		roleRepo: s.roleRepo.ForTenant(tenantID),
+	}
+}
+
 // GetRoleByID 通过ID获取角色
 func (s *roleService) GetRoleByID(id string) (interface{}, error) {
 	// TODO: 实现获取角色逻辑
@@ -90,3 +114,132 @@ func (s *roleService) GetRolePermissions(roleID string) (interface{}, error) {
 	// TODO: 实现获取角色权限逻辑
 	return nil, nil
 }
+
+// SetParents 设置角色的父角色
+func (s *roleService) SetParents(roleID string, parentIDs []string, operatorID string) error {
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		return errors.New("角色不存在")
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(parentIDs))
+	parents := make([]primitive.ObjectID, 0, len(parentIDs))
+	for _, parentID := range parentIDs {
+		parent, err := s.roleRepo.GetByID(parentID)
+		if err != nil {
+			return errors.New("父角色不存在: " + parentID)
+		}
+		if seen[parent.ID] {
+			continue
+		}
+		seen[parent.ID] = true
+
+		if parent.ID == role.ID {
+			return errors.New("角色不能继承自身")
+		}
+		// 否则低级别角色可以通过继承获得高级别角色的权限，绕过分配角色时的级别限制
+		if parent.Level > role.Level {
+			return errors.New("父角色级别不能高于本角色: " + parent.Name)
+		}
+		if err := s.checkCycle(role, parent); err != nil {
+			return err
+		}
+
+		parents = append(parents, parent.ID)
+	}
+
+	if err := s.roleRepo.SetParents(roleID, parents); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("role_parents_changed", map[string]interface{}{
+		"role_id":     roleID,
+		"parent_ids":  parentIDs,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// SetElevation 设置角色的临时提权
+func (s *roleService) SetElevation(roleID string, maxDuration int64, approverRoleIDs []string, operatorID string) error {
+	if maxDuration < 0 {
+		return errors.New("提权时长不能为负数")
+	}
+
+	if _, err := s.roleRepo.GetByID(roleID); err != nil {
+		return errors.New("角色不存在")
+	}
+
+	var elevation *models.RoleElevation
+	if maxDuration > 0 {
+		elevation = &models.RoleElevation{MaxDuration: maxDuration}
+
+		seen := make(map[primitive.ObjectID]bool, len(approverRoleIDs))
+		for _, approverRoleID := range approverRoleIDs {
+			approverRole, err := s.roleRepo.GetByID(approverRoleID)
+			if err != nil {
+				return errors.New("审批角色不存在: " + approverRoleID)
+			}
+			if !seen[approverRole.ID] {
+				seen[approverRole.ID] = true
+				elevation.ApproverRoles = append(elevation.ApproverRoles, approverRole.ID)
+			}
+		}
+	}
+
+	if err := s.roleRepo.SetElevation(roleID, elevation); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("role_elevation_changed", map[string]interface{}{
+		"role_id":           roleID,
+		"max_duration":      maxDuration,
+		"approver_role_ids": approverRoleIDs,
+		"operator_id":       operatorID,
+	})
+
+	return nil
+}
+
+// checkCycle 检查role继承parent后是否形成环，即role是否已是parent的祖先
+func (s *roleService) checkCycle(role, parent *models.Role) error {
+	ancestors, err := s.roleRepo.GetAncestors(parent.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor.ID == role.ID {
+			return errors.New("角色继承关系存在环: " + role.Name + " -> " + parent.Name)
+		}
+	}
+	return nil
+}
+
+// MaxLevel 返回角色分配中最高的角色级别，已过期的分配不计入，没有有效角色时返回0
+func MaxLevel(roles repository.RoleRepository, assignments []models.UserRole) int {
+	level := 0
+	now := time.Now()
+	for _, assignment := range assignments {
+		if assignment.Expired(now) {
+			continue
+		}
+		role, err := roles.GetByID(assignment.RoleID.Hex())
+		if err != nil {
+			continue
+		}
+		if role.Level > level {
+			level = role.Level
+		}
+	}
+	return level
+}
+
+// CheckAssignable 检查操作者能否分配角色，角色级别不能高于操作者拥有的最高级别
+func CheckAssignable(roles repository.RoleRepository, operatorRoles []models.UserRole, role *models.Role) error {
+	if level := MaxLevel(roles, operatorRoles); role.Level > level {
+		return errors.New("不能分配级别高于自己的角色")
+	}
+	return nil
+}
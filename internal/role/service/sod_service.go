@@ -0,0 +1,253 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"authcenter/internal/models"
+	"authcenter/internal/role/repository"
+	userRepo "authcenter/internal/user/repository"
+	"authcenter/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateSoDRuleRequest 创建职责分离规则请求
+type CreateSoDRuleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Type        string   `json:"type"`     // static, dynamic
+	RoleIDs     []string `json:"role_ids"` // 互斥的角色，至少两个
+}
+
+// SoDViolation 用户当前违反的静态职责分离规则
+type SoDViolation struct {
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	RuleID   string   `json:"rule_id"`
+	RuleName string   `json:"rule_name"`
+	Roles    []string `json:"roles"` // 用户同时拥有的规则中的角色，含继承获得的
+}
+
+// SoDService 职责分离规则业务逻辑接口
+type SoDService interface {
+	// ListRules 获取全部规则
+	ListRules() ([]*models.SoDRule, error)
+
+	// CreateRule 创建规则，新规则不影响已有的角色分配，可通过ListViolations检查
+	CreateRule(req *CreateSoDRuleRequest, operatorID string) (*models.SoDRule, error)
+
+	// DeleteRule 删除规则
+	DeleteRule(id, operatorID string) error
+
+	// ListViolations 列出全部用户当前违反的静态规则，用户的角色包括直接分配和所属用户组的，已过期的角色分配不计入
+	ListViolations() ([]*SoDViolation, error)
+
+	// ForTenant 返回只管理指定租户规则的服务，tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) SoDService
+}
+
+// sodService 职责分离规则服务实现
+type sodService struct {
+	sodRepo  repository.SoDRepository
+	roleRepo repository.RoleRepository
+	userRepo userRepo.UserRepository
+}
+
+// NewSoDService 创建职责分离规则服务
+func NewSoDService(sodRepo repository.SoDRepository, roleRepo repository.RoleRepository, userRepo userRepo.UserRepository) SoDService {
+	return &sodService{
+		sodRepo:  sodRepo,
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// ForTenant 返回只管理指定租户规则的服务
+func (s *sodService) ForTenant(tenantID primitive.ObjectID) SoDService {
+	return &sodService{
+		sodRepo:  s.sodRepo.ForTenant(tenantID),
+		roleRepo: s.roleRepo.ForTenant(tenantID),
+		userRepo: s.userRepo.ForTenant(tenantID),
+	}
+}
+
+// ListRules 获取全部规则
+func (s *sodService) ListRules() ([]*models.SoDRule, error) {
+	return s.sodRepo.List("")
+}
+
+// CreateRule 创建规则
+func (s *sodService) CreateRule(req *CreateSoDRuleRequest, operatorID string) (*models.SoDRule, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("规则名称不能为空")
+	}
+
+	if req.Type != repository.SoDStatic && req.Type != repository.SoDDynamic {
+		return nil, errors.New("无效的规则类型")
+	}
+
+	operatorObjID, err := primitive.ObjectIDFromHex(operatorID)
+	if err != nil {
+		return nil, errors.New("无效的操作者ID")
+	}
+
+	rule := &models.SoDRule{
+		Name:        name,
+		Description: req.Description,
+		Type:        req.Type,
+		RoleIDs:     make([]primitive.ObjectID, 0, len(req.RoleIDs)),
+		CreatedBy:   operatorObjID,
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(req.RoleIDs))
+	for _, roleID := range req.RoleIDs {
+		role, err := s.roleRepo.GetByID(roleID)
+		if err != nil {
+			return nil, errors.New("角色不存在: " + roleID)
+		}
+		if !seen[role.ID] {
+			seen[role.ID] = true
+			rule.RoleIDs = append(rule.RoleIDs, role.ID)
+		}
+	}
+	if len(rule.RoleIDs) < 2 {
+		return nil, errors.New("互斥的角色至少需要两个")
+	}
+
+	if err := s.sodRepo.Create(rule); err != nil {
+		return nil, err
+	}
+
+	logger.SecurityEvent("sod_rule_created", map[string]interface{}{
+		"rule_id":     rule.ID.Hex(),
+		"name":        rule.Name,
+		"type":        rule.Type,
+		"role_ids":    req.RoleIDs,
+		"operator_id": operatorID,
+	})
+
+	return rule, nil
+}
+
+// DeleteRule 删除规则
+func (s *sodService) DeleteRule(id, operatorID string) error {
+	rule, err := s.sodRepo.GetByID(id)
+	if err != nil {
+		return errors.New("规则不存在")
+	}
+
+	if err := s.sodRepo.Delete(id); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("sod_rule_deleted", map[string]interface{}{
+		"rule_id":     id,
+		"name":        rule.Name,
+		"type":        rule.Type,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// ListViolations 列出违反静态规则的用户
+// 直接或通过用户组拥有规则中的角色或其子孙角色的用户才可能违反规则，只需检查这部分用户
+func (s *sodService) ListViolations() ([]*SoDViolation, error) {
+	rules, err := s.sodRepo.List(repository.SoDStatic)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]*SoDViolation, 0)
+	if len(rules) == 0 {
+		return violations, nil
+	}
+
+	candidateSet := make(map[primitive.ObjectID]bool)
+	for _, rule := range rules {
+		for _, roleID := range rule.RoleIDs {
+			if candidateSet[roleID] {
+				continue
+			}
+			candidateSet[roleID] = true
+
+			descendants, err := s.roleRepo.GetDescendants(roleID.Hex())
+			if err != nil {
+				return nil, err
+			}
+			for _, descendant := range descendants {
+				candidateSet[descendant.ID] = true
+			}
+		}
+	}
+
+	candidates := make([]primitive.ObjectID, 0, len(candidateSet))
+	for roleID := range candidateSet {
+		candidates = append(candidates, roleID)
+	}
+
+	users, err := s.userRepo.FindByRoleIDs(candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// 角色及其祖先角色，多个用户共用
+	expanded := make(map[primitive.ObjectID][]*models.Role)
+	expand := func(roleID primitive.ObjectID) []*models.Role {
+		if roles, ok := expanded[roleID]; ok {
+			return roles
+		}
+
+		var roles []*models.Role
+		if role, err := s.roleRepo.GetByID(roleID.Hex()); err == nil {
+			roles = append(roles, role)
+			if ancestors, err := s.roleRepo.GetAncestors(roleID.Hex()); err == nil {
+				roles = append(roles, ancestors...)
+			}
+		}
+		expanded[roleID] = roles
+		return roles
+	}
+
+	now := time.Now()
+	for i := range users {
+		user := &users[i]
+		groupRoles, err := s.userRepo.GroupRoles(user)
+		if err != nil {
+			return nil, err
+		}
+
+		held := make(map[primitive.ObjectID]string)
+		for _, userRole := range append(user.Roles, groupRoles...) {
+			if userRole.Expired(now) {
+				continue
+			}
+			for _, role := range expand(userRole.RoleID) {
+				held[role.ID] = role.Name
+			}
+		}
+
+		for _, rule := range rules {
+			names := make([]string, 0, len(rule.RoleIDs))
+			for _, roleID := range rule.RoleIDs {
+				if name, ok := held[roleID]; ok {
+					names = append(names, name)
+				}
+			}
+			if len(names) > 1 {
+				violations = append(violations, &SoDViolation{
+					UserID:   user.ID.Hex(),
+					Username: user.Username,
+					RuleID:   rule.ID.Hex(),
+					RuleName: rule.Name,
+					Roles:    names,
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
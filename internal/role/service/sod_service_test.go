@@ -0,0 +1,165 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	groupRepo "authcenter/internal/group/repository"
+	"authcenter/internal/models"
+	"authcenter/internal/role/repository"
+	"authcenter/internal/testutil"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sodTestEnv 使用内存仓储的职责分离规则服务
+type sodTestEnv struct {
+	svc   SoDService
+	rules *testutil.SoDRules
+	roles *testutil.Roles
+	users *testutil.Users
+}
+
+func newSoDTestEnv() *sodTestEnv {
+	env := &sodTestEnv{
+		rules: testutil.NewSoDRules(),
+		roles: testutil.NewRoles(),
+		users: testutil.NewUsers(),
+	}
+	env.svc = NewSoDService(env.rules, env.roles, env.users)
+	return env
+}
+
+// grant 角色分配，expiresAt为nil时长期有效
+func grant(role *models.Role, expiresAt *time.Time) models.UserRole {
+	return models.UserRole{RoleID: role.ID, RoleName: role.Name, ExpiresAt: expiresAt}
+}
+
+func TestCreateSoDRule(t *testing.T) {
+	env := newSoDTestEnv()
+	cashier := env.roles.Put(&models.Role{Name: "cashier"})
+	auditor := env.roles.Put(&models.Role{Name: "auditor"})
+	operatorID := primitive.NewObjectID().Hex()
+
+	rejected := []struct {
+		name string
+		req  *CreateSoDRuleRequest
+	}{
+		{"名称为空", &CreateSoDRuleRequest{Name: " ", Type: repository.SoDStatic, RoleIDs: []string{cashier.ID.Hex(), auditor.ID.Hex()}}},
+		{"无效的类型", &CreateSoDRuleRequest{Name: "payment", Type: "weak", RoleIDs: []string{cashier.ID.Hex(), auditor.ID.Hex()}}},
+		{"角色不存在", &CreateSoDRuleRequest{Name: "payment", Type: repository.SoDStatic, RoleIDs: []string{cashier.ID.Hex(), primitive.NewObjectID().Hex()}}},
+		{"去重后不足两个角色", &CreateSoDRuleRequest{Name: "payment", Type: repository.SoDStatic, RoleIDs: []string{cashier.ID.Hex(), cashier.ID.Hex()}}},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.svc.CreateRule(tt.req, operatorID); err == nil {
+				t.Fatal("创建成功，期望被拒绝")
+			}
+		})
+	}
+
+	rule, err := env.svc.CreateRule(&CreateSoDRuleRequest{
+		Name:    " payment ",
+		Type:    repository.SoDStatic,
+		RoleIDs: []string{cashier.ID.Hex(), auditor.ID.Hex(), auditor.ID.Hex()},
+	}, operatorID)
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	if rule.Name != "payment" || !reflect.DeepEqual(rule.RoleIDs, []primitive.ObjectID{cashier.ID, auditor.ID}) {
+		t.Fatalf("规则 = %+v，期望名称去除空白且角色去重", rule)
+	}
+}
+
+// 违反规则的检查包括继承的祖先角色，已过期的角色分配和动态规则不计入
+func TestListSoDViolations(t *testing.T) {
+	env := newSoDTestEnv()
+	cashier := env.roles.Put(&models.Role{Name: "cashier"})
+	auditor := env.roles.Put(&models.Role{Name: "auditor"})
+	approver := env.roles.Put(&models.Role{Name: "approver"})
+	seniorCashier := env.roles.Put(&models.Role{Name: "senior-cashier", ParentIDs: []primitive.ObjectID{cashier.ID}})
+
+	operatorID := primitive.NewObjectID().Hex()
+	static, err := env.svc.CreateRule(&CreateSoDRuleRequest{
+		Name:    "payment",
+		Type:    repository.SoDStatic,
+		RoleIDs: []string{cashier.ID.Hex(), auditor.ID.Hex()},
+	}, operatorID)
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	if _, err := env.svc.CreateRule(&CreateSoDRuleRequest{
+		Name:    "approval",
+		Type:    repository.SoDDynamic,
+		RoleIDs: []string{cashier.ID.Hex(), approver.ID.Hex()},
+	}, operatorID); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	direct := env.users.Put(&models.User{Username: "direct", Roles: []models.UserRole{grant(cashier, nil), grant(auditor, nil)}})
+	inherited := env.users.Put(&models.User{Username: "inherited", Roles: []models.UserRole{grant(seniorCashier, nil), grant(auditor, nil)}})
+	env.users.Put(&models.User{Username: "expired", Roles: []models.UserRole{grant(cashier, nil), grant(auditor, &expired)}})
+	env.users.Put(&models.User{Username: "dynamic", Roles: []models.UserRole{grant(cashier, nil), grant(approver, nil)}})
+
+	violations, err := env.svc.ListViolations()
+	if err != nil {
+		t.Fatalf("ListViolations: %v", err)
+	}
+
+	got := make(map[string][]string, len(violations))
+	for _, violation := range violations {
+		if violation.RuleID != static.ID.Hex() {
+			t.Errorf("违反了规则 %s，期望只检查静态规则", violation.RuleName)
+		}
+		sort.Strings(violation.Roles)
+		got[violation.UserID] = violation.Roles
+	}
+	want := map[string][]string{
+		direct.ID.Hex():    {"auditor", "cashier"},
+		inherited.ID.Hex(): {"auditor", "cashier"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("违反规则的用户 = %v，期望 %v", got, want)
+	}
+}
+
+// 用户直接分配的角色与通过用户组获得的角色一起检查
+func TestListSoDViolationsWithGroupRoles(t *testing.T) {
+	env := newSoDTestEnv()
+	groups := testutil.NewGroups()
+	env.users.SetGroups(groups)
+
+	cashier := env.roles.Put(&models.Role{Name: "cashier"})
+	auditor := env.roles.Put(&models.Role{Name: "auditor"})
+	if _, err := env.svc.CreateRule(&CreateSoDRuleRequest{
+		Name:    "payment",
+		Type:    repository.SoDStatic,
+		RoleIDs: []string{cashier.ID.Hex(), auditor.ID.Hex()},
+	}, primitive.NewObjectID().Hex()); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	direct := env.users.Put(&models.User{Username: "direct", Roles: []models.UserRole{grant(cashier, nil)}})
+	member := env.users.Put(&models.User{Username: "member", Profile: models.UserProfile{Department: "finance"}})
+	env.users.Put(&models.User{Username: "outsider", Profile: models.UserProfile{Department: "sales"}, Roles: []models.UserRole{grant(cashier, nil)}})
+
+	groups.Put(&models.Group{Name: "auditors", Type: groupRepo.GroupManual, Members: []primitive.ObjectID{direct.ID}, Roles: []models.UserRole{grant(auditor, nil)}})
+	groups.Put(&models.Group{Name: "finance", Type: groupRepo.GroupDynamic, Department: "finance", Roles: []models.UserRole{grant(cashier, nil), grant(auditor, nil)}})
+
+	violations, err := env.svc.ListViolations()
+	if err != nil {
+		t.Fatalf("ListViolations: %v", err)
+	}
+
+	got := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		got = append(got, violation.Username)
+	}
+	sort.Strings(got)
+	if want := []string{direct.Username, member.Username}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("违反规则的用户 = %v，期望 %v", got, want)
+	}
+}
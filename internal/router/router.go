@@ -1,6 +1,7 @@
 package router
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,7 +17,16 @@ import (
 	categoryRepo "authcenter/internal/category/repository"
 	categoryService "authcenter/internal/category/service"
 	"authcenter/internal/config"
+	elevationHandler "authcenter/internal/elevation/handler"
+	elevationRepo "authcenter/internal/elevation/repository"
+	elevationService "authcenter/internal/elevation/service"
+	groupHandler "authcenter/internal/group/handler"
+	groupRepo "authcenter/internal/group/repository"
+	groupService "authcenter/internal/group/service"
 	"authcenter/internal/middleware"
+	oauthHandler "authcenter/internal/oauth/handler"
+	oauthRepo "authcenter/internal/oauth/repository"
+	oauthService "authcenter/internal/oauth/service"
 	permissionHandler "authcenter/internal/permission/handler"
 	roleHandler "authcenter/internal/role/handler"
 	roleRepo "authcenter/internal/role/repository"
@@ -24,14 +34,24 @@ import (
 	tagHandler "authcenter/internal/tag/handler"
 	tagRepo "authcenter/internal/tag/repository"
 	tagService "authcenter/internal/tag/service"
+	tenantHandler "authcenter/internal/tenant/handler"
+	tenantRepo "authcenter/internal/tenant/repository"
+	tenantService "authcenter/internal/tenant/service"
 	userHandler "authcenter/internal/user/handler"
 	userRepo "authcenter/internal/user/repository"
 	userService "authcenter/internal/user/service"
+	verificationRepo "authcenter/internal/verification/repository"
+	verificationService "authcenter/internal/verification/service"
+	"authcenter/pkg/breach"
 	"authcenter/pkg/jwt"
+	"authcenter/pkg/mail"
+	"authcenter/pkg/password"
+	"authcenter/pkg/policy"
+	"authcenter/pkg/sms"
 )
 
 // Setup 设置路由
-func Setup(db *mongo.Database, cfg *config.Config) *gin.Engine {
+func Setup(db *mongo.Database, cfg *config.Config) (*gin.Engine, error) {
 	r := gin.Default()
 
 	// 添加安全中间件
@@ -47,42 +67,125 @@ func Setup(db *mongo.Database, cfg *config.Config) *gin.Engine {
 	r.Use(rateLimiter.RateLimit())
 
 	// 创建JWT管理器
-	jwtManager := jwt.NewManager(cfg.JWT.Secret, cfg.JWT.AccessTokenExpire, cfg.JWT.RefreshTokenExpire, cfg.JWT.Issuer)
+	jwtManager, err := newJWTManager(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
 
-	// 创建中间件
-	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
-	loginRateLimiter := middleware.NewRateLimiter(50, 1*time.Minute) // 登录限流：1分钟50次（开发调试用）
+	// 创建密码管理器
+	passwordManager, err := newPasswordManager(cfg.Security)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建泄露密码检查器
+	breachChecker, err := newBreachChecker(cfg.Breach)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建策略引擎
+	policyEngine, err := newPolicyEngine(cfg.Policy)
+	if err != nil {
+		return nil, err
+	}
 
 	// 创建Repository
 	userRepository := userRepo.NewUserRepository(db)
 	sessionRepository := authRepo.NewSessionRepository(db)
 	roleRepository := roleRepo.NewRoleRepository(db)
+	sodRepository := roleRepo.NewSoDRepository(db)
 	categoryRepository := categoryRepo.NewCategoryRepository(db)
 	tagRepository := tagRepo.NewTagRepository(db)
+	tenantRepository := tenantRepo.NewTenantRepository(db)
+	groupRepository := groupRepo.NewGroupRepository(db)
 	aiRepository := aiRepo.NewAIRepository(db)
+	clientRepository := oauthRepo.NewClientRepository(db)
+	codeRepository := oauthRepo.NewAuthorizationCodeRepository(db)
+	assertionRepository := oauthRepo.NewClientAssertionRepository(db)
+	revocationRepository := authRepo.NewRevocationRepository(db)
+	loginAttemptRepository := authRepo.NewLoginAttemptRepository(db)
+	webAuthnSessionRepository := authRepo.NewWebAuthnSessionRepository(db)
+	accessTokenRepository := authRepo.NewAccessTokenRepository(db)
+	elevationRepository := elevationRepo.NewElevationRepository(db)
+	verificationCodeRepository := verificationRepo.NewCodeRepository(db)
+	verificationTokenRepository := verificationRepo.NewTokenRepository(db)
 
 	// 创建Service
-	authSvc := authService.NewAuthService(userRepository, sessionRepository, roleRepository, jwtManager)
-	userSvc := userService.NewUserService(userRepository, roleRepository)
+	revocationSvc := authService.NewRevocationService(revocationRepository, cfg.JWT.AccessTokenExpire)
+	revocationSvc.Start(cfg.Security.RevocationSyncInterval)
+	sessionActivity := authService.NewSessionActivityTracker(sessionRepository, cfg.Security.SessionTouchInterval)
+	sessionCleaner := authService.NewSessionCleaner(sessionRepository, cfg.Security.SessionIdleTimeout)
+	sessionCleaner.Start(cfg.Security.SessionCleanupInterval)
+	mfaSvc := authService.NewMFAService(userRepository, cfg.JWT.Issuer)
+	passkeySvc, err := authService.NewPasskeyService(userRepository, webAuthnSessionRepository, cfg.WebAuthn)
+	if err != nil {
+		return nil, err
+	}
+	smsSender, err := newSMSSender(cfg.SMS)
+	if err != nil {
+		return nil, err
+	}
+	verificationSvc := verificationService.NewVerificationService(verificationCodeRepository, smsSender, cfg.SMS)
+	emailSvc := verificationService.NewEmailService(verificationTokenRepository, newMailSender(cfg.Mail), cfg.Mail)
+	authSvc := authService.NewAuthService(userRepository, sessionRepository, loginAttemptRepository, roleRepository, sodRepository, tenantRepository, groupRepository, jwtManager, passwordManager, breachChecker, revocationSvc, mfaSvc, passkeySvc, verificationSvc, emailSvc, cfg.Security)
+	accessTokenSvc := authService.NewAccessTokenService(accessTokenRepository, userRepository, roleRepository, sodRepository, groupRepository, cfg.Security.AccessTokenMaxLifetime)
+	userSvc := userService.NewUserService(userRepository, roleRepository, sessionRepository, revocationSvc)
+	roleGrantCleaner := userService.NewRoleGrantCleaner(userRepository)
+	roleGrantCleaner.Start(cfg.Security.RoleGrantCleanupInterval)
 	roleSvc := roleService.NewRoleService(roleRepository)
+	sodSvc := roleService.NewSoDService(sodRepository, roleRepository, userRepository)
+	elevationSvc := elevationService.NewElevationService(elevationRepository, userRepository, roleRepository, cfg.Security.ElevationRequestTTL)
 	categorySvc := categoryService.NewCategoryService(categoryRepository)
 	tagSvc := tagService.NewTagService(tagRepository)
+	tenantSvc := tenantService.NewTenantService(tenantRepository, userRepository, revocationSvc)
+	groupSvc := groupService.NewGroupService(groupRepository, userRepository, roleRepository, sodRepository)
 	aiSvc := aiService.NewAIService(aiRepository)
+	oauthSvc := oauthService.NewOAuthService(clientRepository, codeRepository, assertionRepository, userRepository, roleRepository, sessionRepository, authSvc, revocationSvc, jwtManager, cfg.JWT.Algorithm, cfg.OAuth)
+
+	// 创建中间件
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, revocationSvc, sessionActivity, accessTokenSvc, policyEngine)
+	loginRateLimiter := middleware.NewRateLimiter(50, 1*time.Minute) // 登录限流：1分钟50次（开发调试用）
 
 	// 创建Handler
 	authHdl := handler.NewAuthHandler(authSvc)
+	jwksHdl := handler.NewJWKSHandler(jwtManager)
+	mfaHdl := handler.NewMFAHandler(mfaSvc)
+	passkeyHdl := handler.NewPasskeyHandler(passkeySvc)
+	accessTokenHdl := handler.NewAccessTokenHandler(accessTokenSvc)
 	userHdl := userHandler.NewUserHandler(userSvc)
 	roleHdl := roleHandler.NewRoleHandler(roleSvc)
+	sodHdl := roleHandler.NewSoDHandler(sodSvc)
+	elevationHdl := elevationHandler.NewElevationHandler(elevationSvc)
 	permissionHdl := permissionHandler.NewPermissionHandler()
 	categoryHdl := categoryHandler.NewCategoryHandler(categorySvc)
 	tagHdl := tagHandler.NewTagHandler(tagSvc)
+	tenantHdl := tenantHandler.NewTenantHandler(tenantSvc)
+	groupHdl := groupHandler.NewGroupHandler(groupSvc)
 	aiHdl := aiHandler.NewAIHandler(aiSvc)
+	oauthHdl := oauthHandler.NewOAuthHandler(oauthSvc, cfg.OAuth.LoginURL)
 
 	// 健康检查
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok", "service": "AuthCenter"})
 	})
 
+	// 公钥集，供下游服务离线验证Token
+	r.GET("/.well-known/jwks.json", jwksHdl.GetJWKS)
+	r.GET("/.well-known/openid-configuration", oauthHdl.Discovery)
+
+	// OAuth2/OpenID Connect协议端点
+	oauth := r.Group("/oauth")
+	{
+		oauth.GET("/authorize", authMiddleware.OptionalAuth(), oauthHdl.Authorize)
+		oauth.POST("/authorize", authMiddleware.RequireAuth(), authMiddleware.RequireSubjectType(jwt.SubjectTypeUser), authMiddleware.RejectAccessTokens(), authMiddleware.RejectClientTokens(), oauthHdl.AuthorizeConsent)
+		oauth.POST("/token", oauthHdl.Token)
+		oauth.POST("/introspect", oauthHdl.Introspect)
+		oauth.POST("/revoke", oauthHdl.Revoke)
+		oauth.GET("/userinfo", authMiddleware.RequireAuth(), oauthHdl.UserInfo)
+		oauth.POST("/userinfo", authMiddleware.RequireAuth(), oauthHdl.UserInfo)
+	}
+
 	// API路由组
 	api := r.Group("/api/v1")
 
@@ -90,7 +193,14 @@ func Setup(db *mongo.Database, cfg *config.Config) *gin.Engine {
 	auth := api.Group("/auth")
 	{
 		auth.POST("/register", authHdl.Register)
+		auth.POST("/sms/code", loginRateLimiter.RateLimit(), authHdl.SendSMSCode)
 		auth.POST("/login", loginRateLimiter.RateLimit(), authHdl.Login) // 登录限流
+		auth.POST("/mfa/verify", loginRateLimiter.RateLimit(), authHdl.VerifyMFA)
+		auth.POST("/passkey/begin", loginRateLimiter.RateLimit(), passkeyHdl.BeginLogin)
+		auth.POST("/password/expired", loginRateLimiter.RateLimit(), authHdl.ChangeExpiredPassword)
+		auth.POST("/password/forgot", loginRateLimiter.RateLimit(), authHdl.ForgotPassword)
+		auth.POST("/password/reset", loginRateLimiter.RateLimit(), authHdl.ResetPassword)
+		auth.POST("/email/verify", authHdl.VerifyEmail)
 		auth.POST("/refresh", authHdl.RefreshToken)
 		auth.POST("/verify", authHdl.VerifyToken)
 		auth.POST("/logout", authHdl.Logout)
@@ -100,16 +210,68 @@ func Setup(db *mongo.Database, cfg *config.Config) *gin.Engine {
 	protected := api.Group("")
 	protected.Use(authMiddleware.RequireAuth())
 	{
-		// 用户管理
+		// 当前用户
+		// 账号操作只允许用户本人在第一方登录获得的令牌，不接受个人访问令牌和第三方应用令牌，避免泄露的令牌被用来接管账号
+		me := protected.Group("/me")
+		me.Use(accountGuards(authMiddleware)...)
+		{
+			me.PUT("/password", authHdl.ChangePassword)
+			me.PUT("/email", authHdl.ChangeEmail)
+			me.POST("/email/verification", authHdl.ResendEmailVerification)
+			me.POST("/mfa/totp", mfaHdl.BeginTOTPEnrollment)
+			me.POST("/mfa/totp/confirm", mfaHdl.ConfirmTOTPEnrollment)
+			me.POST("/mfa/recovery-codes", mfaHdl.RegenerateRecoveryCodes)
+			me.DELETE("/mfa", mfaHdl.DisableMFA)
+			me.GET("/passkeys", passkeyHdl.ListPasskeys)
+			me.POST("/passkeys/register/begin", passkeyHdl.BeginRegistration)
+			me.POST("/passkeys/register/finish", passkeyHdl.FinishRegistration)
+			me.DELETE("/passkeys/:id", passkeyHdl.RemovePasskey)
+			me.GET("/sessions", authHdl.ListSessions)
+			me.DELETE("/sessions", authHdl.RevokeOtherSessions)
+			me.DELETE("/sessions/:id", authHdl.RevokeSession)
+			me.PUT("/active-roles", authHdl.SetActiveRoles)
+			me.GET("/tokens", accessTokenHdl.ListAccessTokens)
+			me.POST("/tokens", accessTokenHdl.CreateAccessToken)
+			me.DELETE("/tokens/:id", accessTokenHdl.RevokeAccessToken)
+			me.GET("/elevations", elevationHdl.ListMyElevations)
+			me.POST("/elevations", elevationHdl.RequestElevation)
+			me.DELETE("/elevations/:id", elevationHdl.CancelElevation)
+			me.GET("/tenants", tenantHdl.ListMyTenants)
+		}
+
+		// 租户管理，租户之间的用户、角色、类别和标签互相隔离，只能使用不限定租户的平台令牌管理
+		tenants := protected.Group("/tenants")
+		tenants.Use(authMiddleware.RequireSubjectType(jwt.SubjectTypeUser), authMiddleware.RequirePlatformScope(), authMiddleware.RequirePermission("tenant", "MANAGE"))
+		{
+			tenants.GET("", tenantHdl.ListTenants)
+			tenants.POST("", tenantHdl.CreateTenant)
+			tenants.GET("/:id/members", tenantHdl.ListMembers)
+			tenants.POST("/:id/members", tenantHdl.AddMember)
+			tenants.DELETE("/:id/members/:user_id", tenantHdl.RemoveMember)
+		}
+
+		// 临时提权审批，审批人不能审批自己的申请
+		elevations := protected.Group("/elevations")
+		elevations.Use(authMiddleware.RequireSubjectType(jwt.SubjectTypeUser), authMiddleware.RejectAccessTokens(), authMiddleware.RequirePermission("elevation", "APPROVE"))
+		{
+			elevations.GET("", elevationHdl.ListPendingElevations)
+			elevations.POST("/:id/approve", elevationHdl.ApproveElevation)
+			elevations.POST("/:id/deny", elevationHdl.DenyElevation)
+		}
+
+		// 用户管理，租户令牌只能访问该租户的成员；状态、解锁和多因素认证作用于整个账号，只能使用平台令牌
 		users := protected.Group("/users")
 		{
 			users.GET("", authMiddleware.RequirePermission("user", "READ"), userHdl.GetUsers)
-			users.GET("/:id", userHdl.GetUser)
-			users.PUT("/:id", userHdl.UpdateUser)
-			users.DELETE("/:id", authMiddleware.RequirePermission("user", "DELETE"), userHdl.DeleteUser)
+			users.GET("/:id", authMiddleware.RequireAuthorization("user", "READ", userResource(userRepository)), userHdl.GetUser)
+			users.PUT("/:id", authMiddleware.RequireAuthorization("user", "UPDATE", userResource(userRepository)), userHdl.UpdateUser)
+			users.DELETE("/:id", authMiddleware.RequirePlatformScope(), authMiddleware.RequirePermission("user", "DELETE"), userHdl.DeleteUser)
 			users.POST("/:id/roles", authMiddleware.RequirePermission("user", "MANAGE"), userHdl.AssignRole)
 			users.DELETE("/:id/roles/:role_id", authMiddleware.RequirePermission("user", "MANAGE"), userHdl.RemoveRole)
-			users.GET("/:id/permissions", userHdl.GetUserPermissions)
+			users.PUT("/:id/status", authMiddleware.RequirePlatformScope(), authMiddleware.RequirePermission("user", "MANAGE"), userHdl.UpdateUserStatus)
+			users.POST("/:id/unlock", authMiddleware.RequirePlatformScope(), authMiddleware.RequirePermission("user", "MANAGE"), userHdl.UnlockUser)
+			users.DELETE("/:id/mfa", authMiddleware.RequirePlatformScope(), authMiddleware.RequirePermission("user", "MANAGE"), mfaHdl.ResetUserMFA)
+			users.GET("/:id/permissions", authMiddleware.RequireAuthorization("user", "READ", userResource(userRepository)), userHdl.GetUserPermissions)
 		}
 
 		// 角色管理
@@ -123,11 +285,38 @@ func Setup(db *mongo.Database, cfg *config.Config) *gin.Engine {
 			roles.DELETE("/:id", roleHdl.DeleteRole)
 			roles.POST("/:id/permissions", roleHdl.AssignPermission)
 			roles.DELETE("/:id/permissions/:permission_id", roleHdl.RemovePermission)
+			roles.PUT("/:id/parents", roleHdl.SetParents)
+			roles.PUT("/:id/elevation", roleHdl.SetElevation)
+		}
+
+		// 职责分离规则，静态规则在分配角色时检查，动态规则在签发令牌时检查
+		sod := protected.Group("/sod-rules")
+		sod.Use(authMiddleware.RequirePermission("role", "MANAGE"))
+		{
+			sod.GET("", sodHdl.ListRules)
+			sod.POST("", sodHdl.CreateRule)
+			sod.GET("/violations", sodHdl.ListViolations)
+			sod.DELETE("/:id", sodHdl.DeleteRule)
+		}
+
+		// 用户组，组的角色在成员签发或刷新Token时生效
+		groups := protected.Group("/groups")
+		groups.Use(authMiddleware.RequirePermission("group", "MANAGE"))
+		{
+			groups.GET("", groupHdl.ListGroups)
+			groups.POST("", groupHdl.CreateGroup)
+			groups.GET("/:id", groupHdl.GetGroup)
+			groups.PUT("/:id", groupHdl.UpdateGroup)
+			groups.DELETE("/:id", groupHdl.DeleteGroup)
+			groups.POST("/:id/members", groupHdl.AddMember)
+			groups.DELETE("/:id/members/:user_id", groupHdl.RemoveMember)
+			groups.POST("/:id/roles", groupHdl.AssignRole)
+			groups.DELETE("/:id/roles/:role_id", groupHdl.RemoveRole)
 		}
 
 		// 权限管理
 		permissions := protected.Group("/permissions")
-		permissions.Use(authMiddleware.RequirePermission("permission", "MANAGE"))
+		permissions.Use(authMiddleware.RequirePlatformScope(), authMiddleware.RequirePermission("permission", "MANAGE"))
 		{
 			permissions.GET("", permissionHdl.GetPermissions)
 			permissions.POST("", permissionHdl.CreatePermission)
@@ -153,12 +342,23 @@ func Setup(db *mongo.Database, cfg *config.Config) *gin.Engine {
 			tags.GET("", tagHdl.GetTags)
 			tags.POST("", authMiddleware.RequirePermission("tag", "CREATE"), tagHdl.CreateTag)
 			tags.GET("/:id", tagHdl.GetTag)
-			tags.PUT("/:id", tagHdl.UpdateTag)    // 权限检查在handler内部处理
-			tags.DELETE("/:id", tagHdl.DeleteTag) // 权限检查在handler内部处理
+			tags.PUT("/:id", authMiddleware.RequireAuthorization("tag", "UPDATE", tagResource(tagRepository)), tagHdl.UpdateTag)
+			tags.DELETE("/:id", authMiddleware.RequireAuthorization("tag", "DELETE", tagResource(tagRepository)), tagHdl.DeleteTag)
 			tags.GET("/:id/documents", tagHdl.GetTagDocuments)
 			tags.GET("/popular", tagHdl.GetPopularTags)
 		}
 
+		// OAuth客户端管理
+		oauthClients := protected.Group("/oauth/clients")
+		oauthClients.Use(authMiddleware.RequirePlatformScope(), authMiddleware.RequirePermission("system", "CONFIG"))
+		{
+			oauthClients.GET("", oauthHdl.ListClients)
+			oauthClients.POST("", oauthHdl.CreateClient)
+			oauthClients.DELETE("/:client_id", oauthHdl.DeleteClient)
+			oauthClients.POST("/:client_id/roles", oauthHdl.AssignClientRole)
+			oauthClients.DELETE("/:client_id/roles/:role_id", oauthHdl.RemoveClientRole)
+		}
+
 		// AI助手
 		ai := protected.Group("/ai")
 		ai.Use(authMiddleware.RequirePermission("ai", "USE"))
@@ -173,5 +373,138 @@ func Setup(db *mongo.Database, cfg *config.Config) *gin.Engine {
 	// 静态文件服务 - 用于测试页面
 	r.Static("/test", "./test")
 
-	return r
+	return r, nil
+}
+
+// accountGuards 账号操作的中间件，只接受用户本人在第一方登录获得的令牌，须在RequireAuth之后使用
+func accountGuards(authMiddleware *middleware.AuthMiddleware) []gin.HandlerFunc {
+	return []gin.HandlerFunc{
+		authMiddleware.RequireSubjectType(jwt.SubjectTypeUser),
+		authMiddleware.RejectAccessTokens(),
+		authMiddleware.RejectClientTokens(),
+	}
+}
+
+// newJWTManager 根据配置的签名算法创建JWT管理器
+func newJWTManager(cfg config.JWTConfig) (jwt.Manager, error) {
+	if cfg.Algorithm == "" || cfg.Algorithm == jwt.AlgorithmHS256 {
+		return jwt.NewManager(cfg.Secret, cfg.AccessTokenExpire, cfg.RefreshTokenExpire, cfg.Issuer), nil
+	}
+
+	// 退役密钥至少保留到其签发的最长有效期Token过期
+	retention := cfg.RefreshTokenExpire
+	if cfg.AccessTokenExpire > retention {
+		retention = cfg.AccessTokenExpire
+	}
+
+	keyRing, err := jwt.NewKeyRing(cfg.Algorithm, jwt.NewFileKeyStore(cfg.KeyFile), retention)
+	if err != nil {
+		return nil, err
+	}
+	keyRing.StartRotation(cfg.KeyRotationInterval)
+
+	return jwt.NewManagerWithKeyRing(keyRing, cfg.AccessTokenExpire, cfg.RefreshTokenExpire, cfg.Issuer), nil
+}
+
+// newPasswordManager 根据安全配置创建密码管理器
+func newPasswordManager(cfg config.SecurityConfig) (password.Manager, error) {
+	return password.NewManager(password.Config{
+		Algorithm:     cfg.PasswordHashAlgorithm,
+		BcryptCost:    cfg.BcryptCost,
+		Argon2Memory:  cfg.Argon2Memory,
+		Argon2Time:    cfg.Argon2Time,
+		Argon2Threads: cfg.Argon2Threads,
+	})
+}
+
+// newBreachChecker 根据配置创建泄露密码检查器，未配置时返回nil
+func newBreachChecker(cfg config.BreachConfig) (breach.Checker, error) {
+	switch cfg.Source {
+	case "":
+		return nil, nil
+	case "dump":
+		return breach.NewDumpChecker(cfg.Path, cfg.MinCount)
+	case "bloom":
+		return breach.NewBloomChecker(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unsupported breach source: %s", cfg.Source)
+	}
+}
+
+// newSMSSender 根据配置创建短信发送器，未知的服务商返回错误，避免验证码被静默写入日志
+func newSMSSender(cfg config.SMSConfig) (sms.SMSSender, error) {
+	switch cfg.Provider {
+	case "log":
+		return sms.NewLogSender(), nil
+	case "file":
+		return sms.NewFileSender(cfg.OutboxFile), nil
+	default:
+		return nil, fmt.Errorf("unsupported sms provider: %s", cfg.Provider)
+	}
+}
+
+// newMailSender 根据配置创建邮件发送器
+func newMailSender(cfg config.MailConfig) mail.MailSender {
+	if cfg.Provider == "smtp" {
+		return mail.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.Username, cfg.Password, cfg.From)
+	}
+	return mail.NewFileSender(cfg.OutboxFile)
+}
+
+// newPolicyEngine 根据配置的规则创建属性访问控制策略引擎
+func newPolicyEngine(cfg config.PolicyConfig) (*policy.Engine, error) {
+	rules := make([]policy.Rule, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		conditions := make([]policy.Condition, 0, len(rule.Conditions))
+		for _, condition := range rule.Conditions {
+			conditions = append(conditions, policy.Condition{
+				Attribute: condition.Attribute,
+				Operator:  condition.Operator,
+				Values:    condition.Values,
+				Ref:       condition.Ref,
+			})
+		}
+
+		rules = append(rules, policy.Rule{
+			Name:       rule.Name,
+			Effect:     rule.Effect,
+			Resources:  rule.Resources,
+			Actions:    rule.Actions,
+			Conditions: conditions,
+		})
+	}
+
+	return policy.NewEngine(rules)
+}
+
+// userResource 加载路径参数id指定的用户，用户本人为其所有者；租户令牌只能加载该租户的成员
+func userResource(repo userRepo.UserRepository) middleware.ResourceLoader {
+	return func(c *gin.Context) (*policy.Resource, error) {
+		user, err := repo.ForTenant(middleware.TenantID(c)).GetByID(c.Param("id"))
+		if err != nil {
+			return nil, err
+		}
+
+		return &policy.Resource{
+			ID:         user.ID.Hex(),
+			Owner:      user.ID.Hex(),
+			Status:     user.Status,
+			Attributes: map[string]string{"department": user.Profile.Department},
+		}, nil
+	}
+}
+
+// tagResource 加载路径参数id指定的标签，创建者为其所有者；租户令牌只能加载该租户的标签
+func tagResource(repo tagRepo.TagRepository) middleware.ResourceLoader {
+	return func(c *gin.Context) (*policy.Resource, error) {
+		tag, err := repo.ForTenant(middleware.TenantID(c)).GetByID(c.Param("id"))
+		if err != nil {
+			return nil, err
+		}
+
+		return &policy.Resource{
+			ID:    tag.ID.Hex(),
+			Owner: tag.CreatedBy.Hex(),
+		}, nil
+	}
 }
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"authcenter/internal/config"
+	"authcenter/internal/middleware"
+	"authcenter/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubAccessTokens 将acp_开头的令牌认证为个人访问令牌
+type stubAccessTokens struct{}
+
+func (stubAccessTokens) IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, "acp_")
+}
+
+func (stubAccessTokens) Authenticate(ctx context.Context, token, ip string) (*jwt.Claims, error) {
+	return &jwt.Claims{UserID: "user-1", TokenType: jwt.TokenTypePersonalAccess}, nil
+}
+
+func TestAccountGuardsRejectDelegatedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := jwt.NewManager("test-secret", time.Hour, 24*time.Hour, "test")
+	authMiddleware := middleware.NewAuthMiddleware(manager, nil, nil, stubAccessTokens{}, nil)
+
+	r := gin.New()
+	me := r.Group("/me", authMiddleware.RequireAuth())
+	me.Use(accountGuards(authMiddleware)...)
+	me.POST("/tokens", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	issue := func(claims *jwt.Claims) string {
+		token, _, err := manager.GenerateAccessToken(claims)
+		if err != nil {
+			t.Fatalf("GenerateAccessToken: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"第一方登录会话", issue(&jwt.Claims{UserID: "user-1", SessionID: "session-1"}), http.StatusOK},
+		{"第三方应用令牌", issue(&jwt.Claims{UserID: "user-1", ClientID: "client-1", Scope: "openid profile"}), http.StatusForbidden},
+		{"只带授权范围的令牌", issue(&jwt.Claims{UserID: "user-1", Scope: "openid"}), http.StatusForbidden},
+		{"服务账号令牌", issue(&jwt.Claims{UserID: "client-1", SubjectType: jwt.SubjectTypeService}), http.StatusForbidden},
+		{"个人访问令牌", "acp_token", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/me/tokens", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("POST /me/tokens = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewSMSSender(t *testing.T) {
+	tests := []struct {
+		provider string
+		wantErr  bool
+	}{
+		{"log", false},
+		{"file", false},
+		{"", true},
+		{"aliyun", true},
+	}
+
+	for _, tt := range tests {
+		sender, err := newSMSSender(config.SMSConfig{Provider: tt.provider, OutboxFile: "sms_outbox.jsonl"})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newSMSSender(%q) error = %v, wantErr %v", tt.provider, err, tt.wantErr)
+		}
+		if err == nil && sender == nil {
+			t.Errorf("newSMSSender(%q) 返回了空的发送器", tt.provider)
+		}
+	}
+}
@@ -46,12 +46,16 @@ type TagRepository interface {
 
 	// GetByNames 通过名称列表获取标签
 	GetByNames(names []string) ([]*models.Tag, error)
+
+	// ForTenant 返回只访问指定租户标签的仓储，创建的标签属于该租户；tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) TagRepository
 }
 
 // tagRepository 标签仓储实现
 type tagRepository struct {
 	db         *mongo.Database
 	collection *mongo.Collection
+	tenantID   primitive.ObjectID // 非零值时只访问该租户的标签
 }
 
 // NewTagRepository 创建标签仓储
@@ -62,6 +66,30 @@ func NewTagRepository(db *mongo.Database) TagRepository {
 	}
 }
 
+// ForTenant 返回只访问指定租户标签的仓储
+func (r *tagRepository) ForTenant(tenantID primitive.ObjectID) TagRepository {
+	scoped := *r
+	scoped.tenantID = tenantID
+	return &scoped
+}
+
+// scope 为查询条件加上租户限定
+func (r *tagRepository) scope(filter bson.M) bson.M {
+	if !r.tenantID.IsZero() {
+		filter["tenant_id"] = r.tenantID
+	}
+	return filter
+}
+
+// scopeName 按名称查询的租户限定，不限定租户时只查找不属于任何租户的标签
+func (r *tagRepository) scopeName(filter bson.M) bson.M {
+	if r.tenantID.IsZero() {
+		filter["tenant_id"] = bson.M{"$exists": false}
+		return filter
+	}
+	return r.scope(filter)
+}
+
 // Create 创建标签
 func (r *tagRepository) Create(tag *models.Tag) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -77,6 +105,11 @@ func (r *tagRepository) Create(tag *models.Tag) error {
 		tag.UsageCount = 0
 	}
 
+	if !r.tenantID.IsZero() {
+		tenantID := r.tenantID
+		tag.TenantID = &tenantID
+	}
+
 	// 插入标签
 	result, err := r.collection.InsertOne(ctx, tag)
 	if err != nil {
@@ -99,7 +132,7 @@ func (r *tagRepository) GetByID(id string) (*models.Tag, error) {
 	}
 
 	var tag models.Tag
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&tag)
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&tag)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("tag not found")
@@ -116,7 +149,7 @@ func (r *tagRepository) GetByName(name string) (*models.Tag, error) {
 	defer cancel()
 
 	var tag models.Tag
-	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&tag)
+	err := r.collection.FindOne(ctx, r.scopeName(bson.M{"name": name})).Decode(&tag)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("tag not found")
@@ -142,7 +175,7 @@ func (r *tagRepository) List(page, pageSize int) ([]*models.Tag, int64, error) {
 	findOptions.SetSort(bson.D{{Key: "usage_count", Value: -1}, {Key: "created_at", Value: -1}}) // 按使用次数和创建时间排序
 
 	// 查询标签
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{}), findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -154,7 +187,7 @@ func (r *tagRepository) List(page, pageSize int) ([]*models.Tag, int64, error) {
 	}
 
 	// 获取总数
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, r.scope(bson.M{}))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -175,7 +208,7 @@ func (r *tagRepository) Update(id string, data *models.Tag) error {
 	// 创建更新文档
 	updateDoc := bson.M{"$set": data}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, updateDoc)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), updateDoc)
 	if err != nil {
 		return err
 	}
@@ -197,7 +230,7 @@ func (r *tagRepository) Delete(id string) error {
 		return errors.New("invalid tag ID format")
 	}
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	result, err := r.collection.DeleteOne(ctx, r.scope(bson.M{"_id": objectID}))
 	if err != nil {
 		return err
 	}
@@ -232,7 +265,7 @@ func (r *tagRepository) Search(keyword string, page, pageSize int) ([]*models.Ta
 	findOptions.SetSort(bson.D{{Key: "usage_count", Value: -1}}) // 按使用次数排序
 
 	// 查询标签
-	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	cursor, err := r.collection.Find(ctx, r.scope(filter), findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -244,7 +277,7 @@ func (r *tagRepository) Search(keyword string, page, pageSize int) ([]*models.Ta
 	}
 
 	// 获取总数
-	total, err := r.collection.CountDocuments(ctx, filter)
+	total, err := r.collection.CountDocuments(ctx, r.scope(filter))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -268,7 +301,7 @@ func (r *tagRepository) GetPopularTags(limit int) ([]*models.Tag, error) {
 	findOptions.SetLimit(int64(limit))
 	findOptions.SetSort(bson.D{{Key: "usage_count", Value: -1}}) // 按使用次数倒序
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{}), findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -297,7 +330,7 @@ func (r *tagRepository) UpdateUsageCount(id string, increment int64) error {
 		"$set": bson.M{"last_used_at": time.Now()},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
 	if err != nil {
 		return err
 	}
@@ -314,7 +347,7 @@ func (r *tagRepository) GetByNames(names []string) ([]*models.Tag, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := r.collection.Find(ctx, bson.M{"name": bson.M{"$in": names}})
+	cursor, err := r.collection.Find(ctx, r.scopeName(bson.M{"name": bson.M{"$in": names}}))
 	if err != nil {
 		return nil, err
 	}
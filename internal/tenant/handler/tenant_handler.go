@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"authcenter/internal/tenant/service"
+	"authcenter/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddMemberRequest 添加租户成员请求
+type AddMemberRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// TenantHandler 租户处理器
+type TenantHandler struct {
+	tenantService service.TenantService
+}
+
+// NewTenantHandler 创建租户处理器
+func NewTenantHandler(tenantService service.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+	}
+}
+
+// ListTenants 获取全部租户
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantService.ListTenants(c)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取租户列表失败", err.Error())
+		return
+	}
+
+	response.Success(c, tenants)
+}
+
+// CreateTenant 创建租户
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req service.CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	tenant, err := h.tenantService.CreateTenant(c, &req, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "创建租户失败", err.Error())
+		return
+	}
+
+	response.Success(c, tenant)
+}
+
+// ListMyTenants 获取当前用户所属的租户，登录或刷新Token时可以切换到其中任一租户
+func (h *TenantHandler) ListMyTenants(c *gin.Context) {
+	tenants, err := h.tenantService.ListMyTenants(c, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "获取租户列表失败", err.Error())
+		return
+	}
+
+	response.Success(c, tenants)
+}
+
+// ListMembers 获取租户成员
+func (h *TenantHandler) ListMembers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	users, total, err := h.tenantService.ListMembers(c, c.Param("id"), page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "获取租户成员失败", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"items": users, "total": total})
+}
+
+// AddMember 添加租户成员
+func (h *TenantHandler) AddMember(c *gin.Context) {
+	var req AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.tenantService.AddMember(c, c.Param("id"), req.UserID, c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "添加租户成员失败", err.Error())
+		return
+	}
+
+	response.Success(c, "添加成功")
+}
+
+// RemoveMember 移除租户成员
+func (h *TenantHandler) RemoveMember(c *gin.Context) {
+	if err := h.tenantService.RemoveMember(c, c.Param("id"), c.Param("user_id"), c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "移除租户成员失败", err.Error())
+		return
+	}
+
+	response.Success(c, "移除成功")
+}
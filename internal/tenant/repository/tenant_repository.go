@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrTenantExists 租户标识已被使用
+var ErrTenantExists = errors.New("tenant name already exists")
+
+// TenantRepository 租户数据访问接口
+type TenantRepository interface {
+	// Create 创建租户，标识已被使用时返回ErrTenantExists
+	Create(ctx context.Context, tenant *models.Tenant) error
+
+	// GetByID 通过ID获取租户
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Tenant, error)
+
+	// GetByName 通过标识获取租户
+	GetByName(ctx context.Context, name string) (*models.Tenant, error)
+
+	// List 获取全部租户，按标识排序
+	List(ctx context.Context) ([]*models.Tenant, error)
+
+	// ListByIDs 获取指定的租户，按标识排序
+	ListByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.Tenant, error)
+}
+
+// tenantRepository 租户仓储实现
+type tenantRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewTenantRepository 创建租户仓储
+func NewTenantRepository(db *mongo.Database) TenantRepository {
+	return &tenantRepository{
+		db:         db,
+		collection: db.Collection("tenants"),
+	}
+}
+
+// Create 创建租户
+func (r *tenantRepository) Create(ctx context.Context, tenant *models.Tenant) error {
+	result, err := r.collection.InsertOne(ctx, tenant)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTenantExists
+		}
+		return err
+	}
+
+	if id, ok := result.InsertedID.(primitive.ObjectID); ok {
+		tenant.ID = id
+	}
+	return nil
+}
+
+// GetByID 通过ID获取租户
+func (r *tenantRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Tenant, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetByName 通过标识获取租户
+func (r *tenantRepository) GetByName(ctx context.Context, name string) (*models.Tenant, error) {
+	return r.findOne(ctx, bson.M{"name": name})
+}
+
+// List 获取全部租户
+func (r *tenantRepository) List(ctx context.Context) ([]*models.Tenant, error) {
+	return r.find(ctx, bson.M{})
+}
+
+// ListByIDs 获取指定的租户
+func (r *tenantRepository) ListByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.Tenant, error) {
+	if len(ids) == 0 {
+		return make([]*models.Tenant, 0), nil
+	}
+	return r.find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+// findOne 按条件查询单个租户
+func (r *tenantRepository) findOne(ctx context.Context, filter bson.M) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.collection.FindOne(ctx, filter).Decode(&tenant)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("tenant not found")
+		}
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+// find 按条件查询租户
+func (r *tenantRepository) find(ctx context.Context, filter bson.M) ([]*models.Tenant, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	tenants := make([]*models.Tenant, 0)
+	if err = cursor.All(ctx, &tenants); err != nil {
+		return nil, err
+	}
+
+	return tenants, nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	authService "authcenter/internal/auth/service"
+	"authcenter/internal/models"
+	"authcenter/internal/tenant/repository"
+	userRepo "authcenter/internal/user/repository"
+	"authcenter/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// tenantNamePattern 租户标识格式，小写字母、数字和连字符
+var tenantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,63}$`)
+
+// CreateTenantRequest 创建租户请求
+type CreateTenantRequest struct {
+	Name        string `json:"name"` // 唯一标识，创建后不能修改
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+}
+
+// TenantService 租户业务逻辑接口
+type TenantService interface {
+	// CreateTenant 创建租户
+	CreateTenant(ctx context.Context, req *CreateTenantRequest, operatorID string) (*models.Tenant, error)
+
+	// ListTenants 获取全部租户
+	ListTenants(ctx context.Context) ([]*models.Tenant, error)
+
+	// ListMyTenants 获取用户所属的租户
+	ListMyTenants(ctx context.Context, userID string) ([]*models.Tenant, error)
+
+	// ListMembers 获取租户的成员
+	ListMembers(ctx context.Context, tenantID string, page, pageSize int) ([]*models.User, int64, error)
+
+	// AddMember 将用户加入租户
+	AddMember(ctx context.Context, tenantID, userID, operatorID string) error
+
+	// RemoveMember 将用户移出租户，同时移除该租户角色的分配，用户已签发的访问令牌随即失效
+	RemoveMember(ctx context.Context, tenantID, userID, operatorID string) error
+}
+
+// tenantService 租户服务实现
+type tenantService struct {
+	tenantRepo repository.TenantRepository
+	userRepo   userRepo.UserRepository
+	revocation authService.RevocationService
+}
+
+// NewTenantService 创建租户服务
+func NewTenantService(tenantRepo repository.TenantRepository, userRepo userRepo.UserRepository, revocation authService.RevocationService) TenantService {
+	return &tenantService{
+		tenantRepo: tenantRepo,
+		userRepo:   userRepo,
+		revocation: revocation,
+	}
+}
+
+// CreateTenant 创建租户
+func (s *tenantService) CreateTenant(ctx context.Context, req *CreateTenantRequest, operatorID string) (*models.Tenant, error) {
+	name := strings.TrimSpace(req.Name)
+	if !tenantNamePattern.MatchString(name) {
+		return nil, errors.New("租户标识只能包含小写字母、数字和连字符，长度为2到64")
+	}
+
+	operatorObjID, err := primitive.ObjectIDFromHex(operatorID)
+	if err != nil {
+		return nil, errors.New("无效的操作者ID")
+	}
+
+	displayName := strings.TrimSpace(req.DisplayName)
+	if displayName == "" {
+		displayName = name
+	}
+
+	now := time.Now()
+	tenant := &models.Tenant{
+		Name:        name,
+		DisplayName: displayName,
+		Description: req.Description,
+		CreatedBy:   operatorObjID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.tenantRepo.Create(ctx, tenant); err != nil {
+		if errors.Is(err, repository.ErrTenantExists) {
+			return nil, errors.New("租户标识已存在")
+		}
+		return nil, err
+	}
+
+	logger.Audit("tenant_created", map[string]interface{}{
+		"tenant_id":   tenant.ID.Hex(),
+		"name":        tenant.Name,
+		"operator_id": operatorID,
+	})
+
+	return tenant, nil
+}
+
+// ListTenants 获取全部租户
+func (s *tenantService) ListTenants(ctx context.Context) ([]*models.Tenant, error) {
+	return s.tenantRepo.List(ctx)
+}
+
+// ListMyTenants 获取用户所属的租户
+func (s *tenantService) ListMyTenants(ctx context.Context, userID string) ([]*models.Tenant, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(user.Tenants))
+	for _, membership := range user.Tenants {
+		ids = append(ids, membership.TenantID)
+	}
+
+	return s.tenantRepo.ListByIDs(ctx, ids)
+}
+
+// ListMembers 获取租户的成员
+func (s *tenantService) ListMembers(ctx context.Context, tenantID string, page, pageSize int) ([]*models.User, int64, error) {
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.userRepo.ForTenant(tenant.ID).List(page, pageSize)
+}
+
+// AddMember 将用户加入租户
+func (s *tenantService) AddMember(ctx context.Context, tenantID, userID, operatorID string) error {
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.AddTenant(userID, tenant.ID); err != nil {
+		return errors.New("用户不存在")
+	}
+
+	logger.Audit("tenant_member_added", map[string]interface{}{
+		"tenant_id":   tenant.ID.Hex(),
+		"user_id":     userID,
+		"operator_id": operatorID,
+	})
+
+	return nil
+}
+
+// RemoveMember 将用户移出租户
+func (s *tenantService) RemoveMember(ctx context.Context, tenantID, userID, operatorID string) error {
+	tenant, err := s.getTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.RemoveTenant(userID, tenant.ID); err != nil {
+		return errors.New("用户不是该租户的成员")
+	}
+
+	logger.Audit("tenant_member_removed", map[string]interface{}{
+		"tenant_id":   tenant.ID.Hex(),
+		"user_id":     userID,
+		"operator_id": operatorID,
+	})
+
+	return s.revocation.RevokeUser(ctx, userID, "tenant_member_removed")
+}
+
+// getTenant 通过ID获取租户
+func (s *tenantService) getTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	id, err := primitive.ObjectIDFromHex(tenantID)
+	if err != nil {
+		return nil, errors.New("无效的租户ID")
+	}
+
+	tenant, err := s.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("租户不存在")
+	}
+
+	return tenant, nil
+}
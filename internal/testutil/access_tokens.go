@@ -0,0 +1,92 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccessTokens 内存个人访问令牌仓储
+type AccessTokens struct {
+	mutex  sync.Mutex
+	tokens []*models.PersonalAccessToken
+}
+
+// NewAccessTokens 创建内存个人访问令牌仓储
+func NewAccessTokens() *AccessTokens {
+	return &AccessTokens{}
+}
+
+// Create 保存令牌
+func (r *AccessTokens) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token.ID = primitive.NewObjectID()
+	record := *token
+	r.tokens = append(r.tokens, &record)
+	return nil
+}
+
+// GetByHash 通过令牌摘要获取令牌
+func (r *AccessTokens) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			record := *token
+			return &record, nil
+		}
+	}
+	return nil, errors.New("access token not found")
+}
+
+// ListByUser 获取用户的全部令牌，按创建时间倒序
+func (r *AccessTokens) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*models.PersonalAccessToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tokens := make([]*models.PersonalAccessToken, 0)
+	for i := len(r.tokens) - 1; i >= 0; i-- {
+		if r.tokens[i].UserID == userID {
+			record := *r.tokens[i]
+			tokens = append(tokens, &record)
+		}
+	}
+	return tokens, nil
+}
+
+// Revoke 吊销用户的令牌，令牌不存在或已吊销时返回false
+func (r *AccessTokens) Revoke(ctx context.Context, userID, id primitive.ObjectID) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, token := range r.tokens {
+		if token.ID == id && token.UserID == userID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RecordUsage 记录令牌的使用时间和IP
+func (r *AccessTokens) RecordUsage(ctx context.Context, id primitive.ObjectID, ip string, at time.Time, interval time.Duration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, token := range r.tokens {
+		if token.ID == id && (token.LastUsedAt == nil || at.Sub(*token.LastUsedAt) >= interval) {
+			token.LastUsedAt = &at
+			token.LastUsedIP = ip
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/internal/elevation/repository"
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Elevations 内存临时提权申请仓储，条件更新的语义与MongoDB实现一致，不限定租户
+type Elevations struct {
+	mutex    sync.Mutex
+	requests []*models.ElevationRequest
+}
+
+// NewElevations 创建内存临时提权申请仓储
+func NewElevations() *Elevations {
+	return &Elevations{}
+}
+
+// Create 保存申请，同一用户对同一角色已有待审批的申请时返回ErrPendingExists
+func (r *Elevations) Create(ctx context.Context, req *models.ElevationRequest) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existing := range r.requests {
+		if existing.UserID == req.UserID && existing.RoleID == req.RoleID && existing.Status == repository.StatusPending {
+			return repository.ErrPendingExists
+		}
+	}
+
+	req.ID = primitive.NewObjectID()
+	record := *req
+	r.requests = append(r.requests, &record)
+	return nil
+}
+
+// GetByID 通过ID获取申请
+func (r *Elevations) GetByID(ctx context.Context, id primitive.ObjectID) (*models.ElevationRequest, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if req := r.find(id); req != nil {
+		record := *req
+		return &record, nil
+	}
+	return nil, errors.New("elevation request not found")
+}
+
+// ListByUser 获取用户的申请，按创建时间倒序
+func (r *Elevations) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]*models.ElevationRequest, error) {
+	return r.filter(func(req *models.ElevationRequest) bool { return req.UserID == userID }, true), nil
+}
+
+// ListPending 获取尚未过期的待审批申请，按创建时间正序
+func (r *Elevations) ListPending(ctx context.Context, now time.Time) ([]*models.ElevationRequest, error) {
+	return r.filter(func(req *models.ElevationRequest) bool {
+		return req.Status == repository.StatusPending && req.ExpiresAt.After(now)
+	}, false), nil
+}
+
+// Decide 将待审批的申请更新为req中的状态和审批信息
+func (r *Elevations) Decide(ctx context.Context, req *models.ElevationRequest) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := r.find(req.ID)
+	if stored == nil || stored.Status != repository.StatusPending {
+		return false, nil
+	}
+	stored.Status = req.Status
+	stored.ApproverID = req.ApproverID
+	stored.ApproverName = req.ApproverName
+	stored.Comment = req.Comment
+	stored.DecidedAt = req.DecidedAt
+	stored.GrantExpiresAt = req.GrantExpiresAt
+	return true, nil
+}
+
+// Reopen 将已通过的申请恢复为待审批
+func (r *Elevations) Reopen(ctx context.Context, id primitive.ObjectID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if stored := r.find(id); stored != nil && stored.Status == repository.StatusApproved {
+		stored.Status = repository.StatusPending
+		stored.ApproverID = nil
+		stored.ApproverName = ""
+		stored.Comment = ""
+		stored.DecidedAt = nil
+		stored.GrantExpiresAt = nil
+	}
+	return nil
+}
+
+// ExpireStale 将用户对角色超过审批期限的待审批申请标记为过期
+func (r *Elevations) ExpireStale(ctx context.Context, userID, roleID primitive.ObjectID, now time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, req := range r.requests {
+		if req.UserID == userID && req.RoleID == roleID && req.Status == repository.StatusPending && !req.ExpiresAt.After(now) {
+			req.Status = repository.StatusExpired
+		}
+	}
+	return nil
+}
+
+// ForTenant 内存仓储不限定租户
+func (r *Elevations) ForTenant(tenantID primitive.ObjectID) repository.ElevationRepository {
+	return r
+}
+
+// find 按ID查找，调用方需持有锁
+func (r *Elevations) find(id primitive.ObjectID) *models.ElevationRequest {
+	for _, req := range r.requests {
+		if req.ID == id {
+			return req
+		}
+	}
+	return nil
+}
+
+// filter 返回满足条件的申请的副本，newestFirst为true时按创建顺序倒序
+func (r *Elevations) filter(match func(*models.ElevationRequest) bool, newestFirst bool) []*models.ElevationRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	requests := make([]*models.ElevationRequest, 0)
+	for _, req := range r.requests {
+		if match(req) {
+			record := *req
+			requests = append(requests, &record)
+		}
+	}
+	if newestFirst {
+		for i, j := 0, len(requests)-1; i < j; i, j = i+1, j-1 {
+			requests[i], requests[j] = requests[j], requests[i]
+		}
+	}
+	return requests
+}
@@ -0,0 +1,178 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	groupRepo "authcenter/internal/group/repository"
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Groups 内存用户组仓储，租户限定的语义与MongoDB实现一致，未实现的方法调用时panic
+type Groups struct {
+	groupRepo.GroupRepository
+
+	mutex    *sync.Mutex
+	groups   map[primitive.ObjectID]*models.Group
+	tenantID primitive.ObjectID // 非零值时只访问该租户的用户组
+}
+
+// NewGroups 创建内存用户组仓储
+func NewGroups() *Groups {
+	return &Groups{
+		mutex:  &sync.Mutex{},
+		groups: make(map[primitive.ObjectID]*models.Group),
+	}
+}
+
+// Put 保存用户组，ID为空时生成，限定租户时属于该租户
+func (r *Groups) Put(group *models.Group) *models.Group {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if group.ID.IsZero() {
+		group.ID = primitive.NewObjectID()
+	}
+	if !r.tenantID.IsZero() {
+		tenantID := r.tenantID
+		group.TenantID = &tenantID
+	}
+	r.groups[group.ID] = copyGroup(group)
+	return group
+}
+
+// Create 创建用户组
+func (r *Groups) Create(ctx context.Context, group *models.Group) error {
+	r.Put(group)
+	return nil
+}
+
+// GetByID 通过ID获取用户组
+func (r *Groups) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Group, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	group, ok := r.groups[id]
+	if !ok || !r.visible(group) {
+		return nil, errors.New("group not found")
+	}
+	return copyGroup(group), nil
+}
+
+// Update 更新用户组的名称、描述和动态组的匹配条件
+func (r *Groups) Update(ctx context.Context, group *models.Group) error {
+	return r.modify(group.ID, func(stored *models.Group) {
+		stored.Name = group.Name
+		stored.Description = group.Description
+		stored.Department = group.Department
+		stored.Position = group.Position
+	})
+}
+
+// AddMember 将用户加入手动组
+func (r *Groups) AddMember(ctx context.Context, id, userID primitive.ObjectID) error {
+	return r.modify(id, func(group *models.Group) {
+		for _, member := range group.Members {
+			if member == userID {
+				return
+			}
+		}
+		group.Members = append(group.Members, userID)
+	})
+}
+
+// AssignRole 为用户组分配角色，已分配时替换原分配
+func (r *Groups) AssignRole(ctx context.Context, id primitive.ObjectID, role models.UserRole) error {
+	return r.modify(id, func(group *models.Group) {
+		roles := make([]models.UserRole, 0, len(group.Roles)+1)
+		for _, assignment := range group.Roles {
+			if assignment.RoleID != role.RoleID {
+				roles = append(roles, assignment)
+			}
+		}
+		group.Roles = append(roles, role)
+	})
+}
+
+// FindByMember 获取用户所属的手动组和资料匹配的动态组
+// 限定租户时包括该租户和不属于任何租户的组，不限定租户时只包括不属于任何租户的组
+func (r *Groups) FindByMember(ctx context.Context, user *models.User) ([]*models.Group, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	groups := make([]*models.Group, 0)
+	for _, group := range r.groups {
+		inScope := group.TenantID == nil || (!r.tenantID.IsZero() && *group.TenantID == r.tenantID)
+		if inScope && isGroupMember(group, user) {
+			groups = append(groups, copyGroup(group))
+		}
+	}
+	return groups, nil
+}
+
+// ForTenant 返回只访问指定租户用户组的仓储，与原仓储共享数据
+func (r *Groups) ForTenant(tenantID primitive.ObjectID) groupRepo.GroupRepository {
+	scoped := *r
+	scoped.tenantID = tenantID
+	return &scoped
+}
+
+// memberRoles 用户在所属的全部租户中以及不属于任何租户的用户组的角色分配
+func (r *Groups) memberRoles(user *models.User) []models.UserRole {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	roles := make([]models.UserRole, 0)
+	for _, group := range r.groups {
+		if (group.TenantID == nil || user.MemberOf(*group.TenantID)) && isGroupMember(group, user) {
+			roles = append(roles, group.Roles...)
+		}
+	}
+	return roles
+}
+
+// visible 用户组在当前租户限定下是否可见，调用方需持有锁
+func (r *Groups) visible(group *models.Group) bool {
+	return r.tenantID.IsZero() || (group.TenantID != nil && *group.TenantID == r.tenantID)
+}
+
+// modify 在锁内修改用户组
+func (r *Groups) modify(id primitive.ObjectID, change func(*models.Group)) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	group, ok := r.groups[id]
+	if !ok || !r.visible(group) {
+		return errors.New("group not found")
+	}
+	change(group)
+	group.UpdatedAt = time.Now()
+	return nil
+}
+
+// isGroupMember 用户是否是手动组的成员或匹配动态组的条件，为空的条件不限
+func isGroupMember(group *models.Group, user *models.User) bool {
+	if group.Type == groupRepo.GroupManual {
+		for _, member := range group.Members {
+			if member == user.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	return (group.Department == "" || group.Department == user.Profile.Department) &&
+		(group.Position == "" || group.Position == user.Profile.Position)
+}
+
+// copyGroup 复制用户组
+func copyGroup(group *models.Group) *models.Group {
+	c := *group
+	c.Members = append([]primitive.ObjectID(nil), group.Members...)
+	c.Roles = append([]models.UserRole(nil), group.Roles...)
+	return &c
+}
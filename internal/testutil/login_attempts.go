@@ -0,0 +1,65 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/internal/models"
+)
+
+// LoginAttempts 内存登录失败记录仓储，不清理过期记录
+type LoginAttempts struct {
+	mutex    sync.Mutex
+	attempts map[string]*models.LoginAttempt
+}
+
+// NewLoginAttempts 创建内存登录失败记录仓储
+func NewLoginAttempts() *LoginAttempts {
+	return &LoginAttempts{attempts: make(map[string]*models.LoginAttempt)}
+}
+
+// Get 获取登录标识的失败记录
+func (r *LoginAttempts) Get(ctx context.Context, identifier string) (*models.LoginAttempt, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	attempt, ok := r.attempts[identifier]
+	if !ok {
+		return nil, errors.New("login attempt not found")
+	}
+	record := *attempt
+	return &record, nil
+}
+
+// RecordFailure 累加登录失败次数
+func (r *LoginAttempts) RecordFailure(ctx context.Context, identifier string, window time.Duration) (*models.LoginAttempt, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	attempt, ok := r.attempts[identifier]
+	if !ok {
+		attempt = &models.LoginAttempt{Identifier: identifier}
+		r.attempts[identifier] = attempt
+	}
+	now := time.Now()
+	attempt.FailedCount++
+	attempt.LastFailedAt = now
+	attempt.ExpiresAt = now.Add(window)
+
+	record := *attempt
+	return &record, nil
+}
+
+// Lock 锁定登录标识直到指定时间
+func (r *LoginAttempts) Lock(ctx context.Context, identifier string, until time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if attempt, ok := r.attempts[identifier]; ok {
+		attempt.LockedUntil = &until
+		attempt.ExpiresAt = until
+	}
+	return nil
+}
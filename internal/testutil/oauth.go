@@ -0,0 +1,85 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/internal/models"
+	"authcenter/internal/oauth/repository"
+)
+
+// AuthorizationCodes 内存授权码仓储
+type AuthorizationCodes struct {
+	mutex sync.Mutex
+	codes map[string]*models.AuthorizationCode
+}
+
+// NewAuthorizationCodes 创建内存授权码仓储
+func NewAuthorizationCodes() *AuthorizationCodes {
+	return &AuthorizationCodes{codes: make(map[string]*models.AuthorizationCode)}
+}
+
+// Create 保存授权码
+func (r *AuthorizationCodes) Create(ctx context.Context, code *models.AuthorizationCode) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code.CreatedAt = time.Now()
+	code.Used = false
+	record := *code
+	r.codes[code.CodeHash] = &record
+	return nil
+}
+
+// GetByHash 获取授权码，包括已兑换的授权码
+func (r *AuthorizationCodes) GetByHash(ctx context.Context, codeHash string) (*models.AuthorizationCode, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code, ok := r.codes[codeHash]
+	if !ok {
+		return nil, errors.New("authorization code not found")
+	}
+	record := *code
+	return &record, nil
+}
+
+// Consume 兑换授权码，已被兑换时返回ErrCodeAlreadyUsed以及原授权码记录
+func (r *AuthorizationCodes) Consume(ctx context.Context, codeHash string) (*models.AuthorizationCode, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code, ok := r.codes[codeHash]
+	if !ok {
+		return nil, errors.New("authorization code not found")
+	}
+	if code.Used {
+		record := *code
+		return &record, repository.ErrCodeAlreadyUsed
+	}
+	code.Used = true
+	record := *code
+	return &record, nil
+}
+
+// SetSessionID 记录授权码兑换后生成的会话
+func (r *AuthorizationCodes) SetSessionID(ctx context.Context, codeHash, sessionID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if code, ok := r.codes[codeHash]; ok {
+		code.SessionID = sessionID
+	}
+	return nil
+}
+
+// Used 授权码是否已被兑换，用于断言
+func (r *AuthorizationCodes) Used(codeHash string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code, ok := r.codes[codeHash]
+	return ok && code.Used
+}
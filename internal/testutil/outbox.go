@@ -0,0 +1,94 @@
+package testutil
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sync"
+
+	"authcenter/pkg/mail"
+)
+
+// linkPattern 邮件正文中的链接
+var linkPattern = regexp.MustCompile(`https?://\S+`)
+
+// Mailbox 记录发出的邮件
+type Mailbox struct {
+	mutex    sync.Mutex
+	messages []mail.Message
+}
+
+// NewMailbox 创建邮件记录器
+func NewMailbox() *Mailbox {
+	return &Mailbox{}
+}
+
+// Send 记录邮件
+func (m *Mailbox) Send(ctx context.Context, msg *mail.Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+// LastToken 发往to的最后一封邮件中链接的token参数，没有时返回空
+func (m *Mailbox) LastToken(to string) string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To != to {
+			continue
+		}
+		link, err := url.Parse(linkPattern.FindString(m.messages[i].Body))
+		if err != nil {
+			return ""
+		}
+		return link.Query().Get("token")
+	}
+	return ""
+}
+
+// SMSOutbox 记录发出的短信
+type SMSOutbox struct {
+	mutex    sync.Mutex
+	messages map[string][]string
+}
+
+// NewSMSOutbox 创建短信记录器
+func NewSMSOutbox() *SMSOutbox {
+	return &SMSOutbox{messages: make(map[string][]string)}
+}
+
+// Send 记录短信
+func (o *SMSOutbox) Send(ctx context.Context, phone, message string) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.messages[phone] = append(o.messages[phone], message)
+	return nil
+}
+
+// Count 发往phone的短信数量
+func (o *SMSOutbox) Count(phone string) int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return len(o.messages[phone])
+}
+
+// codePattern 短信中的数字验证码
+var codePattern = regexp.MustCompile(`\d{4,8}`)
+
+// LastCode 发往phone的最后一条短信中的验证码，没有时返回空
+func (o *SMSOutbox) LastCode(phone string) string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	messages := o.messages[phone]
+	if len(messages) == 0 {
+		return ""
+	}
+	return codePattern.FindString(messages[len(messages)-1])
+}
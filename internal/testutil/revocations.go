@@ -0,0 +1,59 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"authcenter/internal/models"
+)
+
+// Revocations 内存访问令牌吊销记录仓储
+type Revocations struct {
+	mutex   sync.Mutex
+	records []*models.RevokedToken
+}
+
+// NewRevocations 创建内存吊销记录仓储
+func NewRevocations() *Revocations {
+	return &Revocations{}
+}
+
+// Create 创建吊销记录
+func (r *Revocations) Create(ctx context.Context, revoked *models.RevokedToken) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	record := *revoked
+	r.records = append(r.records, &record)
+	return nil
+}
+
+// ListSince 获取指定时间之后创建且未过期的吊销记录
+func (r *Revocations) ListSince(ctx context.Context, since time.Time) ([]*models.RevokedToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	var records []*models.RevokedToken
+	for _, record := range r.records {
+		if !record.RevokedAt.Before(since) && record.ExpiresAt.After(now) {
+			c := *record
+			records = append(records, &c)
+		}
+	}
+	return records, nil
+}
+
+// Has 是否存在指定类型和对象的吊销记录，用于断言
+func (r *Revocations) Has(revocationType, value string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, record := range r.records {
+		if record.Type == revocationType && record.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package testutil
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/internal/models"
+	roleRepo "authcenter/internal/role/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Roles 内存角色仓储，不限定租户，未实现的方法调用时panic
+type Roles struct {
+	roleRepo.RoleRepository
+
+	mutex sync.Mutex
+	roles map[primitive.ObjectID]*models.Role
+}
+
+// NewRoles 创建内存角色仓储
+func NewRoles() *Roles {
+	return &Roles{roles: make(map[primitive.ObjectID]*models.Role)}
+}
+
+// Put 保存角色，ID为空时生成
+func (r *Roles) Put(role *models.Role) *models.Role {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if role.ID.IsZero() {
+		role.ID = primitive.NewObjectID()
+	}
+	if role.Status == "" {
+		role.Status = "active"
+	}
+	record := *role
+	r.roles[role.ID] = &record
+	return role
+}
+
+// Create 创建角色
+func (r *Roles) Create(role *models.Role) error {
+	role.CreatedAt = time.Now()
+	r.Put(role)
+	return nil
+}
+
+// GetByID 通过ID获取角色
+func (r *Roles) GetByID(id string) (*models.Role, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid role ID format")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	role, ok := r.roles[objectID]
+	if !ok {
+		return nil, errors.New("role not found")
+	}
+	record := *role
+	return &record, nil
+}
+
+// GetByName 通过名称获取角色
+func (r *Roles) GetByName(name string) (*models.Role, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, role := range r.roles {
+		if role.Name == name {
+			record := *role
+			return &record, nil
+		}
+	}
+	return nil, errors.New("role not found")
+}
+
+// GetAncestors 获取角色的全部祖先角色，每个角色只返回一次
+func (r *Roles) GetAncestors(id string) ([]*models.Role, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid role ID format")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.ancestors(objectID), nil
+}
+
+// GetDescendants 获取继承该角色的全部子孙角色
+func (r *Roles) GetDescendants(id string) ([]*models.Role, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid role ID format")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	descendants := make([]*models.Role, 0)
+	for _, role := range r.roles {
+		for _, ancestor := range r.ancestors(role.ID) {
+			if ancestor.ID == objectID {
+				descendants = append(descendants, copyRole(role))
+				break
+			}
+		}
+	}
+	return descendants, nil
+}
+
+// ForTenant 内存仓储不限定租户
+func (r *Roles) ForTenant(tenantID primitive.ObjectID) roleRepo.RoleRepository {
+	return r
+}
+
+// ancestors 沿父角色向上查找，不包括角色本身，调用方需持有锁
+func (r *Roles) ancestors(id primitive.ObjectID) []*models.Role {
+	visited := map[primitive.ObjectID]bool{id: true}
+	queue := []primitive.ObjectID{id}
+	ancestors := make([]*models.Role, 0)
+	for len(queue) > 0 {
+		role, ok := r.roles[queue[0]]
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+		for _, parentID := range role.ParentIDs {
+			parent, ok := r.roles[parentID]
+			if !ok || visited[parentID] {
+				continue
+			}
+			visited[parentID] = true
+			ancestors = append(ancestors, copyRole(parent))
+			queue = append(queue, parentID)
+		}
+	}
+	return ancestors
+}
+
+// copyRole 复制角色
+func copyRole(role *models.Role) *models.Role {
+	record := *role
+	return &record
+}
+
+// SoDRules 内存职责分离规则仓储，不限定租户
+type SoDRules struct {
+	mutex sync.Mutex
+	rules []*models.SoDRule
+}
+
+// NewSoDRules 创建内存职责分离规则仓储
+func NewSoDRules() *SoDRules {
+	return &SoDRules{}
+}
+
+// Create 创建规则
+func (r *SoDRules) Create(rule *models.SoDRule) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rule.ID = primitive.NewObjectID()
+	rule.CreatedAt = time.Now()
+	record := *rule
+	r.rules = append(r.rules, &record)
+	return nil
+}
+
+// GetByID 通过ID获取规则
+func (r *SoDRules) GetByID(id string) (*models.SoDRule, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, rule := range r.rules {
+		if rule.ID.Hex() == id {
+			record := *rule
+			return &record, nil
+		}
+	}
+	return nil, errors.New("sod rule not found")
+}
+
+// List 获取指定类型的规则，ruleType为空时返回全部规则
+func (r *SoDRules) List(ruleType string) ([]*models.SoDRule, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rules := make([]*models.SoDRule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		if ruleType == "" || rule.Type == ruleType {
+			record := *rule
+			rules = append(rules, &record)
+		}
+	}
+	return rules, nil
+}
+
+// Delete 删除规则
+func (r *SoDRules) Delete(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, rule := range r.rules {
+		if rule.ID.Hex() == id {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("sod rule not found")
+}
+
+// ForTenant 内存仓储不限定租户
+func (r *SoDRules) ForTenant(tenantID primitive.ObjectID) roleRepo.SoDRepository {
+	return r
+}
@@ -0,0 +1,247 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sessions 内存会话仓储，查询和条件更新的语义与MongoDB实现一致
+type Sessions struct {
+	mutex    sync.Mutex
+	sessions []*models.Session
+}
+
+// NewSessions 创建内存会话仓储
+func NewSessions() *Sessions {
+	return &Sessions{}
+}
+
+// All 获取全部会话的副本，包括已吊销和已过期的会话，用于断言
+func (r *Sessions) All() []*models.Session {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.filter(func(*models.Session) bool { return true })
+}
+
+// Create 创建会话
+func (r *Sessions) Create(ctx context.Context, session *models.Session) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	session.CreatedAt = now
+	session.LastAccessedAt = now
+	session.IsRevoked = false
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+	r.sessions = append(r.sessions, copySession(session))
+	return nil
+}
+
+// GetBySessionID 通过会话ID获取会话
+func (r *Sessions) GetBySessionID(ctx context.Context, sessionID string) (*models.Session, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if session := r.find(sessionID); session != nil {
+		return copySession(session), nil
+	}
+	return nil, errors.New("session not found")
+}
+
+// GetByUserID 获取用户未吊销且未过期的会话
+func (r *Sessions) GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.Session, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	return r.filter(func(s *models.Session) bool {
+		return s.UserID == userID && !s.IsRevoked && s.ExpiresAt.After(now)
+	}), nil
+}
+
+// Update 更新会话
+func (r *Sessions) Update(ctx context.Context, session *models.Session) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, s := range r.sessions {
+		if s.SessionID == session.SessionID {
+			session.LastAccessedAt = time.Now()
+			updated := copySession(session)
+			updated.ID = s.ID
+			r.sessions[i] = updated
+			return nil
+		}
+	}
+	return errors.New("session not found")
+}
+
+// RevokeUserSessions 撤销用户所有会话
+func (r *Sessions) RevokeUserSessions(ctx context.Context, userID primitive.ObjectID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			s.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+// RevokeSession 撤销指定会话
+func (r *Sessions) RevokeSession(ctx context.Context, sessionID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session := r.find(sessionID)
+	if session == nil {
+		return errors.New("session not found")
+	}
+	session.IsRevoked = true
+	return nil
+}
+
+// RotateSession 将未吊销的会话标记为已轮换
+func (r *Sessions) RotateSession(ctx context.Context, sessionID string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session := r.find(sessionID)
+	if session == nil || session.IsRevoked {
+		return false, nil
+	}
+	now := time.Now()
+	session.IsRevoked = true
+	session.RotatedAt = &now
+	session.LastAccessedAt = now
+	return true, nil
+}
+
+// RevokeFamily 撤销刷新令牌族中的所有会话
+func (r *Sessions) RevokeFamily(ctx context.Context, familyID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, s := range r.sessions {
+		if s.FamilyID == familyID {
+			s.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+// GetFamily 获取令牌族中未过期的会话，没有族ID的会话以会话ID作为族ID
+func (r *Sessions) GetFamily(ctx context.Context, familyID string) ([]*models.Session, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	return r.filter(func(s *models.Session) bool {
+		return (s.FamilyID == familyID || s.SessionID == familyID) && s.ExpiresAt.After(now)
+	}), nil
+}
+
+// TouchSession 更新未吊销会话的最近访问时间，不会回退
+func (r *Sessions) TouchSession(ctx context.Context, sessionID string, at time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if session := r.find(sessionID); session != nil && !session.IsRevoked && session.LastAccessedAt.Before(at) {
+		session.LastAccessedAt = at
+	}
+	return nil
+}
+
+// SetActiveRoles 设置未吊销会话激活的角色
+func (r *Sessions) SetActiveRoles(ctx context.Context, sessionID string, roles []string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session := r.find(sessionID)
+	if session == nil || session.IsRevoked {
+		return errors.New("session not found")
+	}
+	session.ActiveRoles = append([]string(nil), roles...)
+	return nil
+}
+
+// RevokeIdleSessions 吊销最近访问时间早于idleBefore的会话
+func (r *Sessions) RevokeIdleSessions(ctx context.Context, idleBefore time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var count int64
+	for _, s := range r.sessions {
+		if !s.IsRevoked && s.LastAccessedAt.Before(idleBefore) {
+			s.IsRevoked = true
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CleanupExpiredSessions 删除已吊销但未轮换的会话和已过期的会话
+func (r *Sessions) CleanupExpiredSessions(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	kept := r.sessions[:0]
+	for _, s := range r.sessions {
+		if (s.IsRevoked && s.RotatedAt == nil) || s.ExpiresAt.Before(now) {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	r.sessions = kept
+	return nil
+}
+
+// SetLastAccessedAt 直接修改会话的最近访问时间，用于模拟空闲
+func (r *Sessions) SetLastAccessedAt(sessionID string, at time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if session := r.find(sessionID); session != nil {
+		session.LastAccessedAt = at
+	}
+}
+
+// find 按会话ID查找，调用方需持有锁
+func (r *Sessions) find(sessionID string) *models.Session {
+	for _, s := range r.sessions {
+		if s.SessionID == sessionID {
+			return s
+		}
+	}
+	return nil
+}
+
+// filter 返回满足条件的会话的副本，调用方需持有锁
+func (r *Sessions) filter(match func(*models.Session) bool) []*models.Session {
+	var sessions []*models.Session
+	for _, s := range r.sessions {
+		if match(s) {
+			sessions = append(sessions, copySession(s))
+		}
+	}
+	return sessions
+}
+
+// copySession 复制会话，避免调用方修改仓储中的数据
+func copySession(session *models.Session) *models.Session {
+	c := *session
+	c.AuthMethods = append([]string(nil), session.AuthMethods...)
+	c.ActiveRoles = append([]string(nil), session.ActiveRoles...)
+	return &c
+}
@@ -0,0 +1,65 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"authcenter/internal/models"
+	tenantRepo "authcenter/internal/tenant/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tenants 内存租户仓储，未实现的方法调用时panic
+type Tenants struct {
+	tenantRepo.TenantRepository
+
+	mutex   sync.Mutex
+	tenants map[primitive.ObjectID]*models.Tenant
+}
+
+// NewTenants 创建内存租户仓储
+func NewTenants() *Tenants {
+	return &Tenants{tenants: make(map[primitive.ObjectID]*models.Tenant)}
+}
+
+// Put 保存租户，ID为空时生成
+func (r *Tenants) Put(tenant *models.Tenant) *models.Tenant {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if tenant.ID.IsZero() {
+		tenant.ID = primitive.NewObjectID()
+	}
+	record := *tenant
+	r.tenants[tenant.ID] = &record
+	return tenant
+}
+
+// GetByID 通过ID获取租户
+func (r *Tenants) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Tenant, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tenant, ok := r.tenants[id]
+	if !ok {
+		return nil, errors.New("tenant not found")
+	}
+	record := *tenant
+	return &record, nil
+}
+
+// GetByName 通过标识获取租户
+func (r *Tenants) GetByName(ctx context.Context, name string) (*models.Tenant, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, tenant := range r.tenants {
+		if tenant.Name == name {
+			record := *tenant
+			return &record, nil
+		}
+	}
+	return nil, errors.New("tenant not found")
+}
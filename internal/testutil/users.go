@@ -0,0 +1,347 @@
+package testutil
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/internal/models"
+	userRepo "authcenter/internal/user/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Users 内存用户仓储，条件更新的语义与MongoDB实现一致，未实现的方法调用时panic
+type Users struct {
+	userRepo.UserRepository
+
+	mutex  sync.Mutex
+	users  map[primitive.ObjectID]*models.User
+	groups *Groups // 为空时用户不属于任何用户组
+}
+
+// NewUsers 创建内存用户仓储
+func NewUsers(users ...*models.User) *Users {
+	r := &Users{users: make(map[primitive.ObjectID]*models.User)}
+	for _, user := range users {
+		r.Put(user)
+	}
+	return r
+}
+
+// Put 保存用户，ID为空时生成
+func (r *Users) Put(user *models.User) *models.User {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if user.Status == "" {
+		user.Status = "active"
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	r.users[user.ID] = copyUser(user)
+	return user
+}
+
+// Get 获取用户当前的状态，用于断言
+func (r *Users) Get(id primitive.ObjectID) *models.User {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if user, ok := r.users[id]; ok {
+		return copyUser(user)
+	}
+	return nil
+}
+
+// Create 创建用户
+func (r *Users) Create(user *models.User) error {
+	r.Put(user)
+	return nil
+}
+
+// GetByID 通过ID获取用户
+func (r *Users) GetByID(id string) (*models.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid user ID format")
+	}
+	return r.find(func(u *models.User) bool { return u.ID == objectID })
+}
+
+// GetByEmail 通过邮箱获取用户
+func (r *Users) GetByEmail(email string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return email != "" && u.Email == email })
+}
+
+// GetByUsername 通过用户名获取用户
+func (r *Users) GetByUsername(username string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Username == username })
+}
+
+// GetByPhone 通过手机号获取用户
+func (r *Users) GetByPhone(phone string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return phone != "" && u.Phone == phone })
+}
+
+// UpdateStatus 更新用户状态并清除解锁时间
+func (r *Users) UpdateStatus(id, status string) error {
+	return r.update(id, func(u *models.User) bool {
+		u.Status = status
+		u.LockedUntil = nil
+		return true
+	})
+}
+
+// IncrementFailedLogins 累加登录失败次数
+func (r *Users) IncrementFailedLogins(id string) (int, error) {
+	var count int
+	err := r.update(id, func(u *models.User) bool {
+		u.FailedLoginAttempts++
+		count = u.FailedLoginAttempts
+		return true
+	})
+	return count, err
+}
+
+// ResetFailedLogins 清零登录失败次数
+func (r *Users) ResetFailedLogins(id string) error {
+	return r.update(id, func(u *models.User) bool {
+		u.FailedLoginAttempts = 0
+		return true
+	})
+}
+
+// Lock 只锁定正常状态的用户
+func (r *Users) Lock(id string, until time.Time) (bool, error) {
+	var locked bool
+	err := r.update(id, func(u *models.User) bool {
+		if u.Status != "active" {
+			return false
+		}
+		u.Status = "locked"
+		u.LockedUntil = &until
+		locked = true
+		return true
+	})
+	return locked, err
+}
+
+// Unlock 解锁用户并清零登录失败次数
+func (r *Users) Unlock(id string) error {
+	return r.update(id, func(u *models.User) bool {
+		u.Status = "active"
+		u.FailedLoginAttempts = 0
+		u.LockedUntil = nil
+		return true
+	})
+}
+
+// ReleaseLockout 只解除设置了解锁时间的锁定
+func (r *Users) ReleaseLockout(id string) (bool, error) {
+	var released bool
+	err := r.update(id, func(u *models.User) bool {
+		if u.Status != "locked" || u.LockedUntil == nil {
+			return false
+		}
+		u.Status = "active"
+		u.FailedLoginAttempts = 0
+		u.LockedUntil = nil
+		released = true
+		return true
+	})
+	return released, err
+}
+
+// UpdatePassword 更新密码摘要和历史密码
+func (r *Users) UpdatePassword(id, passwordHash string, history []string) error {
+	return r.update(id, func(u *models.User) bool {
+		now := time.Now()
+		u.PasswordHash = passwordHash
+		u.PasswordHistory = history
+		u.PasswordChangedAt = &now
+		return true
+	})
+}
+
+// UpdatePasswordHash 以旧摘要为条件升级密码摘要
+func (r *Users) UpdatePasswordHash(id, oldHash, newHash string) error {
+	return r.update(id, func(u *models.User) bool {
+		if u.PasswordHash != oldHash {
+			return false
+		}
+		u.PasswordHash = newHash
+		return true
+	})
+}
+
+// UpdateEmail 更新邮箱，新邮箱未验证
+func (r *Users) UpdateEmail(id, email string) error {
+	return r.update(id, func(u *models.User) bool {
+		u.Email = email
+		u.EmailVerified = false
+		return true
+	})
+}
+
+// MarkEmailVerified 以邮箱为条件标记邮箱已验证
+func (r *Users) MarkEmailVerified(id, email string) (bool, error) {
+	var verified bool
+	err := r.update(id, func(u *models.User) bool {
+		if u.Email != email {
+			return false
+		}
+		u.EmailVerified = true
+		verified = true
+		return true
+	})
+	return verified, err
+}
+
+// UpdateLoginHistory 更新登录历史
+func (r *Users) UpdateLoginHistory(userID string, ip string) error {
+	return r.update(userID, func(u *models.User) bool {
+		u.LoginHistory.LastLoginAt = time.Now()
+		u.LoginHistory.LastIP = ip
+		u.LoginHistory.LoginCount++
+		return true
+	})
+}
+
+// AssignRole 为用户分配角色，已分配的角色更新为新的授权，不检查职责分离规则
+func (r *Users) AssignRole(userID, roleID string, grantedBy string, expiresAt *time.Time) error {
+	roleObjID, err := primitive.ObjectIDFromHex(roleID)
+	if err != nil {
+		return errors.New("invalid role ID format")
+	}
+	grantedByObjID, _ := primitive.ObjectIDFromHex(grantedBy)
+
+	return r.update(userID, func(u *models.User) bool {
+		roles := make([]models.UserRole, 0, len(u.Roles)+1)
+		for _, userRole := range u.Roles {
+			if userRole.RoleID != roleObjID {
+				roles = append(roles, userRole)
+			}
+		}
+		u.Roles = append(roles, models.UserRole{
+			RoleID:    roleObjID,
+			GrantedBy: grantedByObjID,
+			GrantedAt: time.Now(),
+			ExpiresAt: expiresAt,
+		})
+		return true
+	})
+}
+
+// FindByRoleIDs 获取直接或通过用户组被分配了其中任一角色的用户
+func (r *Users) FindByRoleIDs(roleIDs []primitive.ObjectID) ([]models.User, error) {
+	wanted := make(map[primitive.ObjectID]bool, len(roleIDs))
+	for _, roleID := range roleIDs {
+		wanted[roleID] = true
+	}
+
+	r.mutex.Lock()
+	users := make([]*models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, copyUser(user))
+	}
+	r.mutex.Unlock()
+
+	found := make([]models.User, 0)
+	for _, user := range users {
+		groupRoles, err := r.GroupRoles(user)
+		if err != nil {
+			return nil, err
+		}
+		for _, userRole := range append(user.Roles, groupRoles...) {
+			if wanted[userRole.RoleID] {
+				found = append(found, *user)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// SetGroups 设置计算用户组成员关系使用的用户组仓储
+func (r *Users) SetGroups(groups *Groups) {
+	r.groups = groups
+}
+
+// GroupRoles 获取用户在所属的全部租户中以及不属于任何租户的用户组的角色分配
+func (r *Users) GroupRoles(user *models.User) ([]models.UserRole, error) {
+	if r.groups == nil {
+		return []models.UserRole{}, nil
+	}
+	return r.groups.memberRoles(user), nil
+}
+
+// FindGroupMembers 获取用户组的成员，租户的用户组只包括该租户的成员
+func (r *Users) FindGroupMembers(group *models.Group) ([]models.User, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	members := make([]models.User, 0)
+	for _, user := range r.users {
+		if group.TenantID != nil && !user.MemberOf(*group.TenantID) {
+			continue
+		}
+		if isGroupMember(group, user) {
+			members = append(members, *copyUser(user))
+		}
+	}
+	return members, nil
+}
+
+// ForTenant 内存仓储不限定租户
+func (r *Users) ForTenant(tenantID primitive.ObjectID) userRepo.UserRepository {
+	return r
+}
+
+// find 返回第一个满足条件的用户的副本
+func (r *Users) find(match func(*models.User) bool) (*models.User, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, user := range r.users {
+		if match(user) {
+			return copyUser(user), nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+// update 在锁内修改用户，modify返回false表示不满足更新条件
+func (r *Users) update(id string, modify func(*models.User) bool) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, ok := r.users[objectID]
+	if !ok {
+		return errors.New("user not found")
+	}
+	updated := copyUser(user)
+	if modify(updated) {
+		updated.UpdatedAt = time.Now()
+		r.users[objectID] = updated
+	}
+	return nil
+}
+
+// copyUser 复制用户，避免调用方修改仓储中的数据
+func copyUser(user *models.User) *models.User {
+	c := *user
+	c.Roles = append([]models.UserRole(nil), user.Roles...)
+	c.Tenants = append([]models.TenantMembership(nil), user.Tenants...)
+	c.PasswordHistory = append([]string(nil), user.PasswordHistory...)
+	return &c
+}
@@ -0,0 +1,138 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VerificationTokens 内存邮件令牌仓储
+type VerificationTokens struct {
+	mutex  sync.Mutex
+	tokens []*models.VerificationToken
+}
+
+// NewVerificationTokens 创建内存邮件令牌仓储
+func NewVerificationTokens() *VerificationTokens {
+	return &VerificationTokens{}
+}
+
+// Create 保存令牌
+func (r *VerificationTokens) Create(ctx context.Context, token *models.VerificationToken) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	record := *token
+	r.tokens = append(r.tokens, &record)
+	return nil
+}
+
+// Consume 标记未使用且未过期的令牌已使用
+func (r *VerificationTokens) Consume(ctx context.Context, tokenHash, purpose string) (*models.VerificationToken, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose && !token.Used && token.ExpiresAt.After(now) {
+			token.Used = true
+			record := *token
+			return &record, nil
+		}
+	}
+	return nil, errors.New("token not found")
+}
+
+// InvalidateUserTokens 作废用户指定用途的全部未使用令牌
+func (r *VerificationTokens) InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose {
+			token.Used = true
+		}
+	}
+	return nil
+}
+
+// VerificationCodes 内存短信验证码仓储，每个接收方和用途保留一条记录
+type VerificationCodes struct {
+	mutex sync.Mutex
+	codes map[string]*models.VerificationCode
+}
+
+// NewVerificationCodes 创建内存短信验证码仓储
+func NewVerificationCodes() *VerificationCodes {
+	return &VerificationCodes{codes: make(map[string]*models.VerificationCode)}
+}
+
+// Get 获取接收方指定用途的验证码
+func (r *VerificationCodes) Get(ctx context.Context, target, purpose string) (*models.VerificationCode, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code, ok := r.codes[target+"/"+purpose]
+	if !ok {
+		return nil, errors.New("verification code not found")
+	}
+	record := *code
+	return &record, nil
+}
+
+// Save 保存验证码，覆盖同一接收方和用途的旧记录并沿用其ID
+func (r *VerificationCodes) Save(ctx context.Context, code *models.VerificationCode) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := code.Target + "/" + code.Purpose
+	record := *code
+	if existing, ok := r.codes[key]; ok {
+		record.ID = existing.ID
+	} else {
+		record.ID = primitive.NewObjectID()
+	}
+	r.codes[key] = &record
+	return nil
+}
+
+// IncrementAttempts 占用一次校验次数，已使用或次数已达maxAttempts时返回false
+func (r *VerificationCodes) IncrementAttempts(ctx context.Context, id primitive.ObjectID, maxAttempts int) (int, bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code := r.byID(id)
+	if code == nil || code.Used || code.Attempts >= maxAttempts {
+		return 0, false, nil
+	}
+	code.Attempts++
+	return code.Attempts, true, nil
+}
+
+// MarkUsed 标记验证码已使用，已被使用或校验次数超过maxAttempts时返回false
+func (r *VerificationCodes) MarkUsed(ctx context.Context, id primitive.ObjectID, maxAttempts int) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code := r.byID(id)
+	if code == nil || code.Used || code.Attempts > maxAttempts {
+		return false, nil
+	}
+	code.Used = true
+	return true, nil
+}
+
+// byID 按ID查找，调用方需持有锁
+func (r *VerificationCodes) byID(id primitive.ObjectID) *models.VerificationCode {
+	for _, code := range r.codes {
+		if code.ID == id {
+			return code
+		}
+	}
+	return nil
+}
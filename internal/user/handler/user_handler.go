@@ -2,12 +2,26 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
+	"authcenter/internal/middleware"
 	"authcenter/internal/user/service"
+	"authcenter/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
+// AssignRoleRequest 分配角色请求
+type AssignRoleRequest struct {
+	RoleID    string     `json:"role_id" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"` // 限时授权的过期时间（RFC 3339），为空时长期有效
+}
+
+// UpdateStatusRequest 更新用户状态请求
+type UpdateStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
 // UserHandler 用户处理器
 type UserHandler struct {
 	userService service.UserService
@@ -42,12 +56,54 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 // AssignRole 为用户分配角色
 func (h *UserHandler) AssignRole(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "assign role - not implemented"})
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.userService.ForTenant(middleware.TenantID(c)).AssignRole(c.Param("id"), req.RoleID, c.GetString("user_id"), req.ExpiresAt); err != nil {
+		response.Error(c, http.StatusBadRequest, "分配角色失败", err.Error())
+		return
+	}
+
+	response.Success(c, "分配成功")
 }
 
 // RemoveRole 移除用户角色
 func (h *UserHandler) RemoveRole(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "remove role - not implemented"})
+	if err := h.userService.ForTenant(middleware.TenantID(c)).RemoveRole(c, c.Param("id"), c.Param("role_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "移除角色失败", err.Error())
+		return
+	}
+
+	response.Success(c, "移除成功")
+}
+
+// UpdateUserStatus 更新用户状态
+func (h *UserHandler) UpdateUserStatus(c *gin.Context) {
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误", err.Error())
+		return
+	}
+
+	if err := h.userService.ForTenant(middleware.TenantID(c)).UpdateUserStatus(c, c.Param("id"), req.Status); err != nil {
+		response.Error(c, http.StatusBadRequest, "更新用户状态失败", err.Error())
+		return
+	}
+
+	response.Success(c, "更新成功")
+}
+
+// UnlockUser 解锁用户
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	if err := h.userService.ForTenant(middleware.TenantID(c)).UnlockUser(c.Param("id"), c.GetString("user_id")); err != nil {
+		response.Error(c, http.StatusBadRequest, "解锁用户失败", err.Error())
+		return
+	}
+
+	response.Success(c, "解锁成功")
 }
 
 // GetUserPermissions 获取用户权限
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	groupRepo "authcenter/internal/group/repository"
 	"authcenter/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,6 +14,16 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// SoDViolationError 分配角色违反静态职责分离规则
+type SoDViolationError struct {
+	Rule     string // 违反的规则名称
+	Username string // 违反规则的用户
+}
+
+func (e *SoDViolationError) Error() string {
+	return "separation of duties violated: " + e.Rule
+}
+
 // UserRepository 用户数据访问接口
 type UserRepository interface {
 	// Create 创建用户
@@ -39,11 +50,60 @@ type UserRepository interface {
 	// Update 更新用户
 	Update(id string, data *models.User) error
 
+	// UpdateStatus 更新用户状态
+	UpdateStatus(id, status string) error
+
+	// IncrementFailedLogins 累加登录失败次数，返回累加后的次数
+	IncrementFailedLogins(id string) (int, error)
+
+	// ResetFailedLogins 清零登录失败次数
+	ResetFailedLogins(id string) error
+
+	// Lock 因登录失败次数过多锁定用户，until为自动解锁时间；只锁定正常状态的用户，用户不是正常状态时返回false
+	Lock(id string, until time.Time) (bool, error)
+
+	// Unlock 解锁用户并清零登录失败次数
+	Unlock(id string) error
+
+	// ReleaseLockout 解除Lock设置的锁定并清零登录失败次数，管理员锁定或禁用的用户不受影响，未解除时返回false
+	ReleaseLockout(id string) (bool, error)
+
+	// UpdateMFA 更新多因素认证设置
+	UpdateMFA(id string, mfa *models.MFASettings) error
+
+	// UseTOTPCounter 记录已使用的TOTP时间步，时间步不大于已记录值时返回false
+	UseTOTPCounter(id string, counter int64) (bool, error)
+
+	// ConsumeRecoveryCode 消耗恢复码，恢复码不存在或已使用时返回false
+	ConsumeRecoveryCode(id, codeHash string) (bool, error)
+
+	// UpdatePassword 更新密码摘要和历史密码，记录修改时间
+	UpdatePassword(id, passwordHash string, history []string) error
+
+	// UpdatePasswordHash 升级同一密码的摘要，不改变历史密码和修改时间；密码已被修改时不做更新
+	UpdatePasswordHash(id, oldHash, newHash string) error
+
+	// UpdateEmail 更新邮箱，新邮箱需要重新验证
+	UpdateEmail(id, email string) error
+
+	// MarkEmailVerified 标记邮箱已验证，用户当前邮箱与email不一致时返回false
+	MarkEmailVerified(id, email string) (bool, error)
+
+	// AddWebAuthnCredential 添加通行密钥
+	AddWebAuthnCredential(id string, credential *models.WebAuthnCredential) error
+
+	// RemoveWebAuthnCredential 删除通行密钥
+	RemoveWebAuthnCredential(id, credentialID string) error
+
+	// UpdateWebAuthnCredentialUsage 登录成功后更新通行密钥的签名计数和使用时间
+	UpdateWebAuthnCredentialUsage(id, credentialID string, signCount uint32) error
+
 	// Delete 删除用户
 	Delete(id string) error
 
-	// AssignRole 为用户分配角色
-	AssignRole(userID, roleID string, grantedBy string) error
+	// AssignRole 为用户分配角色，expiresAt为空时长期有效，已分配的角色更新为新的授权
+	// 分配后与直接分配和所属用户组的角色一起违反静态职责分离规则时返回*SoDViolationError
+	AssignRole(userID, roleID string, grantedBy string, expiresAt *time.Time) error
 
 	// RemoveRole 移除用户角色
 	RemoveRole(userID, roleID string) error
@@ -54,17 +114,46 @@ type UserRepository interface {
 	// GetUserPermissions 获取用户权限
 	GetUserPermissions(userID string) ([]models.RolePermission, error)
 
+	// FindExpiredRoleGrants 获取存在已过期角色分配的用户
+	FindExpiredRoleGrants(now time.Time, limit int64) ([]models.User, error)
+
+	// RemoveExpiredRoles 移除用户已过期的角色分配
+	RemoveExpiredRoles(userID primitive.ObjectID, now time.Time) error
+
+	// FindByRoleIDs 获取直接或通过用户组被分配了其中任一角色的用户
+	FindByRoleIDs(roleIDs []primitive.ObjectID) ([]models.User, error)
+
+	// GroupRoles 获取用户在所属的全部租户中以及不属于任何租户的用户组的角色分配
+	GroupRoles(user *models.User) ([]models.UserRole, error)
+
+	// FindGroupMembers 获取用户组的成员，动态组按部门和职位匹配
+	FindGroupMembers(group *models.Group) ([]models.User, error)
+
+	// CheckSeparationOfDuties 检查用户在直接分配和所属用户组的角色之外再获得roleIDs后是否违反静态职责分离规则
+	// 违反时返回*SoDViolationError
+	CheckSeparationOfDuties(userIDs, roleIDs []primitive.ObjectID) error
+
 	// UpdateLoginHistory 更新登录历史
 	UpdateLoginHistory(userID string, ip string) error
 
-	// CheckUserExists 检查用户是否存在
+	// CheckUserExists 检查用户是否存在，用户名、邮箱和手机号在所有租户中唯一
 	CheckUserExists(username, email, phone string) (bool, error)
+
+	// AddTenant 将用户加入租户，已是成员时不做修改
+	AddTenant(userID string, tenantID primitive.ObjectID) error
+
+	// RemoveTenant 将用户移出租户，并移除该租户角色的分配
+	RemoveTenant(userID string, tenantID primitive.ObjectID) error
+
+	// ForTenant 返回只访问指定租户成员的仓储，分配和移除角色也只涉及该租户的角色；tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) UserRepository
 }
 
 // userRepository 用户仓储实现
 type userRepository struct {
 	db         *mongo.Database
 	collection *mongo.Collection
+	tenantID   primitive.ObjectID // 非零值时只访问该租户的成员
 }
 
 // NewUserRepository 创建用户仓储
@@ -75,6 +164,21 @@ func NewUserRepository(db *mongo.Database) UserRepository {
 	}
 }
 
+// ForTenant 返回只访问指定租户成员的仓储
+func (r *userRepository) ForTenant(tenantID primitive.ObjectID) UserRepository {
+	scoped := *r
+	scoped.tenantID = tenantID
+	return &scoped
+}
+
+// scope 为查询条件加上租户限定
+func (r *userRepository) scope(filter bson.M) bson.M {
+	if !r.tenantID.IsZero() {
+		filter["tenants.tenant_id"] = r.tenantID
+	}
+	return filter
+}
+
 // Create 创建用户
 func (r *userRepository) Create(user *models.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -90,6 +194,11 @@ func (r *userRepository) Create(user *models.User) error {
 		user.Status = "active"
 	}
 
+	// 限定租户时创建的用户加入该租户
+	if !r.tenantID.IsZero() && !user.MemberOf(r.tenantID) {
+		user.Tenants = append(user.Tenants, models.TenantMembership{TenantID: r.tenantID, JoinedAt: now})
+	}
+
 	// 插入用户
 	result, err := r.collection.InsertOne(ctx, user)
 	if err != nil {
@@ -112,7 +221,7 @@ func (r *userRepository) GetByID(id string) (*models.User, error) {
 	}
 
 	var user models.User
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("user not found")
@@ -129,7 +238,7 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	defer cancel()
 
 	var user models.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(ctx, r.scope(bson.M{"email": email})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("user not found")
@@ -146,7 +255,7 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	defer cancel()
 
 	var user models.User
-	err := r.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	err := r.collection.FindOne(ctx, r.scope(bson.M{"username": username})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("user not found")
@@ -163,7 +272,7 @@ func (r *userRepository) GetByPhone(phone string) (*models.User, error) {
 	defer cancel()
 
 	var user models.User
-	err := r.collection.FindOne(ctx, bson.M{"phone": phone}).Decode(&user)
+	err := r.collection.FindOne(ctx, r.scope(bson.M{"phone": phone})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("user not found")
@@ -188,7 +297,7 @@ func (r *userRepository) GetByUsernameOrEmail(identifier string) (*models.User,
 		},
 	}
 
-	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	err := r.collection.FindOne(ctx, r.scope(filter)).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("user not found")
@@ -214,7 +323,7 @@ func (r *userRepository) List(page, pageSize int) ([]*models.User, int64, error)
 	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}}) // 按创建时间倒序
 
 	// 查询用户
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{}), findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -226,7 +335,7 @@ func (r *userRepository) List(page, pageSize int) ([]*models.User, int64, error)
 	}
 
 	// 获取总数
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, r.scope(bson.M{}))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -250,7 +359,7 @@ func (r *userRepository) Update(id string, data *models.User) error {
 	// 创建更新文档
 	updateDoc := bson.M{"$set": data}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, updateDoc)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), updateDoc)
 	if err != nil {
 		return err
 	}
@@ -262,8 +371,8 @@ func (r *userRepository) Update(id string, data *models.User) error {
 	return nil
 }
 
-// Delete 删除用户
-func (r *userRepository) Delete(id string) error {
+// UpdateStatus 更新用户状态
+func (r *userRepository) UpdateStatus(id, status string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -272,61 +381,119 @@ func (r *userRepository) Delete(id string) error {
 		return errors.New("invalid user ID format")
 	}
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	// 管理员设置的状态不会自动解除，清除登录失败锁定的解锁时间
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"updated_at": time.Now(),
+		},
+		"$unset": bson.M{"locked_until": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return errors.New("user not found")
 	}
 
 	return nil
 }
 
-// AssignRole 为用户分配角色
-func (r *userRepository) AssignRole(userID, roleID string, grantedBy string) error {
+// IncrementFailedLogins 累加登录失败次数
+func (r *userRepository) IncrementFailedLogins(id string) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid user ID format")
+		return 0, errors.New("invalid user ID format")
 	}
 
-	roleObjectID, err := primitive.ObjectIDFromHex(roleID)
+	update := bson.M{
+		"$inc": bson.M{"failed_login_attempts": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user models.User
+	err = r.collection.FindOneAndUpdate(ctx, r.scope(bson.M{"_id": objectID}), update, opts).Decode(&user)
 	if err != nil {
-		return errors.New("invalid role ID format")
+		if err == mongo.ErrNoDocuments {
+			return 0, errors.New("user not found")
+		}
+		return 0, err
 	}
 
-	grantedByObjectID, err := primitive.ObjectIDFromHex(grantedBy)
+	return user.FailedLoginAttempts, nil
+}
+
+// ResetFailedLogins 清零登录失败次数
+func (r *userRepository) ResetFailedLogins(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid granted by ID format")
+		return errors.New("invalid user ID format")
 	}
 
-	// 首先获取角色信息
-	roleCollection := r.db.Collection("roles")
-	var role models.Role
-	err = roleCollection.FindOne(ctx, bson.M{"_id": roleObjectID}).Decode(&role)
+	_, err = r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), bson.M{
+		"$set": bson.M{"failed_login_attempts": 0},
+	})
+	return err
+}
+
+// Lock 锁定用户
+func (r *userRepository) Lock(id string, until time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("role not found")
+		return false, errors.New("invalid user ID format")
 	}
 
-	// 创建用户角色
-	userRole := models.UserRole{
-		RoleID:    roleObjectID,
-		RoleName:  role.Name,
-		GrantedBy: grantedByObjectID,
-		GrantedAt: time.Now(),
+	// 被禁用或已锁定的用户保持原状态，避免到期自动解锁时恢复管理员禁用的账户
+	filter := bson.M{"_id": objectID, "status": "active"}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       "locked",
+			"locked_until": until,
+			"updated_at":   time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(filter), update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// Unlock 解锁用户
+func (r *userRepository) Unlock(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
 	}
 
-	// 添加角色到用户
 	update := bson.M{
-		"$addToSet": bson.M{"roles": userRole},
-		"$set":      bson.M{"updated_at": time.Now()},
+		"$set": bson.M{
+			"status":                "active",
+			"failed_login_attempts": 0,
+			"updated_at":            time.Now(),
+		},
+		"$unset": bson.M{"locked_until": ""},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": userObjectID}, update)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
 	if err != nil {
 		return err
 	}
@@ -338,28 +505,57 @@ func (r *userRepository) AssignRole(userID, roleID string, grantedBy string) err
 	return nil
 }
 
-// RemoveRole 移除用户角色
-func (r *userRepository) RemoveRole(userID, roleID string) error {
+// ReleaseLockout 解除登录失败触发的锁定
+func (r *userRepository) ReleaseLockout(id string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid user ID format")
+		return false, errors.New("invalid user ID format")
 	}
 
-	roleObjectID, err := primitive.ObjectIDFromHex(roleID)
+	// 只有Lock设置了解锁时间，管理员锁定的用户没有解锁时间
+	filter := bson.M{
+		"_id":          objectID,
+		"status":       "locked",
+		"locked_until": bson.M{"$type": "date"},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":                "active",
+			"failed_login_attempts": 0,
+			"updated_at":            time.Now(),
+		},
+		"$unset": bson.M{"locked_until": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(filter), update)
 	if err != nil {
-		return errors.New("invalid role ID format")
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// UpdateMFA 更新多因素认证设置
+func (r *userRepository) UpdateMFA(id string, mfa *models.MFASettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
 	}
 
-	// 移除角色
 	update := bson.M{
-		"$pull": bson.M{"roles": bson.M{"role_id": roleObjectID}},
-		"$set":  bson.M{"updated_at": time.Now()},
+		"$set": bson.M{
+			"mfa":        mfa,
+			"updated_at": time.Now(),
+		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": userObjectID}, update)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
 	if err != nil {
 		return err
 	}
@@ -371,94 +567,127 @@ func (r *userRepository) RemoveRole(userID, roleID string) error {
 	return nil
 }
 
-// GetUserRoles 获取用户角色
-func (r *userRepository) GetUserRoles(userID string) ([]models.UserRole, error) {
+// UseTOTPCounter 记录已使用的TOTP时间步
+func (r *userRepository) UseTOTPCounter(id string, counter int64) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return false, errors.New("invalid user ID format")
 	}
 
-	var user models.User
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	// 条件更新保证同一验证码在并发请求中也只能使用一次
+	filter := bson.M{
+		"_id":                   objectID,
+		"mfa.last_used_counter": bson.M{"$lt": counter},
+	}
+	update := bson.M{"$set": bson.M{"mfa.last_used_counter": counter}}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(filter), update)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
+		return false, err
 	}
 
-	return user.Roles, nil
+	return result.ModifiedCount > 0, nil
 }
 
-// GetUserPermissions 获取用户权限
-func (r *userRepository) GetUserPermissions(userID string) ([]models.RolePermission, error) {
+// ConsumeRecoveryCode 消耗恢复码
+func (r *userRepository) ConsumeRecoveryCode(id, codeHash string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return false, errors.New("invalid user ID format")
 	}
 
-	// 使用聚合查询获取用户的所有权限
-	pipeline := []bson.M{
-		{"$match": bson.M{"_id": objectID}},
-		{"$unwind": "$roles"},
-		{"$lookup": bson.M{
-			"from":         "roles",
-			"localField":   "roles.role_id",
-			"foreignField": "_id",
-			"as":           "role_detail",
-		}},
-		{"$unwind": "$role_detail"},
-		{"$unwind": "$role_detail.permissions"},
-		{"$group": bson.M{
-			"_id":        "$role_detail.permissions",
-			"permission": bson.M{"$first": "$role_detail.permissions"},
-		}},
-		{"$replaceRoot": bson.M{"newRoot": "$permission"}},
+	filter := bson.M{
+		"_id":                objectID,
+		"mfa.recovery_codes": codeHash,
 	}
+	update := bson.M{"$pull": bson.M{"mfa.recovery_codes": codeHash}}
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	result, err := r.collection.UpdateOne(ctx, r.scope(filter), update)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	defer cursor.Close(ctx)
 
-	var permissions []models.RolePermission
-	if err = cursor.All(ctx, &permissions); err != nil {
-		return nil, err
+	return result.ModifiedCount > 0, nil
+}
+
+// UpdatePassword 更新密码摘要和历史密码
+func (r *userRepository) UpdatePassword(id, passwordHash string, history []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
 	}
 
-	return permissions, nil
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"password_hash":       passwordHash,
+			"password_history":    history,
+			"password_changed_at": now,
+			"updated_at":          now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
 }
 
-// UpdateLoginHistory 更新登录历史
-func (r *userRepository) UpdateLoginHistory(userID string, ip string) error {
+// UpdatePasswordHash 升级密码摘要，以旧摘要为条件避免覆盖并发修改的新密码
+func (r *userRepository) UpdatePasswordHash(id, oldHash, newHash string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	filter := bson.M{"_id": objectID, "password_hash": oldHash}
+	update := bson.M{"$set": bson.M{"password_hash": newHash}}
+
+	_, err = r.collection.UpdateOne(ctx, r.scope(filter), update)
+	return err
+}
+
+// UpdateEmail 更新邮箱并清除验证状态
+func (r *userRepository) UpdateEmail(id, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return errors.New("invalid user ID format")
 	}
 
 	update := bson.M{
 		"$set": bson.M{
-			"login_history.last_login_at": time.Now(),
-			"login_history.last_ip":       ip,
-			"updated_at":                  time.Now(),
-		},
-		"$inc": bson.M{
-			"login_history.login_count": 1,
+			"email":          email,
+			"email_verified": false,
+			"updated_at":     time.Now(),
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("email already exists")
+		}
 		return err
 	}
 
@@ -469,28 +698,818 @@ func (r *userRepository) UpdateLoginHistory(userID string, ip string) error {
 	return nil
 }
 
-// CheckUserExists 检查用户是否存在
-func (r *userRepository) CheckUserExists(username, email, phone string) (bool, error) {
+// MarkEmailVerified 标记邮箱已验证
+func (r *userRepository) MarkEmailVerified(id, email string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// 构建查询条件
-	filter := bson.M{"$or": []bson.M{}}
-
-	if username != "" {
-		filter["$or"] = append(filter["$or"].([]bson.M), bson.M{"username": username})
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, errors.New("invalid user ID format")
 	}
-	if email != "" {
-		filter["$or"] = append(filter["$or"].([]bson.M), bson.M{"email": email})
+
+	// 按邮箱条件更新，验证邮件发出后邮箱又被修改时旧邮件不再生效
+	filter := bson.M{
+		"_id":   objectID,
+		"email": email,
 	}
-	if phone != "" {
-		filter["$or"] = append(filter["$or"].([]bson.M), bson.M{"phone": phone})
+	update := bson.M{
+		"$set": bson.M{
+			"email_verified": true,
+			"updated_at":     time.Now(),
+		},
 	}
 
-	count, err := r.collection.CountDocuments(ctx, filter)
+	result, err := r.collection.UpdateOne(ctx, r.scope(filter), update)
 	if err != nil {
 		return false, err
 	}
 
-	return count > 0, nil
+	return result.MatchedCount > 0, nil
+}
+
+// AddWebAuthnCredential 添加通行密钥
+func (r *userRepository) AddWebAuthnCredential(id string, credential *models.WebAuthnCredential) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	update := bson.M{
+		"$push": bson.M{"webauthn_credentials": credential},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("credential already registered")
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// RemoveWebAuthnCredential 删除通行密钥
+func (r *userRepository) RemoveWebAuthnCredential(id, credentialID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	filter := bson.M{
+		"_id":                                objectID,
+		"webauthn_credentials.credential_id": credentialID,
+	}
+	update := bson.M{
+		"$pull": bson.M{"webauthn_credentials": bson.M{"credential_id": credentialID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(filter), update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("credential not found")
+	}
+
+	return nil
+}
+
+// UpdateWebAuthnCredentialUsage 更新通行密钥的签名计数和使用时间
+func (r *userRepository) UpdateWebAuthnCredentialUsage(id, credentialID string, signCount uint32) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	filter := bson.M{
+		"_id":                                objectID,
+		"webauthn_credentials.credential_id": credentialID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"webauthn_credentials.$.sign_count":   signCount,
+			"webauthn_credentials.$.last_used_at": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(filter), update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("credential not found")
+	}
+
+	return nil
+}
+
+// Delete 删除用户
+func (r *userRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	result, err := r.collection.DeleteOne(ctx, r.scope(bson.M{"_id": objectID}))
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// AssignRole 为用户分配角色
+func (r *userRepository) AssignRole(userID, roleID string, grantedBy string, expiresAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	roleObjectID, err := primitive.ObjectIDFromHex(roleID)
+	if err != nil {
+		return errors.New("invalid role ID format")
+	}
+
+	grantedByObjectID, err := primitive.ObjectIDFromHex(grantedBy)
+	if err != nil {
+		return errors.New("invalid granted by ID format")
+	}
+
+	// 首先获取角色信息，限定租户时只能分配该租户的角色
+	roleFilter := bson.M{"_id": roleObjectID}
+	if !r.tenantID.IsZero() {
+		roleFilter["tenant_id"] = r.tenantID
+	}
+	roleCollection := r.db.Collection("roles")
+	var role models.Role
+	err = roleCollection.FindOne(ctx, roleFilter).Decode(&role)
+	if err != nil {
+		return errors.New("role not found")
+	}
+
+	// 租户的角色只能分配给该租户的成员
+	userFilter := r.scope(bson.M{"_id": userObjectID})
+	if role.TenantID != nil {
+		userFilter["tenants.tenant_id"] = *role.TenantID
+	}
+
+	if err := r.checkSeparationOfDuties(ctx, userObjectID, roleObjectID); err != nil {
+		return err
+	}
+
+	// 创建用户角色
+	userRole := models.UserRole{
+		RoleID:    roleObjectID,
+		RoleName:  role.Name,
+		GrantedBy: grantedByObjectID,
+		GrantedAt: time.Now(),
+		ExpiresAt: expiresAt,
+		TenantID:  role.TenantID,
+	}
+
+	// 已分配的角色替换为新的授权，用于延长或取消期限
+	replaceFilter := bson.M{"roles.role_id": roleObjectID}
+	for key, value := range userFilter {
+		replaceFilter[key] = value
+	}
+	result, err := r.collection.UpdateOne(ctx,
+		replaceFilter,
+		bson.M{"$set": bson.M{"roles.$": userRole, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	// 添加角色到用户
+	update := bson.M{
+		"$addToSet": bson.M{"roles": userRole},
+		"$set":      bson.M{"updated_at": time.Now()},
+	}
+
+	result, err = r.collection.UpdateOne(ctx, userFilter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// checkSeparationOfDuties 检查为用户分配角色后是否违反静态职责分离规则，角色及其祖先角色均参与检查，已过期的分配不计入
+// 用户所属用户组的角色与直接分配的角色一起检查
+func (r *userRepository) checkSeparationOfDuties(ctx context.Context, userID, roleID primitive.ObjectID) error {
+	granted, rules, err := r.staticRules(ctx, []primitive.ObjectID{roleID})
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	var user models.User
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": userID}),
+		options.FindOne().SetProjection(sodProjection),
+	).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("user not found")
+		}
+		return err
+	}
+
+	// 重新分配同一角色时替换原分配，不与其自身冲突
+	held, err := r.heldRoles(ctx, &user, roleID)
+	if err != nil {
+		return err
+	}
+
+	if rule := violatedRule(rules, granted, held); rule != "" {
+		return &SoDViolationError{Rule: rule, Username: user.Username}
+	}
+	return nil
+}
+
+// CheckSeparationOfDuties 检查用户获得角色后是否违反静态职责分离规则
+func (r *userRepository) CheckSeparationOfDuties(userIDs, roleIDs []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if len(userIDs) == 0 || len(roleIDs) == 0 {
+		return nil
+	}
+
+	granted, rules, err := r.staticRules(ctx, roleIDs)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{"_id": bson.M{"$in": userIDs}}),
+		options.Find().SetProjection(sodProjection),
+	)
+	if err != nil {
+		return err
+	}
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return err
+	}
+
+	for i := range users {
+		held, err := r.heldRoles(ctx, &users[i], primitive.NilObjectID)
+		if err != nil {
+			return err
+		}
+		if rule := violatedRule(rules, granted, held); rule != "" {
+			return &SoDViolationError{Rule: rule, Username: users[i].Username}
+		}
+	}
+
+	return nil
+}
+
+// sodProjection 检查职责分离规则需要的用户字段：角色，以及确定所属用户组的资料和租户
+var sodProjection = bson.M{"_id": 1, "username": 1, "roles": 1, "profile": 1, "tenants": 1}
+
+// staticRules 返回角色及其祖先角色的ID集合，以及涉及其中任一角色的静态职责分离规则
+func (r *userRepository) staticRules(ctx context.Context, roleIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, []models.SoDRule, error) {
+	granted, err := r.expandRoles(ctx, roleIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grantedIDs := make([]primitive.ObjectID, 0, len(granted))
+	for id := range granted {
+		grantedIDs = append(grantedIDs, id)
+	}
+
+	cursor, err := r.db.Collection("sod_rules").Find(ctx, bson.M{
+		"type":     "static",
+		"role_ids": bson.M{"$in": grantedIDs},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var rules []models.SoDRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, nil, err
+	}
+
+	return granted, rules, nil
+}
+
+// heldRoles 返回用户未过期的直接分配和所属用户组的角色及其祖先角色的ID集合，exclude为不计入的直接分配
+func (r *userRepository) heldRoles(ctx context.Context, user *models.User, exclude primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	groupRoles, err := r.groupRoles(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	heldIDs := make([]primitive.ObjectID, 0, len(user.Roles)+len(groupRoles))
+	for _, userRole := range user.Roles {
+		if userRole.RoleID != exclude && !userRole.Expired(now) {
+			heldIDs = append(heldIDs, userRole.RoleID)
+		}
+	}
+	for _, groupRole := range groupRoles {
+		if !groupRole.Expired(now) {
+			heldIDs = append(heldIDs, groupRole.RoleID)
+		}
+	}
+
+	return r.expandRoles(ctx, heldIDs)
+}
+
+// violatedRule 返回同时拥有其中两个及以上角色的第一条规则的名称，没有违反时为空
+func violatedRule(rules []models.SoDRule, granted, held map[primitive.ObjectID]bool) string {
+	for _, rule := range rules {
+		count := 0
+		for _, id := range rule.RoleIDs {
+			if granted[id] || held[id] {
+				count++
+			}
+		}
+		if count > 1 {
+			return rule.Name
+		}
+	}
+	return ""
+}
+
+// GroupRoles 获取用户所属用户组的角色分配
+func (r *userRepository) GroupRoles(user *models.User) ([]models.UserRole, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return r.groupRoles(ctx, user)
+}
+
+// groupRoles 获取用户所属用户组的角色分配
+func (r *userRepository) groupRoles(ctx context.Context, user *models.User) ([]models.UserRole, error) {
+	cursor, err := r.db.Collection("groups").Find(ctx, groupRepo.AllTenantsMemberFilter(user),
+		options.Find().SetProjection(bson.M{"roles": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []models.Group
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	roles := make([]models.UserRole, 0)
+	for _, group := range groups {
+		roles = append(roles, group.Roles...)
+	}
+	return roles, nil
+}
+
+// FindGroupMembers 获取用户组的成员，只返回ID和用户名
+func (r *userRepository) FindGroupMembers(group *models.Group) ([]models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetProjection(bson.M{"_id": 1, "username": 1})
+
+	cursor, err := r.collection.Find(ctx, r.scope(groupRepo.MembersFilter(group)), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// expandRoles 返回角色及其全部祖先角色的ID集合
+func (r *userRepository) expandRoles(ctx context.Context, roleIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	expanded := make(map[primitive.ObjectID]bool)
+	if len(roleIDs) == 0 {
+		return expanded, nil
+	}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"_id": bson.M{"$in": roleIDs}}},
+		{"$graphLookup": bson.M{
+			"from":             "roles",
+			"startWith":        "$parent_ids",
+			"connectFromField": "parent_ids",
+			"connectToField":   "_id",
+			"as":               "ancestors",
+		}},
+		{"$project": bson.M{"ancestors._id": 1}},
+	}
+
+	cursor, err := r.db.Collection("roles").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Ancestors []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		} `bson:"ancestors"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		expanded[result.ID] = true
+		for _, ancestor := range result.Ancestors {
+			expanded[ancestor.ID] = true
+		}
+	}
+
+	return expanded, nil
+}
+
+// RemoveRole 移除用户角色
+func (r *userRepository) RemoveRole(userID, roleID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	roleObjectID, err := primitive.ObjectIDFromHex(roleID)
+	if err != nil {
+		return errors.New("invalid role ID format")
+	}
+
+	// 移除角色，限定租户时只移除该租户的角色
+	pull := bson.M{"role_id": roleObjectID}
+	if !r.tenantID.IsZero() {
+		pull["tenant_id"] = r.tenantID
+	}
+	update := bson.M{
+		"$pull": bson.M{"roles": pull},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": userObjectID}), update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// GetUserRoles 获取用户角色
+func (r *userRepository) GetUserRoles(userID string) ([]models.UserRole, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID format")
+	}
+
+	var user models.User
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	if !r.tenantID.IsZero() {
+		return user.RolesIn(r.tenantID), nil
+	}
+	return user.Roles, nil
+}
+
+// GetUserPermissions 获取用户权限，包括直接分配和所属用户组的角色的权限
+func (r *userRepository) GetUserPermissions(userID string) ([]models.RolePermission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID format")
+	}
+
+	var user models.User
+	err = r.collection.FindOne(ctx, r.scope(bson.M{"_id": objectID})).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	roleIDs, err := r.effectiveRoleIDs(ctx, &user)
+	if err != nil {
+		return nil, err
+	}
+	if len(roleIDs) == 0 {
+		return []models.RolePermission{}, nil
+	}
+
+	// 使用聚合查询获取角色的所有权限，包括从祖先角色继承的权限
+	pipeline := []bson.M{
+		{"$match": bson.M{"_id": bson.M{"$in": roleIDs}}},
+		{"$graphLookup": bson.M{
+			"from":             "roles",
+			"startWith":        "$parent_ids",
+			"connectFromField": "parent_ids",
+			"connectToField":   "_id",
+			"as":               "ancestors",
+		}},
+		{"$project": bson.M{
+			"role_detail": bson.M{"$concatArrays": bson.A{bson.A{"$$ROOT"}, "$ancestors"}},
+		}},
+		{"$unwind": "$role_detail"},
+		{"$unwind": "$role_detail.permissions"},
+		{"$group": bson.M{
+			"_id":        "$role_detail.permissions",
+			"permission": bson.M{"$first": "$role_detail.permissions"},
+		}},
+		{"$replaceRoot": bson.M{"newRoot": "$permission"}},
+	}
+
+	cursor, err := r.db.Collection("roles").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var permissions []models.RolePermission
+	if err = cursor.All(ctx, &permissions); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// effectiveRoleIDs 用户未过期的角色分配，包括所属用户组的角色
+// 限定租户时只包括该租户和全局的角色分配及用户组，不限定租户时包括全部直接分配的角色和不属于任何租户的用户组
+func (r *userRepository) effectiveRoleIDs(ctx context.Context, user *models.User) ([]primitive.ObjectID, error) {
+	assignments := user.Roles
+	groupFilter := groupRepo.MemberFilter(user)
+	if r.tenantID.IsZero() {
+		groupFilter["tenant_id"] = bson.M{"$exists": false}
+	} else {
+		assignments = user.RolesIn(r.tenantID)
+		groupFilter["tenant_id"] = bson.M{"$in": bson.A{nil, r.tenantID}}
+	}
+
+	cursor, err := r.db.Collection("groups").Find(ctx, groupFilter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []models.Group
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		assignments = append(assignments, groups[i].RolesIn(r.tenantID)...)
+	}
+
+	now := time.Now()
+	seen := make(map[primitive.ObjectID]bool, len(assignments))
+	roleIDs := make([]primitive.ObjectID, 0, len(assignments))
+	for _, userRole := range assignments {
+		if userRole.Expired(now) || seen[userRole.RoleID] {
+			continue
+		}
+		seen[userRole.RoleID] = true
+		roleIDs = append(roleIDs, userRole.RoleID)
+	}
+
+	return roleIDs, nil
+}
+
+// FindExpiredRoleGrants 获取存在已过期角色分配的用户，只返回ID、用户名和角色
+func (r *userRepository) FindExpiredRoleGrants(now time.Time, limit int64) ([]models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().
+		SetProjection(bson.M{"_id": 1, "username": 1, "roles": 1}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{"roles.expires_at": bson.M{"$lte": now}}), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// RemoveExpiredRoles 移除用户在now之前过期的角色分配，期间被重新授权的角色不受影响
+func (r *userRepository) RemoveExpiredRoles(userID primitive.ObjectID, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$pull": bson.M{"roles": bson.M{"expires_at": bson.M{"$lte": now}}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": userID}), update)
+	return err
+}
+
+// FindByRoleIDs 获取直接或通过用户组被分配了其中任一角色的用户，只返回ID、用户名、角色，以及确定所属用户组的资料和租户
+func (r *userRepository) FindByRoleIDs(roleIDs []primitive.ObjectID) ([]models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	groupCursor, err := r.db.Collection("groups").Find(ctx, bson.M{"roles.role_id": bson.M{"$in": roleIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var groups []models.Group
+	if err = groupCursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	conditions := bson.A{bson.M{"roles.role_id": bson.M{"$in": roleIDs}}}
+	for i := range groups {
+		conditions = append(conditions, groupRepo.MembersFilter(&groups[i]))
+	}
+
+	findOptions := options.Find().SetProjection(sodProjection)
+
+	cursor, err := r.collection.Find(ctx, r.scope(bson.M{"$or": conditions}), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// UpdateLoginHistory 更新登录历史
+func (r *userRepository) UpdateLoginHistory(userID string, ip string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"login_history.last_login_at": time.Now(),
+			"login_history.last_ip":       ip,
+			"updated_at":                  time.Now(),
+		},
+		"$inc": bson.M{
+			"login_history.login_count": 1,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, r.scope(bson.M{"_id": objectID}), update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// CheckUserExists 检查用户是否存在
+func (r *userRepository) CheckUserExists(username, email, phone string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 构建查询条件
+	filter := bson.M{"$or": []bson.M{}}
+
+	if username != "" {
+		filter["$or"] = append(filter["$or"].([]bson.M), bson.M{"username": username})
+	}
+	if email != "" {
+		filter["$or"] = append(filter["$or"].([]bson.M), bson.M{"email": email})
+	}
+	if phone != "" {
+		filter["$or"] = append(filter["$or"].([]bson.M), bson.M{"phone": phone})
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// AddTenant 将用户加入租户
+func (r *userRepository) AddTenant(userID string, tenantID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"updated_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	// 已是成员时不重复加入，保留原加入时间
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "tenants.tenant_id": bson.M{"$ne": tenantID}},
+		bson.M{"$push": bson.M{"tenants": models.TenantMembership{TenantID: tenantID, JoinedAt: time.Now()}}},
+	)
+	return err
+}
+
+// RemoveTenant 将用户移出租户，并移除该租户角色的分配
+func (r *userRepository) RemoveTenant(userID string, tenantID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+
+	update := bson.M{
+		"$pull": bson.M{
+			"tenants": bson.M{"tenant_id": tenantID},
+			"roles":   bson.M{"tenant_id": tenantID},
+		},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID, "tenants.tenant_id": tenantID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
 }
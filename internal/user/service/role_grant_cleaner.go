@@ -0,0 +1,85 @@
+package service
+
+import (
+	"time"
+
+	"authcenter/internal/user/repository"
+	"authcenter/pkg/logger"
+)
+
+// roleGrantCleanupBatch 每次清理处理的最大用户数
+const roleGrantCleanupBatch = 500
+
+// RoleGrantCleaner 限时角色分配清理服务接口
+// 过期的分配在签发令牌时已被忽略，访问令牌的有效期也不超过分配的过期时间，清理只是从用户数据中移除并记录审计日志
+type RoleGrantCleaner interface {
+	// Start 启动后台任务，定期移除已过期的角色分配
+	Start(interval time.Duration)
+}
+
+// roleGrantCleaner 限时角色分配清理服务实现
+type roleGrantCleaner struct {
+	userRepo repository.UserRepository
+}
+
+// NewRoleGrantCleaner 创建限时角色分配清理服务
+func NewRoleGrantCleaner(userRepo repository.UserRepository) RoleGrantCleaner {
+	return &roleGrantCleaner{
+		userRepo: userRepo,
+	}
+}
+
+// Start 启动后台清理
+func (c *roleGrantCleaner) Start(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			c.cleanup()
+		}
+	}()
+}
+
+// cleanup 执行一次清理，逐个用户移除过期分配并为每个分配记录审计日志
+func (c *roleGrantCleaner) cleanup() {
+	now := time.Now()
+
+	users, err := c.userRepo.FindExpiredRoleGrants(now, roleGrantCleanupBatch)
+	if err != nil {
+		logger.Error("查询过期角色分配失败: %v", err)
+		return
+	}
+
+	count := 0
+	for _, user := range users {
+		if err := c.userRepo.RemoveExpiredRoles(user.ID, now); err != nil {
+			logger.Error("移除过期角色分配失败: %s, %v", user.ID.Hex(), err)
+			continue
+		}
+
+		for _, role := range user.Roles {
+			if !role.Expired(now) {
+				continue
+			}
+			count++
+			logger.Audit("role_grant_expired", map[string]interface{}{
+				"user_id":    user.ID.Hex(),
+				"username":   user.Username,
+				"role_id":    role.RoleID.Hex(),
+				"role_name":  role.RoleName,
+				"granted_by": role.GrantedBy.Hex(),
+				"granted_at": role.GrantedAt.Format(time.RFC3339),
+				"expires_at": role.ExpiresAt.Format(time.RFC3339),
+			})
+		}
+	}
+
+	if count > 0 {
+		logger.Info("已移除%d个过期角色分配", count)
+	}
+}
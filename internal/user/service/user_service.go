@@ -1,8 +1,26 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	authRepo "authcenter/internal/auth/repository"
+	authService "authcenter/internal/auth/service"
 	roleRepo "authcenter/internal/role/repository"
+	roleService "authcenter/internal/role/service"
 	"authcenter/internal/user/repository"
+	"authcenter/pkg/logger"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 用户状态
+const (
+	UserStatusActive   = "active"
+	UserStatusInactive = "inactive"
+	UserStatusLocked   = "locked"
 )
 
 // UserService 用户业务逻辑接口
@@ -19,30 +37,57 @@ type UserService interface {
 	// DeleteUser 删除用户
 	DeleteUser(id string) error
 
-	// AssignRole 为用户分配角色
-	AssignRole(userID, roleID string) error
+	// AssignRole 为用户分配角色，expiresAt为空时长期有效
+	AssignRole(userID, roleID, grantedBy string, expiresAt *time.Time) error
+
+	// RemoveRole 移除用户角色，用户已签发的访问令牌随即失效
+	RemoveRole(ctx context.Context, userID, roleID string) error
+
+	// UpdateUserStatus 更新用户状态，非激活状态会吊销用户的会话和访问令牌
+	UpdateUserStatus(ctx context.Context, userID, status string) error
 
-	// RemoveRole 移除用户角色
-	RemoveRole(userID, roleID string) error
+	// UnlockUser 解锁因登录失败次数过多被锁定的用户
+	UnlockUser(userID, operatorID string) error
 
 	// GetUserPermissions 获取用户权限
 	GetUserPermissions(userID string) (interface{}, error)
+
+	// ForTenant 返回只管理指定租户成员和角色的服务，tenantID为零值时不限定租户
+	ForTenant(tenantID primitive.ObjectID) UserService
 }
 
 // userService 用户服务实现
 type userService struct {
-	userRepo repository.UserRepository
-	roleRepo roleRepo.RoleRepository
+	userRepo    repository.UserRepository
+	roleRepo    roleRepo.RoleRepository
+	sessionRepo authRepo.SessionRepository
+	revocation  authService.RevocationService
+	tenantID    primitive.ObjectID // 非零值时只管理该租户的成员和角色
 }
 
 // NewUserService 创建用户服务
-func NewUserService(userRepo repository.UserRepository, roleRepo roleRepo.RoleRepository) UserService {
+func NewUserService(
+	userRepo repository.UserRepository,
+	roleRepo roleRepo.RoleRepository,
+	sessionRepo authRepo.SessionRepository,
+	revocation authService.RevocationService,
+) UserService {
 	return &userService{
-		userRepo: userRepo,
-		roleRepo: roleRepo,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		sessionRepo: sessionRepo,
+		revocation:  revocation,
 	}
 }
 
+// ForTenant 返回只管理指定租户成员和角色的服务
+func (s *userService) ForTenant(tenantID primitive.ObjectID) UserService {
+	scoped := *s
+	scoped.tenantID = tenantID
+	scoped.userRepo = s.userRepo.ForTenant(tenantID)
+	return &scoped
+}
+
 // GetUserByID 通过ID获取用户
 func (s *userService) GetUserByID(id string) (interface{}, error) {
 	// TODO: 实现获取用户逻辑
@@ -67,15 +112,110 @@ func (s *userService) DeleteUser(id string) error {
 	return nil
 }
 
-// AssignRole 为用户分配角色
-func (s *userService) AssignRole(userID, roleID string) error {
-	// TODO: 实现分配角色逻辑
+// AssignRole 为用户分配角色，角色级别不能高于操作者拥有的最高级别
+func (s *userService) AssignRole(userID, roleID, grantedBy string, expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return errors.New("过期时间必须晚于当前时间")
+	}
+
+	operator, err := s.userRepo.GetByID(grantedBy)
+	if err != nil {
+		return errors.New("操作者不存在")
+	}
+
+	// 租户中只能分配该租户的角色，操作者的级别按其在租户中生效的角色计算，包括全局角色
+	role, err := s.roleRepo.ForTenant(s.tenantID).GetByID(roleID)
+	if err != nil {
+		return errors.New("角色不存在")
+	}
+
+	if err := roleService.CheckAssignable(s.roleRepo, operator.RolesIn(s.tenantID), role); err != nil {
+		logger.SecurityEvent("role_assignment_denied", map[string]interface{}{
+			"user_id":     userID,
+			"role_id":     roleID,
+			"role_level":  role.Level,
+			"operator_id": grantedBy,
+		})
+		return err
+	}
+
+	if err := s.userRepo.AssignRole(userID, roleID, grantedBy, expiresAt); err != nil {
+		var violation *repository.SoDViolationError
+		if errors.As(err, &violation) {
+			logger.SecurityEvent("sod_violation_blocked", map[string]interface{}{
+				"user_id":     userID,
+				"role_id":     roleID,
+				"operator_id": grantedBy,
+				"rule":        violation.Rule,
+			})
+			return fmt.Errorf("违反职责分离规则: %s", violation.Rule)
+		}
+		return err
+	}
+
 	return nil
 }
 
 // RemoveRole 移除用户角色
-func (s *userService) RemoveRole(userID, roleID string) error {
-	// TODO: 实现移除角色逻辑
+// 访问令牌中携带了角色和权限，需吊销后由客户端刷新获取新的令牌
+func (s *userService) RemoveRole(ctx context.Context, userID, roleID string) error {
+	if err := s.userRepo.RemoveRole(userID, roleID); err != nil {
+		return err
+	}
+
+	return s.revocation.RevokeUser(ctx, userID, "role_removed")
+}
+
+// UpdateUserStatus 更新用户状态
+func (s *userService) UpdateUserStatus(ctx context.Context, userID, status string) error {
+	switch status {
+	case UserStatusActive, UserStatusInactive, UserStatusLocked:
+	default:
+		return errors.New("无效的用户状态")
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("无效的用户ID")
+	}
+
+	// 重新激活时一并清除锁定信息
+	if status == UserStatusActive {
+		return s.userRepo.Unlock(userID)
+	}
+
+	if err := s.userRepo.UpdateStatus(userID, status); err != nil {
+		return err
+	}
+
+	// 禁用用户立即生效：吊销刷新令牌所在的会话以及已签发的访问令牌
+	if err := s.sessionRepo.RevokeUserSessions(ctx, userObjID); err != nil {
+		return err
+	}
+	return s.revocation.RevokeUser(ctx, userID, "user_"+status)
+}
+
+// UnlockUser 解锁用户
+func (s *userService) UnlockUser(userID, operatorID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Status != UserStatusLocked {
+		return errors.New("用户未被锁定")
+	}
+
+	if err := s.userRepo.Unlock(userID); err != nil {
+		return err
+	}
+
+	logger.SecurityEvent("account_unlocked", map[string]interface{}{
+		"user_id":     userID,
+		"operator_id": operatorID,
+		"reason":      "admin",
+	})
+
 	return nil
 }
 
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CodeRepository 验证码数据访问接口
+type CodeRepository interface {
+	// Get 获取接收方指定用途的验证码
+	Get(ctx context.Context, target, purpose string) (*models.VerificationCode, error)
+
+	// Save 保存验证码，覆盖同一接收方和用途的旧记录
+	Save(ctx context.Context, code *models.VerificationCode) error
+
+	// IncrementAttempts 在校验前占用一次校验次数，返回累加后的次数；已使用或次数已达maxAttempts时返回false
+	IncrementAttempts(ctx context.Context, id primitive.ObjectID, maxAttempts int) (int, bool, error)
+
+	// MarkUsed 标记验证码已使用，已被使用或校验次数超过maxAttempts时返回false
+	MarkUsed(ctx context.Context, id primitive.ObjectID, maxAttempts int) (bool, error)
+}
+
+// codeRepository 验证码仓储实现
+type codeRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewCodeRepository 创建验证码仓储
+func NewCodeRepository(db *mongo.Database) CodeRepository {
+	return &codeRepository{
+		db:         db,
+		collection: db.Collection("verification_codes"),
+	}
+}
+
+// Get 获取接收方指定用途的验证码
+func (r *codeRepository) Get(ctx context.Context, target, purpose string) (*models.VerificationCode, error) {
+	var code models.VerificationCode
+	err := r.collection.FindOne(ctx, bson.M{"target": target, "purpose": purpose}).Decode(&code)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("verification code not found")
+		}
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// Save 保存验证码
+func (r *codeRepository) Save(ctx context.Context, code *models.VerificationCode) error {
+	filter := bson.M{"target": code.Target, "purpose": code.Purpose}
+	update := bson.M{"$set": bson.M{
+		"code_hash":         code.CodeHash,
+		"attempts":          code.Attempts,
+		"used":              code.Used,
+		"expires_at":        code.ExpiresAt,
+		"sent_at":           code.SentAt,
+		"send_count":        code.SendCount,
+		"window_started_at": code.WindowStartedAt,
+		"purge_at":          code.PurgeAt,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// IncrementAttempts 占用一次校验次数，条件更新保证并发请求的校验总数不超过上限
+func (r *codeRepository) IncrementAttempts(ctx context.Context, id primitive.ObjectID, maxAttempts int) (int, bool, error) {
+	filter := bson.M{
+		"_id":      id,
+		"used":     false,
+		"attempts": bson.M{"$lt": maxAttempts},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var code models.VerificationCode
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"attempts": 1}}, opts).Decode(&code)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return code.Attempts, true, nil
+}
+
+// MarkUsed 标记验证码已使用，条件更新保证并发请求中只有一个成功
+// 校验次数在比对前已累加，本次校验计入其中，因此允许等于上限
+func (r *codeRepository) MarkUsed(ctx context.Context, id primitive.ObjectID, maxAttempts int) (bool, error) {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "used": false, "attempts": bson.M{"$lte": maxAttempts}},
+		bson.M{"$set": bson.M{"used": true}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"authcenter/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TokenRepository 邮件令牌数据访问接口
+type TokenRepository interface {
+	// Create 保存令牌
+	Create(ctx context.Context, token *models.VerificationToken) error
+
+	// Consume 标记令牌已使用并返回令牌，令牌不存在、已使用或已过期时返回错误
+	Consume(ctx context.Context, tokenHash, purpose string) (*models.VerificationToken, error)
+
+	// InvalidateUserTokens 作废用户指定用途的全部未使用令牌
+	InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID, purpose string) error
+}
+
+// tokenRepository 邮件令牌仓储实现
+type tokenRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewTokenRepository 创建邮件令牌仓储
+func NewTokenRepository(db *mongo.Database) TokenRepository {
+	return &tokenRepository{
+		db:         db,
+		collection: db.Collection("verification_tokens"),
+	}
+}
+
+// Create 保存令牌
+func (r *tokenRepository) Create(ctx context.Context, token *models.VerificationToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+// Consume 标记令牌已使用，条件更新保证令牌只能使用一次
+func (r *tokenRepository) Consume(ctx context.Context, tokenHash, purpose string) (*models.VerificationToken, error) {
+	filter := bson.M{
+		"token_hash": tokenHash,
+		"purpose":    purpose,
+		"used":       false,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var token models.VerificationToken
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used": true}}, opts).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("verification token not found")
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// InvalidateUserTokens 作废用户指定用途的全部未使用令牌
+func (r *tokenRepository) InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID, purpose string) error {
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used": false},
+		bson.M{"$set": bson.M{"used": true}},
+	)
+	return err
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"authcenter/internal/config"
+	"authcenter/internal/models"
+	"authcenter/internal/verification/repository"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/mail"
+	"authcenter/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 邮件令牌用途
+const (
+	PurposePasswordReset     = "password_reset"
+	PurposeEmailVerification = "email_verification"
+)
+
+// EmailService 邮件令牌服务接口
+type EmailService interface {
+	// SendPasswordReset 发送重置密码邮件，用户之前的重置令牌全部作废
+	SendPasswordReset(ctx context.Context, user *models.User) error
+
+	// SendEmailVerification 向用户当前邮箱发送验证邮件
+	SendEmailVerification(ctx context.Context, user *models.User) error
+
+	// ConsumeToken 校验并消耗令牌
+	ConsumeToken(ctx context.Context, token, purpose string) (*models.VerificationToken, error)
+
+	// InvalidateTokens 作废用户全部用途的未使用令牌，用于邮箱变更后使发往旧邮箱的链接失效
+	InvalidateTokens(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// emailService 邮件令牌服务实现
+type emailService struct {
+	tokenRepo repository.TokenRepository
+	sender    mail.MailSender
+	cfg       config.MailConfig
+}
+
+// NewEmailService 创建邮件令牌服务
+func NewEmailService(tokenRepo repository.TokenRepository, sender mail.MailSender, cfg config.MailConfig) EmailService {
+	return &emailService{
+		tokenRepo: tokenRepo,
+		sender:    sender,
+		cfg:       cfg,
+	}
+}
+
+// SendPasswordReset 发送重置密码邮件
+func (s *emailService) SendPasswordReset(ctx context.Context, user *models.User) error {
+	link, err := s.issueToken(ctx, user, PurposePasswordReset, s.cfg.PasswordResetURL, s.cfg.PasswordResetExpire)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"%s，您好：\n\n我们收到了重置您账户密码的请求。请在%d分钟内打开以下链接设置新密码：\n\n%s\n\n如果这不是您本人的操作，请忽略本邮件，您的密码不会被修改。\n",
+		user.Username, int(s.cfg.PasswordResetExpire.Minutes()), link,
+	)
+
+	return s.send(ctx, user.Email, "重置密码", body)
+}
+
+// SendEmailVerification 向用户当前邮箱发送验证邮件
+func (s *emailService) SendEmailVerification(ctx context.Context, user *models.User) error {
+	if user.Email == "" {
+		return errors.New("用户未设置邮箱")
+	}
+
+	link, err := s.issueToken(ctx, user, PurposeEmailVerification, s.cfg.EmailVerifyURL, s.cfg.EmailVerificationExpire)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"%s，您好：\n\n请在%d小时内打开以下链接验证您的邮箱地址：\n\n%s\n\n如果这不是您本人的操作，请忽略本邮件。\n",
+		user.Username, int(s.cfg.EmailVerificationExpire.Hours()), link,
+	)
+
+	return s.send(ctx, user.Email, "验证邮箱", body)
+}
+
+// ConsumeToken 校验并消耗令牌
+func (s *emailService) ConsumeToken(ctx context.Context, token, purpose string) (*models.VerificationToken, error) {
+	if token == "" {
+		return nil, errors.New("链接无效或已过期")
+	}
+
+	record, err := s.tokenRepo.Consume(ctx, utils.HashToken(token), purpose)
+	if err != nil {
+		return nil, errors.New("链接无效或已过期")
+	}
+
+	return record, nil
+}
+
+// InvalidateTokens 作废用户全部用途的未使用令牌
+func (s *emailService) InvalidateTokens(ctx context.Context, userID primitive.ObjectID) error {
+	for _, purpose := range []string{PurposePasswordReset, PurposeEmailVerification} {
+		if err := s.tokenRepo.InvalidateUserTokens(ctx, userID, purpose); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// issueToken 作废旧令牌并生成新令牌，返回附带令牌的链接
+// 数据库只保存令牌摘要，明文仅出现在邮件中
+func (s *emailService) issueToken(ctx context.Context, user *models.User, purpose, baseURL string, expire time.Duration) (string, error) {
+	if err := s.tokenRepo.InvalidateUserTokens(ctx, user.ID, purpose); err != nil {
+		return "", err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	record := &models.VerificationToken{
+		TokenHash: utils.HashToken(token),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: now.Add(expire),
+		CreatedAt: now,
+	}
+	if err := s.tokenRepo.Create(ctx, record); err != nil {
+		return "", err
+	}
+
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
+
+// send 发送邮件
+func (s *emailService) send(ctx context.Context, to, subject, body string) error {
+	if err := s.sender.Send(ctx, &mail.Message{To: to, Subject: subject, Body: body}); err != nil {
+		logger.Error("发送邮件失败: %v", err)
+		return errors.New("邮件发送失败")
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"authcenter/internal/config"
+	"authcenter/internal/models"
+	"authcenter/internal/verification/repository"
+	"authcenter/pkg/logger"
+	"authcenter/pkg/sms"
+	"authcenter/pkg/utils"
+)
+
+// 验证码用途，不同用途的验证码互不通用
+const (
+	PurposeLogin    = "login"
+	PurposeRegister = "register"
+)
+
+// sendWindow 发送次数限流窗口
+const sendWindow = time.Hour
+
+// VerificationService 短信验证码服务接口
+type VerificationService interface {
+	// SendCode 生成验证码并发送到手机号，覆盖该用途未使用的旧验证码
+	SendCode(ctx context.Context, phone, purpose string) error
+
+	// VerifyCode 校验验证码，成功后验证码失效
+	VerifyCode(ctx context.Context, phone, purpose, code string) error
+}
+
+// verificationService 短信验证码服务实现
+type verificationService struct {
+	codeRepo repository.CodeRepository
+	sender   sms.SMSSender
+	cfg      config.SMSConfig
+}
+
+// NewVerificationService 创建短信验证码服务
+func NewVerificationService(codeRepo repository.CodeRepository, sender sms.SMSSender, cfg config.SMSConfig) VerificationService {
+	return &verificationService{
+		codeRepo: codeRepo,
+		sender:   sender,
+		cfg:      cfg,
+	}
+}
+
+// SendCode 生成验证码并发送到手机号
+func (s *verificationService) SendCode(ctx context.Context, phone, purpose string) error {
+	now := time.Now()
+	sendCount := 1
+	windowStartedAt := now
+
+	existing, err := s.codeRepo.Get(ctx, phone, purpose)
+	if err == nil {
+		if now.Sub(existing.SentAt) < s.cfg.SendInterval {
+			return errors.New("验证码发送过于频繁，请稍后再试")
+		}
+		if now.Sub(existing.WindowStartedAt) < sendWindow {
+			sendCount = existing.SendCount + 1
+			windowStartedAt = existing.WindowStartedAt
+		}
+	}
+
+	if s.cfg.MaxSendsPerHour > 0 && sendCount > s.cfg.MaxSendsPerHour {
+		logger.SecurityEvent("sms_send_throttled", map[string]interface{}{
+			"phone":   phone,
+			"purpose": purpose,
+		})
+		return errors.New("验证码发送次数过多，请稍后再试")
+	}
+
+	code, err := generateCode(s.cfg.CodeLength)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := now.Add(s.cfg.CodeExpire)
+	purgeAt := windowStartedAt.Add(sendWindow)
+	if expiresAt.After(purgeAt) {
+		purgeAt = expiresAt
+	}
+
+	record := &models.VerificationCode{
+		Target:          phone,
+		Purpose:         purpose,
+		CodeHash:        utils.HashToken(code),
+		ExpiresAt:       expiresAt,
+		SentAt:          now,
+		SendCount:       sendCount,
+		WindowStartedAt: windowStartedAt,
+		PurgeAt:         purgeAt,
+	}
+	if err := s.codeRepo.Save(ctx, record); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("您的验证码是%s，%d分钟内有效，请勿泄露给他人。", code, int(s.cfg.CodeExpire.Minutes()))
+	if err := s.sender.Send(ctx, phone, message); err != nil {
+		logger.Error("发送短信验证码失败: %v", err)
+		return errors.New("验证码发送失败")
+	}
+
+	return nil
+}
+
+// VerifyCode 校验验证码
+func (s *verificationService) VerifyCode(ctx context.Context, phone, purpose, code string) error {
+	record, err := s.codeRepo.Get(ctx, phone, purpose)
+	if err != nil || record.Used || time.Now().After(record.ExpiresAt) {
+		return errors.New("验证码错误或已过期")
+	}
+
+	// 先占用校验次数再比对，并发请求无法绕过次数上限
+	attempts, ok, err := s.codeRepo.IncrementAttempts(ctx, record.ID, s.cfg.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("验证码错误次数过多，请重新获取")
+	}
+
+	if !utils.CheckToken(code, record.CodeHash) {
+		if attempts >= s.cfg.MaxAttempts {
+			logger.SecurityEvent("sms_code_exhausted", map[string]interface{}{
+				"phone":   phone,
+				"purpose": purpose,
+			})
+			return errors.New("验证码错误次数过多，请重新获取")
+		}
+		return errors.New("验证码错误")
+	}
+
+	used, err := s.codeRepo.MarkUsed(ctx, record.ID, s.cfg.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New("验证码错误或已过期")
+	}
+
+	return nil
+}
+
+// generateCode 生成指定位数的数字验证码
+func generateCode(length int) (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < length; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", length, n), nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"authcenter/internal/config"
+	"authcenter/internal/testutil"
+)
+
+const testPhone = "+8613800000000"
+
+// testSMSConfig 测试使用的短信配置
+var testSMSConfig = config.SMSConfig{
+	CodeLength:      6,
+	CodeExpire:      5 * time.Minute,
+	MaxAttempts:     3,
+	SendInterval:    time.Minute,
+	MaxSendsPerHour: 5,
+}
+
+func newTestVerificationService() (VerificationService, *testutil.VerificationCodes, *testutil.SMSOutbox) {
+	codes := testutil.NewVerificationCodes()
+	outbox := testutil.NewSMSOutbox()
+	return NewVerificationService(codes, outbox, testSMSConfig), codes, outbox
+}
+
+// wrongCode 与code不同的同位数验证码
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+func TestVerifyCode(t *testing.T) {
+	ctx := context.Background()
+	svc, _, outbox := newTestVerificationService()
+
+	if err := svc.SendCode(ctx, testPhone, PurposeLogin); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	code := outbox.LastCode(testPhone)
+
+	if err := svc.VerifyCode(ctx, testPhone, PurposeRegister, code); err == nil {
+		t.Fatal("其他用途的验证码校验成功")
+	}
+	if err := svc.VerifyCode(ctx, testPhone, PurposeLogin, code); err != nil {
+		t.Fatalf("VerifyCode: %v", err)
+	}
+	if err := svc.VerifyCode(ctx, testPhone, PurposeLogin, code); err == nil {
+		t.Fatal("验证码被重复使用")
+	}
+}
+
+// 校验失败次数达到上限后，正确的验证码也不再有效
+func TestVerifyCodeExhaustsAttempts(t *testing.T) {
+	ctx := context.Background()
+	svc, _, outbox := newTestVerificationService()
+
+	if err := svc.SendCode(ctx, testPhone, PurposeLogin); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	code := outbox.LastCode(testPhone)
+
+	for i := 0; i < testSMSConfig.MaxAttempts; i++ {
+		if err := svc.VerifyCode(ctx, testPhone, PurposeLogin, wrongCode(code)); err == nil {
+			t.Fatal("错误的验证码校验成功")
+		}
+	}
+	if err := svc.VerifyCode(ctx, testPhone, PurposeLogin, code); err == nil {
+		t.Fatal("错误次数达到上限后正确的验证码仍然有效")
+	}
+}
+
+// 并发校验先占用次数再比对，比对次数不超过上限，同一验证码只能成功一次
+func TestVerifyCodeConcurrentAttempts(t *testing.T) {
+	ctx := context.Background()
+	svc, codes, outbox := newTestVerificationService()
+
+	if err := svc.SendCode(ctx, testPhone, PurposeLogin); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	code := outbox.LastCode(testPhone)
+
+	const requests = 20
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	succeeded := 0
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(guess string) {
+			defer wg.Done()
+			if svc.VerifyCode(ctx, testPhone, PurposeLogin, guess) == nil {
+				mutex.Lock()
+				succeeded++
+				mutex.Unlock()
+			}
+		}(code)
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Fatalf("并发校验成功 %d 次，期望 1 次", succeeded)
+	}
+	record, err := codes.Get(ctx, testPhone, PurposeLogin)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if record.Attempts > testSMSConfig.MaxAttempts {
+		t.Fatalf("占用的校验次数 = %d，超过上限 %d", record.Attempts, testSMSConfig.MaxAttempts)
+	}
+}
+
+func TestSendCodeThrottling(t *testing.T) {
+	ctx := context.Background()
+	svc, codes, outbox := newTestVerificationService()
+
+	if err := svc.SendCode(ctx, testPhone, PurposeLogin); err != nil {
+		t.Fatalf("SendCode: %v", err)
+	}
+	if err := svc.SendCode(ctx, testPhone, PurposeLogin); err == nil {
+		t.Fatal("发送间隔内再次发送成功")
+	}
+
+	// 模拟发送间隔已过但仍在限流窗口内，且发送次数已达上限
+	record, _ := codes.Get(ctx, testPhone, PurposeLogin)
+	record.SentAt = time.Now().Add(-2 * testSMSConfig.SendInterval)
+	record.SendCount = testSMSConfig.MaxSendsPerHour
+	if err := codes.Save(ctx, record); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := svc.SendCode(ctx, testPhone, PurposeLogin); err == nil {
+		t.Fatal("超过每小时发送次数后发送成功")
+	}
+
+	if got := outbox.Count(testPhone); got != 1 {
+		t.Fatalf("发送了 %d 条短信，期望 1 条", got)
+	}
+}
@@ -0,0 +1,179 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+)
+
+// bloomMagic 布隆过滤器文件头
+var bloomMagic = [4]byte{'A', 'C', 'B', 'F'}
+
+// bloomVersion 布隆过滤器文件格式版本
+const bloomVersion = 1
+
+// BloomFilter 由泄露密码SHA-1摘要构建的布隆过滤器
+// 摘要本身均匀分布，直接取其中两段作为双重散列的种子，无需再次散列
+type BloomFilter struct {
+	bits []uint64
+	m    uint64 // 位数
+	k    uint32 // 散列函数个数
+	n    uint64 // 已加入的元素个数
+}
+
+// NewBloomFilter 按预计元素个数和期望误判率创建布隆过滤器
+func NewBloomFilter(n uint64, falsePositiveRate float64) (*BloomFilter, error) {
+	if n == 0 {
+		n = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("false positive rate must be between 0 and 1")
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &BloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}, nil
+}
+
+// Add 加入一个SHA-1摘要
+func (f *BloomFilter) Add(hash [sha1.Size]byte) {
+	h1, h2 := bloomSeeds(hash)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.n++
+}
+
+// Contains 摘要是否可能在过滤器中，返回false时一定不在
+func (f *BloomFilter) Contains(hash [sha1.Size]byte) bool {
+	h1, h2 := bloomSeeds(hash)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count 已加入的元素个数
+func (f *BloomFilter) Count() uint64 {
+	return f.n
+}
+
+// SizeBytes 位数组占用的字节数
+func (f *BloomFilter) SizeBytes() uint64 {
+	return uint64(len(f.bits)) * 8
+}
+
+// WriteTo 写入过滤器，格式为：文件头、版本、位数、散列函数个数、元素个数、位数组，均为大端序
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, 0, 25)
+	header = append(header, bloomMagic[:]...)
+	header = append(header, bloomVersion)
+	header = binary.BigEndian.AppendUint64(header, f.m)
+	header = binary.BigEndian.AppendUint32(header, f.k)
+	header = binary.BigEndian.AppendUint64(header, f.n)
+
+	written, err := bw.Write(header)
+	total := int64(written)
+	if err != nil {
+		return total, err
+	}
+
+	word := make([]byte, 8)
+	for _, bits := range f.bits {
+		binary.BigEndian.PutUint64(word, bits)
+		written, err := bw.Write(word)
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, bw.Flush()
+}
+
+// ReadBloomFilter 读取WriteTo写入的过滤器
+func ReadBloomFilter(r io.Reader) (*BloomFilter, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, 25)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, errors.New("invalid bloom filter file")
+	}
+
+	if !bytes.Equal(header[:4], bloomMagic[:]) || header[4] != bloomVersion {
+		return nil, errors.New("invalid bloom filter file")
+	}
+
+	f := &BloomFilter{}
+	f.m = binary.BigEndian.Uint64(header[5:13])
+	f.k = binary.BigEndian.Uint32(header[13:17])
+	f.n = binary.BigEndian.Uint64(header[17:25])
+	if f.m == 0 || f.k == 0 {
+		return nil, errors.New("invalid bloom filter file")
+	}
+
+	f.bits = make([]uint64, (f.m+63)/64)
+	word := make([]byte, 8)
+	for i := range f.bits {
+		if _, err := io.ReadFull(br, word); err != nil {
+			return nil, errors.New("truncated bloom filter file")
+		}
+		f.bits[i] = binary.BigEndian.Uint64(word)
+	}
+
+	return f, nil
+}
+
+// bloomSeeds 取摘要前16字节作为双重散列的两个种子，h2为奇数保证探测序列不退化
+func bloomSeeds(hash [sha1.Size]byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(hash[0:8]), binary.BigEndian.Uint64(hash[8:16]) | 1
+}
+
+// bloomChecker 基于布隆过滤器的检查器，过滤器整体载入内存
+type bloomChecker struct {
+	filter *BloomFilter
+}
+
+// NewBloomChecker 从文件加载布隆过滤器创建检查器
+// 布隆过滤器存在误判，极少数未泄露的密码也会被拒绝，误判率在构建时指定
+func NewBloomChecker(path string) (Checker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	filter, err := ReadBloomFilter(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bloomChecker{filter: filter}, nil
+}
+
+// Compromised 检查密码是否泄露
+func (c *bloomChecker) Compromised(password string) (bool, error) {
+	return c.filter.Contains(Hash(password)), nil
+}
@@ -0,0 +1,208 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hashHexLength SHA-1摘要的十六进制长度
+const hashHexLength = sha1.Size * 2
+
+// prefixLength k-anonymity前缀长度，与HIBP range接口一致
+const prefixLength = 5
+
+// maxLineLength 导出文件中一行的最大长度，摘要加出现次数
+const maxLineLength = 128
+
+// Checker 泄露密码检查接口
+type Checker interface {
+	// Compromised 密码是否出现在泄露密码库中
+	Compromised(password string) (bool, error)
+}
+
+// Hash 计算密码的SHA-1摘要，与HIBP密码库一致
+func Hash(password string) [sha1.Size]byte {
+	return sha1.Sum([]byte(password))
+}
+
+// ParseLine 解析HIBP导出文件中的一行，格式为 HASH:COUNT，次数缺省时视为1
+func ParseLine(line string) ([sha1.Size]byte, int, error) {
+	var hash [sha1.Size]byte
+
+	line = strings.TrimSpace(line)
+	hexHash, countText, hasCount := strings.Cut(line, ":")
+	if len(hexHash) != hashHexLength {
+		return hash, 0, fmt.Errorf("invalid hash line: %q", line)
+	}
+	if _, err := hex.Decode(hash[:], []byte(hexHash)); err != nil {
+		return hash, 0, fmt.Errorf("invalid hash line: %q", line)
+	}
+
+	count := 1
+	if hasCount {
+		n, err := strconv.Atoi(countText)
+		if err != nil {
+			return hash, 0, fmt.Errorf("invalid count in line: %q", line)
+		}
+		count = n
+	}
+
+	return hash, count, nil
+}
+
+// NewDumpChecker 基于HIBP导出数据创建检查器，出现次数低于minCount的密码不视为泄露
+// path为文件时应是按摘要排序的 HASH:COUNT 文件，检查时二分查找，无需载入内存；
+// path为目录时按k-anonymity前缀拆分，每个前缀一个 <PREFIX>.txt 文件，行格式为 SUFFIX:COUNT
+func NewDumpChecker(path string, minCount int) (Checker, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if minCount < 1 {
+		minCount = 1
+	}
+
+	if info.IsDir() {
+		return &prefixChecker{dir: path, minCount: minCount}, nil
+	}
+	return &sortedFileChecker{path: path, minCount: minCount}, nil
+}
+
+// sortedFileChecker 在按摘要排序的导出文件中二分查找
+type sortedFileChecker struct {
+	path     string
+	minCount int
+}
+
+// Compromised 检查密码是否泄露
+func (c *sortedFileChecker) Compromised(password string) (bool, error) {
+	hash := Hash(password)
+	target := strings.ToUpper(hex.EncodeToString(hash[:]))
+
+	file, err := os.Open(c.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	// 查找第一个摘要不小于target的行，lineAt对偏移量单调不减
+	lo, hi := int64(0), info.Size()
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		line, err := lineAt(file, mid)
+		if err != nil {
+			return false, err
+		}
+		if line == "" || strings.ToUpper(line[:min(len(line), hashHexLength)]) >= target {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	line, err := lineAt(file, lo)
+	if err != nil || line == "" {
+		return false, err
+	}
+	if !strings.EqualFold(line[:min(len(line), hashHexLength)], target) {
+		return false, nil
+	}
+
+	_, count, err := ParseLine(line)
+	if err != nil {
+		return false, err
+	}
+	return count >= c.minCount, nil
+}
+
+// lineAt 返回从offset开始的第一个完整行，offset位于行中间时跳到下一行，到达文件末尾时返回空字符串
+func lineAt(r io.ReaderAt, offset int64) (string, error) {
+	start := offset
+	if offset > 0 {
+		// 从前一个字节读起，以判断offset是否恰好是行首
+		start = offset - 1
+	}
+
+	buf := make([]byte, 2*maxLineLength)
+	n, err := r.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	buf = buf[:n]
+
+	if offset > 0 {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			return "", nil
+		}
+		buf = buf[i+1:]
+	}
+
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		buf = buf[:i]
+	} else if err != io.EOF {
+		return "", errors.New("line too long in breach dump")
+	}
+
+	return strings.TrimRight(string(buf), "\r"), nil
+}
+
+// prefixChecker 在按前缀拆分的目录中查找
+type prefixChecker struct {
+	dir      string
+	minCount int
+}
+
+// Compromised 检查密码是否泄露
+func (c *prefixChecker) Compromised(password string) (bool, error) {
+	hash := Hash(password)
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+	prefix, suffix := hexHash[:prefixLength], hexHash[prefixLength:]
+
+	file, err := os.Open(filepath.Join(c.dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) < len(suffix) || !strings.EqualFold(line[:len(suffix)], suffix) {
+			continue
+		}
+
+		_, count, err := ParseLine(prefix + line)
+		if err != nil {
+			return false, err
+		}
+		return count >= c.minCount, nil
+	}
+
+	return false, scanner.Err()
+}
+
+// min 返回较小值
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
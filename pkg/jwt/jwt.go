@@ -10,63 +10,119 @@ import (
 
 // Manager JWT管理器接口
 type Manager interface {
-	GenerateAccessToken(userID, username string, roles, permissions []string) (string, *Claims, error)
+	GenerateAccessToken(claims *Claims) (string, *Claims, error)
 	GenerateRefreshToken(userID string) (string, *Claims, error)
+	GenerateIDToken(claims *IDTokenClaims) (string, error)
+	GenerateMFAToken(userID string, authMethods []string) (string, error)
+	GeneratePasswordChangeToken(userID string, authMethods []string) (string, error)
 	ValidateAccessToken(tokenString string) (*Claims, error)
 	ValidateRefreshToken(tokenString string) (*Claims, error)
+	ValidateMFAToken(tokenString string) (*Claims, error)
+	ValidatePasswordChangeToken(tokenString string) (*Claims, error)
+	JWKS() *JWKSet
 }
 
+// TokenTypePersonalAccess 个人访问令牌认证后生成的声明类型，令牌本身不是JWT
+const TokenTypePersonalAccess = "personal_access"
+
+// 令牌主体类型
+const (
+	SubjectTypeUser    = "user"    // 用户
+	SubjectTypeService = "service" // 服务账号，UserID为OAuth客户端ID
+)
+
 // Claims JWT声明
 type Claims struct {
 	UserID      string   `json:"user_id"`
 	Username    string   `json:"username,omitempty"`
 	Roles       []string `json:"roles,omitempty"`
 	Permissions []string `json:"permissions,omitempty"`
-	TokenType   string   `json:"token_type"`    // access, refresh
-	JTI         string   `json:"jti,omitempty"` // JWT ID，用于Refresh Token
+	Department  string   `json:"department,omitempty"` // 所属部门，供属性策略判断
+	TenantID    string   `json:"tid,omitempty"`        // 令牌生效的租户，平台用户和服务账号为空
+	Scope       string   `json:"scope,omitempty"`      // OAuth授权范围，第一方登录为空
+	ClientID    string   `json:"client_id,omitempty"`  // OAuth客户端ID
+	SessionID   string   `json:"sid,omitempty"`        // 访问令牌所属会话
+	AuthMethods []string `json:"amr,omitempty"`        // 认证方式（RFC 8176），如pwd、otp
+	AuthTime    int64    `json:"auth_time,omitempty"`  // 用户登录的时间，刷新令牌时沿用会话的登录时间
+	TokenType   string   `json:"token_type"`           // access, refresh, mfa, password_change, personal_access
+	SubjectType string   `json:"sub_type,omitempty"`   // user, service，为空的历史令牌视为user
+	JTI         string   `json:"jti,omitempty"`        // JWT ID，Refresh Token的JTI即会话ID
+	jwt.RegisteredClaims
+}
+
+// PrincipalType 令牌的主体类型，历史令牌未携带时视为用户
+func (c *Claims) PrincipalType() string {
+	if c.SubjectType == "" {
+		return SubjectTypeUser
+	}
+	return c.SubjectType
+}
+
+// IsServiceAccount 令牌主体是否为服务账号
+func (c *Claims) IsServiceAccount() bool {
+	return c.SubjectType == SubjectTypeService
+}
+
+// IDTokenClaims OpenID Connect ID Token声明
+type IDTokenClaims struct {
+	Nonce             string   `json:"nonce,omitempty"`
+	AuthTime          int64    `json:"auth_time,omitempty"`
+	SessionID         string   `json:"sid,omitempty"`
+	AccessTokenHash   string   `json:"at_hash,omitempty"`
+	PreferredUsername string   `json:"preferred_username,omitempty"`
+	Email             string   `json:"email,omitempty"`
+	PhoneNumber       string   `json:"phone_number,omitempty"`
+	Picture           string   `json:"picture,omitempty"`
+	Roles             []string `json:"roles,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// challengeTokenDuration 多因素认证和修改过期密码等挑战令牌的有效期
+const challengeTokenDuration = 5 * time.Minute
+
 // jwtManager JWT管理器实现
 type jwtManager struct {
-	secretKey            []byte
+	keyRing              *KeyRing
 	accessTokenDuration  time.Duration
 	refreshTokenDuration time.Duration
 	issuer               string
 }
 
-// NewManager 创建JWT管理器
+// NewManager 创建使用HS256共享密钥的JWT管理器
 func NewManager(secretKey string, accessTokenDuration, refreshTokenDuration time.Duration, issuer string) Manager {
+	return NewManagerWithKeyRing(NewHMACKeyRing(secretKey), accessTokenDuration, refreshTokenDuration, issuer)
+}
+
+// NewManagerWithKeyRing 创建使用密钥环签名的JWT管理器
+func NewManagerWithKeyRing(keyRing *KeyRing, accessTokenDuration, refreshTokenDuration time.Duration, issuer string) Manager {
 	return &jwtManager{
-		secretKey:            []byte(secretKey),
+		keyRing:              keyRing,
 		accessTokenDuration:  accessTokenDuration,
 		refreshTokenDuration: refreshTokenDuration,
 		issuer:               issuer,
 	}
 }
 
-// GenerateAccessToken 生成访问令牌
-func (m *jwtManager) GenerateAccessToken(userID, username string, roles, permissions []string) (string, *Claims, error) {
+// GenerateAccessToken 生成访问令牌，调用方填写用户相关声明，标准声明由管理器设置
+// 调用方设置的ExpiresAt早于默认有效期时以其为准，用于限时授权到期后令牌随之失效
+func (m *jwtManager) GenerateAccessToken(claims *Claims) (string, *Claims, error) {
 	now := time.Now()
 	expiresAt := now.Add(m.accessTokenDuration)
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt.Time
+	}
 
-	claims := &Claims{
-		UserID:      userID,
-		Username:    username,
-		Roles:       roles,
-		Permissions: permissions,
-		TokenType:   "access",
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    m.issuer,
-			Subject:   userID,
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			NotBefore: jwt.NewNumericDate(now),
-		},
+	claims.TokenType = "access"
+	claims.JTI = uuid.New().String()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    m.issuer,
+		Subject:   claims.UserID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		NotBefore: jwt.NewNumericDate(now),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(m.secretKey)
+	tokenString, err := m.sign(claims)
 	if err != nil {
 		return "", nil, err
 	}
@@ -93,8 +149,7 @@ func (m *jwtManager) GenerateRefreshToken(userID string) (string, *Claims, error
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(m.secretKey)
+	tokenString, err := m.sign(claims)
 	if err != nil {
 		return "", nil, err
 	}
@@ -102,6 +157,51 @@ func (m *jwtManager) GenerateRefreshToken(userID string) (string, *Claims, error
 	return tokenString, claims, nil
 }
 
+// GenerateIDToken 生成ID Token，有效期与访问令牌一致
+// 调用方需设置Subject和Audience，未设置Issuer时使用管理器的签发者
+func (m *jwtManager) GenerateIDToken(claims *IDTokenClaims) (string, error) {
+	now := time.Now()
+
+	if claims.Issuer == "" {
+		claims.Issuer = m.issuer
+	}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(m.accessTokenDuration))
+
+	return m.sign(claims)
+}
+
+// GenerateMFAToken 生成多因素认证挑战令牌，证明用户已完成第一步认证
+func (m *jwtManager) GenerateMFAToken(userID string, authMethods []string) (string, error) {
+	return m.generateChallengeToken(userID, authMethods, "mfa")
+}
+
+// GeneratePasswordChangeToken 生成修改过期密码的挑战令牌，证明用户已完成认证但必须先修改密码
+func (m *jwtManager) GeneratePasswordChangeToken(userID string, authMethods []string) (string, error) {
+	return m.generateChallengeToken(userID, authMethods, "password_change")
+}
+
+// generateChallengeToken 生成只能用于完成登录下一步的短期令牌
+func (m *jwtManager) generateChallengeToken(userID string, authMethods []string, tokenType string) (string, error) {
+	now := time.Now()
+
+	claims := &Claims{
+		UserID:      userID,
+		AuthMethods: authMethods,
+		TokenType:   tokenType,
+		JTI:         uuid.New().String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(challengeTokenDuration)),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	return m.sign(claims)
+}
+
 // ValidateAccessToken 验证访问令牌
 func (m *jwtManager) ValidateAccessToken(tokenString string) (*Claims, error) {
 	return m.validateToken(tokenString, "access")
@@ -112,15 +212,71 @@ func (m *jwtManager) ValidateRefreshToken(tokenString string) (*Claims, error) {
 	return m.validateToken(tokenString, "refresh")
 }
 
+// ValidateMFAToken 验证多因素认证挑战令牌
+func (m *jwtManager) ValidateMFAToken(tokenString string) (*Claims, error) {
+	return m.validateToken(tokenString, "mfa")
+}
+
+// ValidatePasswordChangeToken 验证修改过期密码的挑战令牌
+func (m *jwtManager) ValidatePasswordChangeToken(tokenString string) (*Claims, error) {
+	return m.validateToken(tokenString, "password_change")
+}
+
+// JWKS 获取用于验证Token的公钥集
+func (m *jwtManager) JWKS() *JWKSet {
+	return m.keyRing.JWKS()
+}
+
+// sign 使用当前签名密钥签名，非对称密钥会在头部写入kid
+func (m *jwtManager) sign(claims jwt.Claims) (string, error) {
+	key, err := m.keyRing.ActiveKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	if key.KID != "" {
+		token.Header["kid"] = key.KID
+	}
+
+	return token.SignedString(key.PrivateKey)
+}
+
+// keyFunc 根据Token头部的kid选择验证密钥，退役中的密钥仍可用于验证
+func (m *jwtManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var key *SigningKey
+	if kid == "" {
+		// 未携带kid的Token只可能由对称密钥签发
+		if m.keyRing.Algorithm() != AlgorithmHS256 {
+			return nil, errors.New("Token缺少kid")
+		}
+		active, err := m.keyRing.ActiveKey()
+		if err != nil {
+			return nil, err
+		}
+		key = active
+	} else {
+		found, ok := m.keyRing.Key(kid)
+		if !ok {
+			return nil, errors.New("未知的签名密钥")
+		}
+		key = found
+	}
+
+	// 确保签名方法与密钥算法一致
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("无效的签名方法")
+	}
+
+	return key.PublicKey, nil
+}
+
 // validateToken 验证令牌
 func (m *jwtManager) validateToken(tokenString, expectedType string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// 确保签名方法是我们期望的
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("无效的签名方法")
-		}
-		return m.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyFunc,
+		jwt.WithValidMethods([]string{AlgorithmHS256, AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA}))
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,350 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+
+	"authcenter/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// 密钥状态
+const (
+	KeyStatusActive   = "active"   // 当前用于签名
+	KeyStatusRetiring = "retiring" // 不再签名，仅用于验证已签发的Token
+)
+
+// rsaKeyBits RSA密钥长度
+const rsaKeyBits = 2048
+
+// SigningKey 签名密钥
+type SigningKey struct {
+	KID        string
+	Algorithm  string
+	PrivateKey interface{} // HS256为[]byte，其余为对应算法的私钥
+	PublicKey  interface{} // HS256为[]byte，其余为对应算法的公钥
+	Status     string
+	CreatedAt  time.Time
+	RetiredAt  *time.Time
+}
+
+// signingMethod 获取签名方法
+func (k *SigningKey) signingMethod() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case AlgorithmES256:
+		return jwt.SigningMethodES256
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// KeyStore 密钥持久化接口
+type KeyStore interface {
+	Load() ([]*SigningKey, error)
+	Save(keys []*SigningKey) error
+}
+
+// KeyRing 密钥环，维护一个签名密钥和若干退役中的验证密钥
+type KeyRing struct {
+	mutex     sync.RWMutex
+	algorithm string
+	keys      []*SigningKey
+	store     KeyStore
+	retention time.Duration // 退役密钥保留时长，应不小于Token的最长有效期
+	stop      chan struct{}
+}
+
+// NewKeyRing 创建非对称密钥环，从存储中加载已有密钥，必要时生成新的签名密钥
+func NewKeyRing(algorithm string, store KeyStore, retention time.Duration) (*KeyRing, error) {
+	if !isAsymmetric(algorithm) {
+		return nil, errors.New("不支持的签名算法: " + algorithm)
+	}
+
+	keys, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	ring := &KeyRing{
+		algorithm: algorithm,
+		keys:      keys,
+		store:     store,
+		retention: retention,
+	}
+
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+
+	ring.pruneLocked(time.Now())
+
+	// 没有可用的签名密钥，或算法已变更时生成新密钥
+	active := ring.activeLocked()
+	if active == nil || active.Algorithm != algorithm {
+		if err := ring.rotateLocked(); err != nil {
+			return nil, err
+		}
+		return ring, nil
+	}
+
+	if err := ring.store.Save(ring.keys); err != nil {
+		return nil, err
+	}
+
+	return ring, nil
+}
+
+// NewHMACKeyRing 创建对称密钥环，仅包含一个固定的HS256密钥
+func NewHMACKeyRing(secretKey string) *KeyRing {
+	return &KeyRing{
+		algorithm: AlgorithmHS256,
+		keys: []*SigningKey{{
+			Algorithm:  AlgorithmHS256,
+			PrivateKey: []byte(secretKey),
+			PublicKey:  []byte(secretKey),
+			Status:     KeyStatusActive,
+			CreatedAt:  time.Now(),
+		}},
+	}
+}
+
+// Algorithm 获取签名算法
+func (r *KeyRing) Algorithm() string {
+	return r.algorithm
+}
+
+// ActiveKey 获取当前签名密钥
+func (r *KeyRing) ActiveKey() (*SigningKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key := r.activeLocked()
+	if key == nil {
+		return nil, errors.New("没有可用的签名密钥")
+	}
+	return key, nil
+}
+
+// Key 通过kid获取验证密钥（包括退役中的密钥）
+func (r *KeyRing) Key(kid string) (*SigningKey, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, key := range r.keys {
+		if key.KID == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// Rotate 轮换签名密钥，原签名密钥转为退役状态
+func (r *KeyRing) Rotate() error {
+	if !isAsymmetric(r.algorithm) {
+		return errors.New("对称密钥不支持轮换")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.rotateLocked()
+}
+
+// StartRotation 启动定时轮换，签名密钥使用时长达到interval后自动轮换
+func (r *KeyRing) StartRotation(interval time.Duration) {
+	if !isAsymmetric(r.algorithm) || interval <= 0 {
+		return
+	}
+
+	checkInterval := time.Hour
+	if interval < checkInterval {
+		checkInterval = interval
+	}
+
+	r.mutex.Lock()
+	if r.stop != nil {
+		r.mutex.Unlock()
+		return
+	}
+	r.stop = make(chan struct{})
+	stop := r.stop
+	r.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.rotateIfDue(interval); err != nil {
+					logger.Error("JWT密钥轮换失败: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopRotation 停止定时轮换
+func (r *KeyRing) StopRotation() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
+
+// rotateIfDue 检查签名密钥是否到期并清理过期的退役密钥
+func (r *KeyRing) rotateIfDue(interval time.Duration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	pruned := r.pruneLocked(now)
+
+	active := r.activeLocked()
+	if active == nil || now.Sub(active.CreatedAt) >= interval {
+		return r.rotateLocked()
+	}
+
+	if pruned {
+		return r.store.Save(r.keys)
+	}
+	return nil
+}
+
+// rotateLocked 生成新的签名密钥，调用方需持有写锁
+func (r *KeyRing) rotateLocked() error {
+	key, err := generateSigningKey(r.algorithm)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, existing := range r.keys {
+		if existing.Status == KeyStatusActive {
+			existing.Status = KeyStatusRetiring
+			existing.RetiredAt = &now
+		}
+	}
+
+	keys := append([]*SigningKey{key}, r.keys...)
+	if err := r.store.Save(keys); err != nil {
+		// 回滚状态变更
+		for _, existing := range r.keys {
+			if existing.RetiredAt == &now {
+				existing.Status = KeyStatusActive
+				existing.RetiredAt = nil
+			}
+		}
+		return err
+	}
+
+	r.keys = keys
+	logger.Info("JWT签名密钥已轮换, kid=%s, alg=%s", key.KID, key.Algorithm)
+	return nil
+}
+
+// pruneLocked 移除已超过保留时长的退役密钥，返回是否有密钥被移除
+func (r *KeyRing) pruneLocked(now time.Time) bool {
+	kept := r.keys[:0]
+	pruned := false
+	for _, key := range r.keys {
+		if key.Status == KeyStatusRetiring && key.RetiredAt != nil && now.Sub(*key.RetiredAt) > r.retention {
+			pruned = true
+			continue
+		}
+		kept = append(kept, key)
+	}
+	r.keys = kept
+	return pruned
+}
+
+// activeLocked 获取签名密钥，调用方需持有锁
+func (r *KeyRing) activeLocked() *SigningKey {
+	for _, key := range r.keys {
+		if key.Status == KeyStatusActive {
+			return key
+		}
+	}
+	return nil
+}
+
+// generateSigningKey 按算法生成新密钥
+func generateSigningKey(algorithm string) (*SigningKey, error) {
+	key := &SigningKey{
+		KID:       generateKID(),
+		Algorithm: algorithm,
+		Status:    KeyStatusActive,
+		CreatedAt: time.Now(),
+	}
+
+	switch algorithm {
+	case AlgorithmRS256:
+		privateKey, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey = privateKey
+		key.PublicKey = &privateKey.PublicKey
+	case AlgorithmES256:
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey = privateKey
+		key.PublicKey = &privateKey.PublicKey
+	case AlgorithmEdDSA:
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey = privateKey
+		key.PublicKey = publicKey
+	default:
+		return nil, errors.New("不支持的签名算法: " + algorithm)
+	}
+
+	return key, nil
+}
+
+// generateKID 生成密钥ID
+func generateKID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// isAsymmetric 判断是否为非对称算法
+func isAsymmetric(algorithm string) bool {
+	switch algorithm {
+	case AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA:
+		return true
+	}
+	return false
+}
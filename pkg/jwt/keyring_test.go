@@ -0,0 +1,183 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// memoryKeyStore 内存密钥存储，failSave为true时保存失败
+type memoryKeyStore struct {
+	keys     []*SigningKey
+	failSave bool
+}
+
+func (s *memoryKeyStore) Load() ([]*SigningKey, error) {
+	return s.keys, nil
+}
+
+func (s *memoryKeyStore) Save(keys []*SigningKey) error {
+	if s.failSave {
+		return errors.New("save failed")
+	}
+	s.keys = append([]*SigningKey(nil), keys...)
+	return nil
+}
+
+// newTestManager 创建使用ES256密钥环的管理器
+func newTestManager(t *testing.T, retention time.Duration) (*KeyRing, Manager) {
+	t.Helper()
+
+	ring, err := NewKeyRing(AlgorithmES256, &memoryKeyStore{}, retention)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	return ring, NewManagerWithKeyRing(ring, time.Hour, 24*time.Hour, "test")
+}
+
+// issue 签发访问令牌
+func issue(t *testing.T, manager Manager) string {
+	t.Helper()
+
+	token, _, err := manager.GenerateAccessToken(&Claims{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	return token
+}
+
+// signWith 使用指定密钥签名，kid为空时不写入头部
+func signWith(t *testing.T, key *SigningKey, kid string) string {
+	t.Helper()
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:    "user-1",
+		TokenType: "access",
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	ring, manager := newTestManager(t, time.Hour)
+
+	before, err := ring.ActiveKey()
+	if err != nil {
+		t.Fatalf("ActiveKey: %v", err)
+	}
+	oldToken := issue(t, manager)
+
+	if err := ring.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	after, err := ring.ActiveKey()
+	if err != nil {
+		t.Fatalf("ActiveKey: %v", err)
+	}
+	newToken := issue(t, manager)
+
+	if after.KID == before.KID {
+		t.Fatal("轮换后签名密钥未变化")
+	}
+	if before.Status != KeyStatusRetiring || before.RetiredAt == nil {
+		t.Fatalf("原签名密钥状态为%s，期望%s", before.Status, KeyStatusRetiring)
+	}
+
+	unknown, err := generateSigningKey(AlgorithmES256)
+	if err != nil {
+		t.Fatalf("generateSigningKey: %v", err)
+	}
+	hmac := &SigningKey{Algorithm: AlgorithmHS256, PrivateKey: []byte("secret")}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"当前签名密钥", newToken, false},
+		{"退役中的密钥", oldToken, false},
+		{"未知kid", signWith(t, unknown, unknown.KID), true},
+		{"冒用已知kid", signWith(t, unknown, after.KID), true},
+		{"缺少kid", signWith(t, unknown, ""), true},
+		{"签名算法与密钥不一致", signWith(t, hmac, after.KID), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.ValidateAccessToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateAccessToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyRingPrunesExpiredRetiringKeys(t *testing.T) {
+	ring, manager := newTestManager(t, time.Minute)
+
+	oldToken := issue(t, manager)
+	if err := ring.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	retired := ring.keys[1]
+	expired := time.Now().Add(-2 * time.Minute)
+	retired.RetiredAt = &expired
+
+	if err := ring.rotateIfDue(24 * time.Hour); err != nil {
+		t.Fatalf("rotateIfDue: %v", err)
+	}
+
+	if _, ok := ring.Key(retired.KID); ok {
+		t.Fatal("超过保留时长的退役密钥未被移除")
+	}
+	if _, err := manager.ValidateAccessToken(oldToken); err == nil {
+		t.Fatal("退役密钥移除后签发的令牌仍然有效")
+	}
+}
+
+func TestKeyRingRotateRollsBackOnSaveFailure(t *testing.T) {
+	store := &memoryKeyStore{}
+	ring, err := NewKeyRing(AlgorithmES256, store, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	before, _ := ring.ActiveKey()
+
+	store.failSave = true
+	if err := ring.Rotate(); err == nil {
+		t.Fatal("保存失败时轮换应返回错误")
+	}
+
+	after, err := ring.ActiveKey()
+	if err != nil {
+		t.Fatalf("ActiveKey: %v", err)
+	}
+	if after.KID != before.KID || after.Status != KeyStatusActive {
+		t.Fatal("保存失败后签名密钥应保持不变")
+	}
+	if len(ring.keys) != 1 {
+		t.Fatalf("保存失败后密钥数量为%d，期望1", len(ring.keys))
+	}
+}
+
+func TestHMACKeyRingCannotRotate(t *testing.T) {
+	if err := NewHMACKeyRing("secret").Rotate(); err == nil {
+		t.Fatal("对称密钥不应支持轮换")
+	}
+}
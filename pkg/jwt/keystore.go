@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileKeyStore 基于本地文件的密钥存储
+type fileKeyStore struct {
+	path string
+}
+
+// storedKey 密钥的持久化格式
+type storedKey struct {
+	KID        string     `json:"kid"`
+	Algorithm  string     `json:"alg"`
+	Status     string     `json:"status"`
+	PrivateKey string     `json:"private_key"` // PKCS#8 PEM
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+// NewFileKeyStore 创建文件密钥存储
+func NewFileKeyStore(path string) KeyStore {
+	return &fileKeyStore{path: path}
+}
+
+// Load 加载密钥，文件不存在时返回空列表
+func (s *fileKeyStore) Load() ([]*SigningKey, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*SigningKey{}, nil
+		}
+		return nil, err
+	}
+
+	var stored []storedKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*SigningKey, 0, len(stored))
+	for _, item := range stored {
+		block, _ := pem.Decode([]byte(item.PrivateKey))
+		if block == nil {
+			return nil, errors.New("无效的密钥格式, kid=" + item.KID)
+		}
+
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey, err := publicKeyOf(privateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, &SigningKey{
+			KID:        item.KID,
+			Algorithm:  item.Algorithm,
+			PrivateKey: privateKey,
+			PublicKey:  publicKey,
+			Status:     item.Status,
+			CreatedAt:  item.CreatedAt,
+			RetiredAt:  item.RetiredAt,
+		})
+	}
+
+	return keys, nil
+}
+
+// Save 保存密钥，先写入临时文件再替换，避免写入中断导致文件损坏
+func (s *fileKeyStore) Save(keys []*SigningKey) error {
+	stored := make([]storedKey, 0, len(keys))
+	for _, key := range keys {
+		der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+		if err != nil {
+			return err
+		}
+
+		stored = append(stored, storedKey{
+			KID:        key.KID,
+			Algorithm:  key.Algorithm,
+			Status:     key.Status,
+			PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+			CreatedAt:  key.CreatedAt,
+			RetiredAt:  key.RetiredAt,
+		})
+	}
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
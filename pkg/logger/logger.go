@@ -2,6 +2,7 @@ package logger
 
 import (
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -68,6 +69,32 @@ func Fatal(format string, args ...interface{}) {
 	}
 }
 
+// SecurityEvent 安全事件日志
+func SecurityEvent(eventType string, fields map[string]interface{}) {
+	if log == nil {
+		return
+	}
+
+	entry := log.WithField("event_type", eventType)
+	if _, exists := fields["timestamp"]; !exists {
+		entry = entry.WithField("timestamp", time.Now().Format(time.RFC3339))
+	}
+	entry.WithFields(fields).Warn("security_event")
+}
+
+// Audit 审计日志，记录请求和授权变更等需要留存的操作
+func Audit(eventType string, fields map[string]interface{}) {
+	if log == nil {
+		return
+	}
+
+	entry := log.WithField("event_type", eventType)
+	if _, exists := fields["timestamp"]; !exists {
+		entry = entry.WithField("timestamp", time.Now().Format(time.RFC3339))
+	}
+	entry.WithFields(fields).Info("audit")
+}
+
 // WithFields 带字段的日志
 func WithFields(fields map[string]interface{}) *logrus.Entry {
 	if log != nil {
@@ -0,0 +1,118 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message 邮件内容，正文为纯文本
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// MailSender 邮件发送接口
+type MailSender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// smtpSender 通过SMTP服务器发送邮件
+type smtpSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPSender 创建SMTP邮件发送器，username为空时不进行认证
+func NewSMTPSender(host string, port int, username, password, from string) MailSender {
+	return &smtpSender{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send 发送邮件，服务器支持时自动启用STARTTLS
+func (s *smtpSender) Send(ctx context.Context, msg *Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return fmt.Errorf("invalid recipient: %q", msg.To)
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	return smtp.SendMail(s.addr, auth, s.from, []string{msg.To}, s.build(msg))
+}
+
+// build 组装邮件头和正文
+func (s *smtpSender) build(msg *Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// fileSender 将邮件按行追加到文件，用于本地开发和测试
+type fileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// outboxMessage 文件中的一封邮件
+type outboxMessage struct {
+	*Message
+	SentAt time.Time `json:"sent_at"`
+}
+
+// NewFileSender 创建文件邮件发送器
+func NewFileSender(path string) MailSender {
+	return &fileSender{path: path}
+}
+
+// Send 将邮件以JSON行的形式追加到文件
+func (s *fileSender) Send(ctx context.Context, msg *Message) error {
+	line, err := json.Marshal(outboxMessage{Message: msg, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
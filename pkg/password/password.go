@@ -1,41 +1,220 @@
 package password
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// 支持的摘要算法
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// 根据需求文档，bcrypt cost factor >= 12
+const minBcryptCost = 12
+
+// argon2id盐值和摘要长度，单位字节
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var (
+	// ErrMismatchedPassword 密码与摘要不匹配
+	ErrMismatchedPassword = errors.New("password does not match hash")
+
+	// ErrUnsupportedHash 无法识别的摘要格式
+	ErrUnsupportedHash = errors.New("unsupported password hash")
+)
+
+// Config 密码摘要参数
+type Config struct {
+	Algorithm     string // 新密码使用的算法，argon2id或bcrypt
+	BcryptCost    int
+	Argon2Memory  uint32 // 内存开销，单位KiB
+	Argon2Time    uint32 // 迭代次数
+	Argon2Threads uint8  // 并行度
+}
+
 // Manager 密码管理器接口
 type Manager interface {
+	// HashPassword 按当前配置的算法生成摘要，摘要中包含算法和参数
 	HashPassword(password string) (string, error)
+
+	// CheckPassword 验证密码，按摘要自身记录的算法比较，不匹配时返回ErrMismatchedPassword
 	CheckPassword(password, hashedPassword string) error
+
+	// NeedsRehash 摘要的算法或参数与当前配置不一致时返回true
+	NeedsRehash(hashedPassword string) bool
 }
 
 // passwordManager 密码管理器实现
 type passwordManager struct {
-	cost int
+	cfg Config
 }
 
 // NewManager 创建密码管理器
-func NewManager(cost int) Manager {
-	// 根据需求文档，cost factor >= 12
-	if cost < 12 {
-		cost = 12
+func NewManager(cfg Config) (Manager, error) {
+	switch cfg.Algorithm {
+	case AlgorithmArgon2id:
+		if cfg.Argon2Memory == 0 || cfg.Argon2Time == 0 || cfg.Argon2Threads == 0 {
+			return nil, errors.New("argon2id memory, time and threads must be positive")
+		}
+	case AlgorithmBcrypt:
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", cfg.Algorithm)
 	}
-	return &passwordManager{
-		cost: cost,
+
+	if cfg.BcryptCost < minBcryptCost {
+		cfg.BcryptCost = minBcryptCost
 	}
+
+	return &passwordManager{
+		cfg: cfg,
+	}, nil
 }
 
 // HashPassword 加密密码
 func (m *passwordManager) HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), m.cost)
-	if err != nil {
+	if m.cfg.Algorithm == AlgorithmBcrypt {
+		hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), m.cfg.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hashedBytes), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
-	return string(hashedBytes), nil
+
+	params := argon2Params{
+		memory:  m.cfg.Argon2Memory,
+		time:    m.cfg.Argon2Time,
+		threads: m.cfg.Argon2Threads,
+	}
+	key := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, argon2KeyLength)
+
+	return params.encode(salt, key), nil
 }
 
 // CheckPassword 验证密码
 func (m *passwordManager) CheckPassword(password, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	return Compare(password, hashedPassword)
+}
+
+// NeedsRehash 检查摘要是否需要按当前配置重新生成
+func (m *passwordManager) NeedsRehash(hashedPassword string) bool {
+	switch {
+	case isBcrypt(hashedPassword):
+		if m.cfg.Algorithm != AlgorithmBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err != nil || cost != m.cfg.BcryptCost
+	case strings.HasPrefix(hashedPassword, "$"+AlgorithmArgon2id+"$"):
+		if m.cfg.Algorithm != AlgorithmArgon2id {
+			return true
+		}
+		params, salt, key, err := decodeArgon2(hashedPassword)
+		return err != nil ||
+			params.memory != m.cfg.Argon2Memory ||
+			params.time != m.cfg.Argon2Time ||
+			params.threads != m.cfg.Argon2Threads ||
+			len(salt) != argon2SaltLength ||
+			len(key) != argon2KeyLength
+	default:
+		return true
+	}
+}
+
+// Compare 按摘要记录的算法验证密码，与具体配置无关，可用于比较历史密码
+func Compare(password, hashedPassword string) error {
+	if isBcrypt(hashedPassword) {
+		if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrMismatchedPassword
+			}
+			return err
+		}
+		return nil
+	}
+
+	params, salt, key, err := decodeArgon2(hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return ErrMismatchedPassword
+	}
+	return nil
+}
+
+// isBcrypt 检查是否为bcrypt摘要（$2a$、$2b$、$2y$）
+func isBcrypt(hashedPassword string) bool {
+	return len(hashedPassword) > 4 &&
+		hashedPassword[0] == '$' &&
+		hashedPassword[1] == '2' &&
+		hashedPassword[3] == '$'
+}
+
+// argon2Params argon2id参数
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// encode 按PHC字符串格式编码：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func (p argon2Params) encode(salt, key []byte) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+// decodeArgon2 解析PHC格式的argon2id摘要
+func decodeArgon2(hashedPassword string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != AlgorithmArgon2id {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+	if params.memory == 0 || params.time == 0 || params.threads == 0 {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	return params, salt, key, nil
 }
@@ -0,0 +1,182 @@
+package password
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"authcenter/pkg/breach"
+)
+
+// bcryptMaxLength bcrypt只使用密码的前72个字节，超出部分会被静默忽略
+const bcryptMaxLength = 72
+
+// minUserInfoLength 用户名或邮箱前缀短于该长度时不做包含检查，避免误判
+const minUserInfoLength = 3
+
+// 违反策略的原因代码
+const (
+	ViolationEmpty            = "empty"
+	ViolationTooShort         = "too_short"
+	ViolationTooLong          = "too_long"
+	ViolationMissingUppercase = "missing_uppercase"
+	ViolationMissingLowercase = "missing_lowercase"
+	ViolationMissingDigit     = "missing_digit"
+	ViolationMissingSymbol    = "missing_symbol"
+	ViolationContainsUserInfo = "contains_user_info"
+	ViolationReused           = "reused"
+	ViolationCompromised      = "compromised"
+)
+
+// Policy 密码策略
+type Policy struct {
+	MinLength        int  // 最小字符数
+	MaxLength        int  // 最大字节数，不超过bcrypt的72字节限制
+	RequireUppercase bool // 必须包含大写字母
+	RequireLowercase bool // 必须包含小写字母
+	RequireDigit     bool // 必须包含数字
+	RequireSymbol    bool // 必须包含特殊字符
+	DisallowUserInfo bool // 不得包含用户名或邮箱前缀
+	HistoryCount     int  // 不得与当前密码及之前N个密码相同，为0时不检查
+
+	Breach breach.Checker // 泄露密码库，为nil时不检查
+}
+
+// UserInfo 校验密码时参考的用户信息
+type UserInfo struct {
+	Username string
+	Email    string
+	History  []string // 当前密码及之前密码的摘要，从新到旧排列
+}
+
+// Violation 违反的策略项
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PolicyError 密码不符合策略
+type PolicyError struct {
+	Violations []Violation
+}
+
+// Error 返回所有违反项的说明
+func (e *PolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return strings.Join(messages, "；")
+}
+
+// Validate 校验密码，违反策略时返回*PolicyError
+func (p *Policy) Validate(password string, user *UserInfo) error {
+	if password == "" {
+		return &PolicyError{Violations: []Violation{{Code: ViolationEmpty, Message: "密码不能为空"}}}
+	}
+
+	var violations []Violation
+	add := func(code, message string) {
+		violations = append(violations, Violation{Code: code, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		add(ViolationTooShort, "密码长度不能少于"+strconv.Itoa(p.MinLength)+"个字符")
+	}
+
+	maxLength := p.MaxLength
+	if maxLength <= 0 || maxLength > bcryptMaxLength {
+		maxLength = bcryptMaxLength
+	}
+	if len(password) > maxLength {
+		add(ViolationTooLong, "密码长度不能超过"+strconv.Itoa(maxLength)+"个字节")
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUppercase && !hasUpper {
+		add(ViolationMissingUppercase, "密码必须包含大写字母")
+	}
+	if p.RequireLowercase && !hasLower {
+		add(ViolationMissingLowercase, "密码必须包含小写字母")
+	}
+	if p.RequireDigit && !hasDigit {
+		add(ViolationMissingDigit, "密码必须包含数字")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		add(ViolationMissingSymbol, "密码必须包含特殊字符")
+	}
+
+	if user != nil {
+		if p.DisallowUserInfo && containsUserInfo(password, user) {
+			add(ViolationContainsUserInfo, "密码不能包含用户名或邮箱")
+		}
+
+		// 其余规则都满足时才比较历史密码，摘要比较代价较高
+		if len(violations) == 0 && p.reused(password, user.History) {
+			add(ViolationReused, "不能使用当前密码或之前"+strconv.Itoa(p.HistoryCount)+"次使用过的密码")
+		}
+	}
+
+	if len(violations) == 0 && p.Breach != nil {
+		compromised, err := p.Breach.Compromised(password)
+		if err != nil {
+			return err
+		}
+		if compromised {
+			add(ViolationCompromised, "该密码已出现在公开泄露的密码库中，请更换其他密码")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// reused 检查密码是否与当前密码或之前的密码相同
+func (p *Policy) reused(password string, history []string) bool {
+	if p.HistoryCount <= 0 {
+		return false
+	}
+
+	for i, hash := range history {
+		if i > p.HistoryCount {
+			break
+		}
+		if hash != "" && Compare(password, hash) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// containsUserInfo 检查密码是否包含用户名或邮箱前缀，忽略大小写
+func containsUserInfo(password string, user *UserInfo) bool {
+	lower := strings.ToLower(password)
+
+	candidates := []string{user.Username}
+	if at := strings.Index(user.Email, "@"); at > 0 {
+		candidates = append(candidates, user.Email[:at])
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if utf8.RuneCountInString(candidate) >= minUserInfoLength && strings.Contains(lower, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,345 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 规则效果
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// 条件运算符
+const (
+	OperatorEq    = "eq"     // 属性等于任一值
+	OperatorNe    = "ne"     // 属性不等于任何值
+	OperatorIn    = "in"     // 同eq，用于多个候选值
+	OperatorNotIn = "not_in" // 同ne，用于多个候选值
+	OperatorCIDR  = "cidr"   // IP属于任一网段
+	OperatorGte   = "gte"    // 数值大于等于
+	OperatorLte   = "lte"    // 数值小于等于
+)
+
+// Wildcard 匹配任意资源类型或操作
+const Wildcard = "*"
+
+// Subject 请求主体
+type Subject struct {
+	ID          string
+	Type        string // user, service
+	Roles       []string
+	Department  string
+	AuthMethods []string
+}
+
+// Resource 被访问的资源
+type Resource struct {
+	Type       string
+	ID         string
+	Owner      string            // 创建者或所属用户ID
+	Category   string            // 所属分类
+	Status     string            // 资源状态
+	Attributes map[string]string // 其他属性，条件中以 resource.<name> 引用
+}
+
+// Environment 请求环境
+type Environment struct {
+	Time time.Time
+	IP   string
+}
+
+// Request 授权请求
+type Request struct {
+	Subject     Subject
+	Resource    Resource
+	Action      string
+	Environment Environment
+}
+
+// Condition 规则条件，Attribute与Values中的字面值或Ref引用的另一属性比较
+// 属性：subject.id/type/roles/department/auth_methods，resource.type/id/owner/category/status/<属性名>，
+// environment.ip/hour/weekday（hour为0-23，weekday为0-6，0表示周日）
+type Condition struct {
+	Attribute string
+	Operator  string
+	Values    []string
+	Ref       string
+}
+
+// Rule 授权规则，资源类型和操作匹配且全部条件成立时生效
+type Rule struct {
+	Name       string
+	Effect     string
+	Resources  []string
+	Actions    []string
+	Conditions []Condition
+}
+
+// Decision 授权决定
+type Decision struct {
+	Effect string // allow、deny，没有规则匹配时为空
+	Rule   string // 决定生效的规则名
+}
+
+// Engine 策略引擎，拒绝规则优先于允许规则
+type Engine struct {
+	rules []compiledRule
+}
+
+// compiledRule 预解析网段后的规则
+type compiledRule struct {
+	Rule
+	networks map[int][]*net.IPNet // 条件下标 -> cidr条件的网段
+}
+
+// NewEngine 校验并创建策略引擎
+func NewEngine(rules []Rule) (*Engine, error) {
+	engine := &Engine{rules: make([]compiledRule, 0, len(rules))}
+
+	for _, rule := range rules {
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("policy rule %q: %w", rule.Name, err)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	return engine, nil
+}
+
+// Evaluate 评估授权请求，任一拒绝规则匹配时拒绝，否则任一允许规则匹配时允许
+func (e *Engine) Evaluate(req *Request) Decision {
+	var decision Decision
+
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if !rule.matches(req) {
+			continue
+		}
+
+		if rule.Effect == EffectDeny {
+			return Decision{Effect: EffectDeny, Rule: rule.Name}
+		}
+		if decision.Effect == "" {
+			decision = Decision{Effect: EffectAllow, Rule: rule.Name}
+		}
+	}
+
+	return decision
+}
+
+// compile 校验规则并预解析网段
+func compile(rule Rule) (compiledRule, error) {
+	compiled := compiledRule{Rule: rule, networks: make(map[int][]*net.IPNet)}
+
+	if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+		return compiled, errors.New("effect must be allow or deny")
+	}
+	if len(rule.Resources) == 0 || len(rule.Actions) == 0 {
+		return compiled, errors.New("resources and actions are required")
+	}
+
+	for i, condition := range rule.Conditions {
+		if !validAttribute(condition.Attribute) {
+			return compiled, fmt.Errorf("unknown attribute %q", condition.Attribute)
+		}
+		if condition.Ref != "" && !validAttribute(condition.Ref) {
+			return compiled, fmt.Errorf("unknown attribute %q", condition.Ref)
+		}
+		if condition.Ref == "" && len(condition.Values) == 0 {
+			return compiled, fmt.Errorf("condition on %q needs values or ref", condition.Attribute)
+		}
+
+		switch condition.Operator {
+		case OperatorEq, OperatorNe, OperatorIn, OperatorNotIn:
+		case OperatorCIDR:
+			for _, value := range condition.Values {
+				_, network, err := net.ParseCIDR(value)
+				if err != nil {
+					return compiled, err
+				}
+				compiled.networks[i] = append(compiled.networks[i], network)
+			}
+		case OperatorGte, OperatorLte:
+			if len(condition.Values) != 1 {
+				return compiled, fmt.Errorf("%s needs exactly one value", condition.Operator)
+			}
+			if _, err := strconv.Atoi(condition.Values[0]); err != nil {
+				return compiled, fmt.Errorf("%s needs an integer value", condition.Operator)
+			}
+		default:
+			return compiled, fmt.Errorf("unknown operator %q", condition.Operator)
+		}
+	}
+
+	return compiled, nil
+}
+
+// matches 规则是否适用于请求
+func (r *compiledRule) matches(req *Request) bool {
+	if !matchAny(r.Resources, req.Resource.Type) || !matchAny(r.Actions, req.Action) {
+		return false
+	}
+
+	for i, condition := range r.Conditions {
+		if !r.holds(i, condition, req) {
+			return false
+		}
+	}
+	return true
+}
+
+// holds 条件是否成立，属性缺失时只有ne和not_in成立
+func (r *compiledRule) holds(i int, condition Condition, req *Request) bool {
+	values := attribute(req, condition.Attribute)
+
+	expected := condition.Values
+	if condition.Ref != "" {
+		expected = attribute(req, condition.Ref)
+		// 引用的属性为空时不能视为相等，否则未登录主体会匹配无所属的资源
+		if len(expected) == 0 {
+			return condition.Operator == OperatorNe || condition.Operator == OperatorNotIn
+		}
+	}
+
+	switch condition.Operator {
+	case OperatorEq, OperatorIn:
+		return intersects(values, expected)
+	case OperatorNe, OperatorNotIn:
+		return !intersects(values, expected)
+	case OperatorCIDR:
+		for _, value := range values {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				continue
+			}
+			for _, network := range r.networks[i] {
+				if network.Contains(ip) {
+					return true
+				}
+			}
+		}
+		return false
+	case OperatorGte, OperatorLte:
+		if len(values) != 1 || len(expected) != 1 {
+			return false
+		}
+		actual, err := strconv.Atoi(values[0])
+		if err != nil {
+			return false
+		}
+		limit, err := strconv.Atoi(expected[0])
+		if err != nil {
+			return false
+		}
+		if condition.Operator == OperatorGte {
+			return actual >= limit
+		}
+		return actual <= limit
+	default:
+		return false
+	}
+}
+
+// attribute 读取请求属性，空值返回nil
+func attribute(req *Request, name string) []string {
+	scope, key, _ := strings.Cut(name, ".")
+
+	var value string
+	switch scope {
+	case "subject":
+		switch key {
+		case "id":
+			value = req.Subject.ID
+		case "type":
+			value = req.Subject.Type
+		case "department":
+			value = req.Subject.Department
+		case "roles":
+			return req.Subject.Roles
+		case "auth_methods":
+			return req.Subject.AuthMethods
+		}
+	case "resource":
+		switch key {
+		case "type":
+			value = req.Resource.Type
+		case "id":
+			value = req.Resource.ID
+		case "owner":
+			value = req.Resource.Owner
+		case "category":
+			value = req.Resource.Category
+		case "status":
+			value = req.Resource.Status
+		default:
+			value = req.Resource.Attributes[key]
+		}
+	case "environment":
+		switch key {
+		case "ip":
+			value = req.Environment.IP
+		case "hour":
+			value = strconv.Itoa(req.Environment.Time.Hour())
+		case "weekday":
+			value = strconv.Itoa(int(req.Environment.Time.Weekday()))
+		}
+	}
+
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+// validAttribute 属性名是否有效，resource下允许任意属性名
+func validAttribute(name string) bool {
+	scope, key, ok := strings.Cut(name, ".")
+	if !ok || key == "" {
+		return false
+	}
+
+	switch scope {
+	case "subject":
+		switch key {
+		case "id", "type", "department", "roles", "auth_methods":
+			return true
+		}
+	case "resource":
+		return true
+	case "environment":
+		switch key {
+		case "ip", "hour", "weekday":
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny 判断值是否匹配列表中的任一项或通配符
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == Wildcard || pattern == value {
+			return true
+		}
+	}
+	return false
+}
+
+// intersects 两个列表是否有相同的值
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestEngine 创建策略引擎，规则非法时终止测试
+func newTestEngine(t *testing.T, rules ...Rule) *Engine {
+	t.Helper()
+
+	engine, err := NewEngine(rules)
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	return engine
+}
+
+// testRequest 研发部用户在工作日上午从内网读取自己的文档
+func testRequest() *Request {
+	return &Request{
+		Subject: Subject{
+			ID:          "user-1",
+			Type:        "user",
+			Roles:       []string{"editor", "viewer"},
+			Department:  "rd",
+			AuthMethods: []string{"password", "totp"},
+		},
+		Resource: Resource{
+			Type:       "document",
+			ID:         "doc-1",
+			Owner:      "user-1",
+			Category:   "internal",
+			Status:     "published",
+			Attributes: map[string]string{"level": "3"},
+		},
+		Action: "read",
+		Environment: Environment{
+			Time: time.Date(2024, 1, 8, 10, 30, 0, 0, time.UTC), // 周一
+			IP:   "10.1.2.3",
+		},
+	}
+}
+
+func TestEvaluateDenyOverridesAllow(t *testing.T) {
+	allowAll := Rule{Name: "allow-all", Effect: EffectAllow, Resources: []string{Wildcard}, Actions: []string{Wildcard}}
+	allowRead := Rule{Name: "allow-read", Effect: EffectAllow, Resources: []string{"document"}, Actions: []string{"read"}}
+	denyDraft := Rule{
+		Name: "deny-draft", Effect: EffectDeny, Resources: []string{"document"}, Actions: []string{Wildcard},
+		Conditions: []Condition{{Attribute: "resource.status", Operator: OperatorEq, Values: []string{"draft"}}},
+	}
+	denyWrite := Rule{Name: "deny-write", Effect: EffectDeny, Resources: []string{Wildcard}, Actions: []string{"write"}}
+
+	draft := testRequest()
+	draft.Resource.Status = "draft"
+
+	write := testRequest()
+	write.Action = "write"
+
+	other := testRequest()
+	other.Resource.Type = "report"
+
+	tests := []struct {
+		name  string
+		rules []Rule
+		req   *Request
+		want  Decision
+	}{
+		{"允许规则在前，拒绝规则仍然优先", []Rule{allowAll, denyDraft}, draft, Decision{EffectDeny, "deny-draft"}},
+		{"拒绝规则在前", []Rule{denyDraft, allowAll}, draft, Decision{EffectDeny, "deny-draft"}},
+		{"拒绝规则条件不成立", []Rule{allowAll, denyDraft}, testRequest(), Decision{EffectAllow, "allow-all"}},
+		{"多条允许规则取第一条", []Rule{allowRead, allowAll}, testRequest(), Decision{EffectAllow, "allow-read"}},
+		{"拒绝规则按操作匹配", []Rule{allowAll, denyWrite}, write, Decision{EffectDeny, "deny-write"}},
+		{"资源类型不匹配", []Rule{allowRead}, other, Decision{}},
+		{"没有规则", nil, testRequest(), Decision{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTestEngine(t, tt.rules...).Evaluate(tt.req); got != tt.want {
+				t.Fatalf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditions(t *testing.T) {
+	anonymous := func(req *Request) { req.Subject.ID = "" }
+	orphan := func(req *Request) { req.Resource.Owner = "" }
+	noDepartment := func(req *Request) { req.Subject.Department = "" }
+	noIP := func(req *Request) { req.Environment.IP = "" }
+	badIP := func(req *Request) { req.Environment.IP = "not-an-ip" }
+	ipv6 := func(req *Request) { req.Environment.IP = "fd00::1" }
+	noLevel := func(req *Request) { delete(req.Resource.Attributes, "level") }
+	textLevel := func(req *Request) { req.Resource.Attributes["level"] = "high" }
+	sunday := func(req *Request) { req.Environment.Time = time.Date(2024, 1, 7, 23, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		condition Condition
+		modify    func(*Request)
+		want      bool
+	}{
+		{"eq 匹配", Condition{Attribute: "subject.department", Operator: OperatorEq, Values: []string{"rd"}}, nil, true},
+		{"eq 多个候选值", Condition{Attribute: "subject.department", Operator: OperatorEq, Values: []string{"hr", "rd"}}, nil, true},
+		{"eq 不匹配", Condition{Attribute: "subject.department", Operator: OperatorEq, Values: []string{"hr"}}, nil, false},
+		{"eq 大小写敏感", Condition{Attribute: "subject.department", Operator: OperatorEq, Values: []string{"RD"}}, nil, false},
+		{"in 多值属性", Condition{Attribute: "subject.roles", Operator: OperatorIn, Values: []string{"admin", "editor"}}, nil, true},
+		{"ne 匹配", Condition{Attribute: "subject.department", Operator: OperatorNe, Values: []string{"hr"}}, nil, true},
+		{"not_in 多值属性", Condition{Attribute: "subject.roles", Operator: OperatorNotIn, Values: []string{"viewer"}}, nil, false},
+		{"auth_methods 包含totp", Condition{Attribute: "subject.auth_methods", Operator: OperatorIn, Values: []string{"totp"}}, nil, true},
+
+		{"属性缺失 eq 不成立", Condition{Attribute: "subject.department", Operator: OperatorEq, Values: []string{"rd"}}, noDepartment, false},
+		{"属性缺失 ne 成立", Condition{Attribute: "subject.department", Operator: OperatorNe, Values: []string{"rd"}}, noDepartment, true},
+		{"属性缺失 not_in 成立", Condition{Attribute: "subject.department", Operator: OperatorNotIn, Values: []string{"rd"}}, noDepartment, true},
+		{"属性缺失 cidr 不成立", Condition{Attribute: "environment.ip", Operator: OperatorCIDR, Values: []string{"0.0.0.0/0"}}, noIP, false},
+		{"属性缺失 gte 不成立", Condition{Attribute: "resource.level", Operator: OperatorGte, Values: []string{"0"}}, noLevel, false},
+		{"属性缺失 lte 不成立", Condition{Attribute: "resource.level", Operator: OperatorLte, Values: []string{"9"}}, noLevel, false},
+
+		{"ref 所有者匹配", Condition{Attribute: "subject.id", Operator: OperatorEq, Ref: "resource.owner"}, nil, true},
+		{"ref 资源无所有者 eq 不成立", Condition{Attribute: "subject.id", Operator: OperatorEq, Ref: "resource.owner"}, orphan, false},
+		{"ref 主体与所有者均为空 eq 不成立", Condition{Attribute: "subject.id", Operator: OperatorEq, Ref: "resource.owner"},
+			func(req *Request) { anonymous(req); orphan(req) }, false},
+		{"ref 资源无所有者 ne 成立", Condition{Attribute: "subject.id", Operator: OperatorNe, Ref: "resource.owner"}, orphan, true},
+		{"ref 主体为空 eq 不成立", Condition{Attribute: "subject.id", Operator: OperatorEq, Ref: "resource.owner"}, anonymous, false},
+
+		{"cidr 匹配", Condition{Attribute: "environment.ip", Operator: OperatorCIDR, Values: []string{"192.168.0.0/16", "10.0.0.0/8"}}, nil, true},
+		{"cidr 不匹配", Condition{Attribute: "environment.ip", Operator: OperatorCIDR, Values: []string{"192.168.0.0/16"}}, nil, false},
+		{"cidr 非法IP", Condition{Attribute: "environment.ip", Operator: OperatorCIDR, Values: []string{"0.0.0.0/0"}}, badIP, false},
+		{"cidr IPv6", Condition{Attribute: "environment.ip", Operator: OperatorCIDR, Values: []string{"fd00::/8"}}, ipv6, true},
+		{"cidr IPv6不属于IPv4网段", Condition{Attribute: "environment.ip", Operator: OperatorCIDR, Values: []string{"0.0.0.0/0"}}, ipv6, false},
+
+		{"gte 等于边界", Condition{Attribute: "resource.level", Operator: OperatorGte, Values: []string{"3"}}, nil, true},
+		{"gte 小于边界", Condition{Attribute: "resource.level", Operator: OperatorGte, Values: []string{"4"}}, nil, false},
+		{"lte 等于边界", Condition{Attribute: "resource.level", Operator: OperatorLte, Values: []string{"3"}}, nil, true},
+		{"lte 大于边界", Condition{Attribute: "resource.level", Operator: OperatorLte, Values: []string{"2"}}, nil, false},
+		{"gte 属性不是数字", Condition{Attribute: "resource.level", Operator: OperatorGte, Values: []string{"0"}}, textLevel, false},
+		{"gte 多值属性", Condition{Attribute: "subject.roles", Operator: OperatorGte, Values: []string{"0"}}, nil, false},
+		{"hour 工作时间", Condition{Attribute: "environment.hour", Operator: OperatorGte, Values: []string{"9"}}, nil, true},
+		{"hour 午夜为0", Condition{Attribute: "environment.hour", Operator: OperatorLte, Values: []string{"0"}},
+			func(req *Request) { req.Environment.Time = time.Date(2024, 1, 8, 0, 15, 0, 0, time.UTC) }, true},
+		{"weekday 周日为0", Condition{Attribute: "environment.weekday", Operator: OperatorEq, Values: []string{"0"}}, sunday, true},
+		{"weekday 周一", Condition{Attribute: "environment.weekday", Operator: OperatorNotIn, Values: []string{"0", "6"}}, nil, true},
+
+		{"resource 自定义属性", Condition{Attribute: "resource.level", Operator: OperatorEq, Values: []string{"3"}}, nil, true},
+		{"resource 未设置的自定义属性", Condition{Attribute: "resource.region", Operator: OperatorEq, Values: []string{"cn"}}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newTestEngine(t, Rule{
+				Name:       "rule",
+				Effect:     EffectAllow,
+				Resources:  []string{"document"},
+				Actions:    []string{"read"},
+				Conditions: []Condition{tt.condition},
+			})
+
+			req := testRequest()
+			if tt.modify != nil {
+				tt.modify(req)
+			}
+
+			if got := engine.Evaluate(req).Effect == EffectAllow; got != tt.want {
+				t.Fatalf("条件成立 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEngineRejectsInvalidRules(t *testing.T) {
+	rule := func(conditions ...Condition) Rule {
+		return Rule{Name: "rule", Effect: EffectAllow, Resources: []string{"document"}, Actions: []string{"read"}, Conditions: conditions}
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"未知效果", Rule{Name: "rule", Effect: "maybe", Resources: []string{"document"}, Actions: []string{"read"}}},
+		{"缺少资源类型", Rule{Name: "rule", Effect: EffectAllow, Actions: []string{"read"}}},
+		{"缺少操作", Rule{Name: "rule", Effect: EffectAllow, Resources: []string{"document"}}},
+		{"未知属性", rule(Condition{Attribute: "subject.email", Operator: OperatorEq, Values: []string{"a"}})},
+		{"属性缺少作用域", rule(Condition{Attribute: "department", Operator: OperatorEq, Values: []string{"rd"}})},
+		{"属性名为空", rule(Condition{Attribute: "resource.", Operator: OperatorEq, Values: []string{"a"}})},
+		{"未知引用属性", rule(Condition{Attribute: "subject.id", Operator: OperatorEq, Ref: "environment.user"})},
+		{"缺少值和引用", rule(Condition{Attribute: "subject.id", Operator: OperatorEq})},
+		{"未知运算符", rule(Condition{Attribute: "subject.id", Operator: "like", Values: []string{"a"}})},
+		{"非法网段", rule(Condition{Attribute: "environment.ip", Operator: OperatorCIDR, Values: []string{"10.0.0.1"}})},
+		{"gte 多个值", rule(Condition{Attribute: "environment.hour", Operator: OperatorGte, Values: []string{"9", "10"}})},
+		{"lte 非整数", rule(Condition{Attribute: "environment.hour", Operator: OperatorLte, Values: []string{"18.5"}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEngine([]Rule{tt.rule}); err == nil {
+				t.Fatal("NewEngine() 应返回错误")
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package rbac
+
+import "strings"
+
+// Wildcard 匹配任意资源或操作
+const Wildcard = "*"
+
+// Permission 拼接权限字符串 resource:action
+func Permission(resource, action string) string {
+	return resource + ":" + action
+}
+
+// Parse 拆分权限字符串，格式不是 resource:action 时返回false
+func Parse(permission string) (resource, action string, ok bool) {
+	i := strings.LastIndexByte(permission, ':')
+	if i <= 0 || i == len(permission)-1 {
+		return "", "", false
+	}
+	return permission[:i], permission[i+1:], true
+}
+
+// Implies 判断已授予的权限是否包含所需权限
+// 资源或操作为 * 时匹配任意值，资源 knowledge 包含其子资源 knowledge.doc；
+// required 也可以是通配符权限，此时granted须覆盖其全部范围，如 user:* 不被 user:READ 包含
+func Implies(granted, required string) bool {
+	grantedResource, grantedAction, ok := Parse(granted)
+	if !ok {
+		return false
+	}
+	requiredResource, requiredAction, ok := Parse(required)
+	if !ok {
+		return false
+	}
+
+	return resourceImplies(grantedResource, requiredResource) && actionImplies(grantedAction, requiredAction)
+}
+
+// Covered 判断权限列表中是否有权限包含所需权限
+func Covered(granted []string, required string) bool {
+	for _, permission := range granted {
+		if Implies(permission, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed 判断权限列表是否允许对资源执行操作
+func Allowed(granted []string, resource, action string) bool {
+	return Covered(granted, Permission(resource, action))
+}
+
+// Intersect 计算两个权限列表同时包含的权限，结果同样可以用Covered判断
+// 如 user:* 与 *:READ 的交集为 user:READ，knowledge:READ 与 knowledge.doc:* 的交集为 knowledge.doc:READ
+func Intersect(a, b []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(a))
+
+	for _, x := range a {
+		for _, y := range b {
+			permission, ok := meet(x, y)
+			if ok && !seen[permission] {
+				seen[permission] = true
+				result = append(result, permission)
+			}
+		}
+	}
+	return result
+}
+
+// meet 两个权限共同包含的最大权限，没有交集时返回false
+func meet(a, b string) (string, bool) {
+	aResource, aAction, ok := Parse(a)
+	if !ok {
+		return "", false
+	}
+	bResource, bAction, ok := Parse(b)
+	if !ok {
+		return "", false
+	}
+
+	var resource, action string
+	switch {
+	case resourceImplies(aResource, bResource):
+		resource = bResource
+	case resourceImplies(bResource, aResource):
+		resource = aResource
+	default:
+		return "", false
+	}
+
+	switch {
+	case actionImplies(aAction, bAction):
+		action = bAction
+	case actionImplies(bAction, aAction):
+		action = aAction
+	default:
+		return "", false
+	}
+
+	return Permission(resource, action), true
+}
+
+// resourceImplies 资源granted是否包含资源required，父资源包含以点分隔的子资源
+func resourceImplies(granted, required string) bool {
+	if granted == Wildcard || granted == required {
+		return true
+	}
+	return required != Wildcard && strings.HasPrefix(required, granted+".")
+}
+
+// actionImplies 操作granted是否包含操作required
+func actionImplies(granted, required string) bool {
+	return granted == Wildcard || granted == required
+}
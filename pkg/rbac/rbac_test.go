@@ -0,0 +1,127 @@
+package rbac
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		permission   string
+		wantResource string
+		wantAction   string
+		wantOK       bool
+	}{
+		{"user:READ", "user", "READ", true},
+		{"*:*", "*", "*", true},
+		{"knowledge.doc:WRITE", "knowledge.doc", "WRITE", true},
+		{"a:b:READ", "a:b", "READ", true},
+		{"user", "", "", false},
+		{":READ", "", "", false},
+		{"user:", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		resource, action, ok := Parse(tt.permission)
+		if resource != tt.wantResource || action != tt.wantAction || ok != tt.wantOK {
+			t.Errorf("Parse(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.permission, resource, action, ok, tt.wantResource, tt.wantAction, tt.wantOK)
+		}
+	}
+}
+
+func TestImplies(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  string
+		required string
+		want     bool
+	}{
+		{"精确匹配", "user:READ", "user:READ", true},
+		{"操作不同", "user:READ", "user:WRITE", false},
+		{"资源不同", "user:READ", "role:READ", false},
+		{"操作区分大小写", "user:READ", "user:read", false},
+
+		{"资源通配", "user:*", "user:DELETE", true},
+		{"资源通配不跨资源", "user:*", "role:READ", false},
+		{"操作通配", "*:READ", "role:READ", true},
+		{"操作通配不跨操作", "*:READ", "role:WRITE", false},
+		{"全局通配", "*:*", "anything:ANY", true},
+		{"单个星号不是合法权限", "*", "user:READ", false},
+
+		{"父资源包含子资源", "knowledge:READ", "knowledge.doc:READ", true},
+		{"父资源包含多级子资源", "knowledge:*", "knowledge.doc.page:WRITE", true},
+		{"子资源不包含父资源", "knowledge.doc:READ", "knowledge:READ", false},
+		{"相同前缀不是子资源", "knowledge:READ", "knowledgebase:READ", false},
+
+		{"所需权限为通配：被全局通配包含", "*:*", "user:*", true},
+		{"所需权限为通配：被同资源通配包含", "user:*", "user:*", true},
+		{"所需权限为通配：不被具体操作包含", "user:READ", "user:*", false},
+		{"所需资源为通配：不被具体资源包含", "user:READ", "*:READ", false},
+		{"所需资源为通配：不被父资源包含", "knowledge:*", "*:READ", false},
+
+		{"非法的已授予权限", "user", "user:READ", false},
+		{"非法的所需权限", "user:READ", "user", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Implies(tt.granted, tt.required); got != tt.want {
+				t.Fatalf("Implies(%q, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	granted := []string{"user:READ", "knowledge:*"}
+
+	tests := []struct {
+		resource string
+		action   string
+		want     bool
+	}{
+		{"user", "READ", true},
+		{"user", "WRITE", false},
+		{"knowledge.doc", "DELETE", true},
+		{"role", "READ", false},
+	}
+
+	for _, tt := range tests {
+		if got := Allowed(granted, tt.resource, tt.action); got != tt.want {
+			t.Errorf("Allowed(%s, %s) = %v, want %v", tt.resource, tt.action, got, tt.want)
+		}
+	}
+
+	if Allowed(nil, "user", "READ") {
+		t.Error("空权限列表不应允许任何操作")
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []string
+		b    []string
+		want []string
+	}{
+		{"资源通配与操作通配", []string{"user:*"}, []string{"*:READ"}, []string{"user:READ"}},
+		{"父资源与子资源", []string{"knowledge:READ"}, []string{"knowledge.doc:*"}, []string{"knowledge.doc:READ"}},
+		{"全局通配", []string{"*:*"}, []string{"user:READ", "role:WRITE"}, []string{"user:READ", "role:WRITE"}},
+		{"精确匹配", []string{"user:READ"}, []string{"user:READ"}, []string{"user:READ"}},
+		{"操作不同", []string{"user:READ"}, []string{"user:WRITE"}, []string{}},
+		{"资源不同", []string{"user:*"}, []string{"role:*"}, []string{}},
+		{"去重", []string{"user:*", "*:READ"}, []string{"user:READ"}, []string{"user:READ"}},
+		{"忽略非法权限", []string{"user"}, []string{"*:*"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Intersect(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Intersect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ type Response struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"` // 结构化的错误明细，如密码违反的策略项
 }
 
 // Success 成功响应
@@ -37,6 +38,16 @@ func Error(c *gin.Context, httpStatus int, message, errorDetail string) {
 	c.JSON(httpStatus, response)
 }
 
+// ErrorWithDetails 附带结构化明细的错误响应
+func ErrorWithDetails(c *gin.Context, httpStatus int, message, errorDetail string, details interface{}) {
+	c.JSON(httpStatus, Response{
+		Code:    httpStatus,
+		Message: message,
+		Error:   errorDetail,
+		Details: details,
+	})
+}
+
 // BadRequest 400错误
 func BadRequest(c *gin.Context, message string) {
 	Error(c, http.StatusBadRequest, message, "")
@@ -0,0 +1,77 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"authcenter/pkg/logger"
+)
+
+// SMSSender 短信发送接口，接入短信服务商时实现该接口
+type SMSSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// logSender 将短信内容写入日志，仅用于本地开发
+type logSender struct{}
+
+// NewLogSender 创建日志短信发送器
+func NewLogSender() SMSSender {
+	return &logSender{}
+}
+
+// Send 将短信内容写入日志
+func (s *logSender) Send(ctx context.Context, phone, message string) error {
+	logger.Info("[SMS] 发送至 %s: %s", phone, message)
+	return nil
+}
+
+// fileSender 将短信按行追加到文件，便于本地开发和测试读取验证码
+type fileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// outboxMessage 文件中的一条短信
+type outboxMessage struct {
+	Phone   string    `json:"phone"`
+	Message string    `json:"message"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// NewFileSender 创建文件短信发送器
+func NewFileSender(path string) SMSSender {
+	return &fileSender{path: path}
+}
+
+// Send 将短信以JSON行的形式追加到文件
+func (s *fileSender) Send(ctx context.Context, phone, message string) error {
+	line, err := json.Marshal(outboxMessage{
+		Phone:   phone,
+		Message: message,
+		SentAt:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,125 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 6238 附录B SHA1测试密钥 "12345678901234567890" 的Base32编码
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 附录B的测试向量，取8位验证码的后6位
+func TestGenerateCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := GenerateCode(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSkewWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := counterAt(now)
+
+	code := func(offset time.Duration) string {
+		c, err := GenerateCode(rfcSecret, now.Add(offset))
+		if err != nil {
+			t.Fatalf("GenerateCode: %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name        string
+		code        string
+		secret      string
+		wantCounter int64
+		wantOK      bool
+	}{
+		{"当前时间步", code(0), rfcSecret, current, true},
+		{"前一个时间步", code(-Period), rfcSecret, current - 1, true},
+		{"后一个时间步", code(Period), rfcSecret, current + 1, true},
+		{"超出偏移窗口（过去）", code(-2 * Period), rfcSecret, 0, false},
+		{"超出偏移窗口（未来）", code(2 * Period), rfcSecret, 0, false},
+		{"前后空白", " " + code(0) + " ", rfcSecret, current, true},
+		{"小写和空格的密钥", code(0), strings.ToLower(rfcSecret[:8] + " " + rfcSecret[8:]), current, true},
+		{"位数不足", code(0)[:Digits-1], rfcSecret, 0, false},
+		{"位数过多", code(0) + "0", rfcSecret, 0, false},
+		{"空验证码", "", rfcSecret, 0, false},
+		{"非法密钥", code(0), "not-base32!", 0, false},
+		{"空密钥", code(0), "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, ok := Validate(tt.code, tt.secret, now)
+			if ok != tt.wantOK || counter != tt.wantCounter {
+				t.Fatalf("Validate() = (%d, %v), want (%d, %v)", counter, ok, tt.wantCounter, tt.wantOK)
+			}
+		})
+	}
+}
+
+// 重放的验证码在偏移窗口内始终对应同一时间步，调用方据此拒绝小于等于已使用时间步的验证码
+func TestValidateReplayedCounter(t *testing.T) {
+	issuedAt := time.Unix(1234567890, 0)
+	code, err := GenerateCode(rfcSecret, issuedAt)
+	if err != nil {
+		t.Fatalf("GenerateCode: %v", err)
+	}
+
+	used, ok := Validate(code, rfcSecret, issuedAt)
+	if !ok {
+		t.Fatal("首次校验失败")
+	}
+
+	for _, later := range []time.Duration{time.Second, Period, Period + Period/2} {
+		counter, ok := Validate(code, rfcSecret, issuedAt.Add(later))
+		if !ok {
+			t.Fatalf("%v后重放的验证码未在偏移窗口内匹配", later)
+		}
+		if counter > used {
+			t.Fatalf("%v后重放的验证码匹配到时间步%d，大于已使用的%d", later, counter, used)
+		}
+	}
+
+	next, err := GenerateCode(rfcSecret, issuedAt.Add(Period))
+	if err != nil {
+		t.Fatalf("GenerateCode: %v", err)
+	}
+	if counter, ok := Validate(next, rfcSecret, issuedAt.Add(Period)); !ok || counter <= used {
+		t.Fatalf("下一时间步的验证码应匹配更大的时间步，got (%d, %v)", counter, ok)
+	}
+}
+
+func TestGenerateSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+
+	key, err := decodeSecret(secret)
+	if err != nil {
+		t.Fatalf("decodeSecret: %v", err)
+	}
+	if len(key) != SecretSize {
+		t.Fatalf("密钥长度为%d，期望%d", len(key), SecretSize)
+	}
+}
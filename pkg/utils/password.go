@@ -1,21 +0,0 @@
-package utils
-
-import (
-	"golang.org/x/crypto/bcrypt"
-)
-
-// HashPassword 密码加密
-func HashPassword(password string) (string, error) {
-	const cost = 12 // bcrypt cost factor, 根据需求文档要求 >= 12
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedBytes), nil
-}
-
-// CheckPassword 验证密码
-func CheckPassword(password, hashedPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
-}
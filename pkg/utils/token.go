@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateRandomToken 生成指定字节长度的随机Token（Base64URL编码）
+func GenerateRandomToken(byteLength int) (string, error) {
+	buf := make([]byte, byteLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken 计算Token的SHA-256摘要，用于存储高熵随机Token
+// 低熵的用户密码请使用password.Manager
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckToken 以常量时间比较Token与摘要
+func CheckToken(token, hashedToken string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(hashedToken)) == 1
+}
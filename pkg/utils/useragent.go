@@ -0,0 +1,30 @@
+package utils
+
+import "strings"
+
+// 设备类型
+const (
+	DeviceTypeWeb    = "web"
+	DeviceTypeMobile = "mobile"
+	DeviceTypeAPI    = "api"
+)
+
+// mobileMarkers User-Agent中表示移动设备的关键字
+var mobileMarkers = []string{"mobile", "android", "iphone", "ipad", "ipod", "windows phone", "okhttp", "cfnetwork", "dalvik"}
+
+// ParseDeviceType 根据User-Agent粗略判断设备类型：移动设备和移动应用为mobile，浏览器为web，其余为api
+func ParseDeviceType(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	for _, marker := range mobileMarkers {
+		if strings.Contains(ua, marker) {
+			return DeviceTypeMobile
+		}
+	}
+
+	if strings.HasPrefix(ua, "mozilla/") || strings.HasPrefix(ua, "opera/") {
+		return DeviceTypeWeb
+	}
+
+	return DeviceTypeAPI
+}
@@ -42,6 +42,9 @@ db.permissions.insertMany([
   { name: "TAG_CREATE", resource: "tag", action: "CREATE", description: "创建标签（灵活标记）", category: "content_organization", created_at: new Date() },
   { name: "TAG_MANAGE", resource: "tag", action: "MANAGE", description: "标签管理（编辑、删除）", category: "content_organization", created_at: new Date() },
   { name: "SYSTEM_CONFIG", resource: "system", action: "CONFIG", description: "系统配置", category: "system_management", created_at: new Date() },
+  { name: "ELEVATION_APPROVE", resource: "elevation", action: "APPROVE", description: "审批临时提权申请", category: "system_management", created_at: new Date() },
+  { name: "TENANT_MANAGE", resource: "tenant", action: "MANAGE", description: "租户管理", category: "system_management", created_at: new Date() },
+  { name: "GROUP_MANAGE", resource: "group", action: "MANAGE", description: "用户组管理", category: "system_management", created_at: new Date() },
   
   // 交互功能权限
   { name: "COMMENT", resource: "knowledge", action: "COMMENT", description: "评论文档", category: "interaction", created_at: new Date() },
@@ -68,8 +71,8 @@ var rolePermissions = {
   "Admin": [
     "KNOWLEDGE_READ", "KNOWLEDGE_CREATE", "KNOWLEDGE_UPDATE", "KNOWLEDGE_DELETE", 
     "KNOWLEDGE_PUBLISH", "KNOWLEDGE_APPROVE", "USER_MANAGE", "ROLE_MANAGE", 
-    "CATEGORY_MANAGE", "TAG_CREATE", "TAG_MANAGE", "SYSTEM_CONFIG", 
-    "COMMENT", "FAVORITE", "SEARCH", "AI_ASSISTANT"
+    "CATEGORY_MANAGE", "TAG_CREATE", "TAG_MANAGE", "SYSTEM_CONFIG", "ELEVATION_APPROVE",
+    "TENANT_MANAGE", "GROUP_MANAGE", "COMMENT", "FAVORITE", "SEARCH", "AI_ASSISTANT"
   ],
   "Editor": [
     "KNOWLEDGE_READ", "KNOWLEDGE_CREATE", "KNOWLEDGE_UPDATE", "KNOWLEDGE_DELETE", 
@@ -93,6 +96,7 @@ db.roles.insertMany([
     description: "拥有最高权限，可管理所有系统功能",
     level: 4,
     status: "active",
+    require_mfa: false,
     permissions: rolePermissions["Admin"].map(function(name) {
       return {
         permission_id: permMap[name]._id,
@@ -110,6 +114,7 @@ db.roles.insertMany([
     description: "负责知识库内容的全面管理",
     level: 3,
     status: "active",
+    require_mfa: false,
     permissions: rolePermissions["Editor"].map(function(name) {
       return {
         permission_id: permMap[name]._id,
@@ -127,6 +132,7 @@ db.roles.insertMany([
     description: "专注于知识库内容的创作和编辑",
     level: 2,
     status: "active",
+    require_mfa: false,
     permissions: rolePermissions["Author"].map(function(name) {
       return {
         permission_id: permMap[name]._id,
@@ -144,6 +150,7 @@ db.roles.insertMany([
     description: "知识库的日常使用者",
     level: 1,
     status: "active",
+    require_mfa: false,
     permissions: rolePermissions["User"].map(function(name) {
       return {
         permission_id: permMap[name]._id,
@@ -124,6 +124,30 @@ async function initializeDB() {
           category: "system_management",
           created_at: new Date()
         },
+        { 
+          name: "ELEVATION_APPROVE", 
+          resource: "elevation", 
+          action: "APPROVE", 
+          description: "审批临时提权申请", 
+          category: "system_management",
+          created_at: new Date()
+        },
+        { 
+          name: "TENANT_MANAGE", 
+          resource: "tenant", 
+          action: "MANAGE", 
+          description: "租户管理", 
+          category: "system_management",
+          created_at: new Date()
+        },
+        { 
+          name: "GROUP_MANAGE", 
+          resource: "group", 
+          action: "MANAGE", 
+          description: "用户组管理", 
+          category: "system_management",
+          created_at: new Date()
+        },
         
         // 交互功能权限
         { 
@@ -177,8 +201,8 @@ async function initializeDB() {
       "Admin": [
         "KNOWLEDGE_READ", "KNOWLEDGE_CREATE", "KNOWLEDGE_UPDATE", "KNOWLEDGE_DELETE", 
         "KNOWLEDGE_PUBLISH", "KNOWLEDGE_APPROVE", "USER_MANAGE", "ROLE_MANAGE", 
-        "CATEGORY_MANAGE", "TAG_CREATE", "TAG_MANAGE", "SYSTEM_CONFIG", 
-        "COMMENT", "FAVORITE", "SEARCH", "AI_ASSISTANT"
+        "CATEGORY_MANAGE", "TAG_CREATE", "TAG_MANAGE", "SYSTEM_CONFIG", "ELEVATION_APPROVE",
+        "TENANT_MANAGE", "GROUP_MANAGE", "COMMENT", "FAVORITE", "SEARCH", "AI_ASSISTANT"
       ],
       "Editor": [
         "KNOWLEDGE_READ", "KNOWLEDGE_CREATE", "KNOWLEDGE_UPDATE", "KNOWLEDGE_DELETE", 
@@ -209,6 +233,7 @@ async function initializeDB() {
           description: "拥有最高权限，可管理所有系统功能",
           level: 4,
           status: "active",
+          require_mfa: false,
           permissions: rolePermissions["Admin"].map((name) => {
             return {
               permission_id: permMap[name]._id,
@@ -226,6 +251,7 @@ async function initializeDB() {
           description: "负责知识库内容的全面管理",
           level: 3,
           status: "active",
+          require_mfa: false,
           permissions: rolePermissions["Editor"].map((name) => {
             return {
               permission_id: permMap[name]._id,
@@ -243,6 +269,7 @@ async function initializeDB() {
           description: "专注于知识库内容的创作和编辑",
           level: 2,
           status: "active",
+          require_mfa: false,
           permissions: rolePermissions["Author"].map((name) => {
             return {
               permission_id: permMap[name]._id,
@@ -260,6 +287,7 @@ async function initializeDB() {
           description: "知识库的日常使用者",
           level: 1,
           status: "active",
+          require_mfa: false,
           permissions: rolePermissions["User"].map((name) => {
             return {
               permission_id: permMap[name]._id,
@@ -409,10 +437,11 @@ async function initializeDB() {
     await db.collection('users').createIndex({ "email": 1 }, { unique: true, sparse: true });
     await db.collection('users').createIndex({ "phone": 1 }, { unique: true, sparse: true });
     await db.collection('users').createIndex({ "roles.role_id": 1 });
+    await db.collection('users').createIndex({ "tenants.tenant_id": 1 });
     await db.collection('users').createIndex({ "status": 1 });
     
     // 角色索引
-    await db.collection('roles').createIndex({ "name": 1 }, { unique: true });
+    await db.collection('roles').createIndex({ "tenant_id": 1, "name": 1 }, { unique: true });
     await db.collection('roles').createIndex({ "level": 1 });
     await db.collection('roles').createIndex({ "permissions.name": 1 });
     
@@ -428,11 +457,20 @@ async function initializeDB() {
     await db.collection('categories').createIndex({ "status": 1 });
     
     // 标签索引
-    await db.collection('tags').createIndex({ "name": 1 }, { unique: true });
+    await db.collection('tags').createIndex({ "tenant_id": 1, "name": 1 }, { unique: true });
     await db.collection('tags').createIndex({ "created_by": 1 });
     await db.collection('tags').createIndex({ "usage_count": -1 });
     await db.collection('tags').createIndex({ "last_used_at": -1 });
     
+    // 租户索引
+    await db.collection('tenants').createIndex({ "name": 1 }, { unique: true });
+    
+    // 用户组索引
+    await db.collection('groups').createIndex({ "tenant_id": 1, "name": 1 }, { unique: true });
+    await db.collection('groups').createIndex({ "members": 1 });
+    await db.collection('groups').createIndex({ "type": 1, "department": 1, "position": 1 });
+    await db.collection('groups').createIndex({ "roles.role_id": 1 });
+    
     // 会话索引
     await db.collection('sessions').createIndex({ "session_id": 1 }, { unique: true });
     await db.collection('sessions').createIndex({ "user_id": 1 });
@@ -260,6 +260,32 @@ async function apiRequest(endpoint, method = 'GET', data = null, withAuth = true
 }
 
 // 认证API
+// 保存登录第二步返回的Token，仍需完成其他挑战时不保存
+function saveLoginTokens(response) {
+    if (response.status !== 200 || !response.data || !response.data.data) {
+        return;
+    }
+
+    const tokenData = response.data.data;
+    if (!tokenData.access_token) {
+        return;
+    }
+
+    accessToken = tokenData.access_token;
+    refreshToken = tokenData.refresh_token;
+    userId = tokenData.user_id;
+
+    if (isLocalStorageSupported) {
+        localStorage.setItem('access_token', accessToken);
+        localStorage.setItem('refresh_token', refreshToken);
+        localStorage.setItem('user_id', userId);
+    }
+    sessionStorage.setItem('access_token', accessToken);
+    sessionStorage.setItem('refresh_token', refreshToken);
+    sessionStorage.setItem('user_id', userId);
+    checkToken();
+}
+
 const authAPI = {
     // 注册
     register: (username, email, password) => {
@@ -351,6 +377,10 @@ const authAPI = {
                 
                 console.log('登录成功，Token已保存');
                 checkToken();
+            } else if (tokenData && tokenData.mfa_required) {
+                console.log('需要多因素认证');
+            } else if (tokenData && tokenData.password_change_required) {
+                console.log('密码已过期，需要修改密码');
             } else {
                 console.error('登录响应格式错误:', response.data);
             }
@@ -359,6 +389,28 @@ const authAPI = {
         return response;
     },
     
+    // 多因素认证登录第二步
+    verifyMFA: async (mfaToken, code) => {
+        const response = await apiRequest('/api/v1/auth/mfa/verify', 'POST', {
+            mfa_token: mfaToken,
+            code
+        }, false);
+
+        saveLoginTokens(response);
+        return response;
+    },
+
+    // 密码过期时修改密码并完成登录
+    changeExpiredPassword: async (passwordChangeToken, newPassword) => {
+        const response = await apiRequest('/api/v1/auth/password/expired', 'POST', {
+            password_change_token: passwordChangeToken,
+            new_password: newPassword
+        }, false);
+
+        saveLoginTokens(response);
+        return response;
+    },
+    
     // 刷新Token
     refreshToken: async () => {
         console.log('=== refreshToken 函数开始执行 ===');
@@ -51,8 +51,35 @@ function initAuthHandlers() {
         const password = document.getElementById('loginPassword').value;
         const type = document.getElementById('loginType').value;
 
-        const response = await authAPI.login(identifier, password, type);
+        let response = await authAPI.login(identifier, password, type);
         document.getElementById('loginResponse').textContent = formatJSON(response);
+
+        // 启用多因素认证的用户需要输入验证器应用中的验证码
+        const loginData = response.data && response.data.data;
+        if (response.status === 200 && loginData && loginData.mfa_required) {
+            const code = prompt('请输入验证器应用中的6位验证码');
+            if (!code) {
+                return;
+            }
+            response = await authAPI.verifyMFA(loginData.mfa_token, code);
+            document.getElementById('loginResponse').textContent = formatJSON(response);
+        }
+
+        // 密码超过最长使用时间时需要先设置新密码
+        const challengeData = response.data && response.data.data;
+        if (response.status === 200 && challengeData && challengeData.password_change_required) {
+            const newPassword = prompt('密码已过期，请输入新密码');
+            if (!newPassword) {
+                return;
+            }
+            response = await authAPI.changeExpiredPassword(challengeData.password_change_token, newPassword);
+            document.getElementById('loginResponse').textContent = formatJSON(response);
+        }
+
+        // OIDC授权流程：登录成功后继续完成授权并跳转回客户端
+        if (response.status === 200) {
+            await continueAuthorization();
+        }
     });
     
     // 刷新Token按钮
@@ -70,6 +97,20 @@ function initAuthHandlers() {
     });
 }
 
+// 继续OIDC授权流程，return_to为登录前的授权地址
+async function continueAuthorization() {
+    const returnTo = new URLSearchParams(window.location.search).get('return_to');
+    if (!returnTo || !returnTo.startsWith('/oauth/authorize')) {
+        return;
+    }
+
+    const params = Object.fromEntries(new URL(returnTo, window.location.origin).searchParams);
+    const response = await apiRequest('/oauth/authorize', 'POST', params);
+    if (response.status === 200 && response.data.data && response.data.data.redirect_to) {
+        window.location.href = response.data.data.redirect_to;
+    }
+}
+
 // 登出
 function logout() {
     authAPI.logout().then(response => {